@@ -0,0 +1,149 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/otelcol"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configconverter"
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+	"github.com/signalfx/splunk-otel-collector/pkg/configsource"
+	"github.com/signalfx/splunk-otel-collector/pkg/distro"
+)
+
+// diffSettings are the settings of the configurations compared by the config diff command.
+type diffSettings struct {
+	factories     otelcol.Factories
+	buildInfo     component.BuildInfo
+	configSources []configsource.Factory
+	converters    []confmap.Converter
+	uris          []string
+	againstURIs   []string
+}
+
+// diffConfigs resolves and converts the configuration of the URIs and the one of the URIs it is
+// compared against, or the last configuration cached for the same URIs if there are none, and
+// returns the changes from the latter to the former, false if there are none.
+func diffConfigs(ctx context.Context, settings diffSettings) (configprovider.ConfigChange, bool, error) {
+	current, provenance, err := resolveConfig(ctx, settings, settings.uris)
+	if err != nil {
+		return configprovider.ConfigChange{}, false, fmt.Errorf("failed to resolve the configuration: %w", err)
+	}
+
+	var previous map[string]any
+	if len(settings.againstURIs) > 0 {
+		if previous, _, err = resolveConfig(ctx, settings, settings.againstURIs); err != nil {
+			return configprovider.ConfigChange{}, false, fmt.Errorf("failed to resolve the configuration compared against: %w", err)
+		}
+	} else {
+		cached, cacheErr := configprovider.CachedConfig(settings.uris)
+		if cacheErr != nil {
+			return configprovider.ConfigChange{}, false, fmt.Errorf("failed to read the cached configuration: %w", cacheErr)
+		}
+		// The cached configuration is resolved but not converted yet.
+		conf := confmap.NewFromStringMap(cached)
+		for _, converter := range settings.converters {
+			if err = converter.Convert(ctx, conf); err != nil {
+				return configprovider.ConfigChange{}, false, fmt.Errorf("failed to convert the cached configuration: %w", err)
+			}
+		}
+		previous = conf.ToStringMap()
+	}
+
+	change, changed := configprovider.DiffConfigs(previous, current, provenance)
+	return configconverter.RedactChange(change), changed, nil
+}
+
+// resolveConfig resolves and converts the configuration of the URIs, without running it, and
+// returns it with the provenance of its keys.
+func resolveConfig(ctx context.Context, settings diffSettings, uris []string) (map[string]any, map[string]configprovider.KeyProvenance, error) {
+	capture := configconverter.NewConfigCapture()
+	provider, err := distro.NewConfigProvider(distro.ConfigProviderSettings{
+		BuildInfo:     settings.buildInfo,
+		URIs:          uris,
+		ConfigSources: settings.configSources,
+		Converters:    append(append([]confmap.Converter(nil), settings.converters...), capture),
+		Hooks:         []distro.Hook{capture},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	_, err = provider.Get(ctx, settings.factories)
+	if shutdownErr := provider.Shutdown(ctx); shutdownErr != nil && err == nil {
+		err = fmt.Errorf("failed to shutdown the config providers: %w", shutdownErr)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return capture.Config(), capture.Provenance(), nil
+}
+
+// writeDiff writes the changes, one key per line prefixed by "+" if it was added, "-" if it was
+// removed, or "~" if it was modified.
+func writeDiff(w io.Writer, change configprovider.ConfigChange, changed bool) error {
+	if !changed {
+		_, err := fmt.Fprintln(w, "No changes.")
+		return err
+	}
+	var lines []string
+	for _, k := range sortedKeys(change.Added) {
+		lines = append(lines, fmt.Sprintf("+ %s: %v", k, change.Added[k]))
+	}
+	for _, k := range sortedKeys(change.Removed) {
+		lines = append(lines, fmt.Sprintf("- %s: %v", k, change.Removed[k]))
+	}
+	modified := make([]string, 0, len(change.Modified))
+	for k := range change.Modified {
+		modified = append(modified, k)
+	}
+	sort.Strings(modified)
+	for _, k := range modified {
+		lines = append(lines, fmt.Sprintf("~ %s: %v -> %v", k, change.Modified[k].Before, change.Modified[k].After))
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeDiffReport writes the JSON report of the changes.
+func writeDiffReport(w io.Writer, change configprovider.ConfigChange, changed bool) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		configprovider.ConfigChange
+		Changed bool `json:"changed"`
+	}{change, changed})
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
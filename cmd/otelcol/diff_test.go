@@ -0,0 +1,70 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestWriteDiff(t *testing.T) {
+	change := configprovider.ConfigChange{
+		Added:   map[string]any{"receivers::otlp::protocols::grpc": nil, "exporters::otlp::endpoint": "otlp:4317"},
+		Removed: map[string]any{"receivers::jaeger::protocols::thrift_http": nil},
+		Modified: map[string]configprovider.ValueChange{
+			"processors::batch::timeout":        {Before: "1s", After: "5s"},
+			"exporters::signalfx::access_token": {Before: "<redacted>", After: "<redacted>"},
+		},
+		Checksum: "abc",
+	}
+
+	out := &bytes.Buffer{}
+	require.NoError(t, writeDiff(out, change, true))
+	require.Equal(t, `+ exporters::otlp::endpoint: otlp:4317
++ receivers::otlp::protocols::grpc: <nil>
+- receivers::jaeger::protocols::thrift_http: <nil>
+~ exporters::signalfx::access_token: <redacted> -> <redacted>
+~ processors::batch::timeout: 1s -> 5s
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, writeDiff(out, configprovider.ConfigChange{}, false))
+	require.Equal(t, "No changes.\n", out.String())
+}
+
+func TestWriteDiffReport(t *testing.T) {
+	change := configprovider.ConfigChange{
+		Modified: map[string]configprovider.ValueChange{"processors::batch::timeout": {Before: "1s", After: "5s"}},
+		Checksum: "abc",
+	}
+
+	out := &bytes.Buffer{}
+	require.NoError(t, writeDiffReport(out, change, true))
+	var report map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	require.Equal(t, map[string]any{
+		"changed":  true,
+		"checksum": "abc",
+		"modified": map[string]any{
+			"processors::batch::timeout": map[string]any{"before": "1s", "after": "5s"},
+		},
+	}, report)
+}
@@ -18,25 +18,27 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 
 	flag "github.com/spf13/pflag"
+	"go.opencensus.io/stats/view"
 	"go.opentelemetry.io/collector/component"
-	"go.opentelemetry.io/collector/confmap"
-	"go.opentelemetry.io/collector/confmap/provider/envprovider"
-	"go.opentelemetry.io/collector/confmap/provider/fileprovider"
 	"go.opentelemetry.io/collector/otelcol"
 	"go.uber.org/zap"
 
 	"github.com/signalfx/splunk-otel-collector/internal/components"
+	"github.com/signalfx/splunk-otel-collector/internal/configaudit"
 	"github.com/signalfx/splunk-otel-collector/internal/configconverter"
 	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
 	"github.com/signalfx/splunk-otel-collector/internal/configsources"
-	"github.com/signalfx/splunk-otel-collector/internal/confmapprovider/discovery"
+	"github.com/signalfx/splunk-otel-collector/internal/crashreport"
+	"github.com/signalfx/splunk-otel-collector/internal/loglevel"
 	"github.com/signalfx/splunk-otel-collector/internal/settings"
 	"github.com/signalfx/splunk-otel-collector/internal/version"
+	"github.com/signalfx/splunk-otel-collector/pkg/distro"
 )
 
 func main() {
@@ -62,53 +64,119 @@ func main() {
 		Version: version.Version,
 	}
 
+	crashReporter := crashreport.New(info)
+	crashReporter.Start()
+	defer crashReporter.Recover()
+
 	confMapConverters := collectorSettings.ConfMapConverters()
+	logLevels := loglevel.NewController()
 	configServer := configconverter.NewConfigServer()
+	configServer.Handle(loglevel.HandlerPath, logLevels)
+	sourceHealth := configprovider.NewHealthReporter()
+	configServer.Handle(configprovider.HealthHandlerPath, sourceHealth)
+	configServer.HandleConfigSources(sourceHealth)
+	if healthCheck, ok := factories.Extensions["health_check"]; ok {
+		factories.Extensions["health_check"] = configprovider.WithSourceHealth(healthCheck, sourceHealth)
+	}
+	if err = view.Register(configprovider.MetricViews()...); err != nil {
+		log.Fatalf("failed to register config source metrics: %v", err)
+	}
 	dryRun := configconverter.NewDryRun(collectorSettings.IsDryRun())
-	confMapConverters = append(confMapConverters, dryRun, configServer)
-
-	discovery, err := discovery.New()
+	crashReporter.SetRedaction(dryRun.Redact)
+	configSnapshot := configconverter.NewConfigSnapshot(collectorSettings.ConfigSnapshotPath())
+	syntaxMigration := configconverter.NewLegacySyntaxMigration(collectorSettings.MigrateConfigSyntaxPath())
+	k8sExport, err := configconverter.NewK8sConfigExport(collectorSettings.K8sConfigExportTarget())
 	if err != nil {
-		log.Fatalf("failed to create discovery provider: %v", err)
-	}
-
-	hooks := []configprovider.Hook{configServer, dryRun}
-	envProvider := envprovider.New()
-	fileProvider := fileprovider.New()
-	serviceConfigProvider, err := otelcol.NewConfigProvider(
-		otelcol.ConfigProviderSettings{
-			ResolverSettings: confmap.ResolverSettings{
-				URIs: collectorSettings.ResolverURIs(),
-				Providers: map[string]confmap.Provider{
-					discovery.ConfigDScheme(): configprovider.NewConfigSourceConfigMapProvider(
-						discovery.ConfigDProvider(),
-						zap.NewNop(), // The service logger is not available yet, setting it to Nop.
-						info, hooks, configsources.Get()...,
-					),
-					discovery.DiscoveryModeScheme(): configprovider.NewConfigSourceConfigMapProvider(
-						discovery.DiscoveryModeProvider(), zap.NewNop(), info, hooks, configsources.Get()...,
-					),
-					envProvider.Scheme(): configprovider.NewConfigSourceConfigMapProvider(
-						envProvider, zap.NewNop(), info, hooks, configsources.Get()...,
-					),
-					fileProvider.Scheme(): configprovider.NewConfigSourceConfigMapProvider(
-						fileProvider, zap.NewNop(), info, hooks, configsources.Get()...,
-					),
-				}, Converters: confMapConverters,
-			},
+		log.Fatalf(`invalid settings detected: %v. Use "--help" to show valid usage`, err)
+	}
+	confMapConverters = append(confMapConverters, configconverter.NewLogLevels(logLevels), configSnapshot, syntaxMigration, k8sExport, dryRun, configServer, crashReporter)
+
+	configSourceFactories := distro.ConfigSources()
+	if pluginsDir := collectorSettings.ConfigSourcePluginsDir(); pluginsDir != "" {
+		if configSourceFactories, err = configsources.WithPlugins(configSourceFactories, pluginsDir); err != nil {
+			log.Fatalf("failed to load config source plugins: %v", err)
+		}
+	}
+
+	if collectorSettings.IsDiff() {
+		change, changed, diffErr := diffConfigs(context.Background(), diffSettings{
+			factories:     factories,
+			buildInfo:     info,
+			configSources: configSourceFactories,
+			converters:    collectorSettings.ConfMapConverters(),
+			uris:          collectorSettings.ResolverURIs(),
+			againstURIs:   collectorSettings.DiffAgainstURIs(),
 		})
+		if diffErr != nil {
+			log.Fatalf("failed to diff the configurations: %v", diffErr)
+		}
+		write := writeDiff
+		if collectorSettings.ValidateOutput() == settings.JSONOutput {
+			write = writeDiffReport
+		}
+		if err = write(os.Stdout, change, changed); err != nil {
+			log.Fatalf("failed to write the configuration diff: %v", err)
+		}
+		return
+	}
+
+	refresher := configprovider.NewRefresher()
+	hooks := []distro.Hook{syntaxMigration, configServer, dryRun, configSnapshot, sourceHealth, refresher}
+	auditor, err := configaudit.NewHECAuditorFromEnv(info)
+	if err != nil {
+		log.Fatalf("invalid config change audit settings: %v", err)
+	}
+	if auditor != nil && !collectorSettings.IsValidate() {
+		hooks = append(hooks, auditor)
+	}
+	webhooks, err := configaudit.NewWebhooksFromEnv(info)
+	if err != nil {
+		log.Fatalf("invalid config webhooks settings: %v", err)
+	}
+	if webhooks != nil && !collectorSettings.IsValidate() {
+		hooks = append(hooks, webhooks)
+	}
+	serviceConfigProvider, err := distro.NewConfigProvider(distro.ConfigProviderSettings{
+		BuildInfo:     info,
+		URIs:          collectorSettings.ResolverURIs(),
+		ConfigSources: configSourceFactories,
+		Converters:    confMapConverters,
+		Hooks:         hooks,
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	if collectorSettings.IsValidate() {
+		errs := validate(context.Background(), serviceConfigProvider, factories, collectorSettings.FeatureGates())
+		if collectorSettings.ValidateOutput() == settings.JSONOutput {
+			if err = writeValidationReport(os.Stdout, errs); err != nil {
+				log.Fatalf("failed to write the validation report: %v", err)
+			}
+			if len(errs) > 0 {
+				os.Exit(1)
+			}
+			return
+		}
+		for _, err = range errs {
+			log.Printf("invalid configuration: %v", err)
+		}
+		if len(errs) > 0 {
+			log.Fatalf("configuration validation failed with %d errors", len(errs))
+		}
+		log.Print("configuration is valid")
+		return
+	}
+
 	serviceSettings := otelcol.CollectorSettings{
 		BuildInfo:      info,
 		Factories:      factories,
 		ConfigProvider: serviceConfigProvider,
+		LoggingOptions: []zap.Option{zap.WrapCore(logLevels.WrapCore)},
 	}
 
 	os.Args = append(os.Args[:1], collectorSettings.ColCoreArgs()...)
-	if err = run(serviceSettings); err != nil {
+	if err = run(serviceSettings, refresher); err != nil {
 		log.Fatal(err)
 	}
 }
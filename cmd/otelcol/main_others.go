@@ -19,9 +19,28 @@
 package main
 
 import (
+	"os"
+	"os/signal"
+	"syscall"
+
 	"go.opentelemetry.io/collector/otelcol"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
 )
 
-func run(params otelcol.CollectorSettings) error {
+func run(params otelcol.CollectorSettings, refresher *configprovider.Refresher) error {
+	// The collector reloads the configuration on SIGHUP by itself, maybe before the cache of the
+	// config sources is expired. The refresher doesn't rely on it: it expires the cache and then
+	// reloads the configuration through the watcher of the config providers if the values retrieved
+	// again changed, so a reload taking the cached values is followed by one with the new values.
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+	go func() {
+		for range sighup {
+			refresher.Refresh()
+		}
+	}()
+
 	return runInteractive(params)
 }
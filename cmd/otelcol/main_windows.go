@@ -24,15 +24,17 @@ import (
 
 	"go.opentelemetry.io/collector/otelcol"
 	"golang.org/x/sys/windows/svc"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
 )
 
-func run(params otelcol.CollectorSettings) error {
+func run(params otelcol.CollectorSettings, refresher *configprovider.Refresher) error {
 	if useInteractiveMode, err := checkUseInteractiveMode(); err != nil {
 		return err
 	} else if useInteractiveMode {
 		return runInteractive(params)
 	} else {
-		return runService(params)
+		return runService(params, refresher)
 	}
 }
 
@@ -52,11 +54,70 @@ func checkUseInteractiveMode() (bool, error) {
 	}
 }
 
-func runService(params otelcol.CollectorSettings) error {
+func runService(params otelcol.CollectorSettings, refresher *configprovider.Refresher) error {
+	handler := &refreshingSvcHandler{handler: otelcol.NewSvcHandler(params), refresher: refresher}
 	// do not need to supply service name when startup is invoked through Service Control Manager directly
-	if err := svc.Run("", otelcol.NewSvcHandler(params)); err != nil {
+	if err := svc.Run("", handler); err != nil {
 		return fmt.Errorf("failed to start service: %w", err)
 	}
 
 	return nil
 }
+
+// refreshingSvcHandler handles the "paramchange" service control code, the Windows equivalent
+// of SIGHUP, e.g. "sc control splunk-otel-collector paramchange", refreshing the config sources
+// and reloading the configuration if it changed. The other requests go to the collector handler.
+type refreshingSvcHandler struct {
+	handler   svc.Handler
+	refresher *configprovider.Refresher
+}
+
+func (h *refreshingSvcHandler) Execute(args []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	handlerRequests := make(chan svc.ChangeRequest)
+	handlerChanges := make(chan svc.Status)
+	paramChanges := make(chan svc.Status)
+	done := make(chan struct{})
+
+	// The statuses of the collector handler and of the "paramchange" requests are sent by a
+	// single goroutine, it stops once the collector handler returns.
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case status, ok := <-handlerChanges:
+				if !ok {
+					return
+				}
+				if status.State == svc.Running {
+					status.Accepts |= svc.AcceptParamChange
+				}
+				changes <- status
+			case status := <-paramChanges:
+				changes <- status
+			}
+		}
+	}()
+	go func() {
+		for req := range requests {
+			if req.Cmd == svc.ParamChange {
+				go h.refresher.Refresh()
+				select {
+				case paramChanges <- req.CurrentStatus:
+				case <-done:
+					return
+				}
+				continue
+			}
+			select {
+			case handlerRequests <- req:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	ssec, errno := h.handler.Execute(args, handlerRequests, handlerChanges)
+	close(handlerChanges)
+	<-done
+	return ssec, errno
+}
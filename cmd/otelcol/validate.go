@@ -0,0 +1,170 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/featuregate"
+	"go.opentelemetry.io/collector/otelcol"
+)
+
+// validate resolves the configuration, including the values of its config sources, unmarshals it
+// into the component configurations and validates them. Unlike otelcol.Config.Validate, which stops
+// at the first invalid component, all the errors found are returned. The feature gates are applied
+// first since the collector core service, applying them otherwise, doesn't run.
+func validate(ctx context.Context, provider otelcol.ConfigProvider, factories otelcol.Factories, featureGates []string) []error {
+	gates := featuregate.FlagValue{}
+	for _, gate := range featureGates {
+		if err := gates.Set(gate); err != nil {
+			return []error{err}
+		}
+	}
+	if err := featuregate.GetRegistry().Apply(gates); err != nil {
+		return []error{err}
+	}
+
+	cfg, err := provider.Get(ctx, factories)
+	if shutdownErr := provider.Shutdown(ctx); shutdownErr != nil && err == nil {
+		err = fmt.Errorf("failed to shutdown the config providers: %w", shutdownErr)
+	}
+	if err != nil {
+		return []error{err}
+	}
+	return validateConfig(cfg)
+}
+
+func validateConfig(cfg *otelcol.Config) []error {
+	var errs []error
+	if len(cfg.Receivers) == 0 {
+		errs = append(errs, &validationError{err: errors.New("no receiver configuration specified in config")})
+	}
+	if len(cfg.Exporters) == 0 {
+		errs = append(errs, &validationError{err: errors.New("no exporter configuration specified in config")})
+	}
+	errs = append(errs, validateComponents("receivers", cfg.Receivers)...)
+	errs = append(errs, validateComponents("processors", cfg.Processors)...)
+	errs = append(errs, validateComponents("exporters", cfg.Exporters)...)
+	errs = append(errs, validateComponents("extensions", cfg.Extensions)...)
+
+	if err := cfg.Service.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	for _, ref := range cfg.Service.Extensions {
+		if cfg.Extensions[ref] == nil {
+			errs = append(errs, &validationError{path: "service::extensions", err: fmt.Errorf("references extension %q which is not configured", ref)})
+		}
+	}
+
+	pipelineIDs := make([]component.ID, 0, len(cfg.Service.Pipelines))
+	for pipelineID := range cfg.Service.Pipelines {
+		pipelineIDs = append(pipelineIDs, pipelineID)
+	}
+	sortIDs(pipelineIDs)
+	for _, pipelineID := range pipelineIDs {
+		pipeline := cfg.Service.Pipelines[pipelineID]
+		for _, ref := range pipeline.Receivers {
+			if cfg.Receivers[ref] == nil {
+				errs = append(errs, &validationError{path: "service::pipeline::" + pipelineID.String(), err: fmt.Errorf("references receiver %q which is not configured", ref)})
+			}
+		}
+		for _, ref := range pipeline.Processors {
+			if cfg.Processors[ref] == nil {
+				errs = append(errs, &validationError{path: "service::pipeline::" + pipelineID.String(), err: fmt.Errorf("references processor %q which is not configured", ref)})
+			}
+		}
+		for _, ref := range pipeline.Exporters {
+			if cfg.Exporters[ref] == nil {
+				errs = append(errs, &validationError{path: "service::pipeline::" + pipelineID.String(), err: fmt.Errorf("references exporter %q which is not configured", ref)})
+			}
+		}
+	}
+	return errs
+}
+
+// validateComponents validates the given component configurations in the order of their ids.
+func validateComponents(kind string, cfgs map[component.ID]component.Config) []error {
+	ids := make([]component.ID, 0, len(cfgs))
+	for id := range cfgs {
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+
+	var errs []error
+	for _, id := range ids {
+		if err := component.ValidateConfig(cfgs[id]); err != nil {
+			errs = append(errs, &validationError{path: kind + "::" + id.String(), err: err})
+		}
+	}
+	return errs
+}
+
+func sortIDs(ids []component.ID) {
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+}
+
+// validationError is an error of the validation of the configuration with the path of the
+// invalid part, e.g. "receivers::otlp", empty for the errors of the whole configuration.
+type validationError struct {
+	err  error
+	path string
+}
+
+func (e *validationError) Error() string {
+	if e.path == "" {
+		return e.err.Error()
+	}
+	return e.path + ": " + e.err.Error()
+}
+
+func (e *validationError) Unwrap() error {
+	return e.err
+}
+
+// validationReport is the JSON result of the validate command.
+type validationReport struct {
+	Errors []validationReportError `json:"errors"`
+	Valid  bool                    `json:"valid"`
+}
+
+type validationReportError struct {
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// writeValidationReport writes the JSON report of the validation errors, e.g. for CI gates.
+func writeValidationReport(w io.Writer, errs []error) error {
+	report := validationReport{Valid: len(errs) == 0, Errors: []validationReportError{}}
+	for _, err := range errs {
+		reportErr := validationReportError{Message: err.Error()}
+		var vErr *validationError
+		if errors.As(err, &vErr) {
+			reportErr = validationReportError{Path: vErr.path, Message: vErr.err.Error()}
+		}
+		report.Errors = append(report.Errors, reportErr)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
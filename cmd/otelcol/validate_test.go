@@ -0,0 +1,111 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/otelcol"
+	"go.opentelemetry.io/collector/service"
+)
+
+type validatedConfig struct {
+	err error
+}
+
+func (cfg *validatedConfig) Validate() error {
+	return cfg.err
+}
+
+func TestValidateConfig(t *testing.T) {
+	valid := &validatedConfig{}
+	cfg := &otelcol.Config{
+		Receivers: map[component.ID]component.Config{
+			component.NewID("otlp"):        valid,
+			component.NewID("hostmetrics"): &validatedConfig{err: errors.New("missing scrapers")},
+		},
+		Processors: map[component.ID]component.Config{
+			component.NewID("batch"): valid,
+		},
+		Exporters: map[component.ID]component.Config{
+			component.NewID("signalfx"): &validatedConfig{err: errors.New("missing access_token")},
+		},
+		Extensions: map[component.ID]component.Config{
+			component.NewID("health_check"): valid,
+		},
+		Service: service.Config{
+			Extensions: []component.ID{component.NewID("health_check"), component.NewID("zpages")},
+			Pipelines: map[component.ID]*service.PipelineConfig{
+				component.NewID("metrics"): {
+					Receivers:  []component.ID{component.NewID("hostmetrics")},
+					Processors: []component.ID{component.NewID("batch"), component.NewID("memory_limiter")},
+					Exporters:  []component.ID{component.NewID("signalfx")},
+				},
+			},
+		},
+	}
+
+	var messages []string
+	for _, err := range validateConfig(cfg) {
+		messages = append(messages, err.Error())
+	}
+	require.Equal(t, []string{
+		"receivers::hostmetrics: missing scrapers",
+		"exporters::signalfx: missing access_token",
+		`service::extensions: references extension "zpages" which is not configured`,
+		`service::pipeline::metrics: references processor "memory_limiter" which is not configured`,
+	}, messages)
+}
+
+func TestValidateConfigMissingComponents(t *testing.T) {
+	var messages []string
+	for _, err := range validateConfig(&otelcol.Config{}) {
+		messages = append(messages, err.Error())
+	}
+	require.Equal(t, []string{
+		"no receiver configuration specified in config",
+		"no exporter configuration specified in config",
+		"service must have at least one pipeline",
+	}, messages)
+}
+
+func TestWriteValidationReport(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeValidationReport(&buf, nil))
+	require.JSONEq(t, `{"valid": true, "errors": []}`, buf.String())
+
+	buf.Reset()
+	errs := validateConfig(&otelcol.Config{
+		Receivers: map[component.ID]component.Config{
+			component.NewID("hostmetrics"): &validatedConfig{err: errors.New("missing scrapers")},
+		},
+	})
+	errs = append(errs, errors.New("cannot resolve the configuration"))
+	require.NoError(t, writeValidationReport(&buf, errs))
+	require.JSONEq(t, `{
+		"valid": false,
+		"errors": [
+			{"message": "no exporter configuration specified in config"},
+			{"path": "receivers::hostmetrics", "message": "missing scrapers"},
+			{"message": "service must have at least one pipeline"},
+			{"message": "cannot resolve the configuration"}
+		]
+	}`, buf.String())
+}
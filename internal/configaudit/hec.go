@@ -0,0 +1,171 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package configaudit notifies external systems about the changes of the configuration: audit
+// events sent to Splunk HEC and webhooks.
+package configaudit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+const (
+	// HECURLEnvVar is the env var with the URL of the Splunk HEC endpoint receiving the audit events,
+	// the auditing is disabled if it isn't set.
+	HECURLEnvVar = "SPLUNK_CONFIG_AUDIT_HEC_URL"
+	// HECTokenEnvVar is the env var with the HEC token of the audit events.
+	// nolint:gosec
+	HECTokenEnvVar = "SPLUNK_CONFIG_AUDIT_HEC_TOKEN" // this isn't a hardcoded token
+	// HECIndexEnvVar is the env var with the index of the audit events, the default index of the
+	// token if it isn't set.
+	HECIndexEnvVar = "SPLUNK_CONFIG_AUDIT_HEC_INDEX"
+
+	hecEventPath  = "/services/collector/event"
+	auditSource   = "splunk-otel-collector"
+	auditType     = "splunk:otel:config:audit"
+	auditTimeout  = 10 * time.Second
+	auditedAction = "config_change"
+)
+
+var _ configprovider.ConfigChangeHook = (*HECAuditor)(nil)
+
+// HECAuditor is a configprovider.ConfigChangeHook sending an audit event for each change of the
+// configuration to a Splunk HEC endpoint. The events are sent in the background, the failures are
+// logged and don't fail the reloads.
+type HECAuditor struct {
+	client    *http.Client
+	url       string
+	token     string
+	index     string
+	host      string
+	buildInfo component.BuildInfo
+	wg        sync.WaitGroup
+}
+
+// NewHECAuditorFromEnv creates a HECAuditor configured by the SPLUNK_CONFIG_AUDIT_HEC_* env vars,
+// nil if SPLUNK_CONFIG_AUDIT_HEC_URL isn't set. The URL without a path is the one of the HEC
+// server, the events are sent to its "/services/collector/event" endpoint.
+func NewHECAuditorFromEnv(buildInfo component.BuildInfo) (*HECAuditor, error) {
+	endpoint := os.Getenv(HECURLEnvVar)
+	if endpoint == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid %s %q, must be an http or https URL", HECURLEnvVar, endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = hecEventPath
+	}
+	token := os.Getenv(HECTokenEnvVar)
+	if token == "" {
+		return nil, fmt.Errorf("%s must be set along with %s", HECTokenEnvVar, HECURLEnvVar)
+	}
+	host, _ := os.Hostname()
+	return &HECAuditor{
+		client:    &http.Client{Timeout: auditTimeout},
+		url:       u.String(),
+		token:     token,
+		index:     os.Getenv(HECIndexEnvVar),
+		host:      host,
+		buildInfo: buildInfo,
+	}, nil
+}
+
+// hecEvent is the HEC envelope of an audit event.
+type hecEvent struct {
+	Event      auditEvent `json:"event"`
+	Host       string     `json:"host,omitempty"`
+	Source     string     `json:"source"`
+	SourceType string     `json:"sourcetype"`
+	Index      string     `json:"index,omitempty"`
+	Time       float64    `json:"time"`
+}
+
+type auditEvent struct {
+	configprovider.ConfigChange
+	Action  string `json:"action"`
+	Command string `json:"command,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+func (a *HECAuditor) OnNew() {}
+
+func (a *HECAuditor) OnRetrieve(string, map[string]any) {}
+
+// OnShutdown waits for the events being sent.
+func (a *HECAuditor) OnShutdown() {
+	a.wg.Wait()
+}
+
+func (a *HECAuditor) OnConfigChange(change configprovider.ConfigChange) {
+	event := hecEvent{
+		Event: auditEvent{
+			ConfigChange: change,
+			Action:       auditedAction,
+			Command:      a.buildInfo.Command,
+			Version:      a.buildInfo.Version,
+		},
+		Host:       a.host,
+		Source:     auditSource,
+		SourceType: auditType,
+		Index:      a.index,
+		Time:       float64(time.Now().UnixNano()) / float64(time.Second),
+	}
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		if err := a.send(context.Background(), event); err != nil {
+			log.Printf("Failed to send the config change audit event: %v", err)
+		}
+	}()
+}
+
+func (a *HECAuditor) send(ctx context.Context, event hecEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Splunk "+a.token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HEC responded %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configaudit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestNewHECAuditorFromEnv(t *testing.T) {
+	t.Setenv(HECURLEnvVar, "")
+	auditor, err := NewHECAuditorFromEnv(component.BuildInfo{})
+	require.NoError(t, err)
+	assert.Nil(t, auditor)
+
+	t.Setenv(HECURLEnvVar, "https://hec.example.com:8088")
+	_, err = NewHECAuditorFromEnv(component.BuildInfo{})
+	assert.EqualError(t, err, "SPLUNK_CONFIG_AUDIT_HEC_TOKEN must be set along with SPLUNK_CONFIG_AUDIT_HEC_URL")
+
+	t.Setenv(HECTokenEnvVar, "token")
+	auditor, err = NewHECAuditorFromEnv(component.BuildInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, "https://hec.example.com:8088/services/collector/event", auditor.url)
+
+	t.Setenv(HECURLEnvVar, "https://hec.example.com:8088/services/collector/raw")
+	auditor, err = NewHECAuditorFromEnv(component.BuildInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, "https://hec.example.com:8088/services/collector/raw", auditor.url)
+
+	t.Setenv(HECURLEnvVar, "hec.example.com")
+	_, err = NewHECAuditorFromEnv(component.BuildInfo{})
+	assert.EqualError(t, err, `invalid SPLUNK_CONFIG_AUDIT_HEC_URL "hec.example.com", must be an http or https URL`)
+}
+
+func TestHECAuditor(t *testing.T) {
+	events := make(chan map[string]any, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/services/collector/event", r.URL.Path)
+		assert.Equal(t, "Splunk token", r.Header.Get("Authorization"))
+		var event map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		events <- event
+	}))
+	defer server.Close()
+
+	t.Setenv(HECURLEnvVar, server.URL)
+	t.Setenv(HECTokenEnvVar, "token")
+	t.Setenv(HECIndexEnvVar, "audit")
+	auditor, err := NewHECAuditorFromEnv(component.BuildInfo{Command: "otelcol", Version: "v1.0.0"})
+	require.NoError(t, err)
+
+	auditor.OnConfigChange(configprovider.ConfigChange{
+		Scheme:        "file",
+		Checksum:      "abc",
+		URIs:          []string{"file:config.yaml"},
+		ConfigSources: []string{"vault:secret/token"},
+		Modified: map[string]configprovider.ValueChange{
+			"exporters::otlp::headers::token": {Before: configprovider.RedactedValue, After: configprovider.RedactedValue},
+		},
+	})
+	auditor.OnShutdown()
+
+	event := <-events
+	assert.Equal(t, "audit", event["index"])
+	assert.Equal(t, "splunk:otel:config:audit", event["sourcetype"])
+	assert.Equal(t, map[string]any{
+		"action":         "config_change",
+		"command":        "otelcol",
+		"version":        "v1.0.0",
+		"scheme":         "file",
+		"checksum":       "abc",
+		"uris":           []any{"file:config.yaml"},
+		"config_sources": []any{"vault:secret/token"},
+		"modified": map[string]any{
+			"exporters::otlp::headers::token": map[string]any{"before": "<redacted>", "after": "<redacted>"},
+		},
+	}, event["event"])
+}
@@ -0,0 +1,227 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configaudit
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"gopkg.in/yaml.v2"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+const (
+	// WebhooksEnvVar is the env var with the YAML list of the webhooks notified about the
+	// configurations applied and rolled back, see WebhookSettings.
+	WebhooksEnvVar = "SPLUNK_CONFIG_WEBHOOKS_YAML"
+	// SignatureHeader is the header of the hex HMAC-SHA256 of the body of the webhook requests,
+	// prefixed by "sha256=", sent if the webhook has a secret.
+	SignatureHeader = "X-Splunk-Signature-256"
+
+	// ConfigAppliedEvent is the event of the webhooks sent when a configuration is applied.
+	ConfigAppliedEvent = "config_applied"
+	// ConfigRollbackEvent is the event of the webhooks sent when a reload is rolled back.
+	ConfigRollbackEvent = "config_rollback"
+
+	webhookTimeout = 10 * time.Second
+)
+
+var _ configprovider.ConfigApplyHook = (*Webhooks)(nil)
+var _ configprovider.ConfigChangeHook = (*Webhooks)(nil)
+
+// WebhookSettings are the settings of a webhook.
+type WebhookSettings struct {
+	// Headers are added to the requests, e.g. an authorization header.
+	Headers map[string]string `yaml:"headers"`
+	// URL is the http or https URL the events are posted to.
+	URL string `yaml:"url"`
+	// Secret is the key of the HMAC-SHA256 signature of the requests, they aren't signed if empty.
+	Secret string `yaml:"secret"`
+	// Events are the events the webhook is notified about, all of them by default.
+	Events []string `yaml:"events"`
+}
+
+// webhookPayload is the JSON body of the webhook requests.
+type webhookPayload struct {
+	Change  *configprovider.ConfigChange `json:"change,omitempty"`
+	Event   string                       `json:"event"`
+	Time    string                       `json:"time"`
+	Host    string                       `json:"host,omitempty"`
+	Command string                       `json:"command,omitempty"`
+	Version string                       `json:"version,omitempty"`
+	Error   string                       `json:"error,omitempty"`
+	Initial bool                         `json:"initial,omitempty"`
+}
+
+// Webhooks is a configprovider.ConfigApplyHook posting an event to each webhook when a configuration
+// is applied or a reload is rolled back. The applied events of the reloads caused by a config source
+// change include the change, see configprovider.ConfigChange. The events are sent in the background,
+// the failures are logged and don't affect the reloads.
+type Webhooks struct {
+	pending   *configprovider.ConfigChange
+	client    *http.Client
+	host      string
+	webhooks  []WebhookSettings
+	buildInfo component.BuildInfo
+	wg        sync.WaitGroup
+	mutex     sync.Mutex
+}
+
+// NewWebhooksFromEnv creates the Webhooks configured by the SPLUNK_CONFIG_WEBHOOKS_YAML env var,
+// nil if it isn't set.
+func NewWebhooksFromEnv(buildInfo component.BuildInfo) (*Webhooks, error) {
+	value := os.Getenv(WebhooksEnvVar)
+	if value == "" {
+		return nil, nil
+	}
+	var webhooks []WebhookSettings
+	if err := yaml.UnmarshalStrict([]byte(value), &webhooks); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", WebhooksEnvVar, err)
+	}
+	for i, webhook := range webhooks {
+		u, err := url.Parse(webhook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid %s: webhook %d: invalid url %q, must be an http or https URL", WebhooksEnvVar, i, webhook.URL)
+		}
+		for _, event := range webhook.Events {
+			if event != ConfigAppliedEvent && event != ConfigRollbackEvent {
+				return nil, fmt.Errorf("invalid %s: webhook %d: unknown event %q, must be %s or %s",
+					WebhooksEnvVar, i, event, ConfigAppliedEvent, ConfigRollbackEvent)
+			}
+		}
+	}
+	host, _ := os.Hostname()
+	return &Webhooks{
+		client:    &http.Client{Timeout: webhookTimeout},
+		host:      host,
+		webhooks:  webhooks,
+		buildInfo: buildInfo,
+	}, nil
+}
+
+func (w *Webhooks) OnNew() {}
+
+func (w *Webhooks) OnRetrieve(string, map[string]any) {}
+
+// OnShutdown waits for the events being sent.
+func (w *Webhooks) OnShutdown() {
+	w.wg.Wait()
+}
+
+// OnConfigChange records the change to include it in the event of the configuration applied next.
+func (w *Webhooks) OnConfigChange(change configprovider.ConfigChange) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.pending = &change
+}
+
+func (w *Webhooks) OnConfigApplied(initial bool) {
+	w.mutex.Lock()
+	change := w.pending
+	w.pending = nil
+	w.mutex.Unlock()
+	w.notify(webhookPayload{Event: ConfigAppliedEvent, Initial: initial, Change: change})
+}
+
+func (w *Webhooks) OnConfigRollback(err error) {
+	w.mutex.Lock()
+	change := w.pending
+	w.pending = nil
+	w.mutex.Unlock()
+	w.notify(webhookPayload{Event: ConfigRollbackEvent, Error: err.Error(), Change: change})
+}
+
+func (w *Webhooks) notify(payload webhookPayload) {
+	payload.Time = time.Now().UTC().Format(time.RFC3339)
+	payload.Host = w.host
+	payload.Command = w.buildInfo.Command
+	payload.Version = w.buildInfo.Version
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Failed to marshal the %s webhook event: %v", payload.Event, err)
+		return
+	}
+	for _, webhook := range w.webhooks {
+		if !webhook.notifies(payload.Event) {
+			continue
+		}
+		w.wg.Add(1)
+		go func(webhook WebhookSettings) {
+			defer w.wg.Done()
+			if err := w.post(context.Background(), webhook, body); err != nil {
+				log.Printf("Failed to send the %s event to the %s webhook: %v", payload.Event, webhook.URL, err)
+			}
+		}(webhook)
+	}
+}
+
+func (s WebhookSettings) notifies(event string) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, e := range s.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Webhooks) post(ctx context.Context, webhook WebhookSettings, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range webhook.Headers {
+		req.Header.Set(k, v)
+	}
+	if webhook.Secret != "" {
+		req.Header.Set(SignatureHeader, Signature(webhook.Secret, body))
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook responded %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// Signature returns the value of the SignatureHeader of the body signed with the secret, for the
+// receivers to verify the requests.
+func Signature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
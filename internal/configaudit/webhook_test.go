@@ -0,0 +1,116 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configaudit
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestNewWebhooksFromEnv(t *testing.T) {
+	t.Setenv(WebhooksEnvVar, "")
+	webhooks, err := NewWebhooksFromEnv(component.BuildInfo{})
+	require.NoError(t, err)
+	assert.Nil(t, webhooks)
+
+	for _, tt := range []struct {
+		name  string
+		value string
+		err   string
+	}{
+		{name: "invalid_yaml", value: "url: a", err: "invalid SPLUNK_CONFIG_WEBHOOKS_YAML: yaml: unmarshal errors:"},
+		{name: "unknown_field", value: "- uri: https://example.com", err: "invalid SPLUNK_CONFIG_WEBHOOKS_YAML: yaml: unmarshal errors:"},
+		{name: "invalid_url", value: "- url: example.com", err: `invalid SPLUNK_CONFIG_WEBHOOKS_YAML: webhook 0: invalid url "example.com", must be an http or https URL`},
+		{name: "unknown_event", value: "- url: https://example.com\n  events: [config_error]", err: `invalid SPLUNK_CONFIG_WEBHOOKS_YAML: webhook 0: unknown event "config_error", must be config_applied or config_rollback`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(WebhooksEnvVar, tt.value)
+			_, err := NewWebhooksFromEnv(component.BuildInfo{})
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+type webhookRequest struct {
+	header  http.Header
+	payload map[string]any
+	body    []byte
+}
+
+func TestWebhooks(t *testing.T) {
+	requests := make(chan webhookRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		var payload map[string]any
+		assert.NoError(t, json.Unmarshal(body, &payload))
+		requests <- webhookRequest{header: r.Header, payload: payload, body: body}
+	}))
+	defer server.Close()
+
+	t.Setenv(WebhooksEnvVar, `
+- url: `+server.URL+`/all
+  secret: s3cr3t
+  headers:
+    X-Team: observability
+- url: `+server.URL+`/rollbacks
+  events: [config_rollback]
+`)
+	webhooks, err := NewWebhooksFromEnv(component.BuildInfo{Command: "otelcol", Version: "v1.0.0"})
+	require.NoError(t, err)
+
+	webhooks.OnConfigApplied(true)
+	webhooks.OnShutdown()
+	req := <-requests
+	assert.Equal(t, "config_applied", req.payload["event"])
+	assert.Equal(t, true, req.payload["initial"])
+	assert.Equal(t, "otelcol", req.payload["command"])
+	assert.Equal(t, "observability", req.header.Get("X-Team"))
+	assert.Equal(t, Signature("s3cr3t", req.body), req.header.Get(SignatureHeader))
+	assert.Empty(t, requests)
+
+	webhooks.OnConfigChange(configprovider.ConfigChange{Scheme: "file", Checksum: "abc"})
+	webhooks.OnConfigApplied(false)
+	webhooks.OnShutdown()
+	req = <-requests
+	assert.Equal(t, "config_applied", req.payload["event"])
+	assert.Nil(t, req.payload["initial"])
+	assert.Equal(t, map[string]any{"scheme": "file", "checksum": "abc"}, req.payload["change"])
+
+	webhooks.OnConfigRollback(errors.New("backend unavailable"))
+	webhooks.OnShutdown()
+	for i := 0; i < 2; i++ {
+		req = <-requests
+		assert.Equal(t, "config_rollback", req.payload["event"])
+		assert.Equal(t, "backend unavailable", req.payload["error"])
+		assert.Nil(t, req.payload["change"])
+	}
+}
+
+func TestSignature(t *testing.T) {
+	assert.Equal(t, "sha256=c3844c172d4e64bcc4d412f3d778dd6ccfb1ea774b97c0d33ac8747ec33ca515",
+		Signature("s3cr3t", []byte(`{"event":"config_applied"}`)))
+}
@@ -0,0 +1,101 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+var _ confmap.Converter = (*ConfigCapture)(nil)
+var _ configprovider.ProvenanceHook = (*ConfigCapture)(nil)
+
+// ConfigCapture records the final configuration, once resolved and converted, and the provenance
+// of its keys, e.g. to compare configurations without running them. It must follow the converters
+// modifying the configuration.
+type ConfigCapture struct {
+	config     map[string]any
+	provenance map[string]configprovider.KeyProvenance
+	mutex      sync.Mutex
+}
+
+func NewConfigCapture() *ConfigCapture {
+	return &ConfigCapture{provenance: map[string]configprovider.KeyProvenance{}}
+}
+
+func (cc *ConfigCapture) OnNew() {}
+
+func (cc *ConfigCapture) OnRetrieve(string, map[string]any) {}
+
+func (cc *ConfigCapture) OnProvenance(_ string, provenance map[string]configprovider.KeyProvenance) {
+	cc.mutex.Lock()
+	defer cc.mutex.Unlock()
+	for k, p := range provenance {
+		cc.provenance[k] = p
+	}
+}
+
+func (cc *ConfigCapture) OnShutdown() {}
+
+func (cc *ConfigCapture) Convert(_ context.Context, conf *confmap.Conf) error {
+	cc.mutex.Lock()
+	defer cc.mutex.Unlock()
+	cc.config = conf.ToStringMap()
+	return nil
+}
+
+// Config returns the captured configuration, nil if none was converted.
+func (cc *ConfigCapture) Config() map[string]any {
+	cc.mutex.Lock()
+	defer cc.mutex.Unlock()
+	return cc.config
+}
+
+// Provenance returns the provenance of the keys of the captured configuration.
+func (cc *ConfigCapture) Provenance() map[string]configprovider.KeyProvenance {
+	cc.mutex.Lock()
+	defer cc.mutex.Unlock()
+	return cc.provenance
+}
+
+// RedactChange redacts the values of the keys looking like credentials in the change, like on the
+// effective config endpoint, in addition to the ones of the sensitive config sources.
+func RedactChange(change configprovider.ConfigChange) configprovider.ConfigChange {
+	if len(change.Added) > 0 {
+		change.Added = simpleRedact(change.Added)
+	}
+	if len(change.Removed) > 0 {
+		change.Removed = simpleRedact(change.Removed)
+	}
+	if len(change.Modified) > 0 {
+		before := map[string]any{}
+		after := map[string]any{}
+		for k, v := range change.Modified {
+			before[k], after[k] = v.Before, v.After
+		}
+		before, after = simpleRedact(before), simpleRedact(after)
+		modified := make(map[string]configprovider.ValueChange, len(change.Modified))
+		for k := range change.Modified {
+			modified[k] = configprovider.ValueChange{Before: before[k], After: after[k]}
+		}
+		change.Modified = modified
+	}
+	return change
+}
@@ -0,0 +1,58 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestConfigCapture(t *testing.T) {
+	capture := NewConfigCapture()
+	assert.Nil(t, capture.Config())
+
+	capture.OnProvenance("file", map[string]configprovider.KeyProvenance{"a": {URI: "file:config.yaml"}})
+	capture.OnProvenance("env", map[string]configprovider.KeyProvenance{"b": {URI: "env:OVERRIDES"}})
+	require.NoError(t, capture.Convert(context.Background(), confmap.NewFromStringMap(map[string]any{"a": 1, "b": 2})))
+	assert.Equal(t, map[string]any{"a": 1, "b": 2}, capture.Config())
+	assert.Equal(t, map[string]configprovider.KeyProvenance{
+		"a": {URI: "file:config.yaml"},
+		"b": {URI: "env:OVERRIDES"},
+	}, capture.Provenance())
+}
+
+func TestRedactChange(t *testing.T) {
+	change := RedactChange(configprovider.ConfigChange{
+		Added:   map[string]any{"extensions::basicauth::password": "hunter2", "receivers::otlp": nil},
+		Removed: map[string]any{"exporters::splunk_hec::token": "old"},
+		Modified: map[string]configprovider.ValueChange{
+			"exporters::otlp::endpoint":            {Before: "localhost:4317", After: "localhost:4318"},
+			"exporters::otlp::headers::auth_token": {Before: "old", After: "new"},
+		},
+	})
+	assert.Equal(t, map[string]any{"extensions::basicauth::password": "<redacted>", "receivers::otlp": nil}, change.Added)
+	assert.Equal(t, map[string]any{"exporters::splunk_hec::token": "<redacted>"}, change.Removed)
+	assert.Equal(t, map[string]configprovider.ValueChange{
+		"exporters::otlp::endpoint":            {Before: "localhost:4317", After: "localhost:4318"},
+		"exporters::otlp::headers::auth_token": {Before: "<redacted>", After: "<redacted>"},
+	}, change.Modified)
+}
@@ -41,6 +41,7 @@ const (
 	defaultConfigServerEndpoint = "localhost:55554"
 	effectivePath               = "/debug/configz/effective"
 	initialPath                 = "/debug/configz/initial"
+	provenancePath              = "/debug/configz/provenance"
 )
 
 type ConfigType int
@@ -51,24 +52,28 @@ const (
 )
 
 var _ confmap.Converter = (*ConfigServer)(nil)
-var _ configprovider.Hook = (*ConfigServer)(nil)
+var _ configprovider.ProvenanceHook = (*ConfigServer)(nil)
 
 type ConfigServer struct {
 	// Use get/set methods instead of direct usage
-	initial        map[string]any
-	effective      map[string]any
-	server         *http.Server
-	doneCh         chan struct{}
-	initialMutex   sync.RWMutex
-	effectiveMutex sync.RWMutex
-	wg             sync.WaitGroup
-	once           sync.Once
+	initial         map[string]any
+	effective       map[string]any
+	provenance      map[string]map[string]configprovider.KeyProvenance
+	server          *http.Server
+	mux             *http.ServeMux
+	doneCh          chan struct{}
+	initialMutex    sync.RWMutex
+	effectiveMutex  sync.RWMutex
+	provenanceMutex sync.RWMutex
+	wg              sync.WaitGroup
+	once            sync.Once
 }
 
 func NewConfigServer() *ConfigServer {
 	cs := &ConfigServer{
 		initial:        map[string]any{},
 		effective:      map[string]any{},
+		provenance:     map[string]map[string]configprovider.KeyProvenance{},
 		initialMutex:   sync.RWMutex{},
 		effectiveMutex: sync.RWMutex{},
 		wg:             sync.WaitGroup{},
@@ -82,7 +87,9 @@ func NewConfigServer() *ConfigServer {
 
 	effectiveHandleFunc := cs.muxHandleFunc(effectiveConfig)
 	mux.HandleFunc(effectivePath, effectiveHandleFunc)
+	mux.HandleFunc(provenancePath, cs.provenanceHandleFunc)
 
+	cs.mux = mux
 	cs.server = &http.Server{
 		ReadHeaderTimeout: 20 * time.Second,
 		Handler:           mux,
@@ -90,6 +97,12 @@ func NewConfigServer() *ConfigServer {
 	return cs
 }
 
+// Handle registers an additional handler for the given pattern on the config server.
+// It must be called before the server is started.
+func (cs *ConfigServer) Handle(pattern string, handler http.Handler) {
+	cs.mux.Handle(pattern, handler)
+}
+
 // Convert is intended to be called as the final service confmap.Converter,
 // which registers the service config before being finally resolved and unmarshalled.
 func (cs *ConfigServer) Convert(_ context.Context, conf *confmap.Conf) error {
@@ -108,6 +121,20 @@ func (cs *ConfigServer) OnRetrieve(scheme string, retrieved map[string]any) {
 	cs.initial[scheme] = retrieved
 }
 
+// OnProvenance records the config sources supplying each key of the configuration retrieved
+// for the scheme, so they are served along the effective configuration.
+func (cs *ConfigServer) OnProvenance(scheme string, provenance map[string]configprovider.KeyProvenance) {
+	cs.provenanceMutex.Lock()
+	defer cs.provenanceMutex.Unlock()
+	cs.provenance[scheme] = provenance
+}
+
+func (cs *ConfigServer) getProvenance() map[string]map[string]configprovider.KeyProvenance {
+	cs.provenanceMutex.RLock()
+	defer cs.provenanceMutex.RUnlock()
+	return cs.provenance
+}
+
 func (cs *ConfigServer) getInitial() map[string]any {
 	cs.initialMutex.RLock()
 	defer cs.initialMutex.RUnlock()
@@ -192,12 +219,46 @@ func (cs *ConfigServer) muxHandleFunc(configType ConfigType) func(http.ResponseW
 		if configType == initialConfig {
 			configYAML, _ = yaml.Marshal(cs.getInitial())
 		} else {
-			configYAML, _ = yaml.Marshal(simpleRedact(cs.getEffective()))
+			annotate := request.URL.Query().Get("provenance") == "true"
+			configYAML, _ = cs.EffectiveConfig(annotate)
 		}
 		_, _ = writer.Write(configYAML)
 	}
 }
 
+func (cs *ConfigServer) provenanceHandleFunc(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != "GET" {
+		writer.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	provenanceYAML, _ := yaml.Marshal(cs.getProvenance())
+	_, _ = writer.Write(provenanceYAML)
+}
+
+// EffectiveConfig returns the YAML of the effective configuration with the sensitive values
+// redacted, like on the effective config endpoint, e.g. to report it to a management server
+// instead of the unresolved configuration. If annotate is set each value is commented with its
+// provenance: the config source invocations that supplied it, or else the URI it came from.
+func (cs *ConfigServer) EffectiveConfig(annotate bool) ([]byte, error) {
+	redacted := simpleRedact(cs.redactSensitive(cs.getEffective()))
+	if annotate {
+		return annotatedYAML(redacted, cs.keyProvenance())
+	}
+	return yaml.Marshal(redacted)
+}
+
+// redactSensitive redacts the values resolved from sensitive config sources.
+func (cs *ConfigServer) redactSensitive(config map[string]any) map[string]any {
+	provenance := map[string]configprovider.KeyProvenance{}
+	for k, p := range cs.keyProvenance() {
+		if p.Redacted {
+			provenance[k] = p
+		}
+	}
+	return configprovider.Redact(config, provenance)
+}
+
 func simpleRedact(config map[string]any) map[string]any {
 	redactedConfig := make(map[string]any)
 	for k, v := range config {
@@ -28,6 +28,7 @@ import (
 	"go.opentelemetry.io/collector/confmap"
 	"gopkg.in/yaml.v2"
 
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
 	"github.com/signalfx/splunk-otel-collector/tests/testutils"
 )
 
@@ -140,7 +141,7 @@ func TestConfigServer_Serve(t *testing.T) {
 		"int":     42,
 		"map": map[string]any{
 			"k0":       true,
-			"k1":       -1,
+			"k1":       "<redacted>",
 			"password": "<redacted>",
 		},
 	}
@@ -151,11 +152,27 @@ func TestConfigServer_Serve(t *testing.T) {
 	t.Cleanup(cs.OnShutdown)
 
 	cs.OnRetrieve("scheme", initial)
+	cs.OnProvenance("scheme", map[string]configprovider.KeyProvenance{
+		"api_key":    {URI: "file:config.yaml", ConfigSources: []string{"vault:secret/data/key"}, Redacted: true},
+		"field":      {URI: "file:config.yaml"},
+		"map::k1":    {URI: "file:config.yaml", ConfigSources: []string{"vault:secret/data/k1"}, Redacted: true},
+		"map::other": {URI: "file:config.yaml", ConfigSources: []string{"env:OTHER"}},
+	})
 	require.NoError(t, cs.Convert(context.Background(), confmap.NewFromStringMap(initial)))
 
 	// Test for the pages to be actually valid YAML files.
 	assertValidYAMLPages(t, map[string]any{"scheme": initial}, "/debug/configz/initial")
 	assertValidYAMLPages(t, effective, "/debug/configz/effective")
+	assertValidYAMLPages(t, map[string]any{
+		"scheme": map[string]any{
+			"api_key": map[string]any{"uri": "file:config.yaml", "config_sources": []any{"vault:secret/data/key"}, "redacted": true},
+			"field":   map[string]any{"uri": "file:config.yaml"},
+			"map": map[string]any{
+				"k1":    map[string]any{"uri": "file:config.yaml", "config_sources": []any{"vault:secret/data/k1"}, "redacted": true},
+				"other": map[string]any{"uri": "file:config.yaml", "config_sources": []any{"env:OTHER"}},
+			},
+		},
+	}, "/debug/configz/provenance")
 }
 
 func assertValidYAMLPages(t *testing.T, expected map[string]any, path string) {
@@ -0,0 +1,57 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+var _ confmap.Converter = (*ConfigSnapshot)(nil)
+var _ configprovider.Hook = (*ConfigSnapshot)(nil)
+
+// ConfigSnapshot records the values retrieved by the config sources and, once the configuration
+// is resolved, writes them to the snapshot bundle used by the offline mode and exits.
+type ConfigSnapshot struct {
+	*configprovider.SnapshotRecorder
+	path string
+}
+
+// NewConfigSnapshot creates a ConfigSnapshot writing to path, it is disabled if path is empty.
+func NewConfigSnapshot(path string) *ConfigSnapshot {
+	cs := &ConfigSnapshot{path: path}
+	if path != "" {
+		cs.SnapshotRecorder = configprovider.NewSnapshotRecorder()
+	}
+	return cs
+}
+
+func (cs *ConfigSnapshot) Convert(context.Context, *confmap.Conf) error {
+	if cs == nil || cs.SnapshotRecorder == nil {
+		return nil
+	}
+	if err := cs.WriteFile(cs.path); err != nil {
+		return fmt.Errorf("failed writing the config snapshot: %w", err)
+	}
+	fmt.Fprintf(os.Stdout, "Config snapshot written to %s\n", cs.path)
+	os.Exit(0)
+	return nil
+}
@@ -0,0 +1,137 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sort"
+
+	"github.com/spf13/cast"
+	"gopkg.in/yaml.v2"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+// configSourcesPath is the path of the page with the state of the config sources, served as
+// HTML or, with the "format=json" query parameter, as JSON.
+const configSourcesPath = "/debug/configsources"
+
+// configSourceState is the state of a config source reported by the config sources page.
+type configSourceState struct {
+	Settings       map[string]any               `json:"settings,omitempty"`
+	Name           string                       `json:"name"`
+	RecentErrors   []configprovider.SourceError `json:"recent_errors,omitempty"`
+	Health         configprovider.SourceHealth  `json:"health"`
+	ActiveWatchers int64                        `json:"active_watchers"`
+}
+
+var configSourcesTemplate = template.Must(template.New("configsources").Funcs(template.FuncMap{
+	"yaml": func(v any) string {
+		out, _ := yaml.Marshal(v)
+		return string(out)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><title>Config Sources</title></head>
+<body>
+<h1>Config Sources</h1>
+<table border="1" cellpadding="4">
+<tr><th>Name</th><th>Settings</th><th>Active watchers</th><th>Last successful retrieve</th><th>Last change</th><th>Consecutive failures</th><th>Circuit breaker</th><th>Recent errors</th></tr>
+{{range .}}<tr>
+<td>{{.Name}}</td>
+<td><pre>{{yaml .Settings}}</pre></td>
+<td>{{.ActiveWatchers}}</td>
+<td>{{if not .Health.LastSuccessfulRetrieve.IsZero}}{{.Health.LastSuccessfulRetrieve.Format "2006-01-02T15:04:05Z07:00"}}{{end}}</td>
+<td>{{if not .Health.LastChange.IsZero}}{{.Health.LastChange.Format "2006-01-02T15:04:05Z07:00"}}{{end}}</td>
+<td>{{.Health.ConsecutiveFailures}}</td>
+<td>{{.Health.CircuitBreaker}}</td>
+<td>{{range .RecentErrors}}{{.Time.Format "2006-01-02T15:04:05Z07:00"}} {{.Error}}<br>{{end}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// HandleConfigSources registers the page with the state of the config sources: their settings, with
+// the sensitive values redacted, the number of retrieved values being watched, and their health, as
+// tracked by reporter, including their recent errors. It must be called before the server is started.
+func (cs *ConfigServer) HandleConfigSources(reporter *configprovider.HealthReporter) {
+	cs.mux.HandleFunc(configSourcesPath, func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			writer.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		states := cs.configSourceStates(reporter)
+		if request.URL.Query().Get("format") == "json" {
+			writer.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(writer).Encode(states)
+			return
+		}
+		writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = configSourcesTemplate.Execute(writer, states)
+	})
+}
+
+// configSourceStates returns the state of the config sources declared in the configuration, or
+// used by it, sorted by name.
+func (cs *ConfigServer) configSourceStates(reporter *configprovider.HealthReporter) []configSourceState {
+	states := map[string]*configSourceState{}
+	state := func(name string) *configSourceState {
+		if states[name] == nil {
+			states[name] = &configSourceState{Name: name}
+		}
+		return states[name]
+	}
+
+	// The config_sources section isn't part of the effective configuration, their settings are
+	// taken from the configuration retrieved for each scheme before its resolution.
+	initial := cs.getInitial()
+	schemes := make([]string, 0, len(initial))
+	for scheme := range initial {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	for _, scheme := range schemes {
+		configSources := cast.ToStringMap(cast.ToStringMap(initial[scheme])["config_sources"])
+		for name, settings := range configSources {
+			state(name).Settings = simpleRedact(cast.ToStringMap(settings))
+		}
+	}
+
+	for name, health := range reporter.Sources() {
+		state(name).Health = health
+	}
+	for name, errs := range reporter.RecentErrors() {
+		state(name).RecentErrors = errs
+	}
+	for name, count := range configprovider.ActiveWatchers() {
+		if count > 0 || states[name] != nil {
+			state(name).ActiveWatchers = count
+		}
+	}
+
+	sorted := make([]configSourceState, 0, len(states))
+	for _, s := range states {
+		sorted = append(sorted, *s)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
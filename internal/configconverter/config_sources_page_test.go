@@ -0,0 +1,84 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestConfigServer_ConfigSources(t *testing.T) {
+	reporter := configprovider.NewHealthReporter()
+	cs := NewConfigServer()
+	cs.HandleConfigSources(reporter)
+
+	cs.OnRetrieve("file", map[string]any{
+		"config_sources": map[string]any{
+			"vault": map[string]any{
+				"endpoint": "https://vault:8200",
+				"auth": map[string]any{
+					"token": "s.secret",
+				},
+			},
+			"env": nil,
+		},
+		"receivers": map[string]any{"otlp": nil},
+	})
+	reporter.OnSourceRetrieve("env", nil)
+	reporter.OnSourceRetrieve("vault", errors.New("permission denied"))
+
+	recorder := httptest.NewRecorder()
+	cs.mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, configSourcesPath+"?format=json", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	var states []configSourceState
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &states))
+	require.Len(t, states, 2)
+	assert.Equal(t, "env", states[0].Name)
+	assert.Empty(t, states[0].Settings)
+	assert.True(t, states[0].Health.Healthy())
+	assert.Empty(t, states[0].RecentErrors)
+
+	assert.Equal(t, "vault", states[1].Name)
+	assert.Equal(t, map[string]any{
+		"endpoint": "https://vault:8200",
+		"auth":     map[string]any{"token": "<redacted>"},
+	}, states[1].Settings)
+	assert.Equal(t, 1, states[1].Health.ConsecutiveFailures)
+	require.Len(t, states[1].RecentErrors, 1)
+	assert.Equal(t, "permission denied", states[1].RecentErrors[0].Error)
+
+	recorder = httptest.NewRecorder()
+	cs.mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, configSourcesPath, nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/html; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), "<td>vault</td>")
+	assert.Contains(t, recorder.Body.String(), "permission denied")
+	assert.NotContains(t, recorder.Body.String(), "s.secret")
+
+	recorder = httptest.NewRecorder()
+	cs.mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, configSourcesPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
@@ -27,57 +27,68 @@ import (
 )
 
 var _ confmap.Converter = (*DryRun)(nil)
-var _ configprovider.Hook = (*DryRun)(nil)
+var _ configprovider.ProvenanceHook = (*DryRun)(nil)
 
+// DryRun prints the final configuration, once discovered, resolved, and converted, with the
+// sensitive values redacted like on the effective config endpoint, and exits. It must follow the
+// converters modifying the configuration so the one printed is the one the service would run.
 type DryRun struct {
-	*sync.Mutex
-	configs []map[string]any
-	enabled bool
+	provenance map[string]map[string]configprovider.KeyProvenance
+	mutex      sync.Mutex
+	enabled    bool
 }
 
 func NewDryRun(enabled bool) *DryRun {
 	return &DryRun{
-		Mutex:   &sync.Mutex{},
-		enabled: enabled,
-		configs: []map[string]any{},
+		enabled:    enabled,
+		provenance: map[string]map[string]configprovider.KeyProvenance{},
 	}
 }
 
 func (dr *DryRun) OnNew() {}
 
-func (dr *DryRun) OnRetrieve(_ string, retrieved map[string]any) {
-	if dr == nil || !dr.enabled {
+func (dr *DryRun) OnRetrieve(string, map[string]any) {}
+
+// OnProvenance records the provenance of the keys resolved for the scheme to redact the values
+// of the sensitive config sources. It is recorded even if --dry-run is not set so Redact can be
+// used for other reports of the configuration, e.g. crash reports.
+func (dr *DryRun) OnProvenance(scheme string, provenance map[string]configprovider.KeyProvenance) {
+	if dr == nil {
 		return
 	}
-	dr.Lock()
-	defer dr.Unlock()
-	dr.configs = append(dr.configs, retrieved)
+	dr.mutex.Lock()
+	defer dr.mutex.Unlock()
+	dr.provenance[scheme] = provenance
 }
 
 func (dr *DryRun) OnShutdown() {}
 
-// Convert disregards the provided *confmap.Conf so that it will use
-// unexpanded values (env vars, config source directives) as
-// accrued by OnRetrieve() calls.
-func (dr *DryRun) Convert(context.Context, *confmap.Conf) error {
+func (dr *DryRun) Convert(_ context.Context, conf *confmap.Conf) error {
 	if dr == nil || !dr.enabled {
 		return nil
 	}
-	cm := confmap.New()
-	dr.Lock()
-	for _, cfg := range dr.configs {
-		if err := cm.Merge(confmap.NewFromStringMap(cfg)); err != nil {
-			dr.Unlock()
-			return err
-		}
-	}
-	dr.Unlock() // not deferred because we are exiting
-	out, err := yaml.Marshal(cm.ToStringMap())
+	out, err := yaml.Marshal(dr.Redact(conf.ToStringMap()))
 	if err != nil {
-		panic(fmt.Errorf("failed marshaling --dry-run config: %w", err))
+		return fmt.Errorf("failed marshaling --dry-run config: %w", err)
 	}
 	fmt.Fprintf(os.Stdout, "%s", out)
 	os.Stdout.Sync()
 	os.Exit(0)
 	return nil
 }
+
+// Redact returns a copy of the configuration with the values resolved from sensitive config
+// sources and the ones of the keys looking like credentials redacted.
+func (dr *DryRun) Redact(config map[string]any) map[string]any {
+	sensitive := map[string]configprovider.KeyProvenance{}
+	dr.mutex.Lock()
+	for _, schemeProvenance := range dr.provenance {
+		for k, p := range schemeProvenance {
+			if p.Redacted {
+				sensitive[k] = p
+			}
+		}
+	}
+	dr.mutex.Unlock()
+	return simpleRedact(configprovider.Redact(config, sensitive))
+}
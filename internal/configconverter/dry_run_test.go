@@ -24,17 +24,33 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/confmap"
 	"gopkg.in/yaml.v2"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
 )
 
 func TestDryRun(t *testing.T) {
-	cfgOne := confmap.NewFromStringMap(map[string]any{"key.one": "value.one"})
+	conf := confmap.NewFromStringMap(map[string]any{
+		"exporters": map[string]any{
+			"otlp": map[string]any{
+				"endpoint": "localhost:4317",
+				"headers":  map[string]any{"x-custom-header": "secret"},
+			},
+		},
+		"extensions": map[string]any{
+			"basicauth": map[string]any{"password": "hunter2"},
+		},
+	})
+	provenance := map[string]configprovider.KeyProvenance{
+		"exporters::otlp::headers::x-custom-header": {ConfigSources: []string{"vault:secret/token"}, Redacted: true},
+		"exporters::otlp::endpoint":                 {URI: "file:config.yaml"},
+	}
+
 	dr := NewDryRun(false)
 	dr.OnNew()
 	defer func() { require.NotPanics(t, dr.OnShutdown) }()
-
-	dr.OnRetrieve("some.scheme", cfgOne.ToStringMap())
+	dr.OnProvenance("file", provenance)
 	require.NotPanics(t, func() {
-		dr.Convert(context.Background(), cfgOne)
+		require.NoError(t, dr.Convert(context.Background(), conf))
 	})
 
 	origStdOut := os.Stdout
@@ -55,17 +71,25 @@ func TestDryRun(t *testing.T) {
 	}())
 
 	dr = NewDryRun(true)
-	cfgTwo := confmap.NewFromStringMap(map[string]any{"key.two": "value.two"})
-	dr.OnRetrieve("some.scheme", cfgOne.ToStringMap())
-	dr.OnRetrieve("another.scheme", cfgTwo.ToStringMap())
+	dr.OnProvenance("file", provenance)
 	require.Panics(t, func() {
-		dr.Convert(context.Background(), cfgTwo)
+		dr.Convert(context.Background(), conf)
 	})
 	stdout.Seek(0, 0)
 	out, err := io.ReadAll(stdout)
 	require.NoError(t, err)
 	actual := map[string]any{}
-	yaml.Unmarshal(out, &actual)
-	expected := map[string]any{"key.one": "value.one", "key.two": "value.two"}
+	require.NoError(t, yaml.Unmarshal(out, &actual))
+	expected := map[string]any{
+		"exporters": map[any]any{
+			"otlp": map[any]any{
+				"endpoint": "localhost:4317",
+				"headers":  map[any]any{"x-custom-header": "<redacted>"},
+			},
+		},
+		"extensions": map[any]any{
+			"basicauth": map[any]any{"password": "<redacted>"},
+		},
+	}
 	require.Equal(t, expected, actual)
 }
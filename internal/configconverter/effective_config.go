@@ -0,0 +1,94 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"bytes"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+// annotatedYAML returns the YAML of the configuration with each value commented with the
+// provenance of its key.
+func annotatedYAML(config map[string]any, provenance map[string]configprovider.KeyProvenance) ([]byte, error) {
+	var node yaml.Node
+	if err := node.Encode(config); err != nil {
+		return nil, err
+	}
+	annotateProvenance(&node, "", provenance)
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// keyProvenance returns the provenance of the keys of the configurations retrieved for all the schemes.
+func (cs *ConfigServer) keyProvenance() map[string]configprovider.KeyProvenance {
+	provenance := map[string]configprovider.KeyProvenance{}
+	for _, schemeProvenance := range cs.getProvenance() {
+		for k, p := range schemeProvenance {
+			provenance[k] = p
+		}
+	}
+	return provenance
+}
+
+// annotateProvenance comments the values of the mapping nodes with the provenance of their keys,
+// flattened with the confmap.KeyDelimiter like the keys of the provenance.
+func annotateProvenance(node *yaml.Node, key string, provenance map[string]configprovider.KeyProvenance) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, n := range node.Content {
+			annotateProvenance(n, key, provenance)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			childKey := keyNode.Value
+			if key != "" {
+				childKey = key + confmap.KeyDelimiter + childKey
+			}
+			if comment := provenanceComment(provenance[childKey]); comment != "" {
+				if valueNode.Kind == yaml.ScalarNode {
+					valueNode.LineComment = comment
+				} else {
+					keyNode.LineComment = comment
+				}
+			}
+			annotateProvenance(valueNode, childKey, provenance)
+		}
+	}
+}
+
+func provenanceComment(provenance configprovider.KeyProvenance) string {
+	switch {
+	case len(provenance.ConfigSources) > 0:
+		return strings.Join(provenance.ConfigSources, ", ")
+	case provenance.URI != "":
+		return provenance.URI
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestEffectiveConfig(t *testing.T) {
+	cs := NewConfigServer()
+	cs.OnProvenance("file", map[string]configprovider.KeyProvenance{
+		"api_key":   {URI: "file:config.yaml", ConfigSources: []string{"vault:secret/data/key"}, Redacted: true},
+		"field":     {URI: "file:config.yaml"},
+		"map::k0":   {URI: "file:config.yaml", ConfigSources: []string{"env:K0", "env:K0_SUFFIX"}},
+		"map::list": {URI: "file:config.yaml", ConfigSources: []string{"include:/etc/list.yaml"}},
+	})
+	require.NoError(t, cs.Convert(context.Background(), confmap.NewFromStringMap(map[string]any{
+		"api_key": "s3cr3t",
+		"field":   "not_redacted",
+		"map": map[string]any{
+			"k0":   true,
+			"list": []any{"a", "b"},
+		},
+	})))
+	expected := map[string]any{
+		"api_key": "<redacted>",
+		"field":   "not_redacted",
+		"map": map[string]any{
+			"k0":   true,
+			"list": []any{"a", "b"},
+		},
+	}
+
+	effective, err := cs.EffectiveConfig(false)
+	require.NoError(t, err)
+	assert.NotContains(t, string(effective), "#")
+	assert.NotContains(t, string(effective), "s3cr3t")
+	var actual map[string]any
+	require.NoError(t, yaml.Unmarshal(effective, &actual))
+	assert.Equal(t, expected, actual)
+
+	annotated, err := cs.EffectiveConfig(true)
+	require.NoError(t, err)
+	assert.NotContains(t, string(annotated), "s3cr3t")
+	assert.Contains(t, string(annotated), "api_key: <redacted> # vault:secret/data/key\n")
+	assert.Contains(t, string(annotated), "field: not_redacted # file:config.yaml\n")
+	assert.Contains(t, string(annotated), "k0: true # env:K0, env:K0_SUFFIX\n")
+	assert.Contains(t, string(annotated), "# include:/etc/list.yaml")
+	actual = nil
+	require.NoError(t, yaml.Unmarshal(annotated, &actual))
+	assert.Equal(t, expected, actual)
+}
@@ -0,0 +1,150 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// K8sExportKey is the key of the Secret or ConfigMap data holding the exported configuration.
+const K8sExportKey = "config.yaml"
+
+var _ confmap.Converter = (*K8sConfigExport)(nil)
+
+// K8sConfigExport writes the resolved configuration, with the values of its config sources, to a
+// Kubernetes Secret or ConfigMap and exits, so a privileged job can render the configuration of
+// collectors that don't have access to the config sources.
+type K8sConfigExport struct {
+	newClient func() (kubernetes.Interface, error)
+	kind      string
+	namespace string
+	name      string
+}
+
+// NewK8sConfigExport creates a K8sConfigExport writing to the "<secret|configmap>/<namespace>/<name>"
+// target, it is disabled if target is empty. The client uses the KUBECONFIG env var, the default
+// kubeconfig file, or the in-cluster configuration.
+func NewK8sConfigExport(target string) (*K8sConfigExport, error) {
+	if target == "" {
+		return &K8sConfigExport{}, nil
+	}
+	parts := strings.Split(target, "/")
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("invalid Kubernetes export target %q, must be <secret|configmap>/<namespace>/<name>", target)
+	}
+	kind := strings.ToLower(parts[0])
+	if kind != "secret" && kind != "configmap" {
+		return nil, fmt.Errorf("invalid Kubernetes export target %q, the kind must be secret or configmap", target)
+	}
+	return &K8sConfigExport{
+		newClient: newK8sClient,
+		kind:      kind,
+		namespace: parts[1],
+		name:      parts[2],
+	}, nil
+}
+
+func newK8sClient() (kubernetes.Interface, error) {
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the Kubernetes client configuration: %w", err)
+	}
+	return kubernetes.NewForConfig(restConfig)
+}
+
+func (e *K8sConfigExport) String() string {
+	return e.kind + "/" + e.namespace + "/" + e.name
+}
+
+func (e *K8sConfigExport) Convert(ctx context.Context, conf *confmap.Conf) error {
+	if e == nil || e.newClient == nil {
+		return nil
+	}
+	out, err := yaml.Marshal(conf.ToStringMap())
+	if err != nil {
+		return fmt.Errorf("failed marshaling the configuration to export: %w", err)
+	}
+	client, err := e.newClient()
+	if err != nil {
+		return err
+	}
+	if err = e.export(ctx, client, out); err != nil {
+		return fmt.Errorf("failed exporting the configuration to %s: %w", e, err)
+	}
+	fmt.Fprintf(os.Stdout, "Configuration written to %s\n", e)
+	os.Exit(0)
+	return nil
+}
+
+// export creates the Secret or ConfigMap holding the configuration, or updates its data if it
+// exists, keeping its other keys.
+func (e *K8sConfigExport) export(ctx context.Context, client kubernetes.Interface, config []byte) error {
+	meta := metav1.ObjectMeta{
+		Name:      e.name,
+		Namespace: e.namespace,
+		Labels:    map[string]string{"app.kubernetes.io/managed-by": "splunk-otel-collector"},
+	}
+	if e.kind == "secret" {
+		secrets := client.CoreV1().Secrets(e.namespace)
+		secret, err := secrets.Get(ctx, e.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			_, err = secrets.Create(ctx, &corev1.Secret{
+				ObjectMeta: meta,
+				Type:       corev1.SecretTypeOpaque,
+				Data:       map[string][]byte{K8sExportKey: config},
+			}, metav1.CreateOptions{})
+			return err
+		} else if err != nil {
+			return err
+		}
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data[K8sExportKey] = config
+		_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+		return err
+	}
+
+	configMaps := client.CoreV1().ConfigMaps(e.namespace)
+	configMap, err := configMaps.Get(ctx, e.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = configMaps.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: meta,
+			Data:       map[string]string{K8sExportKey: string(config)},
+		}, metav1.CreateOptions{})
+		return err
+	} else if err != nil {
+		return err
+	}
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[K8sExportKey] = string(config)
+	_, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+	return err
+}
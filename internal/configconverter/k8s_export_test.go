@@ -0,0 +1,79 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNewK8sConfigExport(t *testing.T) {
+	export, err := NewK8sConfigExport("")
+	require.NoError(t, err)
+	require.NoError(t, export.Convert(context.Background(), confmap.New()))
+
+	export, err = NewK8sConfigExport("Secret/monitoring/collector-config")
+	require.NoError(t, err)
+	assert.Equal(t, "secret/monitoring/collector-config", export.String())
+
+	_, err = NewK8sConfigExport("secret/collector-config")
+	assert.EqualError(t, err, `invalid Kubernetes export target "secret/collector-config", must be <secret|configmap>/<namespace>/<name>`)
+	_, err = NewK8sConfigExport("pod/monitoring/collector-config")
+	assert.EqualError(t, err, `invalid Kubernetes export target "pod/monitoring/collector-config", the kind must be secret or configmap`)
+}
+
+func TestK8sConfigExportSecret(t *testing.T) {
+	export, err := NewK8sConfigExport("secret/monitoring/collector-config")
+	require.NoError(t, err)
+	client := fake.NewSimpleClientset()
+	ctx := context.Background()
+
+	require.NoError(t, export.export(ctx, client, []byte("receivers: {}\n")))
+	secret, err := client.CoreV1().Secrets("monitoring").Get(ctx, "collector-config", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{K8sExportKey: []byte("receivers: {}\n")}, secret.Data)
+	assert.Equal(t, "splunk-otel-collector", secret.Labels["app.kubernetes.io/managed-by"])
+
+	// The existing secret is updated, keeping its other keys.
+	secret.Data["other"] = []byte("value")
+	_, err = client.CoreV1().Secrets("monitoring").Update(ctx, secret, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, export.export(ctx, client, []byte("exporters: {}\n")))
+	secret, err = client.CoreV1().Secrets("monitoring").Get(ctx, "collector-config", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{K8sExportKey: []byte("exporters: {}\n"), "other": []byte("value")}, secret.Data)
+}
+
+func TestK8sConfigExportConfigMap(t *testing.T) {
+	export, err := NewK8sConfigExport("configmap/monitoring/collector-config")
+	require.NoError(t, err)
+	client := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "collector-config", Namespace: "monitoring"},
+	})
+	ctx := context.Background()
+
+	require.NoError(t, export.export(ctx, client, []byte("receivers: {}\n")))
+	configMap, err := client.CoreV1().ConfigMaps("monitoring").Get(ctx, "collector-config", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{K8sExportKey: "receivers: {}\n"}, configMap.Data)
+}
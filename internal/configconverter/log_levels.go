@@ -0,0 +1,81 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/signalfx/splunk-otel-collector/internal/loglevel"
+)
+
+const (
+	logLevelKey        = "service::telemetry::logs::level"
+	componentLevelsKey = "service::telemetry::logs::component_levels"
+)
+
+var _ confmap.Converter = (*LogLevels)(nil)
+
+// LogLevels is a confmap.Converter that applies the configured service log level
+// and the component log levels, under service::telemetry::logs::component_levels,
+// to a loglevel.Controller. The component_levels key is not supported by the
+// collector core service so it is removed from the configuration. Levels changed
+// at runtime via the loglevel.Controller are kept across configuration reloads.
+type LogLevels struct {
+	controller *loglevel.Controller
+}
+
+func NewLogLevels(controller *loglevel.Controller) *LogLevels {
+	return &LogLevels{controller: controller}
+}
+
+func (ll *LogLevels) Convert(_ context.Context, in *confmap.Conf) error {
+	if in == nil {
+		return fmt.Errorf("cannot LogLevels on nil *confmap.Conf")
+	}
+
+	level := zapcore.InfoLevel
+	if configured, ok := in.Get(logLevelKey).(string); ok {
+		if err := level.UnmarshalText([]byte(configured)); err != nil {
+			return fmt.Errorf("invalid %q: %w", logLevelKey, err)
+		}
+	}
+
+	componentLevels := map[string]zapcore.Level{}
+	out := map[string]any{}
+	for _, k := range in.AllKeys() {
+		if !strings.HasPrefix(k, componentLevelsKey+confmap.KeyDelimiter) {
+			out[k] = in.Get(k)
+			continue
+		}
+
+		componentID := strings.TrimPrefix(k, componentLevelsKey+confmap.KeyDelimiter)
+		var componentLevel zapcore.Level
+		if err := componentLevel.UnmarshalText([]byte(fmt.Sprintf("%v", in.Get(k)))); err != nil {
+			return fmt.Errorf("invalid level for component %q: %w", componentID, err)
+		}
+		componentLevels[componentID] = componentLevel
+	}
+
+	ll.controller.SetConfiguredLevels(level, componentLevels)
+
+	*in = *confmap.NewFromStringMap(out)
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/signalfx/splunk-otel-collector/internal/loglevel"
+)
+
+func TestLogLevels(t *testing.T) {
+	cfgMap, err := confmaptest.LoadConf("testdata/log-levels.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, cfgMap)
+
+	controller := loglevel.NewController()
+	controller.SetComponentLevel("otlp", zapcore.DebugLevel)
+	err = NewLogLevels(controller).Convert(context.Background(), cfgMap)
+	require.NoError(t, err)
+
+	assert.False(t, cfgMap.IsSet("service::telemetry::logs::component_levels"))
+	assert.Equal(t, "warn", cfgMap.Get("service::telemetry::logs::level"))
+	assert.Equal(t, zapcore.WarnLevel, controller.Level())
+	assert.Equal(t, map[string]zapcore.Level{
+		"signalfx":       zapcore.DebugLevel,
+		"otlp/secondary": zapcore.ErrorLevel,
+		"otlp":           zapcore.DebugLevel,
+	}, controller.ComponentLevels())
+
+	// Runtime overrides are kept across reloads until they are reset.
+	controller.SetComponentLevel("signalfx", zapcore.InfoLevel)
+	require.NoError(t, NewLogLevels(controller).Convert(context.Background(), confmap.NewFromStringMap(map[string]any{})))
+	assert.Equal(t, map[string]zapcore.Level{
+		"signalfx": zapcore.InfoLevel,
+		"otlp":     zapcore.DebugLevel,
+	}, controller.ComponentLevels())
+	controller.ResetComponentLevel("otlp")
+	controller.ResetComponentLevel("signalfx")
+	assert.Empty(t, controller.ComponentLevels())
+}
+
+func TestLogLevelsDefaults(t *testing.T) {
+	controller := loglevel.NewController()
+	controller.SetLevel(zapcore.ErrorLevel)
+	cfgMap := confmap.NewFromStringMap(map[string]any{"receivers": map[string]any{"otlp": nil}})
+	require.NoError(t, NewLogLevels(controller).Convert(context.Background(), cfgMap))
+
+	assert.Equal(t, zapcore.ErrorLevel, controller.Level())
+	assert.Empty(t, controller.ComponentLevels())
+
+	controller.ResetLevel()
+	assert.Equal(t, zapcore.InfoLevel, controller.Level())
+}
+
+func TestLogLevelsInvalid(t *testing.T) {
+	for _, cfg := range []map[string]any{
+		{"service": map[string]any{"telemetry": map[string]any{"logs": map[string]any{"level": "verbose"}}}},
+		{"service": map[string]any{"telemetry": map[string]any{"logs": map[string]any{"component_levels": map[string]any{"signalfx": "verbose"}}}}},
+	} {
+		err := NewLogLevels(loglevel.NewController()).Convert(context.Background(), confmap.NewFromStringMap(cfg))
+		assert.Error(t, err)
+	}
+}
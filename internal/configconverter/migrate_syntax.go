@@ -0,0 +1,154 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/spf13/cast"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/featuregate"
+	"gopkg.in/yaml.v2"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+var _ confmap.Converter = (*LegacySyntaxMigration)(nil)
+var _ configprovider.ResolutionHook = (*LegacySyntaxMigration)(nil)
+
+// LegacySyntaxMigration rewrites the legacy "$<config source>:<selector>" references of the
+// configuration to the bracketed "${<config source>:<selector>}" form before it is resolved,
+// logging a warning for each rewritten key. If a path is given the migrated configuration is
+// written to it, once resolved, and the collector exits. Nothing is rewritten while the legacy
+// expansion is turned off, see configprovider.DisableLegacyExpansionGateID.
+type LegacySyntaxMigration struct {
+	migrated map[string]any
+	warned   map[string]bool
+	path     string
+	mutex    sync.Mutex
+}
+
+// NewLegacySyntaxMigration creates a LegacySyntaxMigration writing the migrated configuration to
+// path, it is only rewritten in memory if path is empty.
+func NewLegacySyntaxMigration(path string) *LegacySyntaxMigration {
+	return &LegacySyntaxMigration{path: path, warned: map[string]bool{}}
+}
+
+func (m *LegacySyntaxMigration) OnNew() {}
+
+func (m *LegacySyntaxMigration) OnRetrieve(string, map[string]any) {}
+
+func (m *LegacySyntaxMigration) OnShutdown() {}
+
+// PreResolve rewrites the legacy references of the config sources declared in the configuration.
+func (m *LegacySyntaxMigration) PreResolve(_ context.Context, conf *confmap.Conf) error {
+	if featuregate.GetRegistry().IsEnabled(configprovider.DisableLegacyExpansionGateID) {
+		return nil
+	}
+	configSources := cast.ToStringMap(conf.Get("config_sources"))
+	isConfigSource := func(name string) bool {
+		_, ok := configSources[name]
+		return ok
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	migrated := m.migrateValue(conf.ToStringMap(), "", isConfigSource).(map[string]any)
+	if m.migrated == nil {
+		m.migrated = migrated
+	} else {
+		// Keep the keys of the configurations retrieved for the other schemes.
+		merged := confmap.NewFromStringMap(m.migrated)
+		if err := merged.Merge(confmap.NewFromStringMap(migrated)); err != nil {
+			return err
+		}
+		m.migrated = merged.ToStringMap()
+	}
+	*conf = *confmap.NewFromStringMap(migrated)
+	return nil
+}
+
+func (m *LegacySyntaxMigration) PostResolve(context.Context, *confmap.Conf) error {
+	return nil
+}
+
+// migrateValue returns a copy of the value with the legacy references of its strings rewritten.
+func (m *LegacySyntaxMigration) migrateValue(value any, key string, isConfigSource func(string) bool) any {
+	switch v := value.(type) {
+	case string:
+		migrated, reference, err := configprovider.MigrateLegacyReferences(v, isConfigSource)
+		switch {
+		case err != nil:
+			m.warnOnce(key, v, fmt.Sprintf("[WARNING] Unable to migrate the value of %q: %v. Please update the config manually.", key, err))
+		case reference != "":
+			m.warnOnce(key, v, fmt.Sprintf("[WARNING] Legacy config source reference %q of %q is deprecated, rewriting it to %q. "+
+				"Please update the config, e.g. with --migrate-config-syntax.", reference, key, migrated))
+		}
+		return migrated
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[k] = m.migrateValue(e, joinKey(key, k), isConfigSource)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = m.migrateValue(e, joinKey(key, strconv.Itoa(i)), isConfigSource)
+		}
+		return out
+	}
+	return value
+}
+
+// warnOnce logs the warning about the value of the key unless it was already logged, e.g. on a reload.
+func (m *LegacySyntaxMigration) warnOnce(key, value, warning string) {
+	if id := key + "=" + value; !m.warned[id] {
+		m.warned[id] = true
+		log.Println(warning)
+	}
+}
+
+// Convert writes the migrated configuration, if a path was given, and exits.
+func (m *LegacySyntaxMigration) Convert(context.Context, *confmap.Conf) error {
+	if m == nil || m.path == "" {
+		return nil
+	}
+	m.mutex.Lock()
+	out, err := yaml.Marshal(m.migrated)
+	m.mutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed marshaling the migrated config: %w", err)
+	}
+	if err = os.WriteFile(m.path, out, 0600); err != nil {
+		return fmt.Errorf("failed writing the migrated config: %w", err)
+	}
+	fmt.Fprintf(os.Stdout, "Migrated config written to %s\n", m.path)
+	os.Exit(0)
+	return nil
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + confmap.KeyDelimiter + key
+}
@@ -0,0 +1,84 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+func TestLegacySyntaxMigration(t *testing.T) {
+	m := NewLegacySyntaxMigration("")
+	conf := confmap.NewFromStringMap(map[string]any{
+		"config_sources": map[string]any{
+			"vault": map[string]any{
+				"endpoint": "$include:/etc/vault/endpoint",
+			},
+			"include": nil,
+		},
+		"exporters": map[string]any{
+			"signalfx": map[string]any{
+				"access_token": "$vault:secret/data/token?path=$.token",
+				"api_url":      "https://${env:API_HOST}",
+				"headers": []any{
+					"Bearer $vault:secret/data/bearer",
+					"$$escaped:value",
+				},
+				"realm": "$REALM",
+			},
+		},
+	})
+	require.NoError(t, m.PreResolve(context.Background(), conf))
+	assert.Equal(t, map[string]any{
+		"config_sources": map[string]any{
+			"vault": map[string]any{
+				"endpoint": "${include:/etc/vault/endpoint}",
+			},
+			"include": nil,
+		},
+		"exporters": map[string]any{
+			"signalfx": map[string]any{
+				"access_token": "${vault:secret/data/token?path=$.token}",
+				"api_url":      "https://${env:API_HOST}",
+				"headers": []any{
+					"Bearer ${vault:secret/data/bearer}",
+					"$$escaped:value",
+				},
+				"realm": "$REALM",
+			},
+		},
+	}, conf.ToStringMap())
+	assert.Len(t, m.warned, 3)
+
+	// The converter doesn't do anything without a path.
+	require.NoError(t, m.Convert(context.Background(), conf))
+	require.NoError(t, m.PostResolve(context.Background(), conf))
+}
+
+func TestLegacySyntaxMigrationKeepsInvalid(t *testing.T) {
+	m := NewLegacySyntaxMigration("")
+	conf := confmap.NewFromStringMap(map[string]any{
+		"config_sources": map[string]any{"vault": nil},
+		"key":            "$vault:secret/{token}}",
+	})
+	require.NoError(t, m.PreResolve(context.Background(), conf))
+	assert.Equal(t, "$vault:secret/{token}}", conf.Get("key"))
+	assert.Len(t, m.warned, 1)
+}
@@ -0,0 +1,260 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+var _ confmap.Converter = (*ConfigUpgrade)(nil)
+
+// UpgradeRule is a versioned rewrite of the configuration, e.g. renaming fields, moving sections, or
+// setting new defaults, upgrading the configurations written for the earlier collector versions.
+type UpgradeRule struct {
+	// Apply rewrites the configuration and returns the keys it changed, none if the rule doesn't apply.
+	Apply func(conf *confmap.Conf) ([]string, error)
+	// Version is the collector version introducing the change, e.g. "0.37.0". The rules are applied
+	// in version order, and in registration order for the same version.
+	Version string
+	// Description describes the change in the upgrade report.
+	Description string
+}
+
+// AppliedUpgradeRule is an UpgradeRule applied to the configuration with the keys it changed.
+type AppliedUpgradeRule struct {
+	Version     string   `json:"version" yaml:"version"`
+	Description string   `json:"description" yaml:"description"`
+	Keys        []string `json:"keys" yaml:"keys"`
+}
+
+// UpgradeReport lists the upgrade rules applied to the configuration, in order.
+type UpgradeReport []AppliedUpgradeRule
+
+var (
+	upgradeRules      []UpgradeRule
+	upgradeRulesMutex sync.RWMutex
+)
+
+// RegisterUpgradeRules registers rules applied by the ConfigUpgrade converter.
+func RegisterUpgradeRules(rules ...UpgradeRule) {
+	upgradeRulesMutex.Lock()
+	defer upgradeRulesMutex.Unlock()
+	upgradeRules = append(upgradeRules, rules...)
+	sort.SliceStable(upgradeRules, func(i, j int) bool {
+		return compareVersions(upgradeRules[i].Version, upgradeRules[j].Version) < 0
+	})
+}
+
+// ConfigUpgrade is a confmap.Converter applying the registered upgrade rules and logging a
+// warning for each rule applied with the keys it changed.
+type ConfigUpgrade struct{}
+
+func (u ConfigUpgrade) Convert(_ context.Context, in *confmap.Conf) error {
+	if in == nil {
+		return fmt.Errorf("cannot ConfigUpgrade on nil *confmap.Conf")
+	}
+	report, err := u.Upgrade(in)
+	for _, applied := range report {
+		log.Printf("[WARNING] %s (%s), changed: %s. Please update your config accordingly.",
+			applied.Description, applied.Version, strings.Join(applied.Keys, ", "))
+	}
+	return err
+}
+
+// Upgrade applies the registered upgrade rules to the configuration and reports the ones applied.
+func (u ConfigUpgrade) Upgrade(in *confmap.Conf) (UpgradeReport, error) {
+	upgradeRulesMutex.RLock()
+	rules := upgradeRules
+	upgradeRulesMutex.RUnlock()
+
+	var report UpgradeReport
+	for _, rule := range rules {
+		keys, err := rule.Apply(in)
+		if err != nil {
+			return report, fmt.Errorf("failed to upgrade the config for %s, %s: %w", rule.Version, rule.Description, err)
+		}
+		if len(keys) > 0 {
+			report = append(report, AppliedUpgradeRule{Version: rule.Version, Description: rule.Description, Keys: keys})
+		}
+	}
+	return report, nil
+}
+
+// RenameKeys returns an UpgradeRule renaming, or moving, the keys matching the regular expression,
+// anchored to the whole key, to the replacement, expanded like by regexp.Regexp.ReplaceAllString.
+func RenameKeys(version, description, expr, replacement string) UpgradeRule {
+	re := regexp.MustCompile("^(?:" + expr + ")$")
+	return UpgradeRule{
+		Version:     version,
+		Description: description,
+		Apply: func(in *confmap.Conf) ([]string, error) {
+			return rewriteKeys(in, func(k string, v any, out map[string]any) bool {
+				if !re.MatchString(k) {
+					return false
+				}
+				out[re.ReplaceAllString(k, replacement)] = v
+				return true
+			}), nil
+		},
+	}
+}
+
+// RemoveKeys returns an UpgradeRule removing the keys matching the regular expression, anchored
+// to the whole key.
+func RemoveKeys(version, description, expr string) UpgradeRule {
+	re := regexp.MustCompile("^(?:" + expr + ")$")
+	return UpgradeRule{
+		Version:     version,
+		Description: description,
+		Apply: func(in *confmap.Conf) ([]string, error) {
+			return rewriteKeys(in, func(k string, _ any, _ map[string]any) bool {
+				return re.MatchString(k)
+			}), nil
+		},
+	}
+}
+
+// RenameComponent returns an UpgradeRule renaming the components of a type, of the kind, e.g.
+// "processors", to a new type, with their names, along with their references in the service. Like
+// the converter it replaces, the references are renamed in any list of the service with the kind in
+// its key, e.g. "service::extensions", keeping the word characters of their names.
+func RenameComponent(version, description, kind, from, to string) UpgradeRule {
+	keyRe := regexp.MustCompile("^" + regexp.QuoteMeta(kind+confmap.KeyDelimiter+from) + `(/\w+)?(.*)$`)
+	idRe := regexp.MustCompile(regexp.QuoteMeta(from) + `(/\w+)?`)
+	referencesRe := regexp.MustCompile("service(.+)" + regexp.QuoteMeta(kind))
+	return UpgradeRule{
+		Version:     version,
+		Description: description,
+		Apply: func(in *confmap.Conf) ([]string, error) {
+			return rewriteKeys(in, func(k string, v any, out map[string]any) bool {
+				if match := keyRe.FindStringSubmatch(k); match != nil {
+					out[kind+confmap.KeyDelimiter+to+match[1]+match[2]] = v
+					return true
+				}
+				references, ok := v.([]any)
+				if !referencesRe.MatchString(k) || !ok {
+					return false
+				}
+				renamed := make([]any, len(references))
+				changed := false
+				for i, reference := range references {
+					renamed[i] = reference
+					if id, ok := reference.(string); ok {
+						if match := idRe.FindStringSubmatch(id); match != nil {
+							renamed[i] = to + match[1]
+							changed = true
+						}
+					}
+				}
+				if changed {
+					out[k] = renamed
+				}
+				return changed
+			}), nil
+		},
+	}
+}
+
+// SetDefault returns an UpgradeRule setting the key, relative to the components matching the
+// regular expression, e.g. "exporters::otlp(/[^:]+)?", to the value if it isn't set.
+func SetDefault(version, description, componentExpr, key string, value any) UpgradeRule {
+	re := regexp.MustCompile("^(" + componentExpr + ")(?:::|$)")
+	return UpgradeRule{
+		Version:     version,
+		Description: description,
+		Apply: func(in *confmap.Conf) ([]string, error) {
+			var components []string
+			seen := map[string]bool{}
+			for _, k := range in.AllKeys() {
+				if match := re.FindStringSubmatch(k); match != nil && !seen[match[1]] {
+					seen[match[1]] = true
+					components = append(components, match[1])
+				}
+			}
+			var changed []string
+			for _, component := range components {
+				if defaultKey := component + confmap.KeyDelimiter + key; !in.IsSet(defaultKey) {
+					changed = append(changed, defaultKey)
+				}
+			}
+			if len(changed) == 0 {
+				return nil, nil
+			}
+			flat := map[string]any{}
+			for _, k := range in.AllKeys() {
+				flat[k] = in.Get(k)
+			}
+			for _, k := range changed {
+				// Components without settings are nil leaves replaced by a map with the default.
+				delete(flat, strings.TrimSuffix(k, confmap.KeyDelimiter+key))
+				flat[k] = value
+			}
+			*in = *confmap.NewFromStringMap(flat)
+			return changed, nil
+		},
+	}
+}
+
+// rewriteKeys rewrites the configuration with the keys for which rewrite returns true replaced by
+// the ones it adds to out, and returns them.
+func rewriteKeys(in *confmap.Conf, rewrite func(k string, v any, out map[string]any) bool) []string {
+	var changed []string
+	out := map[string]any{}
+	for _, k := range in.AllKeys() {
+		v := in.Get(k)
+		if rewrite(k, v, out) {
+			changed = append(changed, k)
+			continue
+		}
+		if _, ok := out[k]; !ok {
+			out[k] = v
+		}
+	}
+	if len(changed) > 0 {
+		*in = *confmap.NewFromStringMap(out)
+	}
+	return changed
+}
+
+// compareVersions compares the dot separated numeric versions, e.g. "0.37.0" and "0.100.1".
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(strings.TrimPrefix(a, "v"), "."), strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var an, bn int
+		if i < len(as) {
+			an, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			bn, _ = strconv.Atoi(bs[i])
+		}
+		if an != bn {
+			if an < bn {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,37 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+func init() {
+	RegisterUpgradeRules(
+		RemoveKeys("0.35.0",
+			"`ballast_size_mib` parameter in `memory_limiter` processor is deprecated, see "+
+				"https://github.com/signalfx/splunk-otel-collector#from-0340-to-0350",
+			`processors::memory_limiter(/\w+)?::ballast_size_mib`),
+		RenameKeys("0.36.0",
+			"`exporters` -> `otlp` -> `insecure` parameter is deprecated, moved under `tls`, see "+
+				"https://github.com/signalfx/splunk-otel-collector#from-0350-to-0360",
+			`exporters::otlp(/\w+)?::insecure`, "exporters::otlp${1}::tls::insecure"),
+		RenameKeys("0.37.0",
+			"`exporters` -> `splunk_hec` -> `insecure_skip_verify|ca_file|cert_file|key_file` parameters "+
+				"have moved under `tls`, see https://github.com/open-telemetry/opentelemetry-collector-contrib/issues/5433",
+			`exporters::splunk_hec(/\w+)?::(insecure_skip_verify|ca_file|cert_file|key_file)`,
+			"exporters::splunk_hec${1}::tls::${2}"),
+		RenameComponent("0.37.0",
+			"`k8s_tagger` processor was renamed to `k8sattributes`",
+			"processors", "k8s_tagger", "k8sattributes"),
+	)
+}
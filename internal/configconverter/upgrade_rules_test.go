@@ -0,0 +1,115 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestUpgradeRemoveBallastKey(t *testing.T) {
+	cfgMap, err := confmaptest.LoadConf("testdata/ballast_mem_limiter.yaml")
+	require.NoError(t, err)
+
+	report, err := ConfigUpgrade{}.Upgrade(cfgMap)
+	require.NoError(t, err)
+	require.Len(t, report, 1)
+	assert.Equal(t, "0.35.0", report[0].Version)
+	assert.Equal(t, []string{"processors::memory_limiter::ballast_size_mib"}, report[0].Keys)
+
+	assert.False(t, cfgMap.IsSet("processors::memory_limiter::ballast_size_mib"))
+	assert.Equal(t, 4000, cfgMap.Get("processors::memory_limiter::limit_mib"))
+}
+
+func TestUpgradeMoveOTLPInsecureKey(t *testing.T) {
+	cfgMap, err := confmaptest.LoadConf("testdata/otlp-insecure.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, cfgMap)
+
+	err = ConfigUpgrade{}.Convert(context.Background(), cfgMap)
+	require.NoError(t, err)
+
+	assert.False(t, cfgMap.IsSet("exporters::otlp::insecure"))
+	assert.Equal(t, true, cfgMap.Get("exporters::otlp::tls::insecure"))
+}
+
+func TestUpgradeMoveOTLPInsecureKey_Custom(t *testing.T) {
+	cfgMap, err := confmaptest.LoadConf("testdata/otlp-insecure-custom.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, cfgMap)
+
+	err = ConfigUpgrade{}.Convert(context.Background(), cfgMap)
+	require.NoError(t, err)
+
+	assert.False(t, cfgMap.IsSet("exporters::otlp/foo::insecure"))
+	assert.Equal(t, true, cfgMap.Get("exporters::otlp/foo::tls::insecure"))
+	assert.Equal(t, true, cfgMap.Get("exporters::otlp/foo::tls::insecure_skip_verify"))
+}
+
+func TestUpgradeMoveOTLPInsecureKey_NonWordName(t *testing.T) {
+	cfgMap := confmap.NewFromStringMap(map[string]any{
+		"exporters": map[string]any{
+			"otlp/foo-bar": map[string]any{"insecure": true},
+		},
+	})
+
+	report, err := ConfigUpgrade{}.Upgrade(cfgMap)
+	require.NoError(t, err)
+
+	// Like the converter the rule replaced, only the names made of word characters are matched.
+	assert.Empty(t, report)
+	assert.Equal(t, true, cfgMap.Get("exporters::otlp/foo-bar::insecure"))
+}
+
+func TestUpgradeMoveHecTLS(t *testing.T) {
+	cfgMap, err := confmaptest.LoadConf("testdata/hec-tls.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, cfgMap)
+
+	err = ConfigUpgrade{}.Convert(context.Background(), cfgMap)
+	require.NoError(t, err)
+
+	assert.False(t, cfgMap.IsSet("exporters::splunk_hec::ca_file"))
+	assert.Equal(t, true, cfgMap.Get("exporters::splunk_hec::tls::insecure_skip_verify"))
+	assert.Equal(t, "my-ca-file-1", cfgMap.Get("exporters::splunk_hec::tls::ca_file"))
+	assert.Equal(t, "my-cert-file-1", cfgMap.Get("exporters::splunk_hec::tls::cert_file"))
+	assert.Equal(t, "my-key-file-1", cfgMap.Get("exporters::splunk_hec::tls::key_file"))
+
+	assert.False(t, cfgMap.IsSet("exporters::splunk_hec/allsettings::ca_file"))
+	assert.Equal(t, true, cfgMap.Get("exporters::splunk_hec/allsettings::tls::insecure_skip_verify"))
+	assert.Equal(t, "my-ca-file-2", cfgMap.Get("exporters::splunk_hec/allsettings::tls::ca_file"))
+	assert.Equal(t, "my-cert-file-2", cfgMap.Get("exporters::splunk_hec/allsettings::tls::cert_file"))
+	assert.Equal(t, "my-key-file-2", cfgMap.Get("exporters::splunk_hec/allsettings::tls::key_file"))
+}
+
+func TestUpgradeRenameK8sTagger(t *testing.T) {
+	actual, err := confmaptest.LoadConf("testdata/k8s-tagger.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, actual)
+
+	expected, err := confmaptest.LoadConf("testdata/k8sattributes.yaml")
+	require.NoError(t, err)
+
+	err = ConfigUpgrade{}.Convert(context.Background(), actual)
+	require.NoError(t, err)
+
+	require.Equal(t, expected.ToStringMap(), actual.ToStringMap())
+}
@@ -0,0 +1,199 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+func TestRenameKeys(t *testing.T) {
+	conf := confmap.NewFromStringMap(map[string]any{
+		"receivers": map[string]any{
+			"foo":     map[string]any{"old": "a", "keep": "b"},
+			"foo/bar": map[string]any{"old": "c"},
+			"foobar":  map[string]any{"old": "d"},
+		},
+	})
+	rule := RenameKeys("0.1.0", "rename", `receivers::foo(/[^:]+)?::old`, "receivers::foo${1}::new")
+	keys, err := rule.Apply(conf)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"receivers::foo::old", "receivers::foo/bar::old"}, keys)
+	assert.Equal(t, map[string]any{
+		"receivers": map[string]any{
+			"foo":     map[string]any{"new": "a", "keep": "b"},
+			"foo/bar": map[string]any{"new": "c"},
+			"foobar":  map[string]any{"old": "d"},
+		},
+	}, conf.ToStringMap())
+
+	keys, err = rule.Apply(conf)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestRenameKeysKeepsExistingTarget(t *testing.T) {
+	conf := confmap.NewFromStringMap(map[string]any{
+		"exporters": map[string]any{
+			"otlp": map[string]any{"insecure": false, "tls": map[string]any{"insecure": true}},
+		},
+	})
+	rule := RenameKeys("0.1.0", "rename", `exporters::otlp::insecure`, "exporters::otlp::tls::insecure")
+	_, err := rule.Apply(conf)
+	require.NoError(t, err)
+	assert.Equal(t, false, conf.Get("exporters::otlp::tls::insecure"))
+	assert.False(t, conf.IsSet("exporters::otlp::insecure"))
+}
+
+func TestRemoveKeys(t *testing.T) {
+	conf := confmap.NewFromStringMap(map[string]any{
+		"processors": map[string]any{
+			"memory_limiter": map[string]any{"ballast_size_mib": 100, "limit_mib": 200},
+		},
+	})
+	keys, err := RemoveKeys("0.1.0", "remove", `processors::memory_limiter::ballast_size_mib`).Apply(conf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"processors::memory_limiter::ballast_size_mib"}, keys)
+	assert.Equal(t, map[string]any{
+		"processors": map[string]any{
+			"memory_limiter": map[string]any{"limit_mib": 200},
+		},
+	}, conf.ToStringMap())
+}
+
+func TestRenameComponent(t *testing.T) {
+	conf := confmap.NewFromStringMap(map[string]any{
+		"processors": map[string]any{
+			"old":         map[string]any{"timeout": "1s"},
+			"old/foo-bar": map[string]any{"timeout": "2s"},
+			"other":       nil,
+		},
+		"service": map[string]any{
+			"pipelines": map[string]any{
+				"traces": map[string]any{"processors": []any{"old", "other"}},
+			},
+			// The references outside the pipelines are renamed too.
+			"extra": map[string]any{"processors": []any{"old/two"}},
+		},
+	})
+	keys, err := RenameComponent("0.1.0", "rename", "processors", "old", "new").Apply(conf)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"processors::old::timeout", "processors::old/foo-bar::timeout",
+		"service::pipelines::traces::processors", "service::extra::processors"}, keys)
+	assert.Equal(t, map[string]any{
+		"processors": map[string]any{
+			"new":         map[string]any{"timeout": "1s"},
+			"new/foo-bar": map[string]any{"timeout": "2s"},
+			"other":       nil,
+		},
+		"service": map[string]any{
+			"pipelines": map[string]any{
+				"traces": map[string]any{"processors": []any{"new", "other"}},
+			},
+			"extra": map[string]any{"processors": []any{"new/two"}},
+		},
+	}, conf.ToStringMap())
+}
+
+func TestRenameComponentExtensions(t *testing.T) {
+	conf := confmap.NewFromStringMap(map[string]any{
+		"extensions": map[string]any{
+			"old":     map[string]any{"endpoint": "localhost:1234"},
+			"old/two": nil,
+			"other":   nil,
+		},
+		"service": map[string]any{
+			"extensions": []any{"old", "old/two", "other"},
+		},
+	})
+	keys, err := RenameComponent("0.1.0", "rename", "extensions", "old", "new").Apply(conf)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"extensions::old::endpoint", "extensions::old/two", "service::extensions"}, keys)
+	assert.Equal(t, map[string]any{
+		"extensions": map[string]any{
+			"new":     map[string]any{"endpoint": "localhost:1234"},
+			"new/two": nil,
+			"other":   nil,
+		},
+		"service": map[string]any{
+			"extensions": []any{"new", "new/two", "other"},
+		},
+	}, conf.ToStringMap())
+}
+
+func TestSetDefault(t *testing.T) {
+	conf := confmap.NewFromStringMap(map[string]any{
+		"exporters": map[string]any{
+			"otlp":      nil,
+			"otlp/set":  map[string]any{"compression": "none"},
+			"otlp/more": map[string]any{"endpoint": "localhost:4317"},
+			"otlphttp":  nil,
+		},
+	})
+	keys, err := SetDefault("0.1.0", "default", `exporters::otlp(/[^:]+)?`, "compression", "gzip").Apply(conf)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"exporters::otlp::compression", "exporters::otlp/more::compression"}, keys)
+	assert.Equal(t, map[string]any{
+		"exporters": map[string]any{
+			"otlp":      map[string]any{"compression": "gzip"},
+			"otlp/set":  map[string]any{"compression": "none"},
+			"otlp/more": map[string]any{"endpoint": "localhost:4317", "compression": "gzip"},
+			"otlphttp":  nil,
+		},
+	}, conf.ToStringMap())
+}
+
+func TestConfigUpgradeReport(t *testing.T) {
+	saved := upgradeRules
+	t.Cleanup(func() { upgradeRules = saved })
+	upgradeRules = nil
+
+	RegisterUpgradeRules(
+		RenameKeys("0.10.0", "second", "b", "c"),
+		RemoveKeys("0.9.0", "not applied", "missing"),
+	)
+	RegisterUpgradeRules(RenameKeys("0.9.0", "first", "a", "b"))
+
+	conf := confmap.NewFromStringMap(map[string]any{"a": 1})
+	report, err := ConfigUpgrade{}.Upgrade(conf)
+	require.NoError(t, err)
+	assert.Equal(t, UpgradeReport{
+		{Version: "0.9.0", Description: "first", Keys: []string{"a"}},
+		{Version: "0.10.0", Description: "second", Keys: []string{"b"}},
+	}, report)
+	assert.Equal(t, map[string]any{"c": 1}, conf.ToStringMap())
+
+	RegisterUpgradeRules(UpgradeRule{
+		Version:     "0.11.0",
+		Description: "failing",
+		Apply: func(*confmap.Conf) ([]string, error) {
+			return nil, errors.New("boom")
+		},
+	})
+	_, err = ConfigUpgrade{}.Upgrade(conf)
+	require.EqualError(t, err, "failed to upgrade the config for 0.11.0, failing: boom")
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, 0, compareVersions("0.37.0", "v0.37.0"))
+	assert.Equal(t, -1, compareVersions("0.9.0", "0.10.0"))
+	assert.Equal(t, 1, compareVersions("1.0", "0.100.1"))
+	assert.Equal(t, -1, compareVersions("0.37", "0.37.1"))
+}
@@ -0,0 +1,218 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+)
+
+const defaultCircuitBreakerOpenDuration = time.Minute
+
+// CircuitBreakerState is the state of the circuit breaker of a config source.
+type CircuitBreakerState string
+
+const (
+	// CircuitBreakerClosed is the state in which the retrieves reach the config source.
+	CircuitBreakerClosed CircuitBreakerState = "closed"
+	// CircuitBreakerOpen is the state in which the retrieves fail without reaching the config source.
+	CircuitBreakerOpen CircuitBreakerState = "open"
+	// CircuitBreakerHalfOpen is the state in which a single retrieve is tried to check if the
+	// config source recovered.
+	CircuitBreakerHalfOpen CircuitBreakerState = "half-open"
+)
+
+// SourceCircuitBreakerHook is a SourceHealthHook also notified about the state changes of the
+// circuit breakers of the config sources.
+type SourceCircuitBreakerHook interface {
+	SourceHealthHook
+	// OnSourceCircuitBreakerChange is called when the circuit breaker of the config source changes its state.
+	OnSourceCircuitBreakerChange(name string, state CircuitBreakerState)
+}
+
+// circuitBreakers holds the circuit breakers of the config sources so their state is kept
+// across the resolutions of the configuration.
+type circuitBreakers struct {
+	breakers map[string]*circuitBreaker
+	now      func() time.Time
+	mutex    sync.Mutex
+}
+
+func newCircuitBreakers() *circuitBreakers {
+	return &circuitBreakers{
+		breakers: map[string]*circuitBreaker{},
+		now:      time.Now,
+	}
+}
+
+// get returns the circuit breaker of the config source updated with the given settings.
+func (c *circuitBreakers) get(name string, settings CircuitBreakerSettings) *circuitBreaker {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	breaker, ok := c.breakers[name]
+	if !ok {
+		breaker = &circuitBreaker{state: CircuitBreakerClosed, now: c.now}
+		c.breakers[name] = breaker
+	}
+	breaker.mutex.Lock()
+	breaker.settings = settings
+	breaker.mutex.Unlock()
+	return breaker
+}
+
+type circuitBreaker struct {
+	openedAt time.Time
+	now      func() time.Time
+	state    CircuitBreakerState
+	settings CircuitBreakerSettings
+	failures int
+	// trying is set while the retrieve in the half-open state is in progress.
+	trying bool
+	mutex  sync.Mutex
+}
+
+// allow reports if a retrieve can reach the config source and the state of the breaker
+// if it changed.
+func (b *circuitBreaker) allow() (bool, CircuitBreakerState, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	switch b.state {
+	case CircuitBreakerOpen:
+		if b.now().Sub(b.openedAt) < b.settings.OpenDuration {
+			return false, b.state, false
+		}
+		b.state = CircuitBreakerHalfOpen
+		b.trying = true
+		return true, b.state, true
+	case CircuitBreakerHalfOpen:
+		if b.trying {
+			return false, b.state, false
+		}
+		b.trying = true
+		return true, b.state, false
+	default:
+		return true, b.state, false
+	}
+}
+
+// record updates the breaker with the result of a retrieve and returns its state if it changed.
+func (b *circuitBreaker) record(err error) (CircuitBreakerState, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.trying = false
+	previous := b.state
+	if err == nil {
+		b.failures = 0
+		b.state = CircuitBreakerClosed
+	} else {
+		b.failures++
+		if b.state == CircuitBreakerHalfOpen || b.failures >= b.settings.FailureThreshold {
+			b.state = CircuitBreakerOpen
+			b.openedAt = b.now()
+		}
+	}
+	return b.state, b.state != previous
+}
+
+type circuitBreakersCtxKey struct{}
+
+// contextWithCircuitBreakers returns a copy of ctx carrying the circuit breakers shared by the
+// resolutions of the configuration.
+func contextWithCircuitBreakers(ctx context.Context, breakers *circuitBreakers) context.Context {
+	return context.WithValue(ctx, circuitBreakersCtxKey{}, breakers)
+}
+
+// withCircuitBreaker wraps the config sources with circuit breaker settings with their circuit
+// breaker. If ctx doesn't carry the circuit breakers their state is only kept during this resolution.
+func withCircuitBreaker(ctx context.Context, configSources map[string]ConfigSource, settings map[string]Source, logger *zap.Logger) (map[string]ConfigSource, error) {
+	breakers, ok := ctx.Value(circuitBreakersCtxKey{}).(*circuitBreakers)
+	if !ok {
+		breakers = newCircuitBreakers()
+	}
+	for name, cfgSrc := range configSources {
+		breakerSettings, ok := settings[name].(interface {
+			CircuitBreakerSettings() CircuitBreakerSettings
+		})
+		if !ok {
+			continue
+		}
+		cbSettings := breakerSettings.CircuitBreakerSettings()
+		if err := cbSettings.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid circuit_breaker settings for config source %s: %w", name, err)
+		}
+		if cbSettings.FailureThreshold == 0 {
+			continue
+		}
+		if cbSettings.OpenDuration == 0 {
+			cbSettings.OpenDuration = defaultCircuitBreakerOpenDuration
+		}
+		configSources[name] = &breakingConfigSource{
+			ConfigSource: cfgSrc,
+			breaker:      breakers.get(name, cbSettings),
+			settings:     cbSettings,
+			name:         name,
+			logger:       logger,
+		}
+	}
+	return configSources, nil
+}
+
+// breakingConfigSource fails the retrieves without calling the wrapped config source while its
+// circuit breaker is open.
+type breakingConfigSource struct {
+	ConfigSource
+	breaker  *circuitBreaker
+	logger   *zap.Logger
+	name     string
+	settings CircuitBreakerSettings
+}
+
+func (b *breakingConfigSource) Retrieve(ctx context.Context, selector string, paramsConfigMap *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	allowed, state, changed := b.breaker.allow()
+	if changed {
+		b.reportState(ctx, state)
+	}
+	if !allowed {
+		return nil, fmt.Errorf("circuit breaker of config source %s is %s", b.name, state)
+	}
+
+	retrieved, err := b.ConfigSource.Retrieve(ctx, selector, paramsConfigMap, watcher)
+	if state, changed = b.breaker.record(err); changed {
+		b.reportState(ctx, state)
+	}
+	return retrieved, err
+}
+
+// reportState logs the state change of the circuit breaker and notifies the hooks in ctx about it.
+func (b *breakingConfigSource) reportState(ctx context.Context, state CircuitBreakerState) {
+	if state == CircuitBreakerOpen {
+		b.logger.Warn("Config source circuit breaker opened, failing retrieves until it's tried again",
+			zap.String("config_source", b.name), zap.Duration("open_duration", b.settings.OpenDuration))
+	} else {
+		b.logger.Info("Config source circuit breaker changed state",
+			zap.String("config_source", b.name), zap.String("state", string(state)))
+	}
+	for _, h := range healthHooksFromContext(ctx) {
+		if breakerHook, ok := h.(SourceCircuitBreakerHook); ok {
+			breakerHook.OnSourceCircuitBreakerChange(b.name, state)
+		}
+	}
+}
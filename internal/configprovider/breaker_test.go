@@ -0,0 +1,139 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+)
+
+func TestBreakingConfigSource(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	breakers := newCircuitBreakers()
+	breakers.now = func() time.Time { return now }
+	reporter := NewHealthReporter()
+
+	retrieves := 0
+	cfgSrc := &testConfigSource{
+		ValueMap: map[string]valueEntry{
+			"selector": {Value: "value"},
+		},
+		OnRetrieve: func(context.Context, string, *confmap.Conf) error {
+			retrieves++
+			return nil
+		},
+	}
+	settings := &mockCfgSrcSettings{SourceSettings: NewSourceSettings(component.NewID("tstcfgsrc"))}
+	settings.CircuitBreaker = CircuitBreakerSettings{FailureThreshold: 2, OpenDuration: time.Minute}
+
+	ctx := contextWithHealthHooks(contextWithCircuitBreakers(context.Background(), breakers), []Hook{reporter})
+	resolveValue := func() (any, error) {
+		// The config sources are wrapped again on each resolution, like when the configuration is reloaded.
+		cfgSources, err := withCircuitBreaker(ctx, map[string]ConfigSource{"tstcfgsrc": cfgSrc}, map[string]Source{"tstcfgsrc": settings}, nil, zap.NewNop())
+		require.NoError(t, err)
+		require.IsType(t, &breakingConfigSource{}, cfgSources["tstcfgsrc"])
+		res, closeFunc, err := resolve(ctx, cfgSources, confmap.NewFromStringMap(map[string]any{
+			"key": "${tstcfgsrc:selector}",
+		}), nil)
+		if err != nil {
+			return nil, err
+		}
+		require.NoError(t, callClose(closeFunc))
+		return res["key"], nil
+	}
+
+	value, err := resolveValue()
+	require.NoError(t, err)
+	assert.Equal(t, "value", value)
+
+	// The circuit opens after the failure threshold is reached.
+	cfgSrc.ErrOnRetrieve = errors.New("backend unavailable")
+	for i := 0; i < 2; i++ {
+		_, err = resolveValue()
+		require.ErrorContains(t, err, "backend unavailable")
+	}
+	assert.Equal(t, 3, retrieves)
+	assert.Equal(t, "open", reporter.Sources()["tstcfgsrc"].CircuitBreaker)
+
+	// While the circuit is open the config source isn't called.
+	cfgSrc.ErrOnRetrieve = nil
+	_, err = resolveValue()
+	require.ErrorContains(t, err, "circuit breaker of config source tstcfgsrc is open")
+	assert.Equal(t, 3, retrieves)
+
+	// After the open duration a failed retrieve opens the circuit again.
+	now = now.Add(2 * time.Minute)
+	cfgSrc.ErrOnRetrieve = errors.New("backend unavailable")
+	_, err = resolveValue()
+	require.ErrorContains(t, err, "backend unavailable")
+	assert.Equal(t, 4, retrieves)
+	_, err = resolveValue()
+	require.ErrorContains(t, err, "circuit breaker of config source tstcfgsrc is open")
+	assert.Equal(t, 4, retrieves)
+
+	// And a successful one closes it.
+	now = now.Add(2 * time.Minute)
+	cfgSrc.ErrOnRetrieve = nil
+	value, err = resolveValue()
+	require.NoError(t, err)
+	assert.Equal(t, "value", value)
+	assert.Equal(t, 5, retrieves)
+	assert.Equal(t, "closed", reporter.Sources()["tstcfgsrc"].CircuitBreaker)
+}
+
+func TestBreakingConfigSourceCacheFallback(t *testing.T) {
+	cfgSrc := &testConfigSource{
+		ValueMap: map[string]valueEntry{
+			"selector": {Value: "value"},
+		},
+	}
+	settings := &mockCfgSrcSettings{SourceSettings: NewSourceSettings(component.NewID("tstcfgsrc"))}
+	settings.CircuitBreaker = CircuitBreakerSettings{FailureThreshold: 1}
+	settings.Cache = CacheSettings{StaleIfError: time.Hour}
+
+	ctx := contextWithCache(contextWithCircuitBreakers(context.Background(), newCircuitBreakers()), newResolutionCache())
+	cfgSources, err := withCircuitBreaker(ctx, map[string]ConfigSource{"tstcfgsrc": cfgSrc}, map[string]Source{"tstcfgsrc": settings}, nil, zap.NewNop())
+	require.NoError(t, err)
+	cfgSources, err = withCache(ctx, cfgSources, map[string]Source{"tstcfgsrc": settings}, nil, zap.NewNop())
+	require.NoError(t, err)
+
+	for _, retrieveErr := range []error{nil, errors.New("backend unavailable"), nil} {
+		// The last retrieve short-circuits and falls back to the cached value.
+		cfgSrc.ErrOnRetrieve = retrieveErr
+		res, closeFunc, resolveErr := resolve(ctx, cfgSources, confmap.NewFromStringMap(map[string]any{
+			"key": "${tstcfgsrc:selector}",
+		}), nil)
+		require.NoError(t, resolveErr)
+		require.NoError(t, callClose(closeFunc))
+		assert.Equal(t, "value", res["key"])
+	}
+	assert.Equal(t, CircuitBreakerOpen, cfgSources["tstcfgsrc"].(*cachingConfigSource).ConfigSource.(*breakingConfigSource).breaker.state)
+}
+
+func TestWithCircuitBreakerInvalidSettings(t *testing.T) {
+	settings := &mockCfgSrcSettings{SourceSettings: NewSourceSettings(component.NewID("tstcfgsrc"))}
+	settings.CircuitBreaker = CircuitBreakerSettings{FailureThreshold: -1}
+	_, err := withCircuitBreaker(context.Background(), map[string]ConfigSource{"tstcfgsrc": &testConfigSource{}}, map[string]Source{"tstcfgsrc": settings}, nil, zap.NewNop())
+	require.ErrorContains(t, err, "invalid circuit_breaker settings for config source tstcfgsrc")
+}
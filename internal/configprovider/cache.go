@@ -0,0 +1,388 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// TTLParam is the parameter, handled by the config provider for every config source, overriding the
+	// cache TTL of the config source for the invocation, e.g. for values that rarely change.
+	TTLParam = "ttl"
+	// NoCacheParam is the parameter, handled by the config provider for every config source, that makes
+	// the invocation bypass the cache of the config source, e.g. for dynamic database credentials.
+	NoCacheParam = "nocache"
+)
+
+// cacheControl holds the cache parameters of an invocation.
+type cacheControl struct {
+	ttl     time.Duration
+	noCache bool
+}
+
+// cutCacheParams removes the cache parameters from the parameters of an invocation and returns them.
+func cutCacheParams(paramsConfigMap *confmap.Conf) (*confmap.Conf, cacheControl, error) {
+	var control cacheControl
+	paramsConfigMap, value, found := cutParam(paramsConfigMap, TTLParam)
+	if found {
+		var err error
+		ttl, ok := value.(string)
+		if !ok {
+			return nil, control, fmt.Errorf("invalid %s parameter %v, must be a duration", TTLParam, value)
+		}
+		if control.ttl, err = time.ParseDuration(ttl); err != nil || control.ttl <= 0 {
+			return nil, control, fmt.Errorf("invalid %s parameter %v, must be a positive duration", TTLParam, value)
+		}
+	}
+	paramsConfigMap, value, found = cutParam(paramsConfigMap, NoCacheParam)
+	if found {
+		var ok bool
+		if control.noCache, ok = value.(bool); !ok {
+			return nil, control, fmt.Errorf("invalid %s parameter %v, must be true or false", NoCacheParam, value)
+		}
+	}
+	if control.noCache && control.ttl > 0 {
+		return nil, control, fmt.Errorf("%s and %s can't be both set", TTLParam, NoCacheParam)
+	}
+	return paramsConfigMap, control, nil
+}
+
+type cacheControlCtxKey struct{}
+
+// contextWithCacheControl returns a copy of ctx carrying the cache parameters of the invocation.
+func contextWithCacheControl(ctx context.Context, control cacheControl) context.Context {
+	return context.WithValue(ctx, cacheControlCtxKey{}, control)
+}
+
+// resolutionCache holds the values retrieved by the config sources with cache settings.
+type resolutionCache struct {
+	entries map[string]cacheEntry
+	now     func() time.Time
+	mutex   sync.Mutex
+}
+
+type cacheEntry struct {
+	retrievedAt time.Time
+	value       any
+	// expiresAt is the time after which the value can't be used even if the config source fails.
+	expiresAt time.Time
+	// watch forwards the changes of the value reported by the config source to the resolutions using it.
+	watch *cacheWatch
+}
+
+func newResolutionCache() *resolutionCache {
+	return &resolutionCache{
+		entries: map[string]cacheEntry{},
+		now:     time.Now,
+	}
+}
+
+func (c *resolutionCache) get(key string) (cacheEntry, bool) {
+	c.mutex.Lock()
+	entry, ok := c.entries[key]
+	if ok && !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		c.mutex.Unlock()
+		entry.watch.release()
+		return cacheEntry{}, false
+	}
+	c.mutex.Unlock()
+	return entry, ok
+}
+
+// put caches the value, the cache keeps the watch open until the value is replaced or changes.
+func (c *resolutionCache) put(key string, value any, settings CacheSettings, watch *cacheWatch) {
+	c.mutex.Lock()
+	if watch.changed || !watch.acquire() {
+		// The value changed while it was retrieved, it is retrieved again by the next resolution.
+		c.mutex.Unlock()
+		return
+	}
+	now := c.now()
+	previous, ok := c.entries[key]
+	c.entries[key] = cacheEntry{
+		retrievedAt: now,
+		value:       value,
+		expiresAt:   now.Add(settings.TTL + settings.StaleIfError),
+		watch:       watch,
+	}
+	c.mutex.Unlock()
+	if ok {
+		previous.watch.release()
+	}
+}
+
+// remove drops the value cached with the watch because the config source reported a change of it.
+func (c *resolutionCache) remove(key string, watch *cacheWatch) {
+	c.mutex.Lock()
+	watch.changed = true
+	entry, ok := c.entries[key]
+	if !ok || entry.watch != watch {
+		c.mutex.Unlock()
+		return
+	}
+	delete(c.entries, key)
+	c.mutex.Unlock()
+	watch.release()
+}
+
+// expire makes the cached values be retrieved again, they are still used if the retrieve fails
+// and stale values are allowed.
+func (c *resolutionCache) expire() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for key, entry := range c.entries {
+		entry.retrievedAt = time.Time{}
+		c.entries[key] = entry
+	}
+}
+
+// cacheWatch shares the watch of a retrieved value between the cache and the resolutions using
+// the value. The retrieved value, with its watch, is closed once none of them uses it.
+type cacheWatch struct {
+	close    confmap.CloseFunc
+	watchers map[int]confmap.WatcherFunc
+	nextID   int
+	refs     int
+	closed   bool
+	// changed is set, with the mutex of the cache held, when the config source reports a change.
+	changed bool
+	mutex   sync.Mutex
+}
+
+func newCacheWatch() *cacheWatch {
+	return &cacheWatch{watchers: map[int]confmap.WatcherFunc{}}
+}
+
+func (w *cacheWatch) acquire() bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.closed {
+		return false
+	}
+	w.refs++
+	return true
+}
+
+func (w *cacheWatch) release() {
+	if w == nil {
+		return
+	}
+	w.mutex.Lock()
+	w.refs--
+	if w.refs > 0 || w.closed {
+		w.mutex.Unlock()
+		return
+	}
+	w.closed = true
+	closeFunc := w.close
+	w.mutex.Unlock()
+	if closeFunc != nil {
+		_ = closeFunc(context.Background())
+	}
+}
+
+// subscribe notifies the watcher of the changes of the value until the returned close func is
+// called. It returns false if the watch was already closed.
+func (w *cacheWatch) subscribe(watcher confmap.WatcherFunc) (confmap.CloseFunc, bool) {
+	w.mutex.Lock()
+	if w.closed {
+		w.mutex.Unlock()
+		return nil, false
+	}
+	id := w.nextID
+	w.nextID++
+	w.watchers[id] = watcher
+	w.refs++
+	w.mutex.Unlock()
+
+	var once sync.Once
+	return func(context.Context) error {
+		once.Do(func() {
+			w.mutex.Lock()
+			delete(w.watchers, id)
+			w.mutex.Unlock()
+			w.release()
+		})
+		return nil
+	}, true
+}
+
+func (w *cacheWatch) notify(event *confmap.ChangeEvent) {
+	w.mutex.Lock()
+	watchers := make([]confmap.WatcherFunc, 0, len(w.watchers))
+	for _, watcher := range w.watchers {
+		if watcher != nil {
+			watchers = append(watchers, watcher)
+		}
+	}
+	w.mutex.Unlock()
+	for _, watcher := range watchers {
+		watcher(event)
+	}
+}
+
+type cacheCtxKey struct{}
+
+// contextWithCache returns a copy of ctx carrying the cache shared by the resolutions of the configuration.
+func contextWithCache(ctx context.Context, cache *resolutionCache) context.Context {
+	return context.WithValue(ctx, cacheCtxKey{}, cache)
+}
+
+// withCache wraps the config sources so their retrieved values are cached according to their cache
+// settings and the cache parameters of the invocations. The raw settings, as in the config_sources
+// section, are part of the cache keys so the values aren't reused once the settings change.
+// If ctx doesn't carry a cache the values are only cached during this resolution.
+func withCache(ctx context.Context, configSources map[string]ConfigSource, settings map[string]Source, rawSettings map[string]any, logger *zap.Logger) (map[string]ConfigSource, error) {
+	cache, ok := ctx.Value(cacheCtxKey{}).(*resolutionCache)
+	if !ok {
+		cache = newResolutionCache()
+	}
+	for name, cfgSrc := range configSources {
+		// The config sources without cache settings are also wrapped for the invocations with the ttl parameter.
+		var cacheSettings CacheSettings
+		if withSettings, ok := settings[name].(interface{ CacheSettings() CacheSettings }); ok {
+			cacheSettings = withSettings.CacheSettings()
+		}
+		if err := cacheSettings.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid cache settings for config source %s: %w", name, err)
+		}
+		configSources[name] = &cachingConfigSource{
+			ConfigSource: cfgSrc,
+			cache:        cache,
+			settings:     cacheSettings,
+			settingsHash: configChecksum(map[string]any{name: rawSettings[name]}),
+			name:         name,
+			logger:       logger,
+		}
+	}
+	return configSources, nil
+}
+
+// cachingConfigSource reuses the values retrieved by the wrapped config source within the TTL
+// and, if the config source fails, until the stale-if-error time expires. The values are retrieved
+// again as soon as the config source reports a change of them, and the resolutions reusing a value
+// are notified of its changes like the one that retrieved it.
+type cachingConfigSource struct {
+	ConfigSource
+	cache        *resolutionCache
+	logger       *zap.Logger
+	name         string
+	settingsHash string
+	settings     CacheSettings
+}
+
+func (c *cachingConfigSource) Retrieve(ctx context.Context, selector string, paramsConfigMap *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	settings := c.settings
+	control, _ := ctx.Value(cacheControlCtxKey{}).(cacheControl)
+	if control.ttl > 0 {
+		settings.TTL = control.ttl
+	}
+	if control.noCache || !settings.Enabled() {
+		return c.ConfigSource.Retrieve(ctx, selector, paramsConfigMap, watcher)
+	}
+
+	key, err := c.cacheKey(ctx, selector, paramsConfigMap)
+	if err != nil {
+		return c.ConfigSource.Retrieve(ctx, selector, paramsConfigMap, watcher)
+	}
+
+	entry, cached := c.cache.get(key)
+	if cached && c.cache.now().Sub(entry.retrievedAt) < settings.TTL {
+		if retrieved, ok := entry.retrieved(watcher); ok {
+			return retrieved, nil
+		}
+	}
+
+	watch := newCacheWatch()
+	closeFunc, _ := watch.subscribe(watcher)
+	retrieved, err := c.ConfigSource.Retrieve(ctx, selector, paramsConfigMap, func(event *confmap.ChangeEvent) {
+		// The cached value is stale, the resolution triggered by the event retrieves it again.
+		c.cache.remove(key, watch)
+		watch.notify(event)
+	})
+	if err != nil {
+		if !cached {
+			return nil, err
+		}
+		c.logger.Warn("Config source failed to retrieve value, using the cached value",
+			zap.String("config_source", c.name), zap.String("selector", selector),
+			zap.Time("retrieved_at", entry.retrievedAt), zap.Error(err))
+		if retrieved, ok := entry.retrieved(watcher); ok {
+			return retrieved, nil
+		}
+		return confmap.NewRetrieved(entry.value)
+	}
+
+	value, err := retrievedAsRaw(retrieved)
+	if err != nil {
+		// Nothing is cached, the watch of the value is released.
+		_ = closeFunc(ctx)
+		_ = retrieved.Close(ctx)
+		return nil, err
+	}
+	watch.mutex.Lock()
+	watch.close = retrieved.Close
+	watch.mutex.Unlock()
+	c.cache.put(key, value, settings, watch)
+	return confmap.NewRetrieved(value, confmap.WithRetrievedClose(closeFunc))
+}
+
+// retrievedAsRaw returns the value of a retrieved, it's replaced by the tests since the values
+// retrieved by the config sources don't fail to return it with this version of confmap.
+var retrievedAsRaw = (*confmap.Retrieved).AsRaw
+
+// retrieved returns the cached value with the watcher subscribed to its changes. It returns false
+// if the value is no longer watched and it has to be retrieved again.
+func (e cacheEntry) retrieved(watcher confmap.WatcherFunc) (*confmap.Retrieved, bool) {
+	closeFunc, ok := e.watch.subscribe(watcher)
+	if !ok {
+		return nil, false
+	}
+	retrieved, err := confmap.NewRetrieved(e.value, confmap.WithRetrievedClose(closeFunc))
+	return retrieved, err == nil
+}
+
+// cacheKey identifies the invocation of the config source with its settings.
+func (c *cachingConfigSource) cacheKey(ctx context.Context, selector string, paramsConfigMap *confmap.Conf) (string, error) {
+	key, err := invocationKey(ctx, c.name, selector, paramsConfigMap)
+	if err != nil {
+		return "", err
+	}
+	return key + "\x00" + c.settingsHash, nil
+}
+
+// invocationKey identifies the invocation of a config source, including the context used to resolve it.
+func invocationKey(ctx context.Context, name, selector string, paramsConfigMap *confmap.Conf) (string, error) {
+	var params []byte
+	if paramsConfigMap != nil {
+		var err error
+		// The keys of the maps are sorted when marshaled.
+		if params, err = yaml.Marshal(paramsConfigMap.ToStringMap()); err != nil {
+			return "", err
+		}
+	}
+	configFile, _ := ConfigFileFromContext(ctx)
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%t", name, selector, params, configFile, IsOptional(ctx)), nil
+}
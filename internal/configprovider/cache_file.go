@@ -0,0 +1,257 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/knadh/koanf/maps"
+	"go.opentelemetry.io/collector/confmap"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// configCacheFileEnvVar is the env var with the path of the file persisting the last resolved
+	// configuration, so the collector can start with it while the config source backends are unavailable.
+	configCacheFileEnvVar = "SPLUNK_CONFIG_CACHE_FILE"
+	// configCacheKeyEnvVar is the env var with the base64 encoded AES key, of 16, 24, or 32 bytes,
+	// encrypting the config cache file.
+	configCacheKeyEnvVar = "SPLUNK_CONFIG_CACHE_KEY"
+)
+
+// configCacheRetryInterval is the interval at which the configuration is resolved again after it
+// was retrieved from the config cache file.
+var configCacheRetryInterval = 30 * time.Second
+
+// configCacheFileMutex serializes the accesses to the config cache file shared by the config providers.
+var configCacheFileMutex sync.Mutex
+
+// configCacheFile persists, encrypted with AES-GCM, the last resolved configuration of each URI.
+type configCacheFile struct {
+	aead cipher.AEAD
+	path string
+}
+
+// openConfigCacheFile returns the config cache file with the given path and key, defaulting to
+// the ones set by the env vars, nil if no path is set.
+func openConfigCacheFile(path string, key []byte) (*configCacheFile, error) {
+	if path == "" {
+		path = os.Getenv(configCacheFileEnvVar)
+	}
+	if path == "" {
+		return nil, nil
+	}
+	if key == nil {
+		var err error
+		if key, err = base64.StdEncoding.DecodeString(os.Getenv(configCacheKeyEnvVar)); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", configCacheKeyEnvVar, err)
+		}
+	}
+	return newConfigCacheFile(path, key)
+}
+
+func newConfigCacheFile(path string, key []byte) (*configCacheFile, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s, it must be a 16, 24, or 32 bytes key: %w", configCacheKeyEnvVar, err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &configCacheFile{aead: aead, path: path}, nil
+}
+
+// get returns the configuration cached for the URI.
+func (f *configCacheFile) get(uri string) (map[string]any, bool, error) {
+	configCacheFileMutex.Lock()
+	defer configCacheFileMutex.Unlock()
+	entries, err := f.load()
+	if err != nil {
+		return nil, false, err
+	}
+	resolved, ok := entries[uri]
+	return resolved, ok, nil
+}
+
+// put caches the configuration resolved for the URI, replacing the file atomically.
+func (f *configCacheFile) put(uri string, resolved map[string]any) error {
+	configCacheFileMutex.Lock()
+	defer configCacheFileMutex.Unlock()
+	entries, err := f.load()
+	if err != nil {
+		// The file can't be decrypted with the current key, start over.
+		entries = map[string]map[string]any{}
+	}
+	entries[uri] = resolved
+
+	plaintext, err := yaml.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, f.aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(f.aead.Seal(nonce, nonce, plaintext, nil)); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+func (f *configCacheFile) load() (map[string]map[string]any, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]map[string]any{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	nonceSize := f.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, fmt.Errorf("config cache file %q is corrupted", f.path)
+	}
+	plaintext, err := f.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt the config cache file %q: %w", f.path, err)
+	}
+	entries := map[string]map[string]any{}
+	if err = yaml.Unmarshal(plaintext, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse the config cache file %q: %w", f.path, err)
+	}
+	for _, resolved := range entries {
+		maps.IntfaceKeysToStrings(resolved)
+	}
+	return entries, nil
+}
+
+// CachedConfig returns the last configuration resolved for the URIs, merged in order, read from
+// the config cache file set by the SPLUNK_CONFIG_CACHE_FILE and SPLUNK_CONFIG_CACHE_KEY env vars,
+// e.g. to compare it with a new configuration before rolling it out.
+func CachedConfig(uris []string) (map[string]any, error) {
+	cacheFile, err := openConfigCacheFile("", nil)
+	if err != nil {
+		return nil, err
+	} else if cacheFile == nil {
+		return nil, fmt.Errorf("no config cache file, %s isn't set", configCacheFileEnvVar)
+	}
+	configCacheFileMutex.Lock()
+	entries, err := cacheFile.load()
+	configCacheFileMutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	merged := confmap.New()
+	for _, uri := range uris {
+		resolved, ok := entries[uri]
+		if !ok {
+			return nil, fmt.Errorf("no configuration cached for %q in %q", uri, cacheFile.path)
+		}
+		if err = merged.Merge(confmap.NewFromStringMap(resolved)); err != nil {
+			return nil, err
+		}
+	}
+	return merged.ToStringMap(), nil
+}
+
+// storeResolution persists the configuration resolved for the URI, if the config cache file is set.
+func (c *configSourceConfigMapProvider) storeResolution(uri string, resolved map[string]any) {
+	if c.cacheFile == nil {
+		return
+	}
+	if err := c.cacheFile.put(uri, resolved); err != nil {
+		log.Printf("Failed to write the config cache file %q: %v", c.cacheFile.path, err)
+	}
+}
+
+// cachedResolution returns the configuration persisted for the URI when it can't be resolved and,
+// if the configuration is watched, resolves it again periodically until it succeeds to reload it.
+func (c *configSourceConfigMapProvider) cachedResolution(uri string, input *confmap.Conf, resolveErr error, reload confmap.WatcherFunc) (map[string]any, bool) {
+	if c.cacheFile == nil {
+		return nil, false
+	}
+	resolved, ok, err := c.cacheFile.get(uri)
+	if err != nil {
+		log.Printf("Failed to read the config cache file %q: %v", c.cacheFile.path, err)
+		return nil, false
+	} else if !ok {
+		return nil, false
+	}
+	log.Printf("Failed to resolve the configuration, using the configuration cached in %q: %v", c.cacheFile.path, resolveErr)
+	if reload != nil {
+		c.retryResolution(input, c.copyKeyOrigins(), reload)
+	}
+	return resolved, true
+}
+
+// retryResolution resolves the configuration again after the retry interval, and reloads it once
+// the config source backends recovered.
+func (c *configSourceConfigMapProvider) retryResolution(input *confmap.Conf, keyOrigins map[string]string, reload confmap.WatcherFunc) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.stopped {
+		return
+	}
+	if c.cacheRetry != nil {
+		c.cacheRetry.Stop()
+	}
+	c.cacheRetry = time.AfterFunc(configCacheRetryInterval, func() {
+		ctx, cancel := c.resolutionContext(context.Background())
+		defer cancel()
+		_, closeFunc, err := c.resolveWithHooks(c.resolveContext(ctx, keyOrigins), input, nil)
+		if closeFunc != nil {
+			_ = closeFunc(ctx)
+		}
+		if err != nil {
+			c.retryResolution(input, keyOrigins, reload)
+			return
+		}
+		log.Printf("The configuration was resolved after using the configuration cached in %q, reloading", c.cacheFile.path)
+		reload(&confmap.ChangeEvent{})
+	})
+}
+
+// cancelRetry stops resolving again the configuration retrieved from the config cache file, stop
+// prevents any later retry, e.g. on shutdown.
+func (c *configSourceConfigMapProvider) cancelRetry(stop bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.stopped = c.stopped || stop
+	if c.cacheRetry != nil {
+		c.cacheRetry.Stop()
+		c.cacheRetry = nil
+	}
+}
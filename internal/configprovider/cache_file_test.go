@@ -0,0 +1,142 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/provider/yamlprovider"
+	"go.uber.org/zap"
+)
+
+func TestConfigCacheFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.cache")
+	cacheFile, err := newConfigCacheFile(path, []byte("0123456789abcdef"))
+	require.NoError(t, err)
+
+	_, ok, err := cacheFile.get("file:config.yaml")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	resolved := map[string]any{
+		"exporters": map[string]any{"otlp": map[string]any{"headers": map[string]any{"token": "s3cr3t"}}},
+		"port":      4317,
+	}
+	require.NoError(t, cacheFile.put("file:config.yaml", resolved))
+	cached, ok, err := cacheFile.get("file:config.yaml")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, resolved, cached)
+
+	// The values are encrypted at rest.
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "s3cr3t")
+
+	otherKey, err := newConfigCacheFile(path, []byte("fedcba9876543210"))
+	require.NoError(t, err)
+	_, _, err = otherKey.get("file:config.yaml")
+	assert.ErrorContains(t, err, "failed to decrypt the config cache file")
+
+	_, err = newConfigCacheFile(path, []byte("short"))
+	assert.ErrorContains(t, err, "invalid SPLUNK_CONFIG_CACHE_KEY, it must be a 16, 24, or 32 bytes key")
+}
+
+func TestConfigSourceProviderConfigCacheFile(t *testing.T) {
+	t.Setenv(configCacheFileEnvVar, filepath.Join(t.TempDir(), "config.cache"))
+	t.Setenv(configCacheKeyEnvVar, base64.StdEncoding.EncodeToString([]byte("0123456789abcdef")))
+	retryInterval := configCacheRetryInterval
+	configCacheRetryInterval = 10 * time.Millisecond
+	defer func() { configCacheRetryInterval = retryInterval }()
+
+	const rotatingConfig = "yaml:key: ${rotating:secret}"
+	factory := &rotatingCfgSrcFactory{value: "v1"}
+	newProvider := func() *configSourceConfigMapProvider {
+		return NewConfigSourceConfigMapProvider(yamlprovider.New(), zap.NewNop(), component.NewDefaultBuildInfo(),
+			nil, nil, factory).(*configSourceConfigMapProvider)
+	}
+
+	provider := newProvider()
+	retrieved, err := provider.Retrieve(context.Background(), rotatingConfig, nil)
+	require.NoError(t, err)
+	require.NoError(t, retrieved.Close(context.Background()))
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	// The collector restarts during an outage of the backend.
+	factory.setErr(errors.New("backend unavailable"))
+	provider = newProvider()
+	watcher := &recordingWatcher{}
+	retrieved, err = provider.Retrieve(context.Background(), rotatingConfig, watcher.onChange)
+	require.NoError(t, err)
+	resolved, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"key": "v1"}, resolved)
+	require.NoError(t, retrieved.Close(context.Background()))
+
+	// The configuration is reloaded once the backend recovered.
+	time.Sleep(5 * configCacheRetryInterval)
+	assert.Empty(t, watcher.calls())
+	factory.setValue("v2")
+	factory.setErr(nil)
+	require.Eventually(t, func() bool { return len(watcher.calls()) == 1 }, 5*time.Second, configCacheRetryInterval)
+	retrieved, err = provider.Retrieve(context.Background(), rotatingConfig, watcher.onChange)
+	require.NoError(t, err)
+	resolved, err = retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"key": "v2"}, resolved)
+	require.NoError(t, retrieved.Close(context.Background()))
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	// Without the cached configuration the errors are returned.
+	provider = newProvider()
+	factory.setErr(errors.New("backend unavailable"))
+	_, err = provider.Retrieve(context.Background(), "yaml:other: ${rotating:secret}", nil)
+	assert.ErrorContains(t, err, "backend unavailable")
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	t.Setenv(configCacheKeyEnvVar, "not base64")
+	_, err = newProvider().Retrieve(context.Background(), rotatingConfig, nil)
+	assert.ErrorContains(t, err, "invalid SPLUNK_CONFIG_CACHE_KEY")
+}
+
+func TestCachedConfig(t *testing.T) {
+	_, err := CachedConfig([]string{"file:config.yaml"})
+	assert.EqualError(t, err, "no config cache file, SPLUNK_CONFIG_CACHE_FILE isn't set")
+
+	path := filepath.Join(t.TempDir(), "config.cache")
+	t.Setenv(configCacheFileEnvVar, path)
+	t.Setenv(configCacheKeyEnvVar, base64.StdEncoding.EncodeToString([]byte("0123456789abcdef")))
+	cacheFile, err := openConfigCacheFile("", nil)
+	require.NoError(t, err)
+	require.NoError(t, cacheFile.put("file:config.yaml", map[string]any{"a": 1, "b": map[string]any{"c": 1}}))
+	require.NoError(t, cacheFile.put("env:OVERRIDES", map[string]any{"b::c": 2}))
+
+	cached, err := CachedConfig([]string{"file:config.yaml", "env:OVERRIDES"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": 1, "b": map[string]any{"c": 2}}, cached)
+
+	_, err = CachedConfig([]string{"file:other.yaml"})
+	assert.EqualError(t, err, `no configuration cached for "file:other.yaml" in "`+path+`"`)
+}
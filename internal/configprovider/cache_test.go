@@ -0,0 +1,296 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+)
+
+func TestCachingConfigSource(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := newResolutionCache()
+	cache.now = func() time.Time { return now }
+
+	retrieves := 0
+	cfgSrc := &testConfigSource{
+		ValueMap: map[string]valueEntry{
+			"selector": {Value: "value"},
+		},
+		OnRetrieve: func(context.Context, string, *confmap.Conf) error {
+			retrieves++
+			return nil
+		},
+	}
+	settings := &mockCfgSrcSettings{SourceSettings: NewSourceSettings(component.NewID("tstcfgsrc"))}
+	settings.Cache = CacheSettings{TTL: time.Minute, StaleIfError: time.Hour}
+
+	cfgSources, err := withCache(contextWithCache(context.Background(), cache),
+		map[string]ConfigSource{"tstcfgsrc": cfgSrc}, map[string]Source{"tstcfgsrc": settings}, nil, zap.NewNop())
+	require.NoError(t, err)
+	require.IsType(t, &cachingConfigSource{}, cfgSources["tstcfgsrc"])
+
+	resolveValue := func(invocation string) (any, error) {
+		res, closeFunc, resolveErr := resolve(context.Background(), cfgSources, confmap.NewFromStringMap(map[string]any{
+			"key": invocation,
+		}), nil)
+		if resolveErr != nil {
+			return nil, resolveErr
+		}
+		require.NoError(t, callClose(closeFunc))
+		return res["key"], nil
+	}
+
+	// Repeated invocations within the TTL reuse the cached value.
+	for i := 0; i < 3; i++ {
+		value, resolveErr := resolveValue("${tstcfgsrc:selector}")
+		require.NoError(t, resolveErr)
+		assert.Equal(t, "value", value)
+	}
+	assert.Equal(t, 1, retrieves)
+
+	// Invocations with different parameters are cached separately.
+	_, err = resolveValue("${tstcfgsrc:selector?p0=1}")
+	require.NoError(t, err)
+	assert.Equal(t, 2, retrieves)
+
+	// After the TTL the value is retrieved again.
+	now = now.Add(2 * time.Minute)
+	_, err = resolveValue("${tstcfgsrc:selector}")
+	require.NoError(t, err)
+	assert.Equal(t, 3, retrieves)
+
+	// If the config source fails the cached value is used until the stale-if-error time expires.
+	cfgSrc.ErrOnRetrieve = errors.New("backend unavailable")
+	now = now.Add(30 * time.Minute)
+	value, err := resolveValue("${tstcfgsrc:selector}")
+	require.NoError(t, err)
+	assert.Equal(t, "value", value)
+
+	now = now.Add(time.Hour)
+	_, err = resolveValue("${tstcfgsrc:selector}")
+	assert.ErrorContains(t, err, "backend unavailable")
+}
+
+func TestCachingConfigSourceParams(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := newResolutionCache()
+	cache.now = func() time.Time { return now }
+
+	retrieves := map[string]int{}
+	cfgSrc := &testConfigSource{
+		ValueMap: map[string]valueEntry{
+			"realm": {Value: "us0"},
+			"creds": {Value: "password"},
+		},
+		OnRetrieve: func(_ context.Context, selector string, _ *confmap.Conf) error {
+			retrieves[selector]++
+			return nil
+		},
+	}
+	// Without cache settings only the invocations with the ttl parameter are cached.
+	settings := &mockCfgSrcSettings{SourceSettings: NewSourceSettings(component.NewID("tstcfgsrc"))}
+	cfgSources, err := withCache(contextWithCache(context.Background(), cache),
+		map[string]ConfigSource{"tstcfgsrc": cfgSrc}, map[string]Source{"tstcfgsrc": settings}, nil, zap.NewNop())
+	require.NoError(t, err)
+
+	resolveConfig := func(config map[string]any) error {
+		_, closeFunc, resolveErr := resolve(context.Background(), cfgSources, confmap.NewFromStringMap(config), nil)
+		if resolveErr == nil {
+			require.NoError(t, callClose(closeFunc))
+		}
+		return resolveErr
+	}
+	config := map[string]any{
+		"realm": "${tstcfgsrc:realm?ttl=1h}",
+		"creds": "${tstcfgsrc:creds}",
+	}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, resolveConfig(config))
+	}
+	assert.Equal(t, map[string]int{"realm": 1, "creds": 3}, retrieves)
+
+	now = now.Add(2 * time.Hour)
+	require.NoError(t, resolveConfig(config))
+	assert.Equal(t, map[string]int{"realm": 2, "creds": 4}, retrieves)
+
+	// The nocache parameter bypasses the cache settings of the config source.
+	settings.Cache = CacheSettings{TTL: time.Hour}
+	cfgSources, err = withCache(contextWithCache(context.Background(), cache),
+		map[string]ConfigSource{"tstcfgsrc": cfgSrc}, map[string]Source{"tstcfgsrc": settings}, nil, zap.NewNop())
+	require.NoError(t, err)
+	config = map[string]any{
+		"realm": "${tstcfgsrc:realm}",
+		"creds": "${tstcfgsrc:creds?nocache=true}",
+	}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, resolveConfig(config))
+	}
+	assert.Equal(t, map[string]int{"realm": 2, "creds": 7}, retrieves)
+
+	assert.ErrorContains(t, resolveConfig(map[string]any{"realm": "${tstcfgsrc:realm?ttl=soon}"}),
+		"invalid ttl parameter soon, must be a positive duration")
+	assert.ErrorContains(t, resolveConfig(map[string]any{"realm": "${tstcfgsrc:realm?ttl=1h&nocache=true}"}),
+		"ttl and nocache can't be both set")
+}
+
+func TestCachingConfigSourceWatch(t *testing.T) {
+	watchForUpdateCh := make(chan error, 1)
+	retrieves := 0
+	cfgSrc := &testConfigSource{
+		ValueMap: map[string]valueEntry{
+			"selector": {Value: "value", WatchForUpdateCh: watchForUpdateCh},
+		},
+		OnRetrieve: func(context.Context, string, *confmap.Conf) error {
+			retrieves++
+			return nil
+		},
+	}
+	settings := &mockCfgSrcSettings{SourceSettings: NewSourceSettings(component.NewID("tstcfgsrc"))}
+	settings.Cache = CacheSettings{TTL: time.Hour}
+	ctx := contextWithCache(context.Background(), newResolutionCache())
+	cfgSources, err := withCache(ctx, map[string]ConfigSource{"tstcfgsrc": cfgSrc}, map[string]Source{"tstcfgsrc": settings}, nil, zap.NewNop())
+	require.NoError(t, err)
+
+	resolveWatched := func() (chan *confmap.ChangeEvent, confmap.CloseFunc) {
+		watchCh := make(chan *confmap.ChangeEvent, 1)
+		res, closeFunc, resolveErr := resolve(ctx, cfgSources, confmap.NewFromStringMap(map[string]any{
+			"key": "${tstcfgsrc:selector}",
+		}), func(event *confmap.ChangeEvent) {
+			watchCh <- event
+		})
+		require.NoError(t, resolveErr)
+		assert.Equal(t, "value", res["key"])
+		return watchCh, closeFunc
+	}
+
+	firstCh, firstClose := resolveWatched()
+	// The resolution reusing the cached value is notified of its changes too.
+	secondCh, secondClose := resolveWatched()
+	assert.Equal(t, 1, retrieves)
+	require.NoError(t, callClose(firstClose))
+
+	watchForUpdateCh <- nil
+	select {
+	case event := <-secondCh:
+		assert.NoError(t, event.Error)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the change wasn't forwarded to the resolution using the cached value")
+	}
+	assert.Empty(t, firstCh)
+
+	// The change expires the cached value within the TTL.
+	_, thirdClose := resolveWatched()
+	assert.Equal(t, 2, retrieves)
+	require.NoError(t, callClose(secondClose))
+	require.NoError(t, callClose(thirdClose))
+}
+
+func TestCachingConfigSourceAsRawError(t *testing.T) {
+	retrieves, closes := 0, 0
+	cfgSrc := &closingConfigSource{value: "value", onRetrieve: func() { retrieves++ }, onClose: func() { closes++ }}
+	settings := &mockCfgSrcSettings{SourceSettings: NewSourceSettings(component.NewID("tstcfgsrc"))}
+	settings.Cache = CacheSettings{TTL: time.Hour}
+	ctx := contextWithCache(context.Background(), newResolutionCache())
+	cfgSources, err := withCache(ctx, map[string]ConfigSource{"tstcfgsrc": cfgSrc}, map[string]Source{"tstcfgsrc": settings}, nil, zap.NewNop())
+	require.NoError(t, err)
+
+	asRaw := retrievedAsRaw
+	defer func() { retrievedAsRaw = asRaw }()
+	retrievedAsRaw = func(*confmap.Retrieved) (any, error) {
+		return nil, errors.New("invalid value")
+	}
+	_, err = cfgSources["tstcfgsrc"].Retrieve(ctx, "selector", nil, func(*confmap.ChangeEvent) {})
+	assert.ErrorContains(t, err, "invalid value")
+	// The value retrieved isn't cached and its watch is released.
+	assert.Equal(t, 1, closes)
+
+	retrievedAsRaw = asRaw
+	retrieved, err := cfgSources["tstcfgsrc"].Retrieve(ctx, "selector", nil, nil)
+	require.NoError(t, err)
+	value, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, "value", value)
+	assert.Equal(t, 2, retrieves)
+	require.NoError(t, retrieved.Close(ctx))
+	// The cached value is still watched.
+	assert.Equal(t, 1, closes)
+}
+
+// closingConfigSource retrieves its value for any selector, counting the retrieves and the closes of the values.
+type closingConfigSource struct {
+	value      any
+	onRetrieve func()
+	onClose    func()
+}
+
+func (c *closingConfigSource) Retrieve(context.Context, string, *confmap.Conf, confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	c.onRetrieve()
+	return confmap.NewRetrieved(c.value, confmap.WithRetrievedClose(func(context.Context) error {
+		c.onClose()
+		return nil
+	}))
+}
+
+func (c *closingConfigSource) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestCachingConfigSourceSettingsChange(t *testing.T) {
+	retrieves := 0
+	cfgSrc := &testConfigSource{
+		ValueMap: map[string]valueEntry{
+			"selector": {Value: "value"},
+		},
+		OnRetrieve: func(context.Context, string, *confmap.Conf) error {
+			retrieves++
+			return nil
+		},
+	}
+	settings := &mockCfgSrcSettings{SourceSettings: NewSourceSettings(component.NewID("tstcfgsrc"))}
+	settings.Cache = CacheSettings{TTL: time.Hour}
+	ctx := contextWithCache(context.Background(), newResolutionCache())
+
+	for _, endpoint := range []string{"https://vault-a", "https://vault-a", "https://vault-b"} {
+		cfgSources, err := withCache(ctx, map[string]ConfigSource{"tstcfgsrc": cfgSrc}, map[string]Source{"tstcfgsrc": settings},
+			map[string]any{"tstcfgsrc": map[string]any{"endpoint": endpoint}}, zap.NewNop())
+		require.NoError(t, err)
+		_, closeFunc, err := resolve(ctx, cfgSources, confmap.NewFromStringMap(map[string]any{
+			"key": "${tstcfgsrc:selector}",
+		}), nil)
+		require.NoError(t, err)
+		require.NoError(t, callClose(closeFunc))
+	}
+	// The value retrieved with the previous settings isn't reused.
+	assert.Equal(t, 2, retrieves)
+}
+
+func TestCachingConfigSourceInvalidSettings(t *testing.T) {
+	settings := &mockCfgSrcSettings{SourceSettings: NewSourceSettings(component.NewID("tstcfgsrc"))}
+	settings.Cache = CacheSettings{TTL: -time.Minute}
+
+	_, err := withCache(context.Background(), map[string]ConfigSource{"tstcfgsrc": &testConfigSource{}},
+		map[string]Source{"tstcfgsrc": settings}, nil, zap.NewNop())
+	assert.ErrorContains(t, err, "can't be negative")
+}
@@ -5,7 +5,7 @@
 // you may not use this file except in compliance with the License.
 // You may obtain a copy of the License at
 //
-//       http://www.apache.org/licenses/LICENSE-2.0
+//      http://www.apache.org/licenses/LICENSE-2.0
 //
 // Unless required by applicable law or agreed to in writing, software
 // distributed under the License is distributed on an "AS IS" BASIS,
@@ -17,46 +17,24 @@ package configprovider
 
 import (
 	"go.opentelemetry.io/collector/component"
-)
-
-// SourceSettings defines common settings of a Source configuration.
-// Specific config sources can embed this struct and extend it with more fields if needed.
-// When embedded it must be with `mapstructure:",squash"` tag.
-type SourceSettings struct {
-	id component.ID `mapstructure:"-"`
-}
 
-// ID returns the ID of the component that this configuration belongs to.
-func (s *SourceSettings) ID() component.ID {
-	return s.id
-}
+	"github.com/signalfx/splunk-otel-collector/pkg/configsource"
+)
 
-// SetIDName updates the name part of the ID for the component that this configuration belongs to.
-func (s *SourceSettings) SetIDName(idName string) {
-	s.id = component.NewIDWithName(s.id.Type(), idName)
-}
+// The configuration of the config sources is defined by the configsource package, so third
+// party config sources are configured exactly like the built-in ones.
+type (
+	// SourceSettings defines common settings of a Source configuration, see configsource.SourceSettings.
+	SourceSettings         = configsource.SourceSettings
+	CircuitBreakerSettings = configsource.CircuitBreakerSettings
+	LimitSettings          = configsource.LimitSettings
+	RetrySettings          = configsource.RetrySettings
+	CacheSettings          = configsource.CacheSettings
+	// Source is the configuration of a config source, see configsource.Source.
+	Source = configsource.Source
+)
 
 // NewSourceSettings return a new config.SourceSettings struct with the given ComponentID.
 func NewSourceSettings(id component.ID) SourceSettings {
-	return SourceSettings{id}
-}
-
-// Source is the configuration of a config source. Specific config sources must implement this
-// interface and will typically embed SourceSettings struct or a struct that extends it.
-type Source interface {
-	// TODO: While config sources are experimental and not in the config package they can't
-	//	 reference the private interfaces config.identifiable and config.validatable.
-	//   Defining the required methods of the interfaces temporarily here.
-
-	// From config.identifiable:
-
-	// ID returns the ID of the component that this configuration belongs to.
-	ID() component.ID
-	// SetIDName updates the name part of the ID for the component that this configuration belongs to.
-	SetIDName(idName string)
-
-	// From config.validatable:
-
-	// Validate validates the configuration and returns an error if invalid.
-	Validate() error
+	return configsource.NewSourceSettings(id)
 }
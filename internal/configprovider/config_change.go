@@ -0,0 +1,174 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"go.opentelemetry.io/collector/confmap"
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigChangeHook is a Hook notified about the re-resolutions of the configuration changing it,
+// e.g. to audit the changes applied after a config source value changed.
+type ConfigChangeHook interface {
+	Hook
+	// OnConfigChange is called after the configuration retrieved for the scheme is resolved again
+	// with a different result.
+	OnConfigChange(change ConfigChange)
+}
+
+// ConfigChange describes the changes of a re-resolution of the configuration. The values of the
+// keys resolved from sensitive config sources are redacted, see Redact.
+type ConfigChange struct {
+	// Added are the flattened keys added by the re-resolution, with their values.
+	Added map[string]any `json:"added,omitempty"`
+	// Removed are the flattened keys removed by the re-resolution, with their previous values.
+	Removed map[string]any `json:"removed,omitempty"`
+	// Modified are the flattened keys whose values changed.
+	Modified map[string]ValueChange `json:"modified,omitempty"`
+	// Scheme is the scheme of the config provider, e.g. "file".
+	Scheme string `json:"scheme,omitempty"`
+	// Checksum is the hex SHA-256 of the resolved configuration, unredacted, to correlate the
+	// change with the configuration deployed without exposing its values.
+	Checksum string `json:"checksum"`
+	// URIs are the URIs the changed keys were retrieved from.
+	URIs []string `json:"uris,omitempty"`
+	// ConfigSources are the config source invocations, in the "<config source>:<selector>" form,
+	// that supplied the previous or the current values of the changed keys.
+	ConfigSources []string `json:"config_sources,omitempty"`
+}
+
+// ValueChange is the previous and the current values of a key modified by a re-resolution.
+type ValueChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// newConfigChange returns the changes between the previous and the current resolutions, false if
+// the configuration didn't change.
+func newConfigChange(scheme string, previous, current *resolution) (ConfigChange, bool) {
+	diff := diffConfig(previous.resolved, current.resolved)
+	if len(diff.keys()) == 0 {
+		return ConfigChange{}, false
+	}
+	before := Redact(previous.resolved, previous.provenance)
+	after := Redact(current.resolved, current.provenance)
+	change := ConfigChange{
+		Scheme:   scheme,
+		Checksum: configChecksum(current.resolved),
+	}
+	uris := map[string]bool{}
+	sources := map[string]bool{}
+	for _, k := range diff.keys() {
+		for _, p := range []KeyProvenance{previous.provenance[k], current.provenance[k]} {
+			if p.URI != "" {
+				uris[p.URI] = true
+			}
+			for _, source := range p.ConfigSources {
+				sources[source] = true
+			}
+		}
+	}
+	change.URIs = sortedKeys(uris)
+	change.ConfigSources = sortedKeys(sources)
+
+	if len(diff.added) > 0 {
+		change.Added = make(map[string]any, len(diff.added))
+		for _, k := range diff.added {
+			change.Added[k] = after[k]
+		}
+	}
+	if len(diff.removed) > 0 {
+		change.Removed = make(map[string]any, len(diff.removed))
+		for _, k := range diff.removed {
+			change.Removed[k] = before[k]
+		}
+	}
+	if len(diff.modified) > 0 {
+		change.Modified = make(map[string]ValueChange, len(diff.modified))
+		for _, k := range diff.modified {
+			change.Modified[k] = ValueChange{Before: before[k], After: after[k]}
+		}
+	}
+	return change, true
+}
+
+// DiffConfigs returns the changes from the previous to the current configuration, nested or with
+// flattened keys, false if they are the same. The values of the keys resolved from sensitive
+// config sources, according to the provenance of the current configuration, are redacted on both
+// sides, see Redact.
+func DiffConfigs(previous, current map[string]any, provenance map[string]KeyProvenance) (ConfigChange, bool) {
+	return newConfigChange("",
+		&resolution{resolved: flattenConfig(previous), provenance: provenance},
+		&resolution{resolved: flattenConfig(current), provenance: provenance})
+}
+
+// flattenConfig returns the leaf values of the configuration by their keys flattened with the
+// confmap.KeyDelimiter.
+func flattenConfig(config map[string]any) map[string]any {
+	conf := confmap.NewFromStringMap(config)
+	flattened := map[string]any{}
+	for _, k := range conf.AllKeys() {
+		flattened[k] = conf.Get(k)
+	}
+	return flattened
+}
+
+// configChecksum returns the hex SHA-256 of the YAML of the configuration, its keys are sorted.
+func configChecksum(config map[string]any) string {
+	out, err := yaml.Marshal(config)
+	if err != nil {
+		out = []byte(fmt.Sprint(config))
+	}
+	sum := sha256.Sum256(out)
+	return hex.EncodeToString(sum[:])
+}
+
+func sortedKeys(set map[string]bool) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// notifyConfigChange notifies the hooks about the changes of the re-resolution of the configuration.
+func (c *configSourceConfigMapProvider) notifyConfigChange(scheme string, previous, current *resolution) {
+	var hooks []ConfigChangeHook
+	for _, h := range c.hooks {
+		if changeHook, ok := h.(ConfigChangeHook); ok {
+			hooks = append(hooks, changeHook)
+		}
+	}
+	if len(hooks) == 0 {
+		return
+	}
+	change, ok := newConfigChange(scheme, previous, current)
+	if !ok {
+		return
+	}
+	for _, h := range hooks {
+		h.OnConfigChange(change)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConfigChange(t *testing.T) {
+	previous := &resolution{
+		resolved: map[string]any{
+			"exporters::otlp::endpoint":       "localhost:4317",
+			"exporters::otlp::headers::token": "old_secret",
+			"exporters::logging::loglevel":    "info",
+		},
+		provenance: map[string]KeyProvenance{
+			"exporters::otlp::endpoint":       {URI: "file:config.yaml"},
+			"exporters::otlp::headers::token": {URI: "file:config.yaml", ConfigSources: []string{"vault:secret/token"}, Redacted: true},
+			"exporters::logging::loglevel":    {URI: "file:config.yaml"},
+		},
+	}
+	current := &resolution{
+		resolved: map[string]any{
+			"exporters::otlp::endpoint":       "localhost:4317",
+			"exporters::otlp::headers::token": "new_secret",
+			"exporters::otlp::compression":    "gzip",
+		},
+		provenance: map[string]KeyProvenance{
+			"exporters::otlp::endpoint":       {URI: "file:config.yaml"},
+			"exporters::otlp::headers::token": {URI: "file:config.yaml", ConfigSources: []string{"vault:secret/token"}, Redacted: true},
+			"exporters::otlp::compression":    {URI: "env:OVERRIDES", ConfigSources: []string{"env:COMPRESSION"}},
+		},
+	}
+
+	change, ok := newConfigChange("file", previous, current)
+	assert.True(t, ok)
+	assert.Equal(t, "file", change.Scheme)
+	assert.Equal(t, map[string]any{"exporters::otlp::compression": "gzip"}, change.Added)
+	assert.Equal(t, map[string]any{"exporters::logging::loglevel": "info"}, change.Removed)
+	assert.Equal(t, map[string]ValueChange{
+		"exporters::otlp::headers::token": {Before: RedactedValue, After: RedactedValue},
+	}, change.Modified)
+	assert.Equal(t, []string{"env:OVERRIDES", "file:config.yaml"}, change.URIs)
+	assert.Equal(t, []string{"env:COMPRESSION", "vault:secret/token"}, change.ConfigSources)
+	assert.Len(t, change.Checksum, 64)
+	assert.Equal(t, change.Checksum, configChecksum(current.resolved))
+	assert.NotEqual(t, change.Checksum, configChecksum(previous.resolved))
+
+	_, ok = newConfigChange("file", current, current)
+	assert.False(t, ok)
+}
+
+func TestDiffConfigs(t *testing.T) {
+	previous := map[string]any{
+		"exporters": map[string]any{
+			"otlp": map[string]any{"endpoint": "localhost:4317", "headers": map[string]any{"x-secret": "old"}},
+		},
+	}
+	current := map[string]any{
+		"exporters": map[string]any{
+			"otlp": map[string]any{"endpoint": "localhost:4318", "headers": map[string]any{"x-secret": "new"}},
+		},
+	}
+	provenance := map[string]KeyProvenance{
+		"exporters::otlp::headers::x-secret": {ConfigSources: []string{"vault:secret/otlp"}, Redacted: true},
+	}
+
+	change, ok := DiffConfigs(previous, current, provenance)
+	assert.True(t, ok)
+	assert.Empty(t, change.Scheme)
+	assert.Equal(t, map[string]ValueChange{
+		"exporters::otlp::endpoint":          {Before: "localhost:4317", After: "localhost:4318"},
+		"exporters::otlp::headers::x-secret": {Before: RedactedValue, After: RedactedValue},
+	}, change.Modified)
+	assert.Equal(t, []string{"vault:secret/otlp"}, change.ConfigSources)
+
+	_, ok = DiffConfigs(current, current, provenance)
+	assert.False(t, ok)
+}
@@ -0,0 +1,74 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"strings"
+)
+
+// fileScheme is the scheme of the URIs handled by the collector file provider.
+const fileScheme = "file"
+
+type configFileCtxKey struct{}
+
+type keyOriginsCtxKey struct{}
+
+type keyCtxKey struct{}
+
+// ContextWithConfigFile returns a copy of ctx carrying the path of the configuration file
+// in which the config source invocation being resolved was defined.
+func ContextWithConfigFile(ctx context.Context, file string) context.Context {
+	return context.WithValue(ctx, configFileCtxKey{}, file)
+}
+
+// ConfigFileFromContext returns the path of the configuration file in which the config
+// source invocation being resolved was defined. It returns false if the invocation
+// doesn't come from a file, e.g. when the configuration was provided via an env var.
+// Config sources can use it to resolve paths relative to that file.
+func ConfigFileFromContext(ctx context.Context) (string, bool) {
+	file, ok := ctx.Value(configFileCtxKey{}).(string)
+	return file, ok && file != ""
+}
+
+// contextWithKeyOrigins returns a copy of ctx carrying the URIs from which each key of
+// the configuration being resolved was retrieved.
+func contextWithKeyOrigins(ctx context.Context, origins map[string]string) context.Context {
+	return context.WithValue(ctx, keyOriginsCtxKey{}, origins)
+}
+
+// contextForKey returns the context used to resolve the given key: it carries the key, unless
+// it is nested in a key already being resolved, and if the key was retrieved from a file, and
+// no file was set yet, the file path.
+func contextForKey(ctx context.Context, key string) context.Context {
+	if _, ok := keyFromContext(ctx); !ok {
+		ctx = context.WithValue(ctx, keyCtxKey{}, key)
+	}
+	if _, ok := ConfigFileFromContext(ctx); ok {
+		return ctx
+	}
+	origins, _ := ctx.Value(keyOriginsCtxKey{}).(map[string]string)
+	if origin := origins[key]; strings.HasPrefix(origin, fileScheme+":") {
+		return ContextWithConfigFile(ctx, strings.TrimPrefix(origin, fileScheme+":"))
+	}
+	return ctx
+}
+
+// keyFromContext returns the top-level key of the configuration being resolved.
+func keyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(keyCtxKey{}).(string)
+	return key, ok
+}
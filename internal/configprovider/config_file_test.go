@@ -0,0 +1,47 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextForKey(t *testing.T) {
+	ctx := contextWithKeyOrigins(context.Background(), map[string]string{
+		"receivers::otlp":  "file:/etc/otel/collector/receivers.yaml",
+		"exporters::otlp":  "env:OTEL_CONFIG",
+		"processors::none": "",
+	})
+
+	file, ok := ConfigFileFromContext(contextForKey(ctx, "receivers::otlp"))
+	assert.True(t, ok)
+	assert.Equal(t, "/etc/otel/collector/receivers.yaml", file)
+
+	_, ok = ConfigFileFromContext(contextForKey(ctx, "exporters::otlp"))
+	assert.False(t, ok)
+
+	_, ok = ConfigFileFromContext(contextForKey(ctx, "unknown"))
+	assert.False(t, ok)
+
+	// A file already set, e.g. when resolving the parameters of an invocation, is kept.
+	fileCtx := ContextWithConfigFile(ctx, "/etc/otel/collector/config.yaml")
+	file, ok = ConfigFileFromContext(contextForKey(fileCtx, "receivers::otlp"))
+	assert.True(t, ok)
+	assert.Equal(t, "/etc/otel/collector/config.yaml", file)
+}
@@ -18,6 +18,9 @@ package configprovider
 import (
 	"context"
 	"fmt"
+	"os"
+	"sync"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/confmap"
@@ -39,27 +42,167 @@ type configSourceConfigMapProvider struct {
 	hooks            []Hook
 	wrappedProvider  confmap.Provider
 	wrappedRetrieved *confmap.Retrieved
+	keyOrigins       map[string]string
+	uriMerger        *URIMerger
+	cache            *resolutionCache
+	breakers         *circuitBreakers
+	limiters         *sourceLimiters
+	schemeProviders  map[string]confmap.Provider
+	debouncer        *watchDebouncer
+	lastResolution   *resolution
+	refreshWatcher   confmap.WatcherFunc
+	retrievedURIs    map[string]bool
+	cacheFile        *configCacheFile
+	cacheFileErr     error
+	materialize      materializeSettings
+	materializeErr   error
+	cacheRetry       *time.Timer
+	mutex            sync.Mutex
 	buildInfo        component.BuildInfo
 	factories        []Factory
+	watchDebounce    time.Duration
+	resolveTimeout   time.Duration
+	// pendingResolution is the resolution after the last change notified by the config sources.
+	pendingResolution *pendingResolution
+	// sourceWatcher is the watcher of the values retrieved by the config sources.
+	sourceWatcher confmap.WatcherFunc
+	// shutdownCtx is canceled on shutdown to stop the resolutions in progress.
+	shutdownCtx context.Context
+	shutdown    context.CancelFunc
+	stopped     bool
 }
 
-// NewConfigSourceConfigMapProvider creates a ParserProvider that uses config sources.
-func NewConfigSourceConfigMapProvider(wrappedProvider confmap.Provider, logger *zap.Logger,
-	buildInfo component.BuildInfo, hooks []Hook, factories ...Factory) confmap.Provider {
-	for _, h := range hooks {
+// Options configures the config provider created by New. The zero value of each field selects its
+// default, the settings also set by an env var default to it.
+type Options struct {
+	// Logger is the logger of the config provider and of the config sources, a nop logger by default.
+	Logger *zap.Logger
+	// Hooks are notified about the lifecycle of the config provider, see Hook. The hooks implementing
+	// ProvenanceHook, ResolutionHook, or given as a Refresher or a SnapshotRecorder, are also notified
+	// about, or take part in, the resolutions of the configuration.
+	Hooks []Hook
+	// SchemeProviders resolve the bracketed "${<scheme>:<opaque>}" references not matching any config
+	// source, in the same pass, e.g. the env and file providers of the collector.
+	SchemeProviders map[string]confmap.Provider
+	// Factories are the factories of the config sources that can be declared in the configuration.
+	Factories []Factory
+	// BuildInfo is passed to the config sources when they are created.
+	BuildInfo component.BuildInfo
+	// URIMerger merges the configurations retrieved for the URIs, shared by the config providers of
+	// the different schemes so their URIs are merged in order, by default only the URIs of the scheme
+	// of the config provider are merged.
+	URIMerger *URIMerger
+	// WatchDebounce is the quiet period after which the changes notified by the config sources trigger
+	// a single reload, by default the SPLUNK_CONFIG_WATCH_DEBOUNCE env var.
+	WatchDebounce time.Duration
+	// ResolveTimeout is the maximum duration of each resolution of the configuration, including the
+	// retries of the config sources, by default the SPLUNK_CONFIG_RESOLVE_TIMEOUT env var.
+	ResolveTimeout time.Duration
+	// CacheFile is the path of the file persisting the last resolved configuration, to use it if the
+	// configuration can't be resolved, by default the SPLUNK_CONFIG_CACHE_FILE env var.
+	CacheFile string
+	// CacheKey is the AES key, of 16, 24, or 32 bytes, encrypting the cache file, by default the base64
+	// decoded SPLUNK_CONFIG_CACHE_KEY env var.
+	CacheKey []byte
+	// MaterializeDir is the directory of the files the values are materialized to, with the
+	// "materialize=file" parameter, by default the SPLUNK_CONFIG_MATERIALIZE_DIR env var or the
+	// directory for temporary files.
+	MaterializeDir string
+	// MaterializeMode is the permissions of the materialized files, by default the octal
+	// SPLUNK_CONFIG_MATERIALIZE_MODE env var or 0600.
+	MaterializeMode os.FileMode
+	// MaterializeOwner is the "user[:group]", names or ids, owning the materialized files, by default
+	// the SPLUNK_CONFIG_MATERIALIZE_OWNER env var or the user running the collector.
+	MaterializeOwner string
+}
+
+// New creates a config provider resolving the config sources, and the references to the scheme
+// providers, of the configuration retrieved by the wrapped provider. It is the constructor to embed
+// the config sources in other distributions and tools.
+func New(wrappedProvider confmap.Provider, options Options) confmap.Provider {
+	for _, h := range options.Hooks {
 		h.OnNew()
 	}
-	return &configSourceConfigMapProvider{
-		hooks:            hooks,
+	if options.Logger == nil {
+		options.Logger = zap.NewNop()
+	}
+	if options.WatchDebounce <= 0 {
+		options.WatchDebounce = watchDebounce()
+	}
+	if options.ResolveTimeout <= 0 {
+		options.ResolveTimeout = resolveTimeout()
+	}
+	if options.URIMerger == nil {
+		options.URIMerger = NewURIMerger()
+	}
+	provider := &configSourceConfigMapProvider{
+		hooks:            options.Hooks,
 		wrappedProvider:  wrappedProvider,
-		logger:           logger,
-		factories:        factories,
-		buildInfo:        buildInfo,
+		logger:           options.Logger,
+		factories:        options.Factories,
+		buildInfo:        options.BuildInfo,
 		wrappedRetrieved: &confmap.Retrieved{},
+		keyOrigins:       map[string]string{},
+		uriMerger:        options.URIMerger,
+		retrievedURIs:    map[string]bool{},
+		cache:            newResolutionCache(),
+		breakers:         newCircuitBreakers(),
+		limiters:         newSourceLimiters(),
+		schemeProviders:  options.SchemeProviders,
+		watchDebounce:    options.WatchDebounce,
+		resolveTimeout:   options.ResolveTimeout,
+	}
+	provider.shutdownCtx, provider.shutdown = context.WithCancel(context.Background())
+	provider.cacheFile, provider.cacheFileErr = openConfigCacheFile(options.CacheFile, options.CacheKey)
+	provider.materialize, provider.materializeErr = newMaterializeSettings(options.MaterializeDir, options.MaterializeMode, options.MaterializeOwner)
+	for _, h := range options.Hooks {
+		if refresher, ok := h.(*Refresher); ok {
+			refresher.addProvider(provider)
+		}
 	}
+	return provider
+}
+
+// NewConfigSourceConfigMapProvider creates a ParserProvider that uses config sources. The bracketed
+// "${<scheme>:<opaque>}" references not matching any config source are resolved, in the same pass,
+// by the schemeProviders, e.g. the env and file providers of the collector. See New for the other options.
+func NewConfigSourceConfigMapProvider(wrappedProvider confmap.Provider, logger *zap.Logger,
+	buildInfo component.BuildInfo, hooks []Hook, schemeProviders map[string]confmap.Provider, factories ...Factory) confmap.Provider {
+	return New(wrappedProvider, Options{
+		Logger:          logger,
+		BuildInfo:       buildInfo,
+		Hooks:           hooks,
+		SchemeProviders: schemeProviders,
+		Factories:       factories,
+	})
 }
 
 func (c *configSourceConfigMapProvider) Retrieve(ctx context.Context, uri string, onChange confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	if c.cacheFileErr != nil {
+		return nil, c.cacheFileErr
+	}
+	if c.materializeErr != nil {
+		return nil, c.materializeErr
+	}
+	reload := onChange
+	// The changes of the configuration retrieved by the wrapped provider always reload it, the ones
+	// notified by the config sources only if the configuration resolved again is different.
+	sourceOnChange := onChange
+	if onChange != nil {
+		sourceOnChange = c.reloadIfChanged(onChange)
+		c.setRefreshWatcher(sourceOnChange)
+	}
+	if c.watchDebounce > 0 && sourceOnChange != nil {
+		// The same watcher is passed on every retrieve, coalesce the events of all of them.
+		if c.debouncer == nil {
+			c.debouncer = newWatchDebouncer(sourceOnChange, c.watchDebounce)
+		}
+		sourceOnChange = c.debouncer.onChange
+	}
+	c.mutex.Lock()
+	c.sourceWatcher = sourceOnChange
+	c.mutex.Unlock()
+
 	var tmpWR *confmap.Retrieved
 	var err error
 	newWrappedRetrieved := &confmap.Retrieved{}
@@ -69,44 +212,107 @@ func (c *configSourceConfigMapProvider) Retrieve(ctx context.Context, uri string
 		newWrappedRetrieved = tmpWR
 	}
 
-	existingMap, err := c.wrappedRetrieved.AsConf()
+	newMap, err := newWrappedRetrieved.AsConf()
 	if err != nil {
 		return nil, err
 	}
+	// Merge the configuration with the ones of the URIs retrieved before it, the keys of the later
+	// URIs taking precedence, and track the URI each key comes from.
+	wrappedMap, keyOrigins, err := c.uriMerger.merge(uri, newMap)
+	if err != nil {
+		return nil, err
+	}
+	c.setKeyOrigins(keyOrigins)
+	if c.wrappedRetrieved, err = confmap.NewRetrieved(wrappedMap.ToStringMap(), confmap.WithRetrievedClose(newWrappedRetrieved.Close)); err != nil {
+		return nil, err
+	}
 
-	// Need to merge config maps that we've encountered so far
-	if existingMap != nil {
-		wrMap, _ := newWrappedRetrieved.AsConf()
-		wrMap.Merge(existingMap)
-		if tmpWR, err = confmap.NewRetrieved(wrMap.ToStringMap()); err != nil {
-			return nil, err
-		} else if tmpWR != nil {
-			newWrappedRetrieved = tmpWR
+	scheme := c.Scheme()
+	if len(c.hooks) > 0 {
+		stringMap := wrappedMap.ToStringMap()
+		for _, h := range c.hooks {
+			h.OnRetrieve(scheme, stringMap)
 		}
 	}
-	c.wrappedRetrieved = newWrappedRetrieved
 
-	factories, err := makeFactoryMap(c.factories)
+	var provenance *provenanceRecorder
+	var retrieved map[string]any
+	var closeFunc confmap.CloseFunc
+	if pending, ok := c.takePendingResolution(wrappedMap); ok {
+		// The configuration was already resolved again to decide to reload it.
+		provenance, retrieved, closeFunc = pending.provenance, pending.resolved, pending.closeFunc
+	} else {
+		provenance = newProvenanceRecorder()
+		resolveCtx, cancel := c.resolutionContext(ctx)
+		defer cancel()
+		resolveCtx = contextWithProvenance(c.resolveContext(resolveCtx, keyOrigins), provenance)
+		retrieved, closeFunc, err = c.resolveWithHooks(resolveCtx, wrappedMap, sourceOnChange)
+	}
 	if err != nil {
-		return nil, err
+		cached, ok := c.cachedResolution(uri, wrappedMap, err, reload)
+		if !ok {
+			return nil, err
+		}
+		return confmap.NewRetrieved(cached, confmap.WithRetrievedClose(c.wrappedRetrieved.Close))
+	}
+	c.cancelRetry(false)
+	c.storeResolution(uri, retrieved)
+	keyProvenance := provenance.provenance(wrappedMap.AllKeys(), keyOrigins)
+	previous := c.setLastResolution(wrappedMap, retrieved, keyProvenance)
+	if c.retrievedURIs[uri] && previous != nil {
+		// Only report the changes of the re-resolutions, not the keys added by the other URIs.
+		logConfigDiff(scheme, previous, c.lastResolution)
+		c.notifyConfigChange(scheme, previous, c.lastResolution)
 	}
+	c.retrievedURIs[uri] = true
+	c.reportProvenance(scheme, keyProvenance)
 
-	wrappedMap, err := c.wrappedRetrieved.AsConf()
-	if err != nil {
-		return nil, err
+	return confmap.NewRetrieved(retrieved, confmap.WithRetrievedClose(mergeCloseFuncs([]confmap.CloseFunc{closeFunc, c.wrappedRetrieved.Close})))
+}
+
+// setKeyOrigins records the URI each key of the configuration comes from. The resolutions done
+// outside of Retrieve, e.g. after a change notified by a config source, read a copy of it.
+func (c *configSourceConfigMapProvider) setKeyOrigins(keyOrigins map[string]string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.keyOrigins = keyOrigins
+}
+
+// copyKeyOrigins returns a copy of the URI each key of the configuration comes from.
+func (c *configSourceConfigMapProvider) copyKeyOrigins() map[string]string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	keyOrigins := make(map[string]string, len(c.keyOrigins))
+	for k, origin := range c.keyOrigins {
+		keyOrigins[k] = origin
 	}
+	return keyOrigins
+}
 
-	scheme, stringMap := c.Scheme(), wrappedMap.ToStringMap()
+// resolveContext returns a copy of ctx carrying the state shared by the resolutions of the configuration.
+func (c *configSourceConfigMapProvider) resolveContext(ctx context.Context, keyOrigins map[string]string) context.Context {
+	ctx = contextWithCache(contextWithHealthHooks(contextWithKeyOrigins(ctx, keyOrigins), c.hooks), c.cache)
+	ctx = contextWithSourceLimiters(contextWithCircuitBreakers(ctx, c.breakers), c.limiters)
+	ctx = contextWithMaterializeSettings(ctx, c.materialize)
 	for _, h := range c.hooks {
-		h.OnRetrieve(scheme, stringMap)
+		if recorder, ok := h.(interface{ snapshotRecorder() *SnapshotRecorder }); ok && recorder.snapshotRecorder() != nil {
+			ctx = contextWithSnapshotRecorder(ctx, recorder.snapshotRecorder())
+		}
 	}
+	return contextWithSchemeProviders(ctx, c.schemeProviders)
+}
 
-	retrieved, closeFunc, err := Resolve(ctx, wrappedMap, c.logger, c.buildInfo, factories, onChange)
-	if err != nil {
-		return nil, err
+// reportProvenance logs the provenance of the keys of the resolved configuration and notifies
+// the hooks about it.
+func (c *configSourceConfigMapProvider) reportProvenance(scheme string, provenance map[string]KeyProvenance) {
+	if ce := c.logger.Check(zap.DebugLevel, "Resolved configuration provenance"); ce != nil {
+		ce.Write(zap.String("scheme", scheme), zap.Any("provenance", provenance))
+	}
+	for _, h := range c.hooks {
+		if provenanceHook, ok := h.(ProvenanceHook); ok {
+			provenanceHook.OnProvenance(scheme, provenance)
+		}
 	}
-
-	return confmap.NewRetrieved(retrieved, confmap.WithRetrievedClose(mergeCloseFuncs([]confmap.CloseFunc{closeFunc, c.wrappedRetrieved.Close})))
 }
 
 func (c *configSourceConfigMapProvider) Scheme() string {
@@ -114,6 +320,12 @@ func (c *configSourceConfigMapProvider) Scheme() string {
 }
 
 func (c *configSourceConfigMapProvider) Shutdown(ctx context.Context) error {
+	c.shutdown()
+	c.cancelRetry(true)
+	c.setPendingResolution(nil)
+	if c.debouncer != nil {
+		c.debouncer.stop()
+	}
 	for _, h := range c.hooks {
 		h.OnShutdown()
 	}
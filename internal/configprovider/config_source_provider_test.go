@@ -19,7 +19,9 @@ import (
 	"context"
 	"errors"
 	"path"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -104,6 +106,7 @@ func TestConfigSourceConfigMapProvider(t *testing.T) {
 				zap.NewNop(),
 				component.NewDefaultBuildInfo(),
 				[]Hook{hookOne, hookTwo},
+				nil,
 				factories...,
 			)
 			require.NotNil(t, pp)
@@ -205,3 +208,25 @@ func (m *mockHook) OnRetrieve(scheme string, _ map[string]any) {
 func (m *mockHook) OnShutdown() {
 	m.Called()
 }
+
+func TestNewOptions(t *testing.T) {
+	t.Setenv(watchDebounceEnvVar, "5s")
+	provider := New(fileprovider.New(), Options{}).(*configSourceConfigMapProvider)
+	assert.NotNil(t, provider.logger)
+	assert.Equal(t, 5*time.Second, provider.watchDebounce)
+	assert.Nil(t, provider.cacheFile)
+
+	cacheFile := filepath.Join(t.TempDir(), "config.cache")
+	provider = New(fileprovider.New(), Options{
+		WatchDebounce: time.Second,
+		CacheFile:     cacheFile,
+		CacheKey:      []byte("0123456789abcdef"),
+	}).(*configSourceConfigMapProvider)
+	assert.Equal(t, time.Second, provider.watchDebounce)
+	require.NotNil(t, provider.cacheFile)
+	assert.Equal(t, cacheFile, provider.cacheFile.path)
+
+	_, err := New(fileprovider.New(), Options{CacheFile: cacheFile, CacheKey: []byte("short")}).
+		Retrieve(context.Background(), "file:"+path.Join("testdata", "arrays_and_maps.yaml"), nil)
+	assert.ErrorContains(t, err, "invalid SPLUNK_CONFIG_CACHE_KEY")
+}
@@ -0,0 +1,110 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// resolveTimeoutEnvVar is the env var with the maximum duration, e.g. "2m", of each resolution of
+// the configuration, including the retries of the config sources. There is no timeout by default.
+const resolveTimeoutEnvVar = "SPLUNK_CONFIG_RESOLVE_TIMEOUT"
+
+func resolveTimeout() time.Duration {
+	timeout, err := time.ParseDuration(os.Getenv(resolveTimeoutEnvVar))
+	if err != nil || timeout < 0 {
+		return 0
+	}
+	return timeout
+}
+
+// resolutionContext returns a copy of ctx, for a resolution of the configuration, done after the
+// resolve timeout or once the config provider is shut down, so the retrieves in progress don't
+// hang the shutdown waiting on an unresponsive backend.
+func (c *configSourceConfigMapProvider) resolutionContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	if c.resolveTimeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, c.resolveTimeout)
+		cancelCtx := cancel
+		cancel = func() {
+			cancelTimeout()
+			cancelCtx()
+		}
+	}
+	go func() {
+		select {
+		case <-c.shutdownCtx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// withCancellation wraps the config sources so their retrieves return once ctx is done, even if
+// the config source doesn't honor it.
+func withCancellation(configSources map[string]ConfigSource) map[string]ConfigSource {
+	for name, cfgSrc := range configSources {
+		configSources[name] = &cancellableConfigSource{ConfigSource: cfgSrc}
+	}
+	return configSources
+}
+
+type cancellableConfigSource struct {
+	ConfigSource
+}
+
+func (c *cancellableConfigSource) Retrieve(ctx context.Context, selector string, paramsConfigMap *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	return retrieveUntilDone(ctx, c.ConfigSource, selector, paramsConfigMap, watcher)
+}
+
+// retrieveUntilDone retrieves the value from the config source until ctx is done. Then the
+// retrieve is abandoned, returning the error of ctx, and its result closed whenever it's returned.
+func retrieveUntilDone(ctx context.Context, cfgSrc ConfigSource, selector string, paramsConfigMap *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	if ctx.Done() == nil {
+		return cfgSrc.Retrieve(ctx, selector, paramsConfigMap, watcher)
+	}
+
+	type result struct {
+		retrieved *confmap.Retrieved
+		err       error
+	}
+	resultCh := make(chan result)
+	abandoned := make(chan struct{})
+	go func() {
+		retrieved, err := cfgSrc.Retrieve(ctx, selector, paramsConfigMap, watcher)
+		select {
+		case resultCh <- result{retrieved, err}:
+		case <-abandoned:
+			if retrieved != nil {
+				_ = retrieved.Close(context.Background())
+			}
+		}
+	}()
+
+	select {
+	case res := <-resultCh:
+		return res.retrieved, res.err
+	case <-ctx.Done():
+		close(abandoned)
+		return nil, ctx.Err()
+	}
+}
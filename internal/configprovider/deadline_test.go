@@ -0,0 +1,87 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/provider/fileprovider"
+)
+
+func TestCancellableConfigSource(t *testing.T) {
+	unblock := make(chan struct{})
+	defer close(unblock)
+	cfgSrc := &testConfigSource{
+		ValueMap: map[string]valueEntry{
+			"selector": {Value: "value"},
+		},
+		OnRetrieve: func(ctx context.Context, selector string, paramsConfigMap *confmap.Conf) error {
+			// Ignores ctx, like an unresponsive backend.
+			<-unblock
+			return nil
+		},
+	}
+	cfgSources := withCancellation(map[string]ConfigSource{"tstcfgsrc": cfgSrc})
+	require.IsType(t, &cancellableConfigSource{}, cfgSources["tstcfgsrc"])
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	retrieved, err := cfgSources["tstcfgsrc"].Retrieve(ctx, "selector", nil, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, retrieved)
+
+	cfgSources = withCancellation(map[string]ConfigSource{"tstcfgsrc": &testConfigSource{
+		ValueMap: map[string]valueEntry{
+			"selector": {Value: "value"},
+		},
+	}})
+	retrieved, err = cfgSources["tstcfgsrc"].Retrieve(context.Background(), "selector", nil, nil)
+	require.NoError(t, err)
+	raw, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, "value", raw)
+}
+
+func TestResolutionContext(t *testing.T) {
+	t.Setenv(resolveTimeoutEnvVar, "10ms")
+	provider := New(fileprovider.New(), Options{}).(*configSourceConfigMapProvider)
+	assert.Equal(t, 10*time.Millisecond, provider.resolveTimeout)
+
+	ctx, cancel := provider.resolutionContext(context.Background())
+	defer cancel()
+	select {
+	case <-ctx.Done():
+		assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("the resolution didn't time out")
+	}
+
+	provider = New(fileprovider.New(), Options{ResolveTimeout: time.Hour}).(*configSourceConfigMapProvider)
+	ctx, cancel = provider.resolutionContext(context.Background())
+	defer cancel()
+	require.NoError(t, provider.Shutdown(context.Background()))
+	select {
+	case <-ctx.Done():
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("the resolution wasn't canceled on shutdown")
+	}
+}
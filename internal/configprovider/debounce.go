@@ -0,0 +1,94 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/multierr"
+)
+
+// watchDebounceEnvVar is the env var with the quiet period, e.g. "5s", after which the change
+// events notified by the config sources in a burst, e.g. when a whole Vault secret tree is rotated,
+// trigger a single re-resolution of the configuration. Debouncing is disabled by default.
+const watchDebounceEnvVar = "SPLUNK_CONFIG_WATCH_DEBOUNCE"
+
+func watchDebounce() time.Duration {
+	quiet, err := time.ParseDuration(os.Getenv(watchDebounceEnvVar))
+	if err != nil || quiet < 0 {
+		return 0
+	}
+	return quiet
+}
+
+// watchDebouncer coalesces the change events notified within the quiet period of each other into
+// a single call of the watcher, after the quiet period, with the errors of all of them.
+type watchDebouncer struct {
+	watcher confmap.WatcherFunc
+	timer   *time.Timer
+	err     error
+	quiet   time.Duration
+	// generation identifies the last event, only its timer notifies the watcher.
+	generation uint64
+	stopped    bool
+	mu         sync.Mutex
+}
+
+func newWatchDebouncer(watcher confmap.WatcherFunc, quiet time.Duration) *watchDebouncer {
+	return &watchDebouncer{watcher: watcher, quiet: quiet}
+}
+
+// onChange is the confmap.WatcherFunc passed to the config sources instead of the watcher.
+func (d *watchDebouncer) onChange(event *confmap.ChangeEvent) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped {
+		return
+	}
+	d.err = multierr.Append(d.err, event.Error)
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.generation++
+	generation := d.generation
+	d.timer = time.AfterFunc(d.quiet, func() { d.notify(generation) })
+}
+
+func (d *watchDebouncer) notify(generation uint64) {
+	d.mu.Lock()
+	if d.stopped || generation != d.generation {
+		// A later event restarted the quiet period.
+		d.mu.Unlock()
+		return
+	}
+	err := d.err
+	d.err, d.timer = nil, nil
+	d.mu.Unlock()
+	d.watcher(&confmap.ChangeEvent{Error: err})
+}
+
+// stop drops the pending events, the watcher isn't notified anymore.
+func (d *watchDebouncer) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stopped = true
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+}
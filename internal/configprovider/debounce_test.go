@@ -0,0 +1,92 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+type recordingWatcher struct {
+	events []*confmap.ChangeEvent
+	mu     sync.Mutex
+}
+
+func (r *recordingWatcher) onChange(event *confmap.ChangeEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recordingWatcher) calls() []*confmap.ChangeEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*confmap.ChangeEvent(nil), r.events...)
+}
+
+func TestWatchDebouncer(t *testing.T) {
+	watcher := &recordingWatcher{}
+	debouncer := newWatchDebouncer(watcher.onChange, 50*time.Millisecond)
+
+	errWatch := errors.New("watch failed")
+	for i := 0; i < 5; i++ {
+		event := &confmap.ChangeEvent{}
+		if i == 2 {
+			event.Error = errWatch
+		}
+		debouncer.onChange(event)
+	}
+	assert.Empty(t, watcher.calls(), "the watcher must only be notified after the quiet period")
+
+	require.Eventually(t, func() bool { return len(watcher.calls()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.ErrorIs(t, watcher.calls()[0].Error, errWatch)
+
+	// A later burst is notified separately, without the errors of the previous one.
+	debouncer.onChange(&confmap.ChangeEvent{})
+	require.Eventually(t, func() bool { return len(watcher.calls()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	assert.NoError(t, watcher.calls()[1].Error)
+
+	// The pending events are dropped once stopped.
+	debouncer.onChange(&confmap.ChangeEvent{})
+	debouncer.stop()
+	debouncer.onChange(&confmap.ChangeEvent{})
+	time.Sleep(200 * time.Millisecond)
+	assert.Len(t, watcher.calls(), 2)
+}
+
+func TestWatchDebounce(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "", want: 0},
+		{value: "5s", want: 5 * time.Second},
+		{value: "-1s", want: 0},
+		{value: "invalid", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv(watchDebounceEnvVar, tt.value)
+			assert.Equal(t, tt.want, watchDebounce())
+		})
+	}
+}
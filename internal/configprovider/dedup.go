@@ -0,0 +1,65 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// withDedup wraps the config sources so identical invocations, e.g. from expanded YAML anchors,
+// are retrieved only once during a resolution.
+func withDedup(configSources map[string]ConfigSource) map[string]ConfigSource {
+	retrieved := map[string]any{}
+	for name, cfgSrc := range configSources {
+		configSources[name] = &dedupConfigSource{
+			ConfigSource: cfgSrc,
+			retrieved:    retrieved,
+			name:         name,
+		}
+	}
+	return configSources
+}
+
+// dedupConfigSource reuses the values retrieved by the wrapped config source for identical
+// invocations. Only the first invocation is watched and closed, the value of the following
+// ones comes from the same retrieval.
+type dedupConfigSource struct {
+	ConfigSource
+	// retrieved holds the values retrieved by the invocations, shared by the config sources of a resolution.
+	retrieved map[string]any
+	name      string
+}
+
+func (d *dedupConfigSource) Retrieve(ctx context.Context, selector string, paramsConfigMap *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	key, err := invocationKey(ctx, d.name, selector, paramsConfigMap)
+	if err != nil {
+		return d.ConfigSource.Retrieve(ctx, selector, paramsConfigMap, watcher)
+	}
+	if value, ok := d.retrieved[key]; ok {
+		return confmap.NewRetrieved(value)
+	}
+
+	retrieved, err := d.ConfigSource.Retrieve(ctx, selector, paramsConfigMap, watcher)
+	if err != nil {
+		return nil, err
+	}
+	if value, asRawErr := retrieved.AsRaw(); asRawErr == nil {
+		d.retrieved[key] = value
+	}
+	return retrieved, nil
+}
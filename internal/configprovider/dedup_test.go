@@ -0,0 +1,61 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+func TestDedupConfigSource(t *testing.T) {
+	retrieves := map[string]int{}
+	cfgSources := withDedup(map[string]ConfigSource{
+		"tstcfgsrc": &testConfigSource{
+			ValueMap: map[string]valueEntry{
+				"selector": {Value: "value"},
+				"other":    {Value: "other_value"},
+			},
+			OnRetrieve: func(_ context.Context, selector string, _ *confmap.Conf) error {
+				retrieves[selector]++
+				return nil
+			},
+		},
+	})
+
+	res, closeFunc, err := resolve(context.Background(), cfgSources, confmap.NewFromStringMap(map[string]any{
+		"k0": "${tstcfgsrc:selector}",
+		"k1": "${tstcfgsrc:selector}",
+		"k2": []any{"${tstcfgsrc:selector}", "${tstcfgsrc:selector?transform=upper}"},
+		"k3": "${tstcfgsrc:other}",
+		"k4": "${tstcfgsrc:other?p0=1}",
+	}), nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"k0": "value",
+		"k1": "value",
+		"k2": []any{"value", "VALUE"},
+		"k3": "other_value",
+		"k4": "other_value",
+	}, res)
+	// Invocations only differing in the parameters handled by the config provider are identical,
+	// so only the first one is retrieved, watched and closed.
+	assert.Equal(t, map[string]int{"selector": 1, "other": 2}, retrieves)
+	assert.NoError(t, callClose(closeFunc))
+}
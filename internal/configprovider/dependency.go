@@ -0,0 +1,210 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// buildConfigSources loads and builds the config sources of the configuration in dependency order:
+// the settings of each config source are resolved, like any other value of the configuration, with
+// the config sources they reference, e.g. the vault address from an env config source, built before
+// it. In offline mode, see configSnapshotEnvVar, the config sources serve the values of the snapshot
+// instead. The returned close func releases the values retrieved for the settings.
+func buildConfigSources(ctx context.Context, configMap *confmap.Conf, params CreateParams, factories Factories, watcher confmap.WatcherFunc) (map[string]ConfigSource, map[string]Source, confmap.CloseFunc, error) {
+	rawSettings := cast.ToStringMap(configMap.Get(configSourcesKey))
+	order, err := dependencyOrder(rawSettings)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	snapshotValues, offline, err := loadSnapshot()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	cfgSources := make(map[string]ConfigSource, len(rawSettings))
+	cfgSrcSettings := make(map[string]Source, len(rawSettings))
+	var closeFuncs []confmap.CloseFunc
+	for _, name := range order {
+		settings, closeFunc, err := parseConfigValue(contextWithResolver(ctx, cfgSources), cfgSources, rawSettings[name], watcher)
+		if closeFunc != nil {
+			closeFuncs = append(closeFuncs, closeFunc)
+		}
+		if err != nil {
+			return nil, nil, mergeCloseFuncs(closeFuncs), fmt.Errorf("failed to resolve the settings of %s %s: %w", configSourcesKey, name, err)
+		}
+
+		resolvedSettings := map[string]any{name: settings}
+		loaded, err := loadSettings(resolvedSettings, factories)
+		if err != nil {
+			return nil, nil, mergeCloseFuncs(closeFuncs), err
+		}
+		for fullName := range loaded {
+			if cfgSrcSettings[fullName] != nil {
+				return nil, nil, mergeCloseFuncs(closeFuncs), fmt.Errorf("duplicate %s name %s", configSourcesKey, fullName)
+			}
+		}
+		var built map[string]ConfigSource
+		if offline {
+			built = snapshotConfigSources(loaded, snapshotValues)
+		} else if built, err = buildConfigSource(ctx, loaded, resolvedSettings, params, factories); err != nil {
+			return nil, nil, mergeCloseFuncs(closeFuncs), err
+		}
+		for fullName, cfgSrc := range withSnapshotRecorder(ctx, built) {
+			cfgSources[fullName] = cfgSrc
+			cfgSrcSettings[fullName] = loaded[fullName]
+		}
+	}
+
+	return cfgSources, cfgSrcSettings, mergeCloseFuncs(closeFuncs), nil
+}
+
+// buildConfigSource builds the config sources loaded from the settings of a config source and
+// wraps them according to their limits, retry, circuit breaker, and cache settings.
+func buildConfigSource(ctx context.Context, loaded map[string]Source, rawSettings map[string]any, params CreateParams, factories Factories) (map[string]ConfigSource, error) {
+	built, err := Build(context.Background(), loaded, params, factories)
+	if err != nil {
+		return nil, err
+	}
+	built = withCancellation(built)
+	if built, err = withLimits(ctx, built, loaded); err != nil {
+		return nil, err
+	}
+	if built, err = withRetry(built, loaded, params.Logger); err != nil {
+		return nil, err
+	}
+	if built, err = withCircuitBreaker(ctx, built, loaded, params.Logger); err != nil {
+		return nil, err
+	}
+	return withCache(ctx, built, loaded, rawSettings, params.Logger)
+}
+
+// dependencyOrder sorts the config sources so each one comes after the config sources referenced
+// by its settings, failing if the references form a cycle.
+func dependencyOrder(rawSettings map[string]any) ([]string, error) {
+	names := make([]string, 0, len(rawSettings))
+	for name := range rawSettings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(names))
+	order := make([]string, 0, len(names))
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("cycle in the %s references: %s", configSourcesKey, strings.Join(append(path, name), " -> "))
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range configSourceReferences(rawSettings[name], rawSettings) {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		order = append(order, name)
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// configSourceReferences returns the sorted names of the config sources, among the declared ones,
+// referenced in the value, including the references nested in other references.
+func configSourceReferences(value any, declared map[string]any) []string {
+	refs := map[string]bool{}
+	var walk func(value any)
+	walk = func(value any) {
+		switch v := value.(type) {
+		case string:
+			for _, name := range stringReferences(v) {
+				if _, ok := declared[name]; ok {
+					refs[name] = true
+				}
+			}
+		case []any:
+			for _, elem := range v {
+				walk(elem)
+			}
+		case map[string]any:
+			for _, elem := range v {
+				walk(elem)
+			}
+		}
+	}
+	walk(value)
+
+	names := make([]string, 0, len(refs))
+	for name := range refs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// stringReferences returns the names of the config sources referenced in s, scanning it like
+// parseStringValue.
+func stringReferences(s string) []string {
+	var names []string
+	for j := 0; j+1 < len(s); j++ {
+		if s[j] != expandPrefixChar {
+			continue
+		}
+		var content, name string
+		var w int
+		switch s[j+1] {
+		case expandPrefixChar:
+			// Escaped, but the backward compatible "$$<cfgSrcName>:" invocations are found on the next char.
+			continue
+		case '{':
+			content, w, name = getBracketedExpandableContent(s, j+1)
+		default:
+			content, w, name = getBareExpandableContent(s, j+1)
+		}
+		if name == literalMarker && s[j+1] != '{' {
+			// The rest of the string is taken literally.
+			break
+		}
+		if name != "" {
+			names = append(names, name)
+			names = append(names, stringReferences(content[len(name)+1:])...)
+		}
+		j += w
+	}
+	return names
+}
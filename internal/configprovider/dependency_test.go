@@ -0,0 +1,147 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+)
+
+func TestResolveConfigSourceDependencies(t *testing.T) {
+	t.Setenv("DEPENDENCY_ADDRESS", "https://vault:8200")
+	factories := Factories{"valuesrc": &valueCfgSrcFactory{}}
+
+	cfg := confmap.NewFromStringMap(map[string]any{
+		"config_sources": map[string]any{
+			// Sorted by name the config sources come before the ones they reference.
+			"valuesrc/app": map[string]any{
+				"values": map[string]any{
+					"endpoint": "${valuesrc/vault:address}/v1",
+					"token":    "${valuesrc/vault:token}",
+				},
+			},
+			"valuesrc/vault": map[string]any{
+				"values": map[string]any{
+					"address": "$DEPENDENCY_ADDRESS",
+					"token":   "${valuesrc/zfile:token?transform=trim}",
+				},
+			},
+			"valuesrc/zfile": map[string]any{
+				"values": map[string]any{"token": " s3cr3t\n"},
+			},
+		},
+		"exporter": map[string]any{
+			"endpoint": "${valuesrc/app:endpoint}",
+			"token":    "${valuesrc/app:token}",
+		},
+	})
+
+	res, closeFunc, err := Resolve(context.Background(), cfg, zap.NewNop(), component.NewDefaultBuildInfo(), factories, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"exporter": map[string]any{
+			"endpoint": "https://vault:8200/v1",
+			"token":    "s3cr3t",
+		},
+	}, confmap.NewFromStringMap(res).ToStringMap())
+	assert.NoError(t, callClose(closeFunc))
+}
+
+func TestResolveConfigSourceDependenciesErrors(t *testing.T) {
+	factories := Factories{"valuesrc": &valueCfgSrcFactory{}}
+	tests := []struct {
+		configSources map[string]any
+		name          string
+		wantErr       string
+	}{
+		{
+			name: "cycle",
+			configSources: map[string]any{
+				"valuesrc/a": map[string]any{"values": map[string]any{"k": "${valuesrc/b:k}"}},
+				"valuesrc/b": map[string]any{"values": map[string]any{"k": "prefix-${valuesrc/c:${valuesrc/a:k}}"}},
+				"valuesrc/c": map[string]any{},
+			},
+			wantErr: "cycle in the config_sources references: valuesrc/a -> valuesrc/b -> valuesrc/a",
+		},
+		{
+			name: "self_reference",
+			configSources: map[string]any{
+				"valuesrc/a": map[string]any{"values": map[string]any{"k": "${valuesrc/a:k}"}},
+			},
+			wantErr: "cycle in the config_sources references: valuesrc/a -> valuesrc/a",
+		},
+		{
+			name: "missing_value",
+			configSources: map[string]any{
+				"valuesrc/a": map[string]any{"values": map[string]any{"k": "${valuesrc/b:missing}"}},
+				"valuesrc/b": map[string]any{},
+			},
+			wantErr: "failed to resolve the settings of config_sources valuesrc/a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := confmap.NewFromStringMap(map[string]any{"config_sources": tt.configSources})
+			_, _, err := Resolve(context.Background(), cfg, zap.NewNop(), component.NewDefaultBuildInfo(), factories, nil)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestConfigSourceReferences(t *testing.T) {
+	declared := map[string]any{"a": nil, "b": nil, "c": nil, "d": nil, "e": nil}
+	value := map[string]any{
+		"nested":  "${a:${b:key}/suffix}",
+		"bare":    []any{"$c:selector"},
+		"escaped": "$${d}:x $$HOME",
+		"literal": "$literal: ${e:key}",
+		"unknown": "${env:VAR} $HOME",
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, configSourceReferences(value, declared))
+}
+
+type valueCfgSrcSettings struct {
+	SourceSettings
+	Values map[string]any `mapstructure:"values"`
+}
+
+type valueCfgSrcFactory struct{}
+
+var _ Factory = (*valueCfgSrcFactory)(nil)
+
+func (v *valueCfgSrcFactory) Type() component.Type {
+	return "valuesrc"
+}
+
+func (v *valueCfgSrcFactory) CreateDefaultConfig() Source {
+	return &valueCfgSrcSettings{
+		SourceSettings: NewSourceSettings(component.NewID("valuesrc")),
+	}
+}
+
+func (v *valueCfgSrcFactory) CreateConfigSource(_ context.Context, _ CreateParams, cfg Source) (ConfigSource, error) {
+	valueMap := map[string]valueEntry{}
+	for k, value := range cfg.(*valueCfgSrcSettings).Values {
+		valueMap[k] = valueEntry{Value: value}
+	}
+	return &testConfigSource{ValueMap: valueMap}, nil
+}
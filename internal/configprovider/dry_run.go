@@ -0,0 +1,44 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+)
+
+// DryRunResolve resolves the configuration like the config provider, without watching the retrieved
+// values nor starting the service, and returns it with the values of the sensitive config sources
+// redacted, see Redact, so tooling and tests can validate configurations programmatically. The
+// bracketed "${<scheme>:<opaque>}" references not matching any config source are resolved by the
+// schemeProviders, e.g. the env and file providers of the collector, if any.
+func DryRunResolve(ctx context.Context, configMap *confmap.Conf, logger *zap.Logger, buildInfo component.BuildInfo, factories Factories, schemeProviders map[string]confmap.Provider) (*confmap.Conf, error) {
+	provenance := newProvenanceRecorder()
+	ctx = contextWithProvenance(contextWithSchemeProviders(ctx, schemeProviders), provenance)
+	resolved, closeFunc, err := Resolve(ctx, configMap, logger, buildInfo, factories, nil)
+	if err != nil {
+		return nil, err
+	}
+	if closeFunc != nil {
+		if err = closeFunc(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return confmap.NewFromStringMap(Redact(resolved, provenance.provenance(configMap.AllKeys(), nil))), nil
+}
@@ -0,0 +1,65 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/provider/envprovider"
+	"go.uber.org/zap"
+)
+
+type sensitiveRotatingCfgSrcFactory struct {
+	*rotatingCfgSrcFactory
+}
+
+func (sensitiveRotatingCfgSrcFactory) Sensitive() bool {
+	return true
+}
+
+func TestDryRunResolve(t *testing.T) {
+	t.Setenv("DRY_RUN_HOST", "localhost")
+	configMap := confmap.NewFromStringMap(map[string]any{
+		"config_sources": map[string]any{"rotating": nil},
+		"exporters": map[string]any{
+			"otlp": map[string]any{
+				"endpoint": "${env:DRY_RUN_HOST}:4317",
+				"headers":  map[string]any{"token": "${rotating:token}"},
+			},
+		},
+	})
+	factories := Factories{"rotating": sensitiveRotatingCfgSrcFactory{&rotatingCfgSrcFactory{value: "s3cr3t"}}}
+	schemeProviders := map[string]confmap.Provider{"env": envprovider.New()}
+
+	resolved, err := DryRunResolve(context.Background(), configMap, zap.NewNop(), component.NewDefaultBuildInfo(), factories, schemeProviders)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"exporters": map[string]any{
+			"otlp": map[string]any{
+				"endpoint": "localhost:4317",
+				"headers":  map[string]any{"token": RedactedValue},
+			},
+		},
+	}, resolved.ToStringMap())
+
+	_, err = DryRunResolve(context.Background(), configMap, zap.NewNop(), component.NewDefaultBuildInfo(), Factories{}, schemeProviders)
+	assert.ErrorContains(t, err, "unknown config_sources type \"rotating\"")
+}
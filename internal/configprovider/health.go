@@ -0,0 +1,302 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// HealthHandlerPath is the path in which the HealthReporter http.Handler is expected to be registered.
+const HealthHandlerPath = "/debug/configsources/health"
+
+var (
+	_ SourceCircuitBreakerHook = (*HealthReporter)(nil)
+	_ http.Handler             = (*HealthReporter)(nil)
+)
+
+// SourceHealthHook is a Hook notified about the retrieves and the watches of each config source.
+type SourceHealthHook interface {
+	Hook
+	// OnSourceRetrieve is called after each retrieve of the config source with its result.
+	OnSourceRetrieve(name string, err error)
+	// OnSourceChange is called when a watch of the config source reports a change or an error.
+	OnSourceChange(name string, err error)
+}
+
+// SourceHealth holds the health of a config source.
+type SourceHealth struct {
+	LastSuccessfulRetrieve time.Time `json:"last_successful_retrieve"`
+	LastChange             time.Time `json:"last_change"`
+	LastError              string    `json:"last_error,omitempty"`
+	WatchError             string    `json:"watch_error,omitempty"`
+	CircuitBreaker         string    `json:"circuit_breaker,omitempty"`
+	ConsecutiveFailures    int       `json:"consecutive_failures"`
+}
+
+// Healthy reports if the configuration from the config source is up to date: its last
+// retrieve succeeded and its watches, if any, didn't fail.
+func (h SourceHealth) Healthy() bool {
+	return h.ConsecutiveFailures == 0 && h.WatchError == ""
+}
+
+// maxRecentErrors is the number of recent errors kept for each config source.
+const maxRecentErrors = 10
+
+// SourceError is an error of a retrieve, or of a watch, of a config source.
+type SourceError struct {
+	Time  time.Time `json:"time"`
+	Error string    `json:"error"`
+}
+
+// HealthReporter tracks the health of the config sources used to resolve the collector
+// configuration. It is served, in the same format as the health_check extension, with the
+// 503 status code if any config source is unhealthy, i.e. if the collector may be running
+// on stale configuration.
+type HealthReporter struct {
+	sources   map[string]SourceHealth
+	errors    map[string][]SourceError
+	listeners map[int]func(unhealthy []string)
+	now       func() time.Time
+	unhealthy []string
+	nextID    int
+	mutex     sync.RWMutex
+}
+
+// NewHealthReporter creates a new HealthReporter without any config source.
+func NewHealthReporter() *HealthReporter {
+	return &HealthReporter{
+		sources:   map[string]SourceHealth{},
+		errors:    map[string][]SourceError{},
+		listeners: map[int]func([]string){},
+		now:       time.Now,
+	}
+}
+
+func (*HealthReporter) OnNew() {}
+
+func (*HealthReporter) OnRetrieve(string, map[string]any) {}
+
+func (*HealthReporter) OnShutdown() {}
+
+func (r *HealthReporter) OnSourceRetrieve(name string, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	defer r.notifyLocked()
+	health := r.sources[name]
+	if err != nil {
+		health.ConsecutiveFailures++
+		health.LastError = err.Error()
+		r.recordErrorLocked(name, err)
+	} else {
+		health.ConsecutiveFailures = 0
+		health.LastError = ""
+		health.LastSuccessfulRetrieve = r.now()
+		// A successful retrieve starts new watches.
+		health.WatchError = ""
+	}
+	r.sources[name] = health
+}
+
+func (r *HealthReporter) OnSourceChange(name string, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	defer r.notifyLocked()
+	health := r.sources[name]
+	health.LastChange = r.now()
+	if err != nil {
+		health.WatchError = err.Error()
+		r.recordErrorLocked(name, err)
+	}
+	r.sources[name] = health
+}
+
+func (r *HealthReporter) OnSourceCircuitBreakerChange(name string, state CircuitBreakerState) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	defer r.notifyLocked()
+	health := r.sources[name]
+	health.CircuitBreaker = string(state)
+	r.sources[name] = health
+}
+
+// Subscribe registers fn to be called with the names of the unhealthy config sources, sorted, whenever
+// the config sources become unhealthy or recover, and right away with the current ones. The returned
+// function unsubscribes fn. The calls are serialized and fn must not call the HealthReporter.
+func (r *HealthReporter) Subscribe(fn func(unhealthy []string)) (unsubscribe func()) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	id := r.nextID
+	r.nextID++
+	r.listeners[id] = fn
+	fn(r.unhealthy)
+	return func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		delete(r.listeners, id)
+	}
+}
+
+// notifyLocked calls the listeners if the set of unhealthy config sources changed.
+func (r *HealthReporter) notifyLocked() {
+	var unhealthy []string
+	for name, health := range r.sources {
+		if !health.Healthy() {
+			unhealthy = append(unhealthy, name)
+		}
+	}
+	sort.Strings(unhealthy)
+	if equalStrings(unhealthy, r.unhealthy) {
+		return
+	}
+	r.unhealthy = unhealthy
+	for _, fn := range r.listeners {
+		fn(unhealthy)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// recordErrorLocked keeps err among the most recent errors of the config source.
+func (r *HealthReporter) recordErrorLocked(name string, err error) {
+	errs := append(r.errors[name], SourceError{Time: r.now(), Error: err.Error()})
+	if len(errs) > maxRecentErrors {
+		errs = errs[len(errs)-maxRecentErrors:]
+	}
+	r.errors[name] = errs
+}
+
+// RecentErrors returns a copy of the most recent errors of each config source, oldest first.
+func (r *HealthReporter) RecentErrors() map[string][]SourceError {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	errs := make(map[string][]SourceError, len(r.errors))
+	for name, sourceErrs := range r.errors {
+		errs[name] = append([]SourceError(nil), sourceErrs...)
+	}
+	return errs
+}
+
+// Sources returns a copy of the health of each config source.
+func (r *HealthReporter) Sources() map[string]SourceHealth {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	sources := make(map[string]SourceHealth, len(r.sources))
+	for name, health := range r.sources {
+		sources[name] = health
+	}
+	return sources
+}
+
+// Healthy reports if all the config sources are healthy.
+func (r *HealthReporter) Healthy() bool {
+	for _, health := range r.Sources() {
+		if !health.Healthy() {
+			return false
+		}
+	}
+	return true
+}
+
+// ServeHTTP writes the health of the config sources as JSON.
+func (r *HealthReporter) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		writer.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	sources := r.Sources()
+	response := struct {
+		Sources   map[string]SourceHealth `json:"sources"`
+		Status    string                  `json:"status"`
+		Unhealthy []string                `json:"unhealthy,omitempty"`
+	}{Sources: sources, Status: "Server available"}
+	for name, health := range sources {
+		if !health.Healthy() {
+			response.Unhealthy = append(response.Unhealthy, name)
+		}
+	}
+	sort.Strings(response.Unhealthy)
+
+	status := http.StatusOK
+	if len(response.Unhealthy) > 0 {
+		status = http.StatusServiceUnavailable
+		response.Status = "Server not available"
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	_ = json.NewEncoder(writer).Encode(response)
+}
+
+type healthHooksCtxKey struct{}
+
+// contextWithHealthHooks returns a copy of ctx carrying the hooks notified about the
+// health of the config sources.
+func contextWithHealthHooks(ctx context.Context, hooks []Hook) context.Context {
+	var healthHooks []SourceHealthHook
+	for _, h := range hooks {
+		if healthHook, ok := h.(SourceHealthHook); ok {
+			healthHooks = append(healthHooks, healthHook)
+		}
+	}
+	if len(healthHooks) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, healthHooksCtxKey{}, healthHooks)
+}
+
+func healthHooksFromContext(ctx context.Context) []SourceHealthHook {
+	healthHooks, _ := ctx.Value(healthHooksCtxKey{}).([]SourceHealthHook)
+	return healthHooks
+}
+
+// healthWatcher returns a watcher notifying the health hooks in ctx about the changes
+// reported by the config source before calling watcher.
+func healthWatcher(ctx context.Context, name string, watcher confmap.WatcherFunc) confmap.WatcherFunc {
+	healthHooks := healthHooksFromContext(ctx)
+	if len(healthHooks) == 0 || watcher == nil {
+		return watcher
+	}
+	return func(event *confmap.ChangeEvent) {
+		for _, h := range healthHooks {
+			h.OnSourceChange(name, event.Error)
+		}
+		watcher(event)
+	}
+}
+
+// reportRetrieve notifies the health hooks in ctx about the result of a retrieve of the config source.
+func reportRetrieve(ctx context.Context, name string, err error) {
+	for _, h := range healthHooksFromContext(ctx) {
+		h.OnSourceRetrieve(name, err)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+	"go.uber.org/zap"
+)
+
+// sourceHealthCheckEnvVar is the env var making the health check extensions, e.g. the health_check
+// one used by the k8s readiness probes, report the collector as not ready while any config source is
+// unhealthy, i.e. while the collector may be running on stale configuration.
+const sourceHealthCheckEnvVar = "SPLUNK_CONFIG_SOURCES_HEALTH_CHECK"
+
+// WithSourceHealth returns the extension factory creating the extensions of factory, reporting the
+// health of the config sources tracked by reporter to the ones watching the pipelines, if enabled by
+// the SPLUNK_CONFIG_SOURCES_HEALTH_CHECK env var. These are only notified to be ready once both the
+// pipelines are ready and the config sources are healthy, and not ready once either isn't.
+func WithSourceHealth(factory extension.Factory, reporter *HealthReporter) extension.Factory {
+	enabled, err := strconv.ParseBool(strings.ToLower(os.Getenv(sourceHealthCheckEnvVar)))
+	if err != nil || !enabled {
+		return factory
+	}
+	return &sourceHealthFactory{Factory: factory, reporter: reporter}
+}
+
+type sourceHealthFactory struct {
+	extension.Factory
+	reporter *HealthReporter
+}
+
+func (f *sourceHealthFactory) CreateExtension(ctx context.Context, set extension.CreateSettings, cfg component.Config) (extension.Extension, error) {
+	ext, err := f.Factory.CreateExtension(ctx, set, cfg)
+	if err != nil {
+		return nil, err
+	}
+	watcher, ok := ext.(extension.PipelineWatcher)
+	if !ok {
+		return ext, nil
+	}
+	return &sourceHealthExtension{
+		Extension: ext,
+		watcher:   watcher,
+		reporter:  f.reporter,
+		logger:    set.Logger,
+	}, nil
+}
+
+type sourceHealthExtension struct {
+	extension.Extension
+	watcher        extension.PipelineWatcher
+	reporter       *HealthReporter
+	logger         *zap.Logger
+	unsubscribe    func()
+	unhealthy      []string
+	pipelinesReady bool
+	ready          bool
+	mutex          sync.Mutex
+}
+
+var _ extension.PipelineWatcher = (*sourceHealthExtension)(nil)
+
+func (e *sourceHealthExtension) Start(ctx context.Context, host component.Host) error {
+	if err := e.Extension.Start(ctx, host); err != nil {
+		return err
+	}
+	e.unsubscribe = e.reporter.Subscribe(e.onSourceHealth)
+	return nil
+}
+
+func (e *sourceHealthExtension) Shutdown(ctx context.Context) error {
+	if e.unsubscribe != nil {
+		e.unsubscribe()
+	}
+	return e.Extension.Shutdown(ctx)
+}
+
+func (e *sourceHealthExtension) Ready() error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.pipelinesReady = true
+	return e.updateLocked()
+}
+
+func (e *sourceHealthExtension) NotReady() error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.pipelinesReady = false
+	return e.updateLocked()
+}
+
+func (e *sourceHealthExtension) onSourceHealth(unhealthy []string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	switch {
+	case len(unhealthy) > 0:
+		e.logger.Warn("Config sources are unhealthy, the configuration may be stale", zap.Strings("config_sources", unhealthy))
+	case len(e.unhealthy) > 0:
+		e.logger.Info("Config sources recovered", zap.Strings("config_sources", e.unhealthy))
+	}
+	e.unhealthy = unhealthy
+	if err := e.updateLocked(); err != nil {
+		e.logger.Warn("Failed to report the health of the config sources", zap.Error(err))
+	}
+}
+
+// updateLocked notifies the wrapped extension if its readiness changed.
+func (e *sourceHealthExtension) updateLocked() error {
+	ready := e.pipelinesReady && len(e.unhealthy) == 0
+	if ready == e.ready {
+		return nil
+	}
+	e.ready = ready
+	if ready {
+		return e.watcher.Ready()
+	}
+	return e.watcher.NotReady()
+}
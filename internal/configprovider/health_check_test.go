@@ -0,0 +1,116 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+)
+
+type readinessExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+	ready []bool
+}
+
+func (e *readinessExtension) Ready() error {
+	e.ready = append(e.ready, true)
+	return nil
+}
+
+func (e *readinessExtension) NotReady() error {
+	e.ready = append(e.ready, false)
+	return nil
+}
+
+func newReadinessFactory(ext extension.Extension) extension.Factory {
+	return extension.NewFactory(
+		"health_check",
+		func() component.Config { return &struct{}{} },
+		func(context.Context, extension.CreateSettings, component.Config) (extension.Extension, error) {
+			return ext, nil
+		},
+		component.StabilityLevelBeta,
+	)
+}
+
+func TestWithSourceHealthDisabled(t *testing.T) {
+	factory := newReadinessFactory(&readinessExtension{})
+	assert.Same(t, factory, WithSourceHealth(factory, NewHealthReporter()))
+
+	t.Setenv(sourceHealthCheckEnvVar, "false")
+	assert.Same(t, factory, WithSourceHealth(factory, NewHealthReporter()))
+}
+
+func TestWithSourceHealth(t *testing.T) {
+	t.Setenv(sourceHealthCheckEnvVar, "true")
+	reporter := NewHealthReporter()
+	inner := &readinessExtension{}
+	factory := WithSourceHealth(newReadinessFactory(inner), reporter)
+	assert.Equal(t, component.Type("health_check"), factory.Type())
+
+	ext, err := factory.CreateExtension(context.Background(), extensiontest.NewNopCreateSettings(), factory.CreateDefaultConfig())
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	watcher, ok := ext.(extension.PipelineWatcher)
+	require.True(t, ok)
+
+	// A config source failing before the pipelines are ready keeps the extension not ready.
+	reporter.OnSourceRetrieve("vault", errors.New("unavailable"))
+	require.NoError(t, watcher.Ready())
+	assert.Empty(t, inner.ready)
+
+	reporter.OnSourceRetrieve("vault", nil)
+	assert.Equal(t, []bool{true}, inner.ready)
+
+	reporter.OnSourceChange("vault", errors.New("watch failed"))
+	assert.Equal(t, []bool{true, false}, inner.ready)
+
+	// Other failures don't notify the extension again.
+	reporter.OnSourceRetrieve("include", errors.New("not found"))
+	assert.Equal(t, []bool{true, false}, inner.ready)
+
+	reporter.OnSourceRetrieve("vault", nil)
+	reporter.OnSourceRetrieve("include", nil)
+	assert.Equal(t, []bool{true, false, true}, inner.ready)
+
+	require.NoError(t, watcher.NotReady())
+	assert.Equal(t, []bool{true, false, true, false}, inner.ready)
+
+	require.NoError(t, ext.Shutdown(context.Background()))
+	reporter.OnSourceRetrieve("vault", errors.New("unavailable"))
+	assert.Equal(t, []bool{true, false, true, false}, inner.ready)
+}
+
+func TestWithSourceHealthWithoutPipelineWatcher(t *testing.T) {
+	t.Setenv(sourceHealthCheckEnvVar, "true")
+	inner := &struct {
+		component.StartFunc
+		component.ShutdownFunc
+	}{}
+	factory := WithSourceHealth(newReadinessFactory(inner), NewHealthReporter())
+	ext, err := factory.CreateExtension(context.Background(), extensiontest.NewNopCreateSettings(), factory.CreateDefaultConfig())
+	require.NoError(t, err)
+	assert.Same(t, inner, ext)
+}
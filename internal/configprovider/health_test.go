@@ -0,0 +1,142 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+func TestHealthReporter(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	reporter := NewHealthReporter()
+	reporter.now = func() time.Time { return now }
+
+	watchForUpdateCh := make(chan error, 1)
+	cfgSources := map[string]ConfigSource{
+		"tstcfgsrc": &testConfigSource{
+			ValueMap: map[string]valueEntry{
+				"test_selector": {
+					Value:            "test_value",
+					WatchForUpdateCh: watchForUpdateCh,
+				},
+			},
+		},
+		"tstcfgsrc/failing": &testConfigSource{ErrOnRetrieve: errors.New("unavailable")},
+	}
+
+	ctx := contextWithHealthHooks(context.Background(), []Hook{reporter})
+	watchCh := make(chan *confmap.ChangeEvent)
+	_, closeFunc, err := resolve(ctx, cfgSources, confmap.NewFromStringMap(map[string]any{
+		"var0": "${tstcfgsrc:test_selector}",
+	}), func(event *confmap.ChangeEvent) {
+		watchCh <- event
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]SourceHealth{
+		"tstcfgsrc": {LastSuccessfulRetrieve: now},
+	}, reporter.Sources())
+	assert.True(t, reporter.Healthy())
+
+	for i := 0; i < 2; i++ {
+		_, _, err = resolve(ctx, cfgSources, confmap.NewFromStringMap(map[string]any{
+			"var1": "${tstcfgsrc/failing:test_selector}",
+		}), nil)
+		require.Error(t, err)
+	}
+	assert.Equal(t, SourceHealth{ConsecutiveFailures: 2, LastError: "unavailable"}, reporter.Sources()["tstcfgsrc/failing"])
+	assert.False(t, reporter.Healthy())
+
+	watchForUpdateCh <- errors.New("watch failed")
+	ce := <-watchCh
+	assert.Error(t, ce.Error)
+	assert.Equal(t, SourceHealth{
+		LastSuccessfulRetrieve: now,
+		LastChange:             now,
+		WatchError:             "watch failed",
+	}, reporter.Sources()["tstcfgsrc"])
+	assert.NoError(t, callClose(closeFunc))
+
+	recorder := httptest.NewRecorder()
+	reporter.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, HealthHandlerPath, nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	var response map[string]any
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, "Server not available", response["status"])
+	assert.Equal(t, []any{"tstcfgsrc", "tstcfgsrc/failing"}, response["unhealthy"])
+
+	// Successful retrieves, e.g. after a configuration reload, make the config sources healthy again.
+	reporter.OnSourceRetrieve("tstcfgsrc", nil)
+	reporter.OnSourceRetrieve("tstcfgsrc/failing", nil)
+	recorder = httptest.NewRecorder()
+	reporter.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, HealthHandlerPath, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	reporter.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, HealthHandlerPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
+
+func TestHealthReporterRecentErrors(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	reporter := NewHealthReporter()
+	reporter.now = func() time.Time { return now }
+
+	reporter.OnSourceChange("tstcfgsrc", errors.New("watch failed"))
+	for i := 0; i < maxRecentErrors+2; i++ {
+		reporter.OnSourceRetrieve("tstcfgsrc", fmt.Errorf("retrieve %d failed", i))
+	}
+	reporter.OnSourceRetrieve("tstcfgsrc", nil)
+
+	errs := reporter.RecentErrors()["tstcfgsrc"]
+	require.Len(t, errs, maxRecentErrors)
+	assert.Equal(t, SourceError{Time: now, Error: "retrieve 2 failed"}, errs[0])
+	assert.Equal(t, SourceError{Time: now, Error: "retrieve 11 failed"}, errs[maxRecentErrors-1])
+	assert.Empty(t, reporter.RecentErrors()["tstcfgsrc/other"])
+}
+
+func TestHealthReporterSubscribe(t *testing.T) {
+	reporter := NewHealthReporter()
+	reporter.OnSourceRetrieve("tstcfgsrc/failing", errors.New("unavailable"))
+
+	var notified [][]string
+	unsubscribe := reporter.Subscribe(func(unhealthy []string) {
+		notified = append(notified, unhealthy)
+	})
+	reporter.OnSourceRetrieve("tstcfgsrc/failing", errors.New("unavailable"))
+	reporter.OnSourceChange("tstcfgsrc", errors.New("watch failed"))
+	reporter.OnSourceRetrieve("tstcfgsrc", nil)
+	reporter.OnSourceRetrieve("tstcfgsrc/failing", nil)
+	unsubscribe()
+	reporter.OnSourceRetrieve("tstcfgsrc", errors.New("unavailable"))
+
+	assert.Equal(t, [][]string{
+		{"tstcfgsrc/failing"},
+		{"tstcfgsrc", "tstcfgsrc/failing"},
+		{"tstcfgsrc/failing"},
+		nil,
+	}, notified)
+}
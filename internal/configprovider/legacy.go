@@ -0,0 +1,90 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/featuregate"
+)
+
+// DisableLegacyExpansionGateID is the id of the feature gate turning off the legacy, non-bracketed,
+// expansion of the config sources and env vars, e.g. "$vault:secret/token" or "$HOST". Only the
+// "${...}" references, of config sources, env vars, or confmap providers, are expanded, so the
+// configurations can be checked to be forward compatible with the upstream syntax. Enable it with
+// "--feature-gates=configsource.disableLegacyExpansion".
+const DisableLegacyExpansionGateID = "configsource.disableLegacyExpansion"
+
+type legacyExpansionCtxKey struct{}
+
+func init() {
+	featuregate.GetRegistry().MustRegisterID(
+		DisableLegacyExpansionGateID,
+		featuregate.StageAlpha,
+		featuregate.WithRegisterDescription("Only expands the \"${...}\" references in the configuration, "+
+			"keeping the legacy \"$source:selector\" and \"$ENV\" ones as they are."),
+	)
+}
+
+// contextWithoutLegacyExpansion returns a copy of ctx turning off the legacy expansion.
+func contextWithoutLegacyExpansion(ctx context.Context) context.Context {
+	return context.WithValue(ctx, legacyExpansionCtxKey{}, true)
+}
+
+func legacyExpansionDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(legacyExpansionCtxKey{}).(bool)
+	return disabled
+}
+
+// MigrateLegacyReferences rewrites the legacy, non-bracketed, config source reference in s, e.g.
+// "$vault:secret/token", to the bracketed form expanded the same way, e.g. "${vault:secret/token}".
+// isConfigSource tells the config sources from the env vars, which are kept as they are like the
+// bracketed references, the "$$" escapes, and the "$literal:" strings. It returns the rewritten
+// reference, in its legacy form, empty if there is none. A reference whose selector or parameters
+// contain a "}", other than closing a nested reference, can't be bracketed and fails.
+func MigrateLegacyReferences(s string, isConfigSource func(name string) bool) (migrated, reference string, err error) {
+	for j := 0; j+1 < len(s); j++ {
+		if s[j] != expandPrefixChar {
+			continue
+		}
+		switch s[j+1] {
+		case expandPrefixChar:
+			// Skip the escaped prefix.
+			j++
+			continue
+		case '{':
+			_, w := scanToClosingBracket(s[j+1:])
+			j += w
+			continue
+		}
+		content, w, cfgSrcName := getBareExpandableContent(s, j+1)
+		switch {
+		case cfgSrcName == literalMarker:
+			// The rest of the string is taken literally.
+			return s, "", nil
+		case cfgSrcName == "" || !isConfigSource(cfgSrcName):
+			j += w
+			continue
+		}
+		// The legacy reference takes the rest of the string.
+		if bracketed, consumed := scanToClosingBracket("{" + content + "}"); bracketed != content || consumed != len(content)+2 {
+			return s, "", fmt.Errorf("legacy reference %q can't be bracketed, its selector or parameters contain \"}\"", s[j:])
+		}
+		return s[:j] + "${" + content + "}", s[j:], nil
+	}
+	return s, "", nil
+}
@@ -0,0 +1,60 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateLegacyReferences(t *testing.T) {
+	isConfigSource := func(name string) bool {
+		return name == "vault" || name == "env/defaults"
+	}
+	tests := []struct {
+		value     string
+		migrated  string
+		reference string
+		err       string
+	}{
+		{value: "$vault:secret/token", migrated: "${vault:secret/token}", reference: "$vault:secret/token"},
+		{value: "Bearer $vault:secret/token", migrated: "Bearer ${vault:secret/token}", reference: "$vault:secret/token"},
+		{value: "$HOST $env/defaults:PORT", migrated: "$HOST ${env/defaults:PORT}", reference: "$env/defaults:PORT"},
+		{value: "$vault:secret/${env:APP}?path=$.token", migrated: "${vault:secret/${env:APP}?path=$.token}", reference: "$vault:secret/${env:APP}?path=$.token"},
+		{value: "${vault:secret/token} $vault:other", migrated: "${vault:secret/token} ${vault:other}", reference: "$vault:other"},
+		{value: "${vault:secret/token}", migrated: "${vault:secret/token}"},
+		{value: "$$vault:secret/token", migrated: "$$vault:secret/token"},
+		{value: "$HOST:4317", migrated: "$HOST:4317"},
+		{value: "$include:/etc/token", migrated: "$include:/etc/token"},
+		{value: "$literal: echo $vault:secret", migrated: "$literal: echo $vault:secret"},
+		{value: "exit $?", migrated: "exit $?"},
+		{value: "$vault:secret/{token}}", err: `legacy reference "$vault:secret/{token}}" can't be bracketed, its selector or parameters contain "}"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			migrated, reference, err := MigrateLegacyReferences(tt.value, isConfigSource)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.migrated, migrated)
+			assert.Equal(t, tt.reference, reference)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// sourceLimiters holds the limiters of the config sources so the limits also apply across the
+// resolutions of the configuration, e.g. the re-resolutions on reloads.
+type sourceLimiters struct {
+	limiters map[string]*sourceLimiter
+	mutex    sync.Mutex
+}
+
+func newSourceLimiters() *sourceLimiters {
+	return &sourceLimiters{limiters: map[string]*sourceLimiter{}}
+}
+
+// get returns the limiter of the config source, a new one if its settings changed.
+func (l *sourceLimiters) get(name string, settings LimitSettings) *sourceLimiter {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	limiter, ok := l.limiters[name]
+	if !ok || limiter.settings != settings {
+		limiter = newSourceLimiter(settings)
+		l.limiters[name] = limiter
+	}
+	return limiter
+}
+
+type sourceLimiter struct {
+	// next is the time at which the next retrieve is allowed by the rate limit.
+	next time.Time
+	// inFlight holds a token for each retrieve in progress, nil without limit.
+	inFlight chan struct{}
+	settings LimitSettings
+	interval time.Duration
+	mutex    sync.Mutex
+}
+
+func newSourceLimiter(settings LimitSettings) *sourceLimiter {
+	limiter := &sourceLimiter{settings: settings}
+	if settings.MaxInFlight > 0 {
+		limiter.inFlight = make(chan struct{}, settings.MaxInFlight)
+	}
+	if settings.RequestsPerSecond > 0 {
+		limiter.interval = time.Duration(float64(time.Second) / settings.RequestsPerSecond)
+	}
+	return limiter
+}
+
+// acquire waits until a retrieve is allowed by the limits, or ctx is done, and returns the func
+// to call once the retrieve completes.
+func (l *sourceLimiter) acquire(ctx context.Context) (func(), error) {
+	release := func() {}
+	if l.inFlight != nil {
+		select {
+		case l.inFlight <- struct{}{}:
+			release = func() { <-l.inFlight }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if l.interval == 0 {
+		return release, nil
+	}
+
+	l.mutex.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mutex.Unlock()
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
+
+type sourceLimitersCtxKey struct{}
+
+// contextWithSourceLimiters returns a copy of ctx carrying the limiters shared by the resolutions
+// of the configuration.
+func contextWithSourceLimiters(ctx context.Context, limiters *sourceLimiters) context.Context {
+	return context.WithValue(ctx, sourceLimitersCtxKey{}, limiters)
+}
+
+// withLimits wraps the config sources with limit settings so their retrieves wait for the limits.
+// If ctx doesn't carry the limiters the limits only apply during this resolution.
+func withLimits(ctx context.Context, configSources map[string]ConfigSource, settings map[string]Source) (map[string]ConfigSource, error) {
+	limiters, ok := ctx.Value(sourceLimitersCtxKey{}).(*sourceLimiters)
+	if !ok {
+		limiters = newSourceLimiters()
+	}
+	for name, cfgSrc := range configSources {
+		limitSettings, ok := settings[name].(interface{ LimitSettings() LimitSettings })
+		if !ok {
+			continue
+		}
+		limits := limitSettings.LimitSettings()
+		if err := limits.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid limits settings for config source %s: %w", name, err)
+		}
+		if limits.MaxInFlight == 0 && limits.RequestsPerSecond == 0 {
+			continue
+		}
+		configSources[name] = &limitingConfigSource{
+			ConfigSource: cfgSrc,
+			limiter:      limiters.get(name, limits),
+			name:         name,
+		}
+	}
+	return configSources, nil
+}
+
+// limitingConfigSource waits for the limits of the config source before each retrieve.
+type limitingConfigSource struct {
+	ConfigSource
+	limiter *sourceLimiter
+	name    string
+}
+
+func (l *limitingConfigSource) Retrieve(ctx context.Context, selector string, paramsConfigMap *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	release, err := l.limiter.acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("config source %s retrieve waiting for its limits: %w", l.name, err)
+	}
+	defer release()
+	return l.ConfigSource.Retrieve(ctx, selector, paramsConfigMap, watcher)
+}
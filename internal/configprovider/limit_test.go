@@ -0,0 +1,95 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+func TestLimitingConfigSource(t *testing.T) {
+	var mutex sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	var retrievedAt []time.Time
+	cfgSrc := &testConfigSource{
+		ValueMap: map[string]valueEntry{
+			"selector": {Value: "value"},
+		},
+		OnRetrieve: func(context.Context, string, *confmap.Conf) error {
+			mutex.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			retrievedAt = append(retrievedAt, time.Now())
+			mutex.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			mutex.Lock()
+			inFlight--
+			mutex.Unlock()
+			return nil
+		},
+	}
+	settings := &mockCfgSrcSettings{SourceSettings: NewSourceSettings(component.NewID("tstcfgsrc"))}
+	settings.Limits = LimitSettings{MaxInFlight: 2, RequestsPerSecond: 50}
+
+	limiters := newSourceLimiters()
+	ctx := contextWithSourceLimiters(context.Background(), limiters)
+	cfgSources, err := withLimits(ctx, map[string]ConfigSource{"tstcfgsrc": cfgSrc}, map[string]Source{"tstcfgsrc": settings})
+	require.NoError(t, err)
+	require.IsType(t, &limitingConfigSource{}, cfgSources["tstcfgsrc"])
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			retrieved, retrieveErr := cfgSources["tstcfgsrc"].Retrieve(ctx, "selector", nil, nil)
+			assert.NoError(t, retrieveErr)
+			assert.NoError(t, retrieved.Close(ctx))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 2, maxInFlight)
+	require.Len(t, retrievedAt, 6)
+	// The retrieves are spaced by the rate limit, allowing for the timer precision.
+	assert.GreaterOrEqual(t, retrievedAt[5].Sub(retrievedAt[0]), 90*time.Millisecond)
+
+	// The limiters are kept across the resolutions unless the settings change.
+	assert.Same(t, limiters.get("tstcfgsrc", settings.Limits), limiters.get("tstcfgsrc", settings.Limits))
+	assert.NotSame(t, limiters.get("tstcfgsrc", settings.Limits), limiters.get("tstcfgsrc", LimitSettings{MaxInFlight: 1}))
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	blocked := newSourceLimiter(LimitSettings{MaxInFlight: 1})
+	release, err := blocked.acquire(context.Background())
+	require.NoError(t, err)
+	_, err = blocked.acquire(canceled)
+	assert.ErrorIs(t, err, context.Canceled)
+	release()
+
+	settings.Limits = LimitSettings{RequestsPerSecond: -1}
+	_, err = withLimits(ctx, map[string]ConfigSource{"tstcfgsrc": cfgSrc}, map[string]Source{"tstcfgsrc": settings})
+	assert.ErrorContains(t, err, "can't be negative")
+}
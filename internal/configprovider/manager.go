@@ -16,17 +16,21 @@ package configprovider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/knadh/koanf/maps"
 	"github.com/spf13/cast"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/featuregate"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v2"
@@ -42,6 +46,12 @@ const (
 	// typeAndNameSeparator is the separator that is used between type and name in type/name
 	// composite keys.
 	typeAndNameSeparator = '/'
+	// defaultValueDelim separates a single-line config source invocation from the value used
+	// if the config source fails to retrieve it.
+	defaultValueDelim = "|-"
+	// literalMarker is the reserved config source name marking the rest of the string to be
+	// taken literally, without expanding env vars or config sources, e.g. "$literal: echo $1".
+	literalMarker = "literal"
 	// dollarDollarCompatEnvVar is a temporary env var to disable backward compatibility (true by default)
 	dollarDollarCompatEnvVar = "SPLUNK_DOUBLE_DOLLAR_CONFIG_SOURCE_COMPATIBLE"
 )
@@ -85,6 +95,9 @@ var ddBackwardCompatible = func() bool {
 // to retrieve the value.
 //
 // The <selector> is the mandatory parameter required when retrieving data from a config source.
+// It is always a string, e.g. "8080" for $file:8080, and the values of references nested in it, e.g.
+// ${file:${env:PORT}}, aren't parsed as YAML. The values of the parameters are parsed as YAML, so
+// ?enabled=true is a bool and ?port='8080' a string.
 //
 // Not all config sources need the optional parameters, they are used to provide extra control when
 // retrieving and preparing the data to be injected into the configuration.
@@ -124,6 +137,96 @@ var ddBackwardCompatible = func() bool {
 //	  # as a string if params doesn't specify that "binary" is true.
 //	  text_from_file: $file:/etc/text.txt
 //
+// Single-line invocations can provide a default value after "|-", used instead of failing if the selected
+// value doesn't exist, i.e. the config source returns an error matching ErrNotFound. Other failures, e.g.
+// authentication errors or timeouts, aren't hidden by the default value. Using it logs a warning and is
+// reported to the HealthReporter like a failed retrieve. The default value is taken
+// literally, until the closing bracket, and parsed as YAML like any other retrieved value. If it contains
+// "${...}" references these are only resolved when the default value is used, so invocations can be chained
+// to try other config sources in order, e.g. across environments with different backends. Example:
+//
+//	component:
+//	  # Retrieves the value of the environment variable LOGS_DIR or uses /var/log if it isn't set.
+//	  logs_dir: ${env:LOGS_DIR|-/var/log}
+//	  # Retrieves the token from vault, or from the TOKEN environment variable if the secret doesn't exist,
+//	  # or uses "dev-token" if neither does.
+//	  token: ${vault:secret/data/app?path=$.token|-${env:TOKEN|-dev-token}}
+//
+// The "fallback" config source names such a chain to reuse it, see the fallbackconfigsource package.
+//
+// Invocations with the "optional" parameter set to true resolve to nil, instead of failing, if the selected
+// value doesn't exist, like with a default value. The parameter is handled for all config sources and isn't passed to them,
+// see IsOptional. Example:
+//
+//	component:
+//	  # Retrieves the value of the environment variable LOGS_DIR or leaves the field unset.
+//	  logs_dir: ${env:LOGS_DIR?optional=true}
+//
+// The "transform" parameter, also handled for all config sources, applies a comma separated list of
+// transforms, in order, to the retrieved string: "base64decode", "base64encode", "trim", "trimnewline",
+// "lower", and "upper". Example:
+//
+//	component:
+//	  # Retrieves the base64 encoded token from the file and removes the trailing new line after decoding it.
+//	  token: ${file:/etc/token.b64?transform=base64decode,trimnewline}
+//
+// The "path" parameter, also handled for all config sources, extracts a single field from a structured
+// value, or from a string holding a JSON or YAML document, before any transform is applied. Like a missing
+// value, a missing field resolves to the default value or, if the invocation is optional, to nil. Example:
+//
+//	component:
+//	  # Retrieves the JSON secret and injects its "token" field.
+//	  token: ${file:/etc/secret.json?path=$.data.token}
+//
+// Invocations can also use the URI form of the source providers, see NewSourceProviders,
+// "${<name>://<selector>[?<params>][#<key>]}", the key is extracted like with the "path" parameter. Example:
+//
+//	component:
+//	  # Same as ${vault:secret/data/app?path=$['token']}.
+//	  token: ${vault://secret/data/app#token}
+//
+// The "splice" parameter, also handled for all config sources, splices the elements of the retrieved list
+// into the enclosing sequence instead of nesting the list as a single element. Example:
+//
+//	exporter:
+//	  # Retrieves the list of endpoints from the file and appends them after "localhost:4317".
+//	  endpoints:
+//	    - localhost:4317
+//	    - ${file:/etc/endpoints.yaml?splice=true}
+//
+// The "type" parameter, also handled for all config sources, converts the retrieved value, after the
+// transforms, to "int", "bool", "float", or "string", or parses it as "yaml", failing if that's not possible.
+// A "string" value isn't parsed as YAML even if it is the whole value of the key. Example:
+//
+//	component:
+//	  # Injects the port as an integer and the version, e.g. "1.10", as a string instead of a float.
+//	  port: ${file:/etc/port.txt?type=int&transform=trim}
+//	  version: ${file:/etc/version.txt?type=string&transform=trim}
+//
+// The "merge" parameter, also handled for all config sources, deep merges the retrieved map into the map
+// containing the invocation, overriding the values of the sibling keys, instead of injecting the map as the
+// value of the key. The key itself is removed. Example:
+//
+//	exporters:
+//	  otlp:
+//	    endpoint: localhost:4317
+//	    tls:
+//	      insecure: true
+//	    # Overrides the endpoint, and any other setting, defined in the file.
+//	    site_overrides: ${file:/etc/site/otlp.yaml?merge=true&optional=true}
+//
+// The "materialize=file" parameter, also handled for all config sources, writes the retrieved value to a
+// temporary file, only readable by its owner by default, and injects its path instead. The invocation is
+// materialized to the same file, rewritten in place, each time the configuration is resolved, so reloads
+// don't change its path. The file is shredded once no configuration uses it. Example:
+//
+//	receivers:
+//	  otlp:
+//	    protocols:
+//	      grpc:
+//	        tls:
+//	          key_file: ${env:OTLP_TLS_KEY?materialize=file}
+//
 // Bracketed single-line should be used when concatenating a suffix to the value retrieved by
 // the config source. Example:
 //
@@ -140,6 +243,26 @@ var ddBackwardCompatible = func() bool {
 //	  # until the first character different than '_' and non-alpha-numeric.
 //	  text_from_file: $file:$DATA_PATH/text.txt
 //
+// Bracketed invocations of config sources, and environment variables, can also be nested, at any depth, in
+// the selector or the parameters of a bracketed invocation. The inner references are resolved first and a
+// nested reference missing its closing bracket is an error. Example:
+//
+//	component:
+//	  # Retrieves the token from the vault path specified by the environment variable VAULT_PATH.
+//	  token: ${vault:${env:VAULT_PATH}/token}
+//	  # Passes the value retrieved from the file as the "role" parameter.
+//	  secret: ${vault:secret/data/app?role=${file:/etc/role.txt}}
+//
+// The bracketed references to a name not matching any config source, but the scheme of one of the confmap
+// providers given to NewConfigSourceConfigMapProvider, e.g. "env" or "file", are retrieved from the provider,
+// in the same pass, with the opaque value after expanding the nested references. The config sources take
+// precedence over the providers and the non-bracketed syntax is only used for config sources. Example:
+//
+//	component:
+//	  # Expands the environment variable HOST, even without an "env" config source, and retrieves the path
+//	  # from the "file" config source, or from the file provider if it isn't declared.
+//	  endpoint: http://${env:HOST}:4317${file:/etc/path.txt}
+//
 // Since environment variables and config sources both use the '$', with or without brackets, as a prefix
 // for their expansion it is necessary to have a way to distinguish between them. For the non-bracketed
 // syntax the code will peek at the first character other than alpha-numeric and '_' after the '$'. If
@@ -165,32 +288,126 @@ var ddBackwardCompatible = func() bool {
 // If the character following the '$' is in the set {'*', '#', '$', '@', '!', '?', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9'}
 // the code will consider it to be the name of an environment variable to expand, or config source if followed by ':'. Do not use any of these
 // characters as the first char on the name of a config source or an environment variable (even if allowed by the system) to avoid unexpected
-// results.
+// results. The single character shell variables, e.g. "$?" or "$1", not defined as environment variables are kept as they are.
+//
+// A literal '$' is escaped as "$$", e.g. for Windows paths or PromQL templates. The backward compatible "$$<cfgSrcName>:" and
+// "$${<cfgSrcName>:...}" invocations only apply to the config sources in use, so "$${VAR:-default}" in a shell snippet
+// resolves to "${VAR:-default}". Alternatively, "$literal:" takes the rest of the value literally, without any expansion, after
+// dropping the first space or new line following it. For example:
+//
+//	component:
+//	  path: C:\$$Recycle.Bin        # Resolves to "C:\$Recycle.Bin".
+//	  summary: "{{ $$labels.job }}" # Resolves to "{{ $labels.job }}".
+//	  script: |
+//	    $literal:
+//	    echo "${HOME:-/tmp}" $1
+//
+// The settings of a config source can reference other config sources, these are built first and the
+// references can't form a cycle. Example:
+//
+//	config_sources:
+//	  env:
+//	  include:
+//	  vault:
+//	    endpoint: ${env:VAULT_ADDR}
+//	    auth:
+//	      token: ${include:/etc/vault/token?transform=trimnewline}
+//
+// Every config source accepts the "cache" settings, see CacheSettings, to reuse the retrieved values
+// within a TTL and, optionally, after it if the config source fails to retrieve them again. A value
+// whose change is reported by the config source, or retrieved with different settings, isn't reused:
+//
+//	config_sources:
+//	  vault:
+//	    cache:
+//	      ttl: 5m
+//	      stale_if_error: 1h
+//
+// The "ttl" parameter, handled for all config sources, overrides the TTL for an invocation, even of a config
+// source without "cache" settings, and the "nocache" parameter bypasses the cache. Example:
+//
+//	component:
+//	  # The realm rarely changes while the database credentials are rotated on every retrieve.
+//	  realm: ${vault:secret/data/realm?ttl=24h}
+//	  password: ${vault:database/creds/app?nocache=true}
+//
+// and the "retrieve_timeout" and "retry" settings, see RetrySettings, to limit the time waiting for
+// each attempt to retrieve a value and to retry the failed attempts with exponential backoff:
+//
+//	config_sources:
+//	  vault:
+//	    retrieve_timeout: 10s
+//	    retry:
+//	      max_retries: 3
+//	      initial_interval: 1s
+//	      max_interval: 30s
+//	      multiplier: 2
+//
+// and the "circuit_breaker" settings, see CircuitBreakerSettings, to fail fast, or fall back to
+// the cached values, after repeated failures of the config source:
+//
+//	config_sources:
+//	  vault:
+//	    circuit_breaker:
+//	      failure_threshold: 5
+//	      open_duration: 1m
+//	    cache:
+//	      stale_if_error: 1h
+//
+// and the "limits" settings, see LimitSettings, so the retrieves of a large configuration don't overwhelm
+// the backend of the config source:
+//
+//	config_sources:
+//	  vault:
+//	    limits:
+//	      max_in_flight: 4
+//	      requests_per_second: 10
 //
 // For an overview about the internals of the Manager refer to the package README.md.
 func Resolve(ctx context.Context, configMap *confmap.Conf, logger *zap.Logger, buildInfo component.BuildInfo, factories Factories, watcher confmap.WatcherFunc) (map[string]any, confmap.CloseFunc, error) {
-	configSourcesSettings, err := Load(context.Background(), configMap, factories)
-	if err != nil {
-		return nil, nil, err
+	if strictResolution() {
+		ctx = contextWithStrict(ctx)
 	}
+	if featuregate.GetRegistry().IsEnabled(DisableLegacyExpansionGateID) {
+		ctx = contextWithoutLegacyExpansion(ctx)
+	}
+	ctx = contextWithLogger(ctx, logger)
 
 	params := CreateParams{
 		Logger:    logger,
 		BuildInfo: buildInfo,
 	}
-	cfgSources, err := Build(context.Background(), configSourcesSettings, params, factories)
+	cfgSources, configSourcesSettings, settingsCloseFunc, err := buildConfigSources(ctx, configMap, params, factories, watcher)
 	if err != nil {
+		if settingsCloseFunc != nil {
+			_ = settingsCloseFunc(ctx)
+		}
 		return nil, nil, err
 	}
+	cfgSources = withDedup(cfgSources)
 
-	return resolve(ctx, cfgSources, configMap, watcher)
+	recordResolution(ctx)
+	ctx = contextWithSensitiveSources(ctx, sensitiveSources(configSourcesSettings, factories))
+	res, closeFunc, err := resolve(ctx, cfgSources, configMap, watcher)
+	if err != nil {
+		if settingsCloseFunc != nil {
+			_ = settingsCloseFunc(ctx)
+		}
+		return nil, nil, err
+	}
+	return res, mergeCloseFuncs([]confmap.CloseFunc{settingsCloseFunc, closeFunc}), nil
 }
 
 func resolve(ctx context.Context, configSources map[string]ConfigSource, configMap *confmap.Conf, watcher confmap.WatcherFunc) (map[string]any, confmap.CloseFunc, error) {
-	res := map[string]any{}
-	allKeys := configMap.AllKeys()
+	ctx = contextWithResolver(ctx, configSources)
+	leaves := configLeaves(configMap.ToStringMap())
+	res := make(map[string]any, len(leaves))
 	var closeFuncs []confmap.CloseFunc
-	for _, k := range allKeys {
+	var merges []mergeEntry
+	var failedKeys []string
+	var errs []error
+	for _, leaf := range leaves {
+		k := leaf.key
 		if strings.HasPrefix(k, configSourcesKey) {
 			// Remove everything under the config_sources section. The `config_sources` section
 			// is read when loading the config sources used in the configuration, but it is not
@@ -198,20 +415,73 @@ func resolve(ctx context.Context, configSources map[string]ConfigSource, configM
 			continue
 		}
 
-		value, closeFunc, err := parseConfigValue(ctx, configSources, configMap.Get(k), watcher)
+		value, closeFunc, err := parseNodeValue(contextForKey(ctx, k), configSources, leaf.value, watcher)
 		if err != nil {
-			return nil, nil, err
+			if _, nested := keyFromContext(ctx); nested {
+				// The parameters of an invocation fail as a whole.
+				closeAll(ctx, closeFuncs)
+				return nil, nil, err
+			}
+			// Keep resolving the other keys to report all the failures at once.
+			failedKeys, errs = append(failedKeys, k), append(errs, err)
+			continue
 		}
-		res[k] = value
 		if closeFunc != nil {
 			closeFuncs = append(closeFuncs, closeFunc)
 		}
+		if m, ok := value.(mergedMap); ok {
+			merges = append(merges, mergeEntry{key: k, value: m})
+			continue
+		}
+		res[k] = unwrapValue(value)
+	}
+
+	if len(errs) > 0 {
+		closeAll(ctx, closeFuncs)
+		return nil, nil, keyErrors(ctx, failedKeys, errs)
 	}
 
+	res = mergeInto(res, merges, confmap.KeyDelimiter)
 	maps.IntfaceKeysToStrings(res)
 	return res, mergeCloseFuncs(closeFuncs), nil
 }
 
+// configLeaf is a leaf of the configuration with its flattened key.
+type configLeaf struct {
+	key   string
+	value any
+}
+
+// configLeaves returns the leaves of the configuration, with the keys and the order of
+// confmap.Conf.AllKeys. Unlike getting each key from the confmap.Conf, the leaves aren't copied,
+// the configuration is copied once by confmap.Conf.ToStringMap.
+func configLeaves(config map[string]any) []configLeaf {
+	var leaves []configLeaf
+	var collect func(prefix string, m map[string]any)
+	collect = func(prefix string, m map[string]any) {
+		for k, v := range m {
+			key := prefix + k
+			if child, ok := v.(map[string]any); ok && len(child) > 0 {
+				collect(key+confmap.KeyDelimiter, child)
+				continue
+			}
+			leaves = append(leaves, configLeaf{key: key, value: v})
+		}
+	}
+	collect("", config)
+	sort.Slice(leaves, func(i, j int) bool { return leaves[i].key < leaves[j].key })
+	return leaves
+}
+
+// parseNodeValue is like parseConfigValue but it keeps the spliced lists and merged maps
+// retrieved for string values, to be handled by the enclosing sequence or map.
+func parseNodeValue(ctx context.Context, configSources map[string]ConfigSource, value any, watcher confmap.WatcherFunc) (any, confmap.CloseFunc, error) {
+	if s, ok := value.(string); ok {
+		return parseStringValue(ctx, configSources, s, watcher)
+	}
+	return parseConfigValue(ctx, configSources, value, watcher)
+}
+
 // parseConfigValue takes the value of a "config node" and process it recursively. The processing consists
 // in transforming invocations of config sources and/or environment variables into literal data that can be
 // used directly from a `confmap.Conf` object.
@@ -220,7 +490,8 @@ func parseConfigValue(ctx context.Context, configSources map[string]ConfigSource
 	case string:
 		// Only if the value of the node is a string it can contain an env var or config source
 		// invocation that requires transformation.
-		return parseStringValue(ctx, configSources, v, watcher)
+		value, closeFunc, err := parseStringValue(ctx, configSources, v, watcher)
+		return unwrapValue(value), closeFunc, err
 	case []any:
 		// The value is of type []any when an array is used in the configuration, YAML example:
 		//
@@ -237,14 +508,18 @@ func parseConfigValue(ctx context.Context, configSources map[string]ConfigSource
 		nslice := make([]any, 0, len(v))
 		var closeFuncs []confmap.CloseFunc
 		for _, vint := range v {
-			value, closeFunc, err := parseConfigValue(ctx, configSources, vint, watcher)
+			value, closeFunc, err := parseNodeValue(ctx, configSources, vint, watcher)
 			if err != nil {
 				return nil, nil, err
 			}
 			if closeFunc != nil {
 				closeFuncs = append(closeFuncs, closeFunc)
 			}
-			nslice = append(nslice, value)
+			if elems, ok := value.(splicedList); ok {
+				nslice = append(nslice, elems...)
+				continue
+			}
+			nslice = append(nslice, unwrapValue(value))
 		}
 		return nslice, mergeCloseFuncs(closeFuncs), nil
 	case map[string]any:
@@ -253,15 +528,23 @@ func parseConfigValue(ctx context.Context, configSources map[string]ConfigSource
 		// the current case block.
 		nmap := make(map[any]any, len(v))
 		var closeFuncs []confmap.CloseFunc
+		var merges []mergeEntry
 		for k, vint := range v {
-			value, closeFunc, err := parseConfigValue(ctx, configSources, vint, watcher)
+			value, closeFunc, err := parseNodeValue(ctx, configSources, vint, watcher)
 			if err != nil {
 				return nil, nil, err
 			}
 			if closeFunc != nil {
 				closeFuncs = append(closeFuncs, closeFunc)
 			}
-			nmap[k] = value
+			if m, ok := value.(mergedMap); ok {
+				merges = append(merges, mergeEntry{key: k, value: m})
+				continue
+			}
+			nmap[k] = unwrapValue(value)
+		}
+		if len(merges) > 0 {
+			return mergeInto(cast.ToStringMap(nmap), merges, ""), mergeCloseFuncs(closeFuncs), nil
 		}
 		return nmap, mergeCloseFuncs(closeFuncs), nil
 	default:
@@ -273,6 +556,12 @@ func parseConfigValue(ctx context.Context, configSources map[string]ConfigSource
 // parseStringValue transforms environment variables and config sources, if any are present, on
 // the given string in the configuration into an object to be inserted into the resulting configuration.
 func parseStringValue(ctx context.Context, configSources map[string]ConfigSource, s string, watcher confmap.WatcherFunc) (any, confmap.CloseFunc, error) {
+	return expandStringValue(ctx, configSources, s, watcher, true)
+}
+
+// expandStringValue is parseStringValue, parsing the value retrieved as YAML if parseYAML is set and
+// it is the whole string. Selectors and uris aren't parsed, e.g. "on" isn't turned into a bool.
+func expandStringValue(ctx context.Context, configSources map[string]ConfigSource, s string, watcher confmap.WatcherFunc, parseYAML bool) (any, confmap.CloseFunc, error) {
 	var closeFuncs []confmap.CloseFunc
 
 	// Code based on os.Expand function. All delimiters that are checked against are
@@ -283,10 +572,12 @@ func parseStringValue(ctx context.Context, configSources map[string]ConfigSource
 	// i tracks the index in s from which a slice to be appended to buf should start.
 	// j tracks the char being currently checked and also the end of the slice to be appended to buf.
 	// w tracks the number of characters being consumed after a prefix identifying env vars or config sources.
+	legacy := !legacyExpansionDisabled(ctx)
 	i := 0
 	for j := 0; j < len(s); j++ {
-		// Skip chars until a candidate for expansion is found.
-		if s[j] == expandPrefixChar && j+1 < len(s) {
+		// Skip chars until a candidate for expansion is found. Without the legacy expansion only
+		// the bracketed references and the "$$" escape are candidates.
+		if s[j] == expandPrefixChar && j+1 < len(s) && (legacy || s[j+1] == '{' || s[j+1] == expandPrefixChar) {
 			if buf == nil {
 				// Assuming that the length of the string will double after expansion of env vars and config sources.
 				buf = make([]byte, 0, 2*len(s))
@@ -306,13 +597,15 @@ func parseStringValue(ctx context.Context, configSources map[string]ConfigSource
 
 				var expanded, sourceName string
 				var ww int
-				if ddBackwardCompatible && len(s[j+1:]) > 2 {
+				if ddBackwardCompatible && legacy && len(s[j+1:]) > 2 {
+					// Only the invocations of the config sources in use are kept for backward compatibility,
+					// so escaped strings like $${VAR:-default} in shell snippets stay as they are.
 					if s[j+2] == '{' {
-						if expanded, ww, sourceName = getBracketedExpandableContent(s, j+2); sourceName != "" {
+						if expanded, ww, sourceName = getBracketedExpandableContent(s, j+2); isConfigSource(configSources, sourceName) {
 							bwCompatibilityRequired = true
 						}
 					} else {
-						if expanded, ww, sourceName = getBareExpandableContent(s, j+2); sourceName != "" {
+						if expanded, ww, sourceName = getBareExpandableContent(s, j+2); isConfigSource(configSources, sourceName) {
 							if len(expanded) > (len(sourceName) + 1) {
 								if !strings.Contains(expanded[len(sourceName)+1:], "$") {
 									bwCompatibilityRequired = true
@@ -339,6 +632,10 @@ func parseStringValue(ctx context.Context, configSources map[string]ConfigSource
 
 			case s[j+1] == '{':
 				expandableContent, w, cfgSrcName = getBracketedExpandableContent(s, j+1)
+				if w == 1 && strings.Contains(s[j+2:], "${") {
+					err := fmt.Errorf("malformed nested reference at %q: missing the closing \"}\"", s[j:])
+					return nil, nil, newResolutionError(ctx, s[j:], cfgSrcName, err)
+				}
 
 			default:
 				expandableContent, w, cfgSrcName = getBareExpandableContent(s, j+1)
@@ -347,15 +644,39 @@ func parseStringValue(ctx context.Context, configSources map[string]ConfigSource
 
 			// At this point expandableContent contains a string to be expanded, evaluate and expand it.
 			switch {
+			case cfgSrcName == "" && isUndefinedShellSpecialVar(expandableContent):
+				// Keep shell snippets, e.g. "exit $?", as they are since these can't be env vars.
+				buf = append(buf, s[j:j+w+1]...)
+
+			case cfgSrcName == literalMarker && s[j+1] != '{':
+				// The rest of the string is taken literally.
+				literal := strings.TrimPrefix(expandableContent, literalMarker+string(configSourceNameDelimChar))
+				if literal != "" && (literal[0] == ' ' || literal[0] == '\n') {
+					literal = literal[1:]
+				}
+				return string(append(buf, literal...)), mergeCloseFuncs(closeFuncs), nil
+
 			case cfgSrcName == "":
 				// Not a config source, expand as os.ExpandEnv
+				if isStrict(ctx) {
+					if err := checkEnvVarReference(expandableContent, w, s[j:j+w+1]); err != nil {
+						return nil, nil, newResolutionError(ctx, s[j:j+w+1], "", err)
+					}
+				}
 				buf = osExpandEnv(buf, expandableContent, w)
 
 			default:
-				// A config source, retrieve and apply results.
-				retrieved, closeFunc, err := retrieveConfigSourceData(ctx, configSources, cfgSrcName, expandableContent, watcher)
+				// A config source, or the scheme of a confmap provider, retrieve and apply results.
+				var retrieved any
+				var closeFunc confmap.CloseFunc
+				var err error
+				if provider, ok := schemeProviderFromContext(ctx, cfgSrcName); ok && s[j+1] == '{' && !isConfigSource(configSources, cfgSrcName) {
+					retrieved, closeFunc, err = retrieveSchemeData(ctx, configSources, provider, cfgSrcName, expandableContent[len(cfgSrcName)+1:], watcher)
+				} else {
+					retrieved, closeFunc, err = retrieveConfigSourceData(ctx, configSources, cfgSrcName, expandableContent, watcher)
+				}
 				if err != nil {
-					return nil, nil, err
+					return nil, nil, newResolutionError(ctx, s[j:j+w+1], cfgSrcName, err)
 				}
 				if closeFunc != nil {
 					closeFuncs = append(closeFuncs, closeFunc)
@@ -366,6 +687,9 @@ func parseStringValue(ctx context.Context, configSources map[string]ConfigSource
 					// This is the only expandableContent on the string, config
 					// source is free to return any but parse it as YAML
 					// if it is a string or byte slice.
+					if !parseYAML {
+						return retrieved, mergeCloseFuncs(closeFuncs), nil
+					}
 					switch value := retrieved.(type) {
 					case []byte:
 						if err := yaml.Unmarshal(value, &retrieved); err != nil {
@@ -452,17 +776,26 @@ func retrieveConfigSourceData(ctx context.Context, configSources map[string]Conf
 		return nil, nil, newErrUnknownConfigSource(cfgSrcName)
 	}
 
+	cfgSrcInvocation, defaultValue, hasDefault := cutDefaultValue(cfgSrcInvocation)
+	if uriPrefix := cfgSrcName + string(configSourceNameDelimChar) + "//"; strings.HasPrefix(cfgSrcInvocation, uriPrefix) {
+		// The URI form of the invocation, "<name>://<selector>[?<params>][#<key>]", like the source providers.
+		invocation, err := invocationFromURI(cfgSrcInvocation[len(cfgSrcName)+1:])
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid uri for config source %q invocation %q: %w", cfgSrcName, cfgSrcInvocation, err)
+		}
+		cfgSrcInvocation = cfgSrcName + string(configSourceNameDelimChar) + invocation
+	}
 	cfgSrcName, selector, paramsConfigMap, err := parseCfgSrcInvocation(cfgSrcInvocation)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// Recursively expand the selector.
-	expandedSelector, closeFunc, err := parseStringValue(ctx, configSources, selector, watcher)
+	expandedSelector, closeFunc, err := expandStringValue(ctx, configSources, selector, watcher, false)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to process selector for config source %q selector %q: %w", cfgSrcName, selector, err)
 	}
-	if selector, ok = expandedSelector.(string); !ok {
+	if selector, ok = scalarString(unwrapValue(expandedSelector)); !ok {
 		return nil, nil, fmt.Errorf("processed selector must be a string instead got a %T %v", expandedSelector, expandedSelector)
 	}
 	if closeFunc != nil {
@@ -481,14 +814,145 @@ func retrieveConfigSourceData(ctx context.Context, configSources map[string]Conf
 		paramsConfigMap = confmap.NewFromStringMap(paramsConfigMapRet)
 	}
 
-	retrieved, err := cfgSrc.Retrieve(ctx, selector, paramsConfigMap, watcher)
+	paramsConfigMap, optional, err := cutOptionalParam(paramsConfigMap)
 	if err != nil {
+		return nil, nil, fmt.Errorf("invalid parameters for config source %q invocation %q: %w", cfgSrcName, cfgSrcInvocation, err)
+	}
+	paramsConfigMap, transformList, err := cutTransformParam(paramsConfigMap)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid parameters for config source %q invocation %q: %w", cfgSrcName, cfgSrcInvocation, err)
+	}
+	paramsConfigMap, path, err := cutPathParam(paramsConfigMap)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid parameters for config source %q invocation %q: %w", cfgSrcName, cfgSrcInvocation, err)
+	}
+	paramsConfigMap, splice, err := cutSpliceParam(paramsConfigMap)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid parameters for config source %q invocation %q: %w", cfgSrcName, cfgSrcInvocation, err)
+	}
+	paramsConfigMap, merge, err := cutMergeParam(paramsConfigMap)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid parameters for config source %q invocation %q: %w", cfgSrcName, cfgSrcInvocation, err)
+	}
+	paramsConfigMap, typeHint, err := cutTypeParam(paramsConfigMap)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid parameters for config source %q invocation %q: %w", cfgSrcName, cfgSrcInvocation, err)
+	}
+	paramsConfigMap, control, err := cutCacheParams(paramsConfigMap)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid parameters for config source %q invocation %q: %w", cfgSrcName, cfgSrcInvocation, err)
+	}
+	paramsConfigMap, materialize, err := cutMaterializeParam(paramsConfigMap)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid parameters for config source %q invocation %q: %w", cfgSrcName, cfgSrcInvocation, err)
+	}
+	if splice && merge {
+		return nil, nil, fmt.Errorf("invalid parameters for config source %q invocation %q: %s and %s can't be both set", cfgSrcName, cfgSrcInvocation, SpliceParam, MergeParam)
+	}
+	if materialize && (splice || merge) {
+		return nil, nil, fmt.Errorf("invalid parameters for config source %q invocation %q: %s can't be set with %s or %s", cfgSrcName, cfgSrcInvocation, MaterializeParam, SpliceParam, MergeParam)
+	}
+	retrieveCtx := contextWithCacheControl(ctx, control)
+	if optional {
+		retrieveCtx = ContextWithOptional(retrieveCtx)
+	}
+
+	// The value used instead of failing if the value can't be retrieved. The references in the
+	// default value, e.g. to chain other config sources, are only resolved if it is used.
+	fallback := func() (any, error) {
+		if !hasDefault || !strings.Contains(defaultValue, "${") {
+			if hasDefault {
+				return defaultValue, nil
+			}
+			return nil, nil
+		}
+		value, closeFunc, err := expandStringValue(ctx, configSources, defaultValue, watcher, false)
+		if err != nil {
+			return nil, err
+		}
+		if closeFunc != nil {
+			closeFuncs = append(closeFuncs, closeFunc)
+		}
+		return value, nil
+	}
+
+	recordProvenance(ctx, cfgSrcName, selector)
+
+	var val any
+	start := time.Now()
+	retrieved, err := cfgSrc.Retrieve(retrieveCtx, selector, paramsConfigMap, healthWatcher(ctx, cfgSrcName, watcher))
+	recordRetrieve(ctx, cfgSrcName, time.Since(start), err)
+	switch {
+	case err != nil && (hasDefault || optional) && isNotFound(err):
+		// Only a missing value falls back to the default value, or nil, other failures, e.g.
+		// authentication errors or timeouts, are still reported as failures of the config source.
+		msg := "Config source value not found, using nil"
+		if hasDefault {
+			msg = "Config source value not found, using the default value"
+		}
+		loggerFromContext(ctx).Warn(msg,
+			zap.String("config_source", cfgSrcName), zap.String("selector", selector), zap.Error(err))
+		reportRetrieve(ctx, cfgSrcName, err)
+		if val, err = fallback(); err != nil {
+			return nil, mergeCloseFuncs(closeFuncs), err
+		}
+	case err != nil:
+		reportRetrieve(ctx, cfgSrcName, err)
 		return nil, nil, fmt.Errorf("config source %q failed to retrieve value: %w", cfgSrcName, err)
+	default:
+		reportRetrieve(ctx, cfgSrcName, nil)
+		if watcher != nil {
+			closeFuncs = append(closeFuncs, trackWatcher(cfgSrcName, retrieved.Close))
+		} else {
+			closeFuncs = append(closeFuncs, retrieved.Close)
+		}
+		if val, err = retrieved.AsRaw(); err != nil {
+			return nil, mergeCloseFuncs(closeFuncs), err
+		}
+		if val, err = extractPath(val, path); err != nil {
+			if !errors.Is(err, errPathNotFound) || !(hasDefault || optional) {
+				err = fmt.Errorf("config source %q invocation %q: %w", cfgSrcName, cfgSrcInvocation, err)
+				return nil, mergeCloseFuncs(closeFuncs), err
+			}
+			if val, err = fallback(); err != nil {
+				return nil, mergeCloseFuncs(closeFuncs), err
+			}
+		} else if val, err = applyTransforms(val, transformList); err != nil {
+			err = fmt.Errorf("config source %q invocation %q: %w", cfgSrcName, cfgSrcInvocation, err)
+			return nil, mergeCloseFuncs(closeFuncs), err
+		}
+	}
+
+	if val, err = applyTypeHint(val, typeHint); err != nil {
+		err = fmt.Errorf("config source %q invocation %q: %w", cfgSrcName, cfgSrcInvocation, err)
+		return nil, mergeCloseFuncs(closeFuncs), err
 	}
 
-	closeFuncs = append(closeFuncs, retrieved.Close)
-	val, err := retrieved.AsRaw()
-	return val, mergeCloseFuncs(closeFuncs), err
+	if materialize {
+		var closeFunc confmap.CloseFunc
+		key, keyErr := invocationKey(ctx, cfgSrcName, selector, paramsConfigMap)
+		if keyErr != nil {
+			err = fmt.Errorf("config source %q invocation %q: %w", cfgSrcName, cfgSrcInvocation, keyErr)
+			return nil, mergeCloseFuncs(closeFuncs), err
+		}
+		if val, closeFunc, err = materializeValue(ctx, key, val); err != nil {
+			err = fmt.Errorf("config source %q invocation %q: %w", cfgSrcName, cfgSrcInvocation, err)
+			return nil, mergeCloseFuncs(closeFuncs), err
+		}
+		if closeFunc != nil {
+			closeFuncs = append(closeFuncs, closeFunc)
+		}
+		return val, mergeCloseFuncs(closeFuncs), nil
+	}
+
+	if merge {
+		if val, err = mergeValue(val); err != nil {
+			err = fmt.Errorf("config source %q invocation %q: %w", cfgSrcName, cfgSrcInvocation, err)
+			return nil, mergeCloseFuncs(closeFuncs), err
+		}
+		return val, mergeCloseFuncs(closeFuncs), nil
+	}
+	return spliceValue(val, splice), mergeCloseFuncs(closeFuncs), nil
 }
 
 func newErrUnknownConfigSource(cfgSrcName string) error {
@@ -497,6 +961,42 @@ func newErrUnknownConfigSource(cfgSrcName string) error {
 	}
 }
 
+// cutDefaultValue separates the default value from a single-line config source invocation.
+// Multi-line invocations don't support default values, their parameters can contain any YAML.
+func cutDefaultValue(s string) (cfgSrcInvocation, defaultValue string, found bool) {
+	if strings.Contains(s, "\n") {
+		return s, "", false
+	}
+	delimIndex := indexTopLevel(s, defaultValueDelim)
+	if delimIndex < 0 {
+		return s, "", false
+	}
+	return s[:delimIndex], s[delimIndex+len(defaultValueDelim):], true
+}
+
+// scalarString returns the string form of scalar values, e.g. the selectors retrieved as ints or
+// bools, and false for values without one, e.g. lists, maps, or nil.
+func scalarString(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case int:
+		return strconv.Itoa(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	default:
+		return "", false
+	}
+}
+
 // parseCfgSrcInvocation parses the original string in the configuration that has a config source
 // retrieve operation and return its "logical components": the config source name, the selector, and
 // a confmap.Conf to be used in this invocation of the config source. See Test_parseCfgSrcInvocation
@@ -530,7 +1030,10 @@ func parseCfgSrcInvocation(s string) (cfgSrcName, selector string, paramsConfigM
 	default:
 		// Single line, and parameters as URL query.
 		const selectorDelim string = "?"
-		parts = strings.SplitN(parts[1], selectorDelim, 2)
+		parts = []string{parts[1]}
+		if delimIndex := indexTopLevel(parts[0], selectorDelim); delimIndex >= 0 {
+			parts = []string{parts[0][:delimIndex], parts[0][delimIndex+len(selectorDelim):]}
+		}
 		selector = strings.Trim(parts[0], " ")
 
 		if len(parts) == 2 {
@@ -547,7 +1050,7 @@ func parseCfgSrcInvocation(s string) (cfgSrcName, selector string, paramsConfigM
 }
 
 func parseParamsAsURLQuery(s string) (*confmap.Conf, error) {
-	values, err := url.ParseQuery(s)
+	values, err := parseQuery(s)
 	if err != nil {
 		return nil, err
 	}
@@ -580,6 +1083,58 @@ func parseParamsAsURLQuery(s string) (*confmap.Conf, error) {
 	return confmap.NewFromStringMap(params), err
 }
 
+// parseQuery is like url.ParseQuery but it keeps the references nested in the parameters,
+// e.g. p0=${env:VALUE}, as they are, so they are resolved before the config source is called.
+func parseQuery(s string) (url.Values, error) {
+	if !strings.Contains(s, "${") {
+		return url.ParseQuery(s)
+	}
+
+	values := url.Values{}
+	for _, param := range splitTopLevel(s, "&") {
+		if param == "" {
+			continue
+		}
+		key, value := param, ""
+		if i := indexTopLevel(param, "="); i >= 0 {
+			key, value = param[:i], param[i+1:]
+		}
+		key, err := queryUnescapeTopLevel(key)
+		if err != nil {
+			return nil, err
+		}
+		if value, err = queryUnescapeTopLevel(value); err != nil {
+			return nil, err
+		}
+		values[key] = append(values[key], value)
+	}
+	return values, nil
+}
+
+// queryUnescapeTopLevel unescapes s as url.QueryUnescape except the nested "${...}" references.
+func queryUnescapeTopLevel(s string) (string, error) {
+	var sb strings.Builder
+	for s != "" {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			start = len(s)
+		}
+		unescaped, err := url.QueryUnescape(s[:start])
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(unescaped)
+		if start == len(s) {
+			break
+		}
+		_, consumed := scanToClosingBracket(s[start+1:])
+		end := start + 1 + consumed
+		sb.WriteString(s[start:end])
+		s = s[end:]
+	}
+	return sb.String(), nil
+}
+
 // osExpandEnv replicate the internal behavior of os.ExpandEnv when handling env
 // vars updating the buffer accordingly.
 func osExpandEnv(buf []byte, name string, w int) []byte {
@@ -603,8 +1158,17 @@ func osExpandEnv(buf []byte, name string, w int) []byte {
 // variables with the "${<env_var>}" syntax. It returns the expression between brackets
 // and the number of characters consumed from the original string.
 func scanToClosingBracket(s string) (string, int) {
+	// Track the nested references, e.g. ${vault:${env:VAULT_PATH}/token}, so the content
+	// goes until the bracket closing the outer one.
+	depth := 0
 	for i := 1; i < len(s); i++ {
-		if s[i] == '}' {
+		switch {
+		case s[i] == expandPrefixChar && i+1 < len(s) && s[i+1] == '{':
+			depth++
+			i++
+		case s[i] == '}' && depth > 0:
+			depth--
+		case s[i] == '}':
 			if i == 1 {
 				return "", 2 // Bad syntax; eat "${}"
 			}
@@ -614,6 +1178,34 @@ func scanToClosingBracket(s string) (string, int) {
 	return "", 1 // Bad syntax; eat "${"
 }
 
+// indexTopLevel returns the index of the first instance of sep in s that isn't nested in a
+// "${...}" reference, or -1 if there is none.
+func indexTopLevel(s, sep string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == expandPrefixChar && i+1 < len(s) && s[i+1] == '{':
+			depth++
+			i++
+		case s[i] == '}' && depth > 0:
+			depth--
+		case depth == 0 && strings.HasPrefix(s[i:], sep):
+			return i
+		}
+	}
+	return -1
+}
+
+// splitTopLevel splits s around the instances of sep that aren't nested in a "${...}" reference.
+func splitTopLevel(s, sep string) []string {
+	var parts []string
+	for i := indexTopLevel(s, sep); i >= 0; i = indexTopLevel(s, sep) {
+		parts = append(parts, s[:i])
+		s = s[i+len(sep):]
+	}
+	return append(parts, s)
+}
+
 // getTokenName consumes characters until it has the name of either an environment
 // variable or config source. It returns the name of the config source or environment
 // variable and the number of characters consumed from the original string.
@@ -666,11 +1258,34 @@ func isShellSpecialVar(c uint8) bool {
 	return false
 }
 
+// isUndefinedShellSpecialVar reports if the name is a shell special variable, e.g. "?" or "1",
+// not defined as an env var.
+func isUndefinedShellSpecialVar(name string) bool {
+	if len(name) != 1 || name == string(expandPrefixChar) || !isShellSpecialVar(name[0]) {
+		return false
+	}
+	_, ok := os.LookupEnv(name)
+	return !ok
+}
+
+// isConfigSource reports if the name is one of the config sources in use.
+func isConfigSource(configSources map[string]ConfigSource, name string) bool {
+	_, ok := configSources[name]
+	return name != "" && ok
+}
+
 // isAlphaNum reports whether the byte is an ASCII letter, number, or underscore
 func isAlphaNum(c uint8) bool {
 	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
 }
 
+// closeAll calls the close funcs of a resolution failing after some values were retrieved.
+func closeAll(ctx context.Context, closeFuncs []confmap.CloseFunc) {
+	if closeFunc := mergeCloseFuncs(closeFuncs); closeFunc != nil {
+		_ = closeFunc(ctx)
+	}
+}
+
 func mergeCloseFuncs(closeFuncs []confmap.CloseFunc) confmap.CloseFunc {
 	if len(closeFuncs) == 0 {
 		return nil
@@ -0,0 +1,338 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/multierr"
+	"gopkg.in/yaml.v2"
+)
+
+// MaterializeParam is the parameter, handled by the config provider for every config source, that
+// writes the retrieved value to a temporary file, only readable by its owner, and injects the path
+// of the file instead, e.g. for TLS settings only accepting files. The only supported value is
+// "file". The same invocation is materialized to the same file, rewritten in place, so reloads don't
+// change the configuration. The file is shredded once no configuration uses it, e.g. on shutdown.
+const MaterializeParam = "materialize"
+
+const materializeFile = "file"
+
+const (
+	// materializeDirEnvVar is the env var with the directory of the materialized files.
+	materializeDirEnvVar = "SPLUNK_CONFIG_MATERIALIZE_DIR"
+	// materializeModeEnvVar is the env var with the octal permissions of the materialized files.
+	materializeModeEnvVar = "SPLUNK_CONFIG_MATERIALIZE_MODE"
+	// materializeOwnerEnvVar is the env var with the "user[:group]" owning the materialized files.
+	materializeOwnerEnvVar = "SPLUNK_CONFIG_MATERIALIZE_OWNER"
+
+	defaultMaterializeMode os.FileMode = 0600
+)
+
+// cutMaterializeParam removes the materialize parameter from the parameters of an invocation and
+// returns whether the value must be written to a file.
+func cutMaterializeParam(paramsConfigMap *confmap.Conf) (*confmap.Conf, bool, error) {
+	paramsConfigMap, value, found := cutParam(paramsConfigMap, MaterializeParam)
+	if !found {
+		return paramsConfigMap, false, nil
+	}
+	if value != materializeFile {
+		return nil, false, fmt.Errorf("invalid %s parameter %v, must be %q", MaterializeParam, value, materializeFile)
+	}
+	return paramsConfigMap, true, nil
+}
+
+// materializeSalt makes the names of the materialized files unpredictable by other processes.
+var materializeSalt = func() []byte {
+	salt := make([]byte, 16)
+	_, _ = rand.Read(salt)
+	return salt
+}()
+
+// materializedFiles counts the configurations using each materialized file. The configuration
+// resolved again, e.g. to reload it, materializes its values to the same files, they are only
+// shredded once all the configurations using them are closed.
+var materializedFiles = struct {
+	refs map[string]int
+	sync.Mutex
+}{refs: map[string]int{}}
+
+// materializedFileName returns the name of the file of the invocation identified by key, stable
+// during the lifetime of the collector.
+func materializedFileName(key string) string {
+	sum := sha256.Sum256(append(append([]byte(nil), materializeSalt...), key...))
+	return "otelcol-materialized-" + hex.EncodeToString(sum[:16])
+}
+
+// materializeValue writes the value retrieved by the invocation identified by key to a file, with
+// the materialize settings of ctx, and returns its path, and the close func shredding the file once
+// no other configuration uses it. Strings and byte slices are written as they are, the other
+// scalars as their string form, and lists and maps as YAML. A nil value, e.g. from an optional
+// invocation, isn't materialized.
+func materializeValue(ctx context.Context, key string, value any) (any, confmap.CloseFunc, error) {
+	if value == nil {
+		return nil, nil, nil
+	}
+
+	var content []byte
+	if s, ok := scalarString(unwrapValue(value)); ok {
+		content = []byte(s)
+	} else {
+		var err error
+		if content, err = yaml.Marshal(unwrapValue(value)); err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal the value to materialize: %w", err)
+		}
+	}
+
+	settings := materializeSettingsFromContext(ctx)
+	dir := settings.dir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	path := filepath.Join(dir, materializedFileName(key))
+
+	materializedFiles.Lock()
+	defer materializedFiles.Unlock()
+	inUse := materializedFiles.refs[path] > 0
+	var err error
+	if inUse {
+		err = rewriteMaterializedFile(path, content, settings)
+	} else {
+		err = createMaterializedFile(path, content, settings)
+	}
+	if err != nil {
+		if !inUse {
+			_ = shredFile(path)
+		}
+		return nil, nil, fmt.Errorf("failed to write the file to materialize the value: %w", err)
+	}
+	materializedFiles.refs[path]++
+
+	var once sync.Once
+	closeFunc := func(context.Context) error {
+		var closeErr error
+		once.Do(func() {
+			materializedFiles.Lock()
+			defer materializedFiles.Unlock()
+			if materializedFiles.refs[path]--; materializedFiles.refs[path] > 0 {
+				return
+			}
+			delete(materializedFiles.refs, path)
+			closeErr = shredFile(path)
+		})
+		return closeErr
+	}
+	return typedString(path), closeFunc, nil
+}
+
+// createMaterializedFile creates the file, only readable by its owner until the settings are
+// applied, replacing any file left behind, e.g. by a crash.
+func createMaterializedFile(path string, content []byte, settings materializeSettings) error {
+	if err := shredFile(path); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, defaultMaterializeMode)
+	if err != nil {
+		return err
+	}
+	if settings.mode != defaultMaterializeMode {
+		err = file.Chmod(settings.mode)
+	}
+	if err == nil && (settings.uid != -1 || settings.gid != -1) {
+		err = file.Chown(settings.uid, settings.gid)
+	}
+	if err == nil {
+		_, err = file.Write(content)
+	}
+	return multierr.Append(err, file.Close())
+}
+
+// rewriteMaterializedFile rewrites the file in use by other configurations if the value changed,
+// the previous value is overwritten with zeros first.
+func rewriteMaterializedFile(path string, content []byte, settings materializeSettings) error {
+	current, err := os.ReadFile(path)
+	if err == nil && bytes.Equal(current, content) {
+		return nil
+	}
+	if err != nil {
+		return createMaterializedFile(path, content, settings)
+	}
+	// The mode of the file can prevent its owner from writing it.
+	if err = os.Chmod(path, defaultMaterializeMode); err != nil {
+		return err
+	}
+	if err = overwriteFile(path, int64(len(current))); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(content)
+	if err = multierr.Append(err, file.Close()); err != nil {
+		return err
+	}
+	return os.Chmod(path, settings.mode)
+}
+
+// shredFile overwrites the content of the file with zeros before removing it, so the value isn't
+// left on the disk.
+func shredFile(path string) error {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err == nil {
+		// The mode of the file can prevent its owner from writing it.
+		_ = os.Chmod(path, defaultMaterializeMode)
+		err = overwriteFile(path, info.Size())
+	}
+	if removeErr := os.Remove(path); removeErr != nil && !os.IsNotExist(removeErr) {
+		err = multierr.Append(err, removeErr)
+	}
+	return err
+}
+
+func overwriteFile(path string, size int64) error {
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	zeros := make([]byte, 4096)
+	for written := int64(0); written < size && err == nil; {
+		n := int64(len(zeros))
+		if size-written < n {
+			n = size - written
+		}
+		_, err = file.Write(zeros[:n])
+		written += n
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	return multierr.Append(err, file.Close())
+}
+
+type materializeSettingsCtxKey struct{}
+
+// materializeSettings are the settings of the files the values are materialized to.
+type materializeSettings struct {
+	// dir is the directory of the files, the default directory for temporary files if empty.
+	dir  string
+	mode os.FileMode
+	// uid and gid own the files, -1 keeps the ones of the collector.
+	uid, gid int
+}
+
+// newMaterializeSettings returns the materialize settings of the directory, the mode, and the
+// "user[:group]" owner, names or ids, of the files, the env vars by default.
+func newMaterializeSettings(dir string, mode os.FileMode, owner string) (materializeSettings, error) {
+	settings := materializeSettings{dir: dir, mode: mode, uid: -1, gid: -1}
+	if settings.dir == "" {
+		settings.dir = os.Getenv(materializeDirEnvVar)
+	}
+	if settings.mode == 0 {
+		if env := os.Getenv(materializeModeEnvVar); env != "" {
+			parsed, err := strconv.ParseUint(env, 8, 32)
+			if err != nil {
+				return settings, fmt.Errorf("invalid %s %q, must be octal permissions, e.g. 0400", materializeModeEnvVar, env)
+			}
+			settings.mode = os.FileMode(parsed)
+		} else {
+			settings.mode = defaultMaterializeMode
+		}
+	}
+	if settings.mode&^os.ModePerm != 0 || settings.mode&0400 == 0 {
+		return settings, fmt.Errorf("invalid materialize mode %v, must be permissions readable by the owner", settings.mode)
+	}
+	if owner == "" {
+		owner = os.Getenv(materializeOwnerEnvVar)
+	}
+	if owner != "" {
+		var err error
+		if settings.uid, settings.gid, err = lookupOwner(owner); err != nil {
+			return settings, fmt.Errorf("invalid materialize owner %q: %w", owner, err)
+		}
+	}
+	return settings, nil
+}
+
+// lookupOwner returns the ids of the "user[:group]" owner, the gid is -1 if the group isn't set.
+func lookupOwner(owner string) (uid, gid int, err error) {
+	if runtime.GOOS == "windows" {
+		return -1, -1, errors.New("the owner of the files can't be set on Windows")
+	}
+	userName, groupName, hasGroup := strings.Cut(owner, ":")
+	if uid, err = lookupID(userName, func(name string) (string, error) {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return "", err
+		}
+		return u.Uid, nil
+	}); err != nil {
+		return -1, -1, err
+	}
+	gid = -1
+	if hasGroup {
+		if gid, err = lookupID(groupName, func(name string) (string, error) {
+			g, err := user.LookupGroup(name)
+			if err != nil {
+				return "", err
+			}
+			return g.Gid, nil
+		}); err != nil {
+			return -1, -1, err
+		}
+	}
+	return uid, gid, nil
+}
+
+func lookupID(name string, lookup func(string) (string, error)) (int, error) {
+	if id, err := strconv.Atoi(name); err == nil {
+		return id, nil
+	}
+	id, err := lookup(name)
+	if err != nil {
+		return -1, err
+	}
+	return strconv.Atoi(id)
+}
+
+func contextWithMaterializeSettings(ctx context.Context, settings materializeSettings) context.Context {
+	return context.WithValue(ctx, materializeSettingsCtxKey{}, settings)
+}
+
+func materializeSettingsFromContext(ctx context.Context) materializeSettings {
+	settings, ok := ctx.Value(materializeSettingsCtxKey{}).(materializeSettings)
+	if !ok {
+		return materializeSettings{mode: defaultMaterializeMode, uid: -1, gid: -1}
+	}
+	return settings
+}
@@ -0,0 +1,141 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMaterializeSettings(t *testing.T) {
+	settings, err := newMaterializeSettings("", 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, materializeSettings{mode: 0600, uid: -1, gid: -1}, settings)
+
+	dir := t.TempDir()
+	t.Setenv(materializeDirEnvVar, dir)
+	t.Setenv(materializeModeEnvVar, "0440")
+	settings, err = newMaterializeSettings("", 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, materializeSettings{dir: dir, mode: 0440, uid: -1, gid: -1}, settings)
+
+	// The options take precedence over the env vars.
+	settings, err = newMaterializeSettings("/run/secrets", 0400, "")
+	require.NoError(t, err)
+	assert.Equal(t, materializeSettings{dir: "/run/secrets", mode: 0400, uid: -1, gid: -1}, settings)
+
+	t.Setenv(materializeModeEnvVar, "rw")
+	_, err = newMaterializeSettings("", 0, "")
+	assert.EqualError(t, err, `invalid SPLUNK_CONFIG_MATERIALIZE_MODE "rw", must be octal permissions, e.g. 0400`)
+	_, err = newMaterializeSettings("", 0200, "")
+	assert.EqualError(t, err, "invalid materialize mode --w-------, must be permissions readable by the owner")
+
+	if runtime.GOOS == "windows" {
+		_, err = newMaterializeSettings("", 0600, "0")
+		assert.Error(t, err)
+		return
+	}
+	uid, gid := os.Getuid(), os.Getgid()
+	settings, err = newMaterializeSettings("", 0600, strconv.Itoa(uid)+":"+strconv.Itoa(gid))
+	require.NoError(t, err)
+	assert.Equal(t, uid, settings.uid)
+	assert.Equal(t, gid, settings.gid)
+	settings, err = newMaterializeSettings("", 0600, strconv.Itoa(uid))
+	require.NoError(t, err)
+	assert.Equal(t, uid, settings.uid)
+	assert.Equal(t, -1, settings.gid)
+	_, err = newMaterializeSettings("", 0600, "missing-materialize-user")
+	assert.ErrorContains(t, err, `invalid materialize owner "missing-materialize-user"`)
+}
+
+func TestMaterializeValueSettings(t *testing.T) {
+	dir := t.TempDir()
+	ctx := contextWithMaterializeSettings(context.Background(), materializeSettings{dir: dir, mode: 0400, uid: -1, gid: -1})
+	value, closeFunc, err := materializeValue(ctx, "tstcfgsrc\x00key", "secret")
+	require.NoError(t, err)
+	path := string(value.(typedString))
+	assert.Equal(t, dir, filepath.Dir(path))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(content))
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0400), info.Mode().Perm())
+	}
+
+	// The read-only file is shredded on close.
+	require.NoError(t, closeFunc(context.Background()))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+	require.NoError(t, closeFunc(context.Background()))
+}
+
+func TestMaterializeValueSameInvocation(t *testing.T) {
+	ctx := contextWithMaterializeSettings(context.Background(), materializeSettings{dir: t.TempDir(), mode: 0400, uid: -1, gid: -1})
+	value, closeFirst, err := materializeValue(ctx, "tstcfgsrc\x00key", "secret")
+	require.NoError(t, err)
+	path := string(value.(typedString))
+
+	// The same invocation is materialized to the same file, rewritten in place.
+	value, closeSecond, err := materializeValue(ctx, "tstcfgsrc\x00key", "rotated")
+	require.NoError(t, err)
+	assert.Equal(t, path, string(value.(typedString)))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "rotated", string(content))
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0400), info.Mode().Perm())
+	}
+
+	value, closeOther, err := materializeValue(ctx, "tstcfgsrc\x00other", "secret")
+	require.NoError(t, err)
+	assert.NotEqual(t, path, string(value.(typedString)))
+	require.NoError(t, closeOther(context.Background()))
+
+	// The file is shredded once it isn't used anymore.
+	require.NoError(t, closeFirst(context.Background()))
+	require.NoError(t, closeFirst(context.Background()))
+	_, err = os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, closeSecond(context.Background()))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestShredFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(path, bytes.Repeat([]byte("s"), 10000), 0600))
+	require.NoError(t, overwriteFile(path, 10000))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, make([]byte, 10000), content)
+
+	require.NoError(t, shredFile(path))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+	assert.NoError(t, shredFile(path))
+}
@@ -0,0 +1,154 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/knadh/koanf/maps"
+	"github.com/spf13/cast"
+	"go.opentelemetry.io/collector/confmap"
+	"gopkg.in/yaml.v2"
+)
+
+// MergeParam is the parameter, handled by the config provider for every config source, that
+// deep merges the retrieved map into the map containing the invocation, overriding the values
+// of its sibling keys, instead of injecting the map as the value of the key.
+const MergeParam = "merge"
+
+// mergedMap is a retrieved map to be deep merged into the enclosing map.
+type mergedMap map[string]any
+
+// cutMergeParam removes the merge parameter from the parameters of an invocation and returns
+// its value.
+func cutMergeParam(paramsConfigMap *confmap.Conf) (*confmap.Conf, bool, error) {
+	paramsConfigMap, value, found := cutParam(paramsConfigMap, MergeParam)
+	if !found {
+		return paramsConfigMap, false, nil
+	}
+	merge, ok := value.(bool)
+	if !ok {
+		return nil, false, fmt.Errorf("invalid %s parameter %v, must be true or false", MergeParam, value)
+	}
+	return paramsConfigMap, merge, nil
+}
+
+// mergeValue returns the retrieved value as a map to be merged. Strings and byte slices are
+// parsed as YAML first and a nil value, e.g. from an optional invocation, merges no keys.
+// Values other than maps can't be merged.
+func mergeValue(value any) (mergedMap, error) {
+	switch v := value.(type) {
+	case string:
+		if err := yaml.Unmarshal([]byte(v), &value); err != nil {
+			return nil, fmt.Errorf("failed to parse the value to merge: %w", err)
+		}
+	case []byte:
+		if err := yaml.Unmarshal(v, &value); err != nil {
+			return nil, fmt.Errorf("failed to parse the value to merge: %w", err)
+		}
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return mergedMap{}, nil
+	case map[string]any:
+		maps.IntfaceKeysToStrings(v)
+		return mergedMap(v), nil
+	case map[any]any:
+		m := cast.ToStringMap(v)
+		maps.IntfaceKeysToStrings(m)
+		return mergedMap(m), nil
+	default:
+		return nil, fmt.Errorf("only maps can be merged, got a %T", value)
+	}
+}
+
+// mergeEntry is a map retrieved for the key of a configuration to be merged into its parent.
+type mergeEntry struct {
+	value mergedMap
+	key   string
+}
+
+// mergeInto deep merges the retrieved maps into the given map, in the order of their keys,
+// and removes the keys holding the invocations. The keys of the map are paths separated by
+// the delimiter, like the ones of a confmap.Conf.
+func mergeInto(m map[string]any, entries []mergeEntry, delim string) map[string]any {
+	if len(entries) == 0 {
+		return m
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	for _, entry := range entries {
+		delete(m, entry.key)
+	}
+	nested := m
+	if delim != "" {
+		nested = maps.Unflatten(m, delim)
+	}
+	for _, entry := range entries {
+		parent := nested
+		if delim != "" {
+			path := strings.Split(entry.key, delim)
+			for _, name := range path[:len(path)-1] {
+				child, ok := toStringMap(parent[name])
+				if !ok {
+					child = map[string]any{}
+				}
+				parent[name] = child
+				parent = child
+			}
+		}
+		deepMerge(parent, entry.value)
+	}
+	return nested
+}
+
+// deepMerge merges src into dst, the values of src override the ones of dst unless both are maps.
+func deepMerge(dst, src map[string]any) {
+	for k, v := range src {
+		if srcMap, ok := toStringMap(v); ok {
+			if dstMap, ok := toStringMap(dst[k]); ok {
+				deepMerge(dstMap, srcMap)
+				dst[k] = dstMap
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+// toStringMap returns a copy of the value as a map with string keys, so merging into it
+// doesn't modify the maps retrieved by the config sources.
+func toStringMap(value any) (map[string]any, bool) {
+	var m map[string]any
+	switch v := value.(type) {
+	case map[string]any:
+		m = v
+	case mergedMap:
+		m = v
+	case map[any]any:
+		return cast.ToStringMap(v), true
+	default:
+		return nil, false
+	}
+	copied := make(map[string]any, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied, true
+}
@@ -0,0 +1,55 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/pkg/configsource"
+)
+
+// ErrNotFound is matched by the errors of config sources for values that don't exist, see
+// configsource.ErrNotFound.
+var ErrNotFound = configsource.ErrNotFound
+
+// NotFoundError returns an error with the same message as err that is matched by ErrNotFound.
+func NotFoundError(err error) error {
+	return configsource.NotFoundError(err)
+}
+
+// isNotFound reports if the retrieve failed because the value doesn't exist, including the
+// field selected by the path parameter. Only then the default value, or nil for optional
+// invocations, is used.
+func isNotFound(err error) bool {
+	return configsource.IsNotFound(err) || errors.Is(err, errPathNotFound)
+}
+
+type loggerCtxKey struct{}
+
+// contextWithLogger returns a copy of ctx carrying the logger used while resolving.
+func contextWithLogger(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerCtxKey{}, logger)
+}
+
+func loggerFromContext(ctx context.Context) *zap.Logger {
+	if logger, ok := ctx.Value(loggerCtxKey{}).(*zap.Logger); ok && logger != nil {
+		return logger
+	}
+	return zap.NewNop()
+}
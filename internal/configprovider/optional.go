@@ -0,0 +1,70 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/signalfx/splunk-otel-collector/pkg/configsource"
+)
+
+// OptionalParam is the parameter, handled by the config provider for every config source,
+// that makes an invocation resolve to nil instead of failing if the value can't be retrieved.
+const OptionalParam = configsource.OptionalParam
+
+// ContextWithOptional returns a copy of ctx marking the retrieve as optional.
+func ContextWithOptional(ctx context.Context) context.Context {
+	return configsource.ContextWithOptional(ctx)
+}
+
+// IsOptional reports if the retrieve using ctx was invoked with the optional parameter, see
+// configsource.IsOptional.
+func IsOptional(ctx context.Context) bool {
+	return configsource.IsOptional(ctx)
+}
+
+// cutOptionalParam removes the optional parameter from the parameters of an invocation and
+// returns its value.
+func cutOptionalParam(paramsConfigMap *confmap.Conf) (*confmap.Conf, bool, error) {
+	paramsConfigMap, value, found := cutParam(paramsConfigMap, OptionalParam)
+	if !found {
+		return paramsConfigMap, false, nil
+	}
+	optional, ok := value.(bool)
+	if !ok {
+		return nil, false, fmt.Errorf("invalid %s parameter %v, must be true or false", OptionalParam, value)
+	}
+	return paramsConfigMap, optional, nil
+}
+
+// cutParam removes the parameter, handled by the config provider, from the parameters of an
+// invocation and returns its value. The parameters are nil if no other parameter remains.
+func cutParam(paramsConfigMap *confmap.Conf, name string) (*confmap.Conf, any, bool) {
+	if paramsConfigMap == nil || !paramsConfigMap.IsSet(name) {
+		return paramsConfigMap, nil, false
+	}
+
+	params := paramsConfigMap.ToStringMap()
+	value := params[name]
+	delete(params, name)
+	if len(params) == 0 {
+		return nil, value, true
+	}
+	return confmap.NewFromStringMap(params), value, true
+}
@@ -30,7 +30,8 @@ const (
 )
 
 // Load reads the configuration for ConfigSource objects from the given parser and returns a map
-// from the full name of config sources to the respective ConfigSettings.
+// from the full name of config sources to the respective ConfigSettings. Only environment variables
+// are expanded in the settings, the references to other config sources are resolved by Resolve.
 func Load(ctx context.Context, v *confmap.Conf, factories Factories) (map[string]Source, error) {
 	processedParser, err := processParser(ctx, v)
 	if err != nil {
@@ -0,0 +1,160 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+	"gopkg.in/yaml.v2"
+)
+
+// PathParam is the parameter, handled by the config provider for every config source, with
+// the path of the field extracted from the retrieved structured value, e.g. "$.data.token".
+const PathParam = "path"
+
+// errPathNotFound is returned when the retrieved value doesn't have the extracted field.
+var errPathNotFound = errors.New("path not found")
+
+// pathSegment is either the key of a map or the index of a list.
+type pathSegment struct {
+	key   string
+	index int
+	isKey bool
+}
+
+// cutPathParam removes the path parameter from the parameters of an invocation and returns
+// the parsed path, nil if the parameter isn't set.
+func cutPathParam(paramsConfigMap *confmap.Conf) (*confmap.Conf, []pathSegment, error) {
+	paramsConfigMap, value, found := cutParam(paramsConfigMap, PathParam)
+	if !found {
+		return paramsConfigMap, nil, nil
+	}
+	path, ok := value.(string)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid %s parameter %v, must be a string", PathParam, value)
+	}
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid %s parameter %q: %w", PathParam, path, err)
+	}
+	return paramsConfigMap, segments, nil
+}
+
+// parsePath parses a JSONPath-like path: an optional leading "$" followed by keys separated
+// by ".", and bracketed list indexes or quoted keys, e.g. "$.items[0]['app.kubernetes.io/name']".
+func parsePath(path string) ([]pathSegment, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(path), "$")
+	var segments []pathSegment
+	for s != "" {
+		switch s[0] {
+		case '.':
+			s = s[1:]
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, errors.New("empty key")
+			}
+			segments = append(segments, pathSegment{key: s[:end], isKey: true})
+			s = s[end:]
+		case '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, errors.New("missing closing bracket")
+			}
+			content := s[1:end]
+			s = s[end+1:]
+			if len(content) >= 2 && (content[0] == '\'' || content[0] == '"') && content[len(content)-1] == content[0] {
+				segments = append(segments, pathSegment{key: content[1 : len(content)-1], isKey: true})
+				continue
+			}
+			index, err := strconv.Atoi(content)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid index %q", content)
+			}
+			segments = append(segments, pathSegment{index: index})
+		default:
+			if len(segments) > 0 {
+				return nil, fmt.Errorf("unexpected %q", s)
+			}
+			// Allow the path to start without the "$." prefix.
+			s = "." + s
+		}
+	}
+	if len(segments) == 0 {
+		return nil, errors.New("no field selected")
+	}
+	return segments, nil
+}
+
+// extractPath returns the field selected by the path in the retrieved value. String and byte
+// slice values are parsed as YAML, or JSON, documents before extracting the field.
+func extractPath(value any, segments []pathSegment) (any, error) {
+	if len(segments) == 0 {
+		return value, nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		if err := yaml.Unmarshal([]byte(v), &value); err != nil {
+			return nil, fmt.Errorf("failed to parse the value to extract the %s: %w", PathParam, err)
+		}
+	case []byte:
+		if err := yaml.Unmarshal(v, &value); err != nil {
+			return nil, fmt.Errorf("failed to parse the value to extract the %s: %w", PathParam, err)
+		}
+	}
+
+	for i, segment := range segments {
+		var ok bool
+		switch v := value.(type) {
+		case map[string]any:
+			if segment.isKey {
+				value, ok = v[segment.key]
+			}
+		case map[any]any:
+			if segment.isKey {
+				value, ok = v[segment.key]
+			}
+		case []any:
+			if !segment.isKey && segment.index < len(v) {
+				value, ok = v[segment.index], true
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", errPathNotFound, formatPath(segments[:i+1]))
+		}
+	}
+	return value, nil
+}
+
+func formatPath(segments []pathSegment) string {
+	var sb strings.Builder
+	sb.WriteString("$")
+	for _, segment := range segments {
+		if segment.isKey {
+			sb.WriteString("." + segment.key)
+		} else {
+			sb.WriteString("[" + strconv.Itoa(segment.index) + "]")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,103 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// ProvenanceHook is a Hook notified about the provenance of the keys of each resolved configuration.
+type ProvenanceHook interface {
+	Hook
+	// OnProvenance is called after the configuration retrieved for the scheme is resolved with
+	// the provenance of each of its keys.
+	OnProvenance(scheme string, provenance map[string]KeyProvenance)
+}
+
+// KeyProvenance records where the value of a resolved configuration key came from.
+type KeyProvenance struct {
+	// URI is the URI from which the key was retrieved, e.g. the configuration file.
+	URI string `json:"uri,omitempty" yaml:"uri,omitempty"`
+	// ConfigSources are the config source invocations, in the "<config source>:<selector>"
+	// form, that supplied the value of the key.
+	ConfigSources []string `json:"config_sources,omitempty" yaml:"config_sources,omitempty"`
+	// Redacted is set if any of the config sources is sensitive, see SensitiveFactory.
+	Redacted bool `json:"redacted,omitempty" yaml:"redacted,omitempty"`
+}
+
+// provenanceRecorder collects the config source invocations resolved for each key.
+type provenanceRecorder struct {
+	sources  map[string][]string
+	redacted map[string]bool
+	mutex    sync.Mutex
+}
+
+func newProvenanceRecorder() *provenanceRecorder {
+	return &provenanceRecorder{
+		sources:  map[string][]string{},
+		redacted: map[string]bool{},
+	}
+}
+
+func (p *provenanceRecorder) add(key, invocation string, sensitive bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if sensitive {
+		p.redacted[key] = true
+	}
+	for _, existing := range p.sources[key] {
+		if existing == invocation {
+			return
+		}
+	}
+	p.sources[key] = append(p.sources[key], invocation)
+}
+
+// provenance returns the provenance of the given keys of the configuration.
+func (p *provenanceRecorder) provenance(keys []string, origins map[string]string) map[string]KeyProvenance {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	provenance := make(map[string]KeyProvenance, len(keys))
+	for _, k := range keys {
+		if strings.HasPrefix(k, configSourcesKey) {
+			continue
+		}
+		provenance[k] = KeyProvenance{URI: origins[k], ConfigSources: p.sources[k], Redacted: p.redacted[k]}
+	}
+	return provenance
+}
+
+type provenanceCtxKey struct{}
+
+// contextWithProvenance returns a copy of ctx carrying the recorder of the provenance of the keys.
+func contextWithProvenance(ctx context.Context, recorder *provenanceRecorder) context.Context {
+	return context.WithValue(ctx, provenanceCtxKey{}, recorder)
+}
+
+// recordProvenance records the invocation of the config source for the key being resolved. The
+// invocations resolved for the selector or the parameters of a config source are recorded for
+// the key enclosing them.
+func recordProvenance(ctx context.Context, cfgSrcName, selector string) {
+	recorder, ok := ctx.Value(provenanceCtxKey{}).(*provenanceRecorder)
+	if !ok {
+		return
+	}
+	if key, ok := keyFromContext(ctx); ok {
+		recorder.add(key, cfgSrcName+string(configSourceNameDelimChar)+selector, isSensitiveSource(ctx, cfgSrcName))
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+func TestProvenance(t *testing.T) {
+	cfgSources := map[string]ConfigSource{
+		"tstcfgsrc": &testConfigSource{
+			ValueMap: map[string]valueEntry{
+				"endpoint": {Value: "localhost:4317"},
+				"token":    {Value: "token_value"},
+				"key":      {Value: "token"},
+			},
+		},
+	}
+	configMap := confmap.NewFromStringMap(map[string]any{
+		"config_sources": map[string]any{
+			"tstcfgsrc": nil,
+		},
+		"exporter": map[string]any{
+			"endpoint": "${tstcfgsrc:endpoint}",
+			// The invocations in the selector are recorded for the key enclosing them.
+			"token":   "${tstcfgsrc:$tstcfgsrc:key}",
+			"literal": "value",
+		},
+	})
+
+	recorder := newProvenanceRecorder()
+	res, closeFunc, err := resolve(contextWithProvenance(context.Background(), recorder), cfgSources, configMap, nil)
+	require.NoError(t, err)
+	require.NoError(t, callClose(closeFunc))
+	assert.Equal(t, map[string]any{
+		"exporter": map[string]any{
+			"endpoint": "localhost:4317",
+			"token":    "token_value",
+			"literal":  "value",
+		},
+	}, confmap.NewFromStringMap(res).ToStringMap())
+
+	origins := map[string]string{
+		"exporter::endpoint": "file:config.yaml",
+		"exporter::token":    "file:config.yaml",
+		"exporter::literal":  "env:CONFIG_YAML",
+	}
+	assert.Equal(t, map[string]KeyProvenance{
+		"exporter::endpoint": {URI: "file:config.yaml", ConfigSources: []string{"tstcfgsrc:endpoint"}},
+		"exporter::token":    {URI: "file:config.yaml", ConfigSources: []string{"tstcfgsrc:key", "tstcfgsrc:token"}},
+		"exporter::literal":  {URI: "env:CONFIG_YAML"},
+	}, recorder.provenance(configMap.AllKeys(), origins))
+}
@@ -0,0 +1,108 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/spf13/cast"
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/signalfx/splunk-otel-collector/pkg/configsource"
+)
+
+// RedactedValue replaces the redacted values of the configuration.
+const RedactedValue = "<redacted>"
+
+// SensitiveFactory is implemented by the factories of config sources retrieving secrets. The
+// values resolved from their config sources are redacted, see Redact, unless the "redact"
+// setting of the config source is set to false.
+type SensitiveFactory = configsource.SensitiveFactory
+
+type sensitiveSourcesCtxKey struct{}
+
+// sensitiveSources returns the names of the config sources whose values must be redacted.
+func sensitiveSources(settings map[string]Source, factories Factories) map[string]bool {
+	sensitive := map[string]bool{}
+	for name, cfgSrcSettings := range settings {
+		if redactSettings, ok := cfgSrcSettings.(interface{ RedactSettings() *bool }); ok && redactSettings.RedactSettings() != nil {
+			sensitive[name] = *redactSettings.RedactSettings()
+			continue
+		}
+		if factory, ok := factories[cfgSrcSettings.ID().Type()].(SensitiveFactory); ok {
+			sensitive[name] = factory.Sensitive()
+		}
+	}
+	return sensitive
+}
+
+// contextWithSensitiveSources returns a copy of ctx carrying the names of the config sources
+// whose values must be redacted.
+func contextWithSensitiveSources(ctx context.Context, sensitive map[string]bool) context.Context {
+	return context.WithValue(ctx, sensitiveSourcesCtxKey{}, sensitive)
+}
+
+func isSensitiveSource(ctx context.Context, name string) bool {
+	sensitive, _ := ctx.Value(sensitiveSourcesCtxKey{}).(map[string]bool)
+	return sensitive[name]
+}
+
+// Redact returns a copy of the configuration with the values of the keys resolved from
+// sensitive config sources, according to their provenance, replaced by RedactedValue. It
+// must only be used for the configuration logged or reported, the components always get
+// the values unredacted.
+func Redact(config map[string]any, provenance map[string]KeyProvenance) map[string]any {
+	var redactedKeys []string
+	for key, p := range provenance {
+		if p.Redacted {
+			redactedKeys = append(redactedKeys, key)
+		}
+	}
+	if len(redactedKeys) == 0 {
+		return config
+	}
+	return redactMap(config, "", redactedKeys)
+}
+
+func redactMap(config map[string]any, prefix string, redactedKeys []string) map[string]any {
+	redacted := make(map[string]any, len(config))
+	for k, v := range config {
+		key := prefix + k
+		if isRedactedKey(key, redactedKeys) {
+			redacted[k] = RedactedValue
+			continue
+		}
+		switch value := v.(type) {
+		case map[string]any:
+			v = redactMap(value, key+confmap.KeyDelimiter, redactedKeys)
+		case map[any]any:
+			v = redactMap(cast.ToStringMap(value), key+confmap.KeyDelimiter, redactedKeys)
+		}
+		redacted[k] = v
+	}
+	return redacted
+}
+
+// isRedactedKey reports if the key, or the section enclosing it, was redacted.
+func isRedactedKey(key string, redactedKeys []string) bool {
+	for _, redactedKey := range redactedKeys {
+		if key == redactedKey || strings.HasPrefix(key, redactedKey+confmap.KeyDelimiter) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+type sensitiveCfgSrcFactory struct {
+	mockCfgSrcFactory
+}
+
+func (*sensitiveCfgSrcFactory) Sensitive() bool {
+	return true
+}
+
+func TestSensitiveSources(t *testing.T) {
+	doNotRedact := false
+	notRedacted := &mockCfgSrcSettings{SourceSettings: NewSourceSettings(component.NewIDWithName("tstcfgsrc", "not_redacted"))}
+	notRedacted.Redact = &doNotRedact
+	settings := map[string]Source{
+		"tstcfgsrc":              &mockCfgSrcSettings{SourceSettings: NewSourceSettings(component.NewID("tstcfgsrc"))},
+		"tstcfgsrc/not_redacted": notRedacted,
+	}
+
+	assert.Equal(t, map[string]bool{}, sensitiveSources(settings, Factories{"tstcfgsrc": &mockCfgSrcFactory{}}))
+	assert.Equal(t, map[string]bool{"tstcfgsrc": true, "tstcfgsrc/not_redacted": false},
+		sensitiveSources(settings, Factories{"tstcfgsrc": &sensitiveCfgSrcFactory{}}))
+}
+
+func TestRedact(t *testing.T) {
+	cfgSources := map[string]ConfigSource{
+		"secrets": &testConfigSource{
+			ValueMap: map[string]valueEntry{
+				"token":   {Value: "secret_token"},
+				"headers": {Value: map[string]any{"authorization": "Bearer secret"}},
+			},
+		},
+		"plain": &testConfigSource{
+			ValueMap: map[string]valueEntry{
+				"endpoint": {Value: "localhost:4317"},
+			},
+		},
+	}
+	configMap := confmap.NewFromStringMap(map[string]any{
+		"exporter": map[string]any{
+			"endpoint": "${plain:endpoint}",
+			"token":    "Bearer ${secrets:token}",
+			"headers":  "${secrets:headers}",
+			"literal":  "value",
+		},
+	})
+
+	recorder := newProvenanceRecorder()
+	ctx := contextWithSensitiveSources(contextWithProvenance(context.Background(), recorder), map[string]bool{"secrets": true})
+	res, closeFunc, err := resolve(ctx, cfgSources, configMap, nil)
+	require.NoError(t, err)
+	require.NoError(t, callClose(closeFunc))
+
+	resolved := confmap.NewFromStringMap(res).ToStringMap()
+	redacted := Redact(resolved, recorder.provenance(configMap.AllKeys(), nil))
+	assert.Equal(t, map[string]any{
+		"exporter": map[string]any{
+			"endpoint": "localhost:4317",
+			"token":    RedactedValue,
+			"headers":  RedactedValue,
+			"literal":  "value",
+		},
+	}, redacted)
+
+	// The resolved configuration passed to the components isn't redacted.
+	assert.Equal(t, map[string]any{
+		"endpoint": "localhost:4317",
+		"token":    "Bearer secret_token",
+		"headers":  map[string]any{"authorization": "Bearer secret"},
+		"literal":  "value",
+	}, resolved["exporter"])
+}
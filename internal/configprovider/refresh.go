@@ -0,0 +1,79 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"sync"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+var _ Hook = (*Refresher)(nil)
+
+// Refresher forces the config providers it is given to, as a Hook, to retrieve the values of all
+// the config sources again, bypassing their cache, and to reload the configuration if it changed,
+// e.g. on SIGHUP after the secrets were rotated out-of-band.
+type Refresher struct {
+	providers []*configSourceConfigMapProvider
+	mutex     sync.Mutex
+}
+
+// NewRefresher creates a Refresher, it must be given to the config providers as a Hook.
+func NewRefresher() *Refresher {
+	return &Refresher{}
+}
+
+func (r *Refresher) OnNew() {}
+
+func (r *Refresher) OnRetrieve(string, map[string]any) {}
+
+func (r *Refresher) OnShutdown() {}
+
+func (r *Refresher) addProvider(provider *configSourceConfigMapProvider) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.providers = append(r.providers, provider)
+}
+
+// Refresh resolves the configuration of each config provider again, retrieving the values of the
+// config sources instead of using the cached ones, and reloads it if it changed. Like when a value
+// changes the cached values are still used if the config source fails and "stale_if_error" is set.
+func (r *Refresher) Refresh() {
+	r.mutex.Lock()
+	providers := append([]*configSourceConfigMapProvider(nil), r.providers...)
+	r.mutex.Unlock()
+	for _, provider := range providers {
+		provider.refresh()
+	}
+}
+
+func (c *configSourceConfigMapProvider) setRefreshWatcher(watcher confmap.WatcherFunc) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.refreshWatcher = watcher
+}
+
+// refresh expires the cached values and notifies the watcher of the last retrieve, if any, that
+// only reloads the configuration if it changed.
+func (c *configSourceConfigMapProvider) refresh() {
+	c.cache.expire()
+	c.mutex.Lock()
+	watcher := c.refreshWatcher
+	c.mutex.Unlock()
+	if watcher != nil {
+		watcher(&confmap.ChangeEvent{})
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/provider/yamlprovider"
+	"go.uber.org/zap"
+)
+
+func TestRefresher(t *testing.T) {
+	const rotatingConfig = "yaml:config_sources::rotating::cache::ttl: 1h\nkey: ${rotating:secret}"
+	factory := &rotatingCfgSrcFactory{value: "v1"}
+	refresher := NewRefresher()
+	provider := NewConfigSourceConfigMapProvider(yamlprovider.New(), zap.NewNop(), component.NewDefaultBuildInfo(),
+		[]Hook{refresher}, nil, factory)
+
+	// Refreshing before any retrieve doesn't do anything.
+	refresher.Refresh()
+
+	watcher := &recordingWatcher{}
+	retrieved, err := provider.Retrieve(context.Background(), rotatingConfig, watcher.onChange)
+	require.NoError(t, err)
+	resolved, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"key": "v1"}, resolved)
+	require.NoError(t, retrieved.Close(context.Background()))
+
+	// The value is rotated out-of-band, the cache hides it until refreshed.
+	factory.setValue("v2")
+	refresher.Refresh()
+	require.Len(t, watcher.calls(), 1)
+	retrieved, err = provider.Retrieve(context.Background(), rotatingConfig, watcher.onChange)
+	require.NoError(t, err)
+	resolved, err = retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"key": "v2"}, resolved)
+	require.NoError(t, retrieved.Close(context.Background()))
+
+	// Without changes the configuration isn't reloaded.
+	refresher.Refresh()
+	assert.Len(t, watcher.calls(), 1)
+
+	// The collector reloading on SIGHUP before the refresh takes the cached value, the refresh
+	// still reloads the configuration with the new one.
+	factory.setValue("v3")
+	retrieved, err = provider.Retrieve(context.Background(), rotatingConfig, watcher.onChange)
+	require.NoError(t, err)
+	resolved, err = retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"key": "v2"}, resolved)
+	require.NoError(t, retrieved.Close(context.Background()))
+	refresher.Refresh()
+	require.Len(t, watcher.calls(), 2)
+	retrieved, err = provider.Retrieve(context.Background(), rotatingConfig, watcher.onChange)
+	require.NoError(t, err)
+	resolved, err = retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"key": "v3"}, resolved)
+	require.NoError(t, retrieved.Close(context.Background()))
+
+	assert.NoError(t, provider.Shutdown(context.Background()))
+}
+
+type rotatingSettings struct {
+	SourceSettings `mapstructure:",squash"`
+}
+
+// rotatingCfgSrcFactory creates config sources retrieving its current value, or failing with
+// its current error, for any selector.
+type rotatingCfgSrcFactory struct {
+	err   error
+	value string
+	mutex sync.Mutex
+}
+
+var _ Factory = (*rotatingCfgSrcFactory)(nil)
+
+func (f *rotatingCfgSrcFactory) setValue(value string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.value = value
+}
+
+func (f *rotatingCfgSrcFactory) setErr(err error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.err = err
+}
+
+func (f *rotatingCfgSrcFactory) Type() component.Type {
+	return "rotating"
+}
+
+func (f *rotatingCfgSrcFactory) CreateDefaultConfig() Source {
+	return &rotatingSettings{SourceSettings: NewSourceSettings(component.NewID("rotating"))}
+}
+
+func (f *rotatingCfgSrcFactory) CreateConfigSource(context.Context, CreateParams, Source) (ConfigSource, error) {
+	return &rotatingConfigSource{factory: f}, nil
+}
+
+type rotatingConfigSource struct {
+	factory *rotatingCfgSrcFactory
+}
+
+func (r *rotatingConfigSource) Retrieve(context.Context, string, *confmap.Conf, confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	r.factory.mutex.Lock()
+	defer r.factory.mutex.Unlock()
+	if r.factory.err != nil {
+		return nil, r.factory.err
+	}
+	return confmap.NewRetrieved(r.factory.value)
+}
+
+func (r *rotatingConfigSource) Shutdown(context.Context) error {
+	return nil
+}
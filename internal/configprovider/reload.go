@@ -0,0 +1,211 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+)
+
+// resolution is the input and the result of a resolution of the configuration.
+type resolution struct {
+	input      *confmap.Conf
+	keyOrigins map[string]string
+	resolved   map[string]any
+	provenance map[string]KeyProvenance
+}
+
+// setLastResolution records the last resolution of the configuration and returns the previous one.
+func (c *configSourceConfigMapProvider) setLastResolution(input *confmap.Conf, resolved map[string]any, provenance map[string]KeyProvenance) *resolution {
+	keyOrigins := c.copyKeyOrigins()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	previous := c.lastResolution
+	c.lastResolution = &resolution{input: input, keyOrigins: keyOrigins, resolved: resolved, provenance: provenance}
+	return previous
+}
+
+// pendingResolution is a resolution of the configuration after a change notified by a config source.
+// The retrieve of the reload it triggers uses it instead of resolving the configuration once more, and
+// while the reload is skipped it keeps watching the values retrieved again.
+type pendingResolution struct {
+	input      *confmap.Conf
+	resolved   map[string]any
+	closeFunc  confmap.CloseFunc
+	provenance *provenanceRecorder
+}
+
+func (p *pendingResolution) close() {
+	if p != nil && p.closeFunc != nil {
+		_ = p.closeFunc(context.Background())
+	}
+}
+
+// setPendingResolution replaces the pending resolution, the previous one is closed.
+func (c *configSourceConfigMapProvider) setPendingResolution(pending *pendingResolution) {
+	c.mutex.Lock()
+	previous := c.pendingResolution
+	c.pendingResolution = pending
+	c.mutex.Unlock()
+	previous.close()
+}
+
+// takePendingResolution returns the pending resolution if its input is the configuration to
+// resolve, otherwise the pending resolution is outdated and closed.
+func (c *configSourceConfigMapProvider) takePendingResolution(input *confmap.Conf) (*pendingResolution, bool) {
+	c.mutex.Lock()
+	pending := c.pendingResolution
+	c.pendingResolution = nil
+	c.mutex.Unlock()
+	if pending == nil {
+		return nil, false
+	}
+	if !reflect.DeepEqual(pending.input.ToStringMap(), input.ToStringMap()) {
+		pending.close()
+		return nil, false
+	}
+	return pending, true
+}
+
+// reloadIfChanged returns a watcher, for the changes notified by the config sources, notifying onChange
+// only if the configuration resolved again is different. The collector restarts all the pipelines on
+// each reload, so the reloads not changing the configuration, e.g. after a secret is rotated to the same
+// value, are skipped and the components changed by the other ones are logged. Restarting only the
+// pipelines of these components isn't supported, the collector service has no API for it, so any
+// change still reloads the whole configuration. The configuration is resolved again from the last
+// input since only the config sources changed, the changes of the wrapped provider always reload it.
+func (c *configSourceConfigMapProvider) reloadIfChanged(onChange confmap.WatcherFunc) confmap.WatcherFunc {
+	return func(event *confmap.ChangeEvent) {
+		c.mutex.Lock()
+		last := c.lastResolution
+		watcher := c.sourceWatcher
+		c.mutex.Unlock()
+		if event.Error != nil || last == nil {
+			onChange(event)
+			return
+		}
+
+		ctx, cancel := c.resolutionContext(context.Background())
+		defer cancel()
+		provenance := newProvenanceRecorder()
+		resolveCtx := contextWithProvenance(c.resolveContext(ctx, last.keyOrigins), provenance)
+		resolved, closeFunc, err := c.resolveWithHooks(resolveCtx, last.input, watcher)
+		if err != nil {
+			// Let the reload report the error.
+			onChange(event)
+			return
+		}
+		c.setPendingResolution(&pendingResolution{input: last.input, resolved: resolved, closeFunc: closeFunc, provenance: provenance})
+
+		changed := changedComponents(last.resolved, resolved)
+		if len(changed) == 0 {
+			c.logger.Info("Configuration unchanged after a config source change, skipping the reload", zap.String("scheme", c.Scheme()))
+			return
+		}
+		c.logger.Info("Configuration changed by a config source, reloading", zap.String("scheme", c.Scheme()), zap.Strings("components", changed))
+		onChange(event)
+	}
+}
+
+// configDiff holds the flattened keys added, removed, and modified by a resolution of the configuration.
+type configDiff struct {
+	added    []string
+	removed  []string
+	modified []string
+}
+
+func diffConfig(previous, current map[string]any) configDiff {
+	var diff configDiff
+	for k, v := range current {
+		if previousValue, ok := previous[k]; !ok {
+			diff.added = append(diff.added, k)
+		} else if !reflect.DeepEqual(previousValue, v) {
+			diff.modified = append(diff.modified, k)
+		}
+	}
+	for k := range previous {
+		if _, ok := current[k]; !ok {
+			diff.removed = append(diff.removed, k)
+		}
+	}
+	sort.Strings(diff.added)
+	sort.Strings(diff.removed)
+	sort.Strings(diff.modified)
+	return diff
+}
+
+func (d configDiff) keys() []string {
+	return append(append(append([]string(nil), d.added...), d.removed...), d.modified...)
+}
+
+// logConfigDiff logs the keys changed by the re-resolution of the configuration, with their
+// values redacted according to their provenance, so operators can see why it was reloaded.
+func logConfigDiff(scheme string, previous, current *resolution) {
+	diff := diffConfig(previous.resolved, current.resolved)
+	if len(diff.keys()) == 0 {
+		return
+	}
+	before := Redact(previous.resolved, previous.provenance)
+	after := Redact(current.resolved, current.provenance)
+	var changes []string
+	for _, k := range diff.added {
+		changes = append(changes, fmt.Sprintf("  + %s: %v", k, after[k]))
+	}
+	for _, k := range diff.removed {
+		changes = append(changes, fmt.Sprintf("  - %s: %v", k, before[k]))
+	}
+	for _, k := range diff.modified {
+		changes = append(changes, fmt.Sprintf("  ~ %s: %v -> %v", k, before[k], after[k]))
+	}
+	log.Printf("Configuration retrieved by the %q provider changed:\n%s", scheme, strings.Join(changes, "\n"))
+}
+
+// changedComponents returns the sorted components, e.g. "exporters::otlp" or "service::pipelines::traces",
+// with different values in the previous and the current configurations, given with flattened keys.
+func changedComponents(previous, current map[string]any) []string {
+	changed := map[string]bool{}
+	for _, k := range diffConfig(previous, current).keys() {
+		changed[componentOfKey(k)] = true
+	}
+
+	components := make([]string, 0, len(changed))
+	for component := range changed {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+	return components
+}
+
+// componentOfKey returns the prefix of the key identifying the component, or the pipeline for the
+// keys of the service pipelines, it belongs to.
+func componentOfKey(key string) string {
+	parts := strings.SplitN(key, confmap.KeyDelimiter, 4)
+	n := 2
+	if parts[0] == "service" && len(parts) > 1 && parts[1] == "pipelines" {
+		n = 3
+	}
+	if len(parts) < n {
+		n = len(parts)
+	}
+	return strings.Join(parts[:n], confmap.KeyDelimiter)
+}
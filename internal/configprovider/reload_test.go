@@ -0,0 +1,233 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/provider/yamlprovider"
+	"go.uber.org/zap"
+)
+
+func TestReloadIfChanged(t *testing.T) {
+	t.Setenv("RELOAD_ENDPOINT", "localhost:4317")
+	provider := NewConfigSourceConfigMapProvider(yamlprovider.New(), zap.NewNop(), component.NewDefaultBuildInfo(), nil, nil)
+	cspp := provider.(*configSourceConfigMapProvider)
+
+	watcher := &recordingWatcher{}
+	onChange := cspp.reloadIfChanged(watcher.onChange)
+
+	// Without a previous resolution the events are always notified.
+	onChange(&confmap.ChangeEvent{})
+	require.Len(t, watcher.calls(), 1)
+
+	retrieved, err := provider.Retrieve(context.Background(), "yaml:exporters::otlp::endpoint: $RELOAD_ENDPOINT", watcher.onChange)
+	require.NoError(t, err)
+	require.NoError(t, retrieved.Close(context.Background()))
+
+	onChange(&confmap.ChangeEvent{})
+	assert.Len(t, watcher.calls(), 1, "the reload must be skipped if the configuration didn't change")
+
+	errWatch := errors.New("watch failed")
+	onChange(&confmap.ChangeEvent{Error: errWatch})
+	require.Len(t, watcher.calls(), 2)
+	assert.ErrorIs(t, watcher.calls()[1].Error, errWatch)
+
+	t.Setenv("RELOAD_ENDPOINT", "localhost:4318")
+	onChange(&confmap.ChangeEvent{})
+	require.Len(t, watcher.calls(), 3)
+	assert.NoError(t, watcher.calls()[2].Error)
+
+	assert.NoError(t, provider.Shutdown(context.Background()))
+}
+
+// watchedProvider is a yaml provider keeping the watcher of its last retrieve.
+type watchedProvider struct {
+	confmap.Provider
+	onChange confmap.WatcherFunc
+}
+
+func (p *watchedProvider) Retrieve(ctx context.Context, uri string, onChange confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	p.onChange = onChange
+	return p.Provider.Retrieve(ctx, uri, onChange)
+}
+
+func TestReloadIfChangedWrappedProvider(t *testing.T) {
+	wrapped := &watchedProvider{Provider: yamlprovider.New()}
+	provider := NewConfigSourceConfigMapProvider(wrapped, zap.NewNop(), component.NewDefaultBuildInfo(), nil, nil)
+
+	watcher := &recordingWatcher{}
+	retrieved, err := provider.Retrieve(context.Background(), "yaml:exporters::otlp::endpoint: localhost:4317", watcher.onChange)
+	require.NoError(t, err)
+	require.NoError(t, retrieved.Close(context.Background()))
+
+	// The changes of the wrapped provider always reload the configuration, it isn't resolved again
+	// from the previous input to compare it.
+	require.NotNil(t, wrapped.onChange)
+	wrapped.onChange(&confmap.ChangeEvent{})
+	assert.Len(t, watcher.calls(), 1)
+
+	assert.NoError(t, provider.Shutdown(context.Background()))
+}
+
+func TestReloadIfChangedPendingResolution(t *testing.T) {
+	t.Setenv("RELOAD_ENDPOINT", "localhost:4317")
+	provider := NewConfigSourceConfigMapProvider(yamlprovider.New(), zap.NewNop(), component.NewDefaultBuildInfo(), nil, nil)
+	cspp := provider.(*configSourceConfigMapProvider)
+
+	watcher := &recordingWatcher{}
+	const uri = "yaml:exporters::otlp::endpoint: $RELOAD_ENDPOINT"
+	retrieved, err := provider.Retrieve(context.Background(), uri, watcher.onChange)
+	require.NoError(t, err)
+	require.NoError(t, retrieved.Close(context.Background()))
+
+	t.Setenv("RELOAD_ENDPOINT", "localhost:4318")
+	cspp.reloadIfChanged(watcher.onChange)(&confmap.ChangeEvent{})
+	require.Len(t, watcher.calls(), 1)
+
+	// The reload uses the resolution that triggered it instead of resolving the configuration again.
+	t.Setenv("RELOAD_ENDPOINT", "localhost:4319")
+	retrieved, err = provider.Retrieve(context.Background(), uri, watcher.onChange)
+	require.NoError(t, err)
+	conf, err := retrieved.AsConf()
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:4318", conf.Get("exporters::otlp::endpoint"))
+	require.NoError(t, retrieved.Close(context.Background()))
+
+	// Without a pending resolution it is resolved as usual.
+	retrieved, err = provider.Retrieve(context.Background(), uri, watcher.onChange)
+	require.NoError(t, err)
+	conf, err = retrieved.AsConf()
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:4319", conf.Get("exporters::otlp::endpoint"))
+	require.NoError(t, retrieved.Close(context.Background()))
+
+	assert.NoError(t, provider.Shutdown(context.Background()))
+}
+
+func TestReloadIfChangedMaterialize(t *testing.T) {
+	t.Setenv(materializeDirEnvVar, t.TempDir())
+	factory := &rotatingCfgSrcFactory{value: "v1"}
+	provider := NewConfigSourceConfigMapProvider(yamlprovider.New(), zap.NewNop(), component.NewDefaultBuildInfo(), nil, nil, factory)
+	cspp := provider.(*configSourceConfigMapProvider)
+
+	watcher := &recordingWatcher{}
+	const uri = "yaml:key_file: ${rotating:secret?materialize=file}"
+	first, err := provider.Retrieve(context.Background(), uri, watcher.onChange)
+	require.NoError(t, err)
+	conf, err := first.AsConf()
+	require.NoError(t, err)
+	path, ok := conf.Get("key_file").(string)
+	require.True(t, ok)
+
+	// The value is materialized to the same file, the configuration didn't change.
+	onChange := cspp.reloadIfChanged(watcher.onChange)
+	onChange(&confmap.ChangeEvent{})
+	assert.Empty(t, watcher.calls(), "the reload must be skipped if the materialized value didn't change")
+
+	factory.setValue("v2")
+	onChange(&confmap.ChangeEvent{})
+	require.Len(t, watcher.calls(), 1)
+	second, err := provider.Retrieve(context.Background(), uri, watcher.onChange)
+	require.NoError(t, err)
+	conf, err = second.AsConf()
+	require.NoError(t, err)
+	assert.Equal(t, path, conf.Get("key_file"))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(content))
+
+	// The file is only shredded once no configuration uses it.
+	require.NoError(t, first.Close(context.Background()))
+	_, err = os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, second.Close(context.Background()))
+	assert.NoError(t, provider.Shutdown(context.Background()))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestChangedComponents(t *testing.T) {
+	previous := map[string]any{
+		"receivers::otlp::protocols::grpc::endpoint": "0.0.0.0:4317",
+		"exporters::otlp::endpoint":                  "localhost:4317",
+		"exporters::otlp::headers":                   map[string]any{"token": "old"},
+		"processors::batch":                          nil,
+		"service::pipelines::traces::exporters":      []any{"otlp"},
+	}
+	current := map[string]any{
+		"receivers::otlp::protocols::grpc::endpoint": "0.0.0.0:4317",
+		"exporters::otlp::endpoint":                  "localhost:4317",
+		"exporters::otlp::headers":                   map[string]any{"token": "new"},
+		"service::pipelines::traces::exporters":      []any{"otlp", "logging"},
+		"service::telemetry::logs::level":            "debug",
+	}
+	assert.Equal(t, []string{"exporters::otlp", "processors::batch", "service::pipelines::traces", "service::telemetry"}, changedComponents(previous, current))
+	assert.Empty(t, changedComponents(current, current))
+}
+
+func TestLogConfigDiff(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	previous := &resolution{
+		resolved: map[string]any{
+			"exporters::otlp::endpoint":         "localhost:4317",
+			"exporters::otlp::headers::token":   "old_secret",
+			"exporters::logging::loglevel":      "info",
+			"service::pipelines::traces::hosts": []any{"a"},
+		},
+		provenance: map[string]KeyProvenance{
+			"exporters::otlp::headers::token": {ConfigSources: []string{"vault:secret/token"}, Redacted: true},
+		},
+	}
+	current := &resolution{
+		resolved: map[string]any{
+			"exporters::otlp::endpoint":         "localhost:4317",
+			"exporters::otlp::headers::token":   "new_secret",
+			"exporters::otlp::compression":      "gzip",
+			"service::pipelines::traces::hosts": []any{"a", "b"},
+		},
+		provenance: map[string]KeyProvenance{
+			"exporters::otlp::headers::token": {ConfigSources: []string{"vault:secret/token"}, Redacted: true},
+		},
+	}
+
+	logConfigDiff("file", previous, current)
+	logged := buf.String()
+	assert.Contains(t, logged, `Configuration retrieved by the "file" provider changed:
+  + exporters::otlp::compression: gzip
+  - exporters::logging::loglevel: info
+  ~ exporters::otlp::headers::token: <redacted> -> <redacted>
+  ~ service::pipelines::traces::hosts: [a] -> [a b]
+`)
+	assert.NotContains(t, logged, "secret")
+	assert.NotContains(t, logged, "endpoint")
+
+	buf.Reset()
+	logConfigDiff("file", current, current)
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,104 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ResolutionError is the error resolving a reference in the configuration, e.g. to a config source
+// failing to retrieve its value. Its message is the one of Err, the other fields are meant for the
+// tools reporting the errors, use errors.As to get it from the errors of Resolve.
+type ResolutionError struct {
+	// Err is the error of the reference.
+	Err error
+	// Key is the flattened key, e.g. "exporters::otlp::endpoint", of the configuration with the
+	// reference, or enclosing it for the references in the parameters of an invocation.
+	Key string
+	// Reference is the raw reference, e.g. "${vault:secret/data/otlp?path=endpoint}", as it is in the
+	// value of the key.
+	Reference string
+	// ConfigSource is the name of the config source, or the scheme of the confmap provider, of the
+	// reference, empty for env vars.
+	ConfigSource string
+}
+
+func (e *ResolutionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ResolutionError) Unwrap() error {
+	return e.Err
+}
+
+// newResolutionError returns the error of the reference in the key being resolved in ctx, err is
+// returned as it is if it's already the error of a reference.
+func newResolutionError(ctx context.Context, reference, cfgSrcName string, err error) error {
+	if _, ok := err.(*ResolutionError); ok {
+		return err
+	}
+	key, _ := keyFromContext(ctx)
+	return &ResolutionError{Err: err, Key: key, Reference: reference, ConfigSource: cfgSrcName}
+}
+
+// ResolutionErrors are the errors of a resolution of the configuration failing for several keys,
+// reported together instead of only the first one.
+type ResolutionErrors []error
+
+func (e ResolutionErrors) Error() string {
+	lines := make([]string, 0, len(e)+1)
+	lines = append(lines, fmt.Sprintf("failed to resolve %d keys of the configuration:", len(e)))
+	for _, err := range e {
+		lines = append(lines, "  "+err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Is reports whether any of the errors matches target.
+func (e ResolutionErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors matching target.
+func (e ResolutionErrors) As(target any) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// keyErrors returns the error of a resolution failing for the given keys, each with its error. A
+// single error is reported as it is, see keyError.
+func keyErrors(ctx context.Context, keys []string, errs []error) error {
+	if len(errs) == 1 {
+		return keyError(ctx, keys[0], errs[0])
+	}
+	report := make(ResolutionErrors, len(errs))
+	for i, err := range errs {
+		report[i] = fmt.Errorf("failed to resolve key %q: %w", keys[i], err)
+	}
+	return report
+}
@@ -0,0 +1,108 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// ResolutionHook is a Hook modifying the configuration retrieved by the config provider before its
+// config sources and env vars are expanded, e.g. to rewrite a legacy syntax, and after, e.g. to
+// validate the resolved values or inject defaults. The hooks are run in order on every resolution,
+// including the re-resolutions on reloads, and any error fails the resolution.
+type ResolutionHook interface {
+	Hook
+	// PreResolve is called with the configuration before it is resolved.
+	PreResolve(ctx context.Context, conf *confmap.Conf) error
+	// PostResolve is called with the resolved configuration.
+	PostResolve(ctx context.Context, conf *confmap.Conf) error
+}
+
+// NewConverterHook creates a ResolutionHook running the pre converters before the resolution of the
+// configuration and the post converters after it.
+func NewConverterHook(pre, post []confmap.Converter) ResolutionHook {
+	return &converterHook{pre: pre, post: post}
+}
+
+type converterHook struct {
+	pre  []confmap.Converter
+	post []confmap.Converter
+}
+
+func (h *converterHook) OnNew() {}
+
+func (h *converterHook) OnRetrieve(string, map[string]any) {}
+
+func (h *converterHook) OnShutdown() {}
+
+func (h *converterHook) PreResolve(ctx context.Context, conf *confmap.Conf) error {
+	return convert(ctx, h.pre, conf)
+}
+
+func (h *converterHook) PostResolve(ctx context.Context, conf *confmap.Conf) error {
+	return convert(ctx, h.post, conf)
+}
+
+func convert(ctx context.Context, converters []confmap.Converter, conf *confmap.Conf) error {
+	for _, converter := range converters {
+		if err := converter.Convert(ctx, conf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveWithHooks resolves the configuration running the ResolutionHooks before and after it.
+func (c *configSourceConfigMapProvider) resolveWithHooks(ctx context.Context, input *confmap.Conf, watcher confmap.WatcherFunc) (map[string]any, confmap.CloseFunc, error) {
+	factories, err := makeFactoryMap(c.factories)
+	if err != nil {
+		return nil, nil, err
+	}
+	var hooks []ResolutionHook
+	for _, h := range c.hooks {
+		if resolutionHook, ok := h.(ResolutionHook); ok {
+			hooks = append(hooks, resolutionHook)
+		}
+	}
+	if len(hooks) == 0 {
+		return Resolve(ctx, input, c.logger, c.buildInfo, factories, watcher)
+	}
+
+	// The hooks modify a copy, the input is kept to resolve the configuration again.
+	input = confmap.NewFromStringMap(input.ToStringMap())
+	for _, h := range hooks {
+		if err = h.PreResolve(ctx, input); err != nil {
+			return nil, nil, fmt.Errorf("pre-resolution hook failed: %w", err)
+		}
+	}
+	resolved, closeFunc, err := Resolve(ctx, input, c.logger, c.buildInfo, factories, watcher)
+	if err != nil {
+		return nil, nil, err
+	}
+	conf := confmap.NewFromStringMap(resolved)
+	for _, h := range hooks {
+		if err = h.PostResolve(ctx, conf); err != nil {
+			if closeFunc != nil {
+				_ = closeFunc(ctx)
+			}
+			return nil, nil, fmt.Errorf("post-resolution hook failed: %w", err)
+		}
+	}
+	return conf.ToStringMap(), closeFunc, nil
+}
@@ -0,0 +1,76 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/provider/yamlprovider"
+	"go.uber.org/zap"
+)
+
+type converterFunc func(context.Context, *confmap.Conf) error
+
+func (f converterFunc) Convert(ctx context.Context, conf *confmap.Conf) error {
+	return f(ctx, conf)
+}
+
+func TestResolutionHooks(t *testing.T) {
+	// Rewrites the legacy "secret_ref" key to a config source invocation.
+	legacySyntax := converterFunc(func(_ context.Context, conf *confmap.Conf) error {
+		if ref, ok := conf.Get("secret_ref").(string); ok {
+			return conf.Merge(confmap.NewFromStringMap(map[string]any{"secret": "${rotating:" + ref + "}"}))
+		}
+		return nil
+	})
+	defaults := converterFunc(func(_ context.Context, conf *confmap.Conf) error {
+		if !conf.IsSet("endpoint") {
+			return conf.Merge(confmap.NewFromStringMap(map[string]any{"endpoint": "localhost:4317"}))
+		}
+		return nil
+	})
+	validation := converterFunc(func(_ context.Context, conf *confmap.Conf) error {
+		if secret, _ := conf.Get("secret").(string); secret == "" {
+			return errors.New("secret can't be empty")
+		}
+		return nil
+	})
+
+	factory := &rotatingCfgSrcFactory{value: "s3cr3t"}
+	provider := NewConfigSourceConfigMapProvider(yamlprovider.New(), zap.NewNop(), component.NewDefaultBuildInfo(),
+		[]Hook{NewConverterHook([]confmap.Converter{legacySyntax}, []confmap.Converter{defaults, validation})}, nil, factory)
+	const config = "yaml:config_sources::rotating:\nsecret_ref: token"
+	retrieved, err := provider.Retrieve(context.Background(), config, nil)
+	require.NoError(t, err)
+	resolved, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"secret_ref": "token", "secret": "s3cr3t", "endpoint": "localhost:4317"}, resolved)
+	require.NoError(t, retrieved.Close(context.Background()))
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	factory.setValue("")
+	provider = NewConfigSourceConfigMapProvider(yamlprovider.New(), zap.NewNop(), component.NewDefaultBuildInfo(),
+		[]Hook{NewConverterHook([]confmap.Converter{legacySyntax}, []confmap.Converter{defaults, validation})}, nil, factory)
+	_, err = provider.Retrieve(context.Background(), config, nil)
+	assert.EqualError(t, err, "post-resolution hook failed: secret can't be empty")
+	require.NoError(t, provider.Shutdown(context.Background()))
+}
@@ -0,0 +1,52 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+
+	"github.com/knadh/koanf/maps"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// ValueResolver resolves the config source invocations and environment variables in a value,
+// a string or slices and maps of them, the same way the values of the configuration are resolved.
+// Maps in the resolved value always have string keys.
+type ValueResolver func(ctx context.Context, value any, watcher confmap.WatcherFunc) (any, confmap.CloseFunc, error)
+
+type resolverCtxKey struct{}
+
+// ResolverFromContext returns the ValueResolver of the configuration being resolved. Config
+// sources can use it to resolve invocations in the data they retrieve, e.g. files that include
+// other files. It returns false if the context doesn't come from a configuration being resolved.
+func ResolverFromContext(ctx context.Context) (ValueResolver, bool) {
+	resolver, ok := ctx.Value(resolverCtxKey{}).(ValueResolver)
+	return resolver, ok
+}
+
+func contextWithResolver(ctx context.Context, configSources map[string]ConfigSource) context.Context {
+	var resolver ValueResolver = func(ctx context.Context, value any, watcher confmap.WatcherFunc) (any, confmap.CloseFunc, error) {
+		resolved, closeFunc, err := parseConfigValue(ctx, configSources, value, watcher)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Wrap the value so maps at any level, including the top one, get string keys.
+		wrapper := map[string]any{"value": resolved}
+		maps.IntfaceKeysToStrings(wrapper)
+		return wrapper["value"], closeFunc, nil
+	}
+	return context.WithValue(ctx, resolverCtxKey{}, resolver)
+}
@@ -0,0 +1,81 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// nestingConfigSource retrieves values that contain config source invocations and
+// resolves them via the ValueResolver in the context.
+type nestingConfigSource struct {
+	values map[string]any
+}
+
+func (n *nestingConfigSource) Retrieve(ctx context.Context, selector string, _ *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	resolver, ok := ResolverFromContext(ctx)
+	if !ok {
+		return nil, errors.New("no resolver in the context")
+	}
+	value, closeFunc, err := resolver(ctx, n.values[selector], watcher)
+	if err != nil {
+		return nil, err
+	}
+	return confmap.NewRetrieved(value, confmap.WithRetrievedClose(closeFunc))
+}
+
+func (n *nestingConfigSource) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestResolverFromContext(t *testing.T) {
+	_, ok := ResolverFromContext(context.Background())
+	assert.False(t, ok)
+
+	cfgSources := map[string]ConfigSource{
+		"tstcfgsrc": &testConfigSource{
+			ValueMap: map[string]valueEntry{
+				"endpoint": {Value: "localhost:4317"},
+			},
+		},
+		"nesting": &nestingConfigSource{
+			values: map[string]any{
+				"exporter": map[string]any{
+					"endpoint": "$tstcfgsrc:endpoint",
+					"headers":  []any{"${tstcfgsrc:endpoint}/path"},
+				},
+			},
+		},
+	}
+
+	res, closeFunc, err := resolve(context.Background(), cfgSources, confmap.NewFromStringMap(map[string]any{
+		"exporters": "$nesting:exporter",
+	}), nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"exporters": map[string]any{
+			"endpoint": "localhost:4317",
+			"headers":  []any{"localhost:4317/path"},
+		},
+	}, res)
+	assert.NoError(t, callClose(closeFunc))
+}
@@ -0,0 +1,134 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRetryInitialInterval = time.Second
+	defaultRetryMaxInterval     = 30 * time.Second
+	defaultRetryMultiplier      = 2
+)
+
+// withRetry wraps the config sources with a retrieve timeout or retries so they are enforced
+// by the config provider.
+func withRetry(configSources map[string]ConfigSource, settings map[string]Source, logger *zap.Logger) (map[string]ConfigSource, error) {
+	for name, cfgSrc := range configSources {
+		retrieveSettings, ok := settings[name].(interface {
+			RetrieveSettings() (time.Duration, RetrySettings)
+		})
+		if !ok {
+			continue
+		}
+		timeout, retry := retrieveSettings.RetrieveSettings()
+		if timeout == 0 && retry.MaxRetries == 0 {
+			continue
+		}
+		if timeout < 0 {
+			return nil, fmt.Errorf("invalid retrieve_timeout for config source %s: can't be negative", name)
+		}
+		if err := retry.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid retry settings for config source %s: %w", name, err)
+		}
+		if retry.InitialInterval == 0 {
+			retry.InitialInterval = defaultRetryInitialInterval
+		}
+		if retry.MaxInterval == 0 {
+			retry.MaxInterval = defaultRetryMaxInterval
+		}
+		if retry.Multiplier == 0 {
+			retry.Multiplier = defaultRetryMultiplier
+		}
+		configSources[name] = &retryingConfigSource{
+			ConfigSource: cfgSrc,
+			timeout:      timeout,
+			retry:        retry,
+			name:         name,
+			logger:       logger,
+			sleep:        sleepContext,
+		}
+	}
+	return configSources, nil
+}
+
+// retryingConfigSource retries the failed retrieves of the wrapped config source, with
+// exponential backoff, and fails the attempts taking longer than the timeout.
+type retryingConfigSource struct {
+	ConfigSource
+	logger  *zap.Logger
+	sleep   func(ctx context.Context, d time.Duration) error
+	name    string
+	retry   RetrySettings
+	timeout time.Duration
+}
+
+func (r *retryingConfigSource) Retrieve(ctx context.Context, selector string, paramsConfigMap *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	interval := r.retry.InitialInterval
+	for attempt := 0; ; attempt++ {
+		retrieved, err := r.retrieveWithTimeout(ctx, selector, paramsConfigMap, watcher)
+		if err == nil || attempt >= r.retry.MaxRetries {
+			return retrieved, err
+		}
+
+		r.logger.Warn("Config source failed to retrieve value, retrying",
+			zap.String("config_source", r.name), zap.String("selector", selector),
+			zap.Int("attempt", attempt+1), zap.Duration("backoff", interval), zap.Error(err))
+		if sleepErr := r.sleep(ctx, interval); sleepErr != nil {
+			return nil, err
+		}
+		interval = time.Duration(float64(interval) * r.retry.Multiplier)
+		if interval > r.retry.MaxInterval {
+			interval = r.retry.MaxInterval
+		}
+	}
+}
+
+// retrieveWithTimeout retrieves the value, the context passed to the config source is canceled
+// after the timeout and, since not all config sources honor it, the retrieve is abandoned and
+// its result closed whenever it's returned.
+func (r *retryingConfigSource) retrieveWithTimeout(ctx context.Context, selector string, paramsConfigMap *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	if r.timeout == 0 {
+		return r.ConfigSource.Retrieve(ctx, selector, paramsConfigMap, watcher)
+	}
+
+	retrieveCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	retrieved, err := retrieveUntilDone(retrieveCtx, r.ConfigSource, selector, paramsConfigMap, watcher)
+	if err != nil && errors.Is(retrieveCtx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("retrieve timed out after %v", r.timeout)
+	}
+	return retrieved, err
+}
+
+// sleepContext waits for the duration or until the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+)
+
+func TestRetryingConfigSource(t *testing.T) {
+	failures := 4
+	retrieves := 0
+	cfgSrc := &testConfigSource{
+		ValueMap: map[string]valueEntry{
+			"selector": {Value: "value"},
+		},
+		OnRetrieve: func(context.Context, string, *confmap.Conf) error {
+			retrieves++
+			if retrieves <= failures {
+				return errors.New("backend unavailable")
+			}
+			return nil
+		},
+	}
+	settings := &mockCfgSrcSettings{SourceSettings: NewSourceSettings(component.NewID("tstcfgsrc"))}
+	settings.Retry = RetrySettings{MaxRetries: 5, MaxInterval: 5 * time.Second}
+
+	cfgSources, err := withRetry(map[string]ConfigSource{"tstcfgsrc": cfgSrc}, map[string]Source{"tstcfgsrc": settings}, zap.NewNop())
+	require.NoError(t, err)
+	require.IsType(t, &retryingConfigSource{}, cfgSources["tstcfgsrc"])
+
+	var intervals []time.Duration
+	cfgSources["tstcfgsrc"].(*retryingConfigSource).sleep = func(_ context.Context, d time.Duration) error {
+		intervals = append(intervals, d)
+		return nil
+	}
+
+	res, closeFunc, err := resolve(context.Background(), cfgSources, confmap.NewFromStringMap(map[string]any{
+		"key": "${tstcfgsrc:selector}",
+	}), nil)
+	require.NoError(t, err)
+	require.NoError(t, callClose(closeFunc))
+	assert.Equal(t, "value", res["key"])
+	assert.Equal(t, 5, retrieves)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}, intervals)
+
+	// Once the retries are exhausted the last error is returned.
+	retrieves, failures, intervals = 0, 10, nil
+	_, _, err = resolve(context.Background(), cfgSources, confmap.NewFromStringMap(map[string]any{
+		"key": "${tstcfgsrc:selector}",
+	}), nil)
+	require.ErrorContains(t, err, "backend unavailable")
+	assert.Equal(t, 6, retrieves)
+	assert.Len(t, intervals, 5)
+}
+
+func TestRetryingConfigSourceTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	retrieves := 0
+	cfgSrc := &testConfigSource{
+		ValueMap: map[string]valueEntry{
+			"selector": {Value: "value"},
+		},
+		OnRetrieve: func(context.Context, string, *confmap.Conf) error {
+			retrieves++
+			if retrieves == 1 {
+				// Ignores the context to check that the retrieve is abandoned anyway.
+				<-block
+			}
+			return nil
+		},
+	}
+	settings := &mockCfgSrcSettings{SourceSettings: NewSourceSettings(component.NewID("tstcfgsrc"))}
+	settings.RetrieveTimeout = 10 * time.Millisecond
+
+	cfgSources, err := withRetry(map[string]ConfigSource{"tstcfgsrc": cfgSrc}, map[string]Source{"tstcfgsrc": settings}, zap.NewNop())
+	require.NoError(t, err)
+
+	_, _, err = resolve(context.Background(), cfgSources, confmap.NewFromStringMap(map[string]any{
+		"key": "${tstcfgsrc:selector}",
+	}), nil)
+	require.ErrorContains(t, err, "retrieve timed out after 10ms")
+}
+
+func TestWithRetryInvalidSettings(t *testing.T) {
+	tests := []struct {
+		name            string
+		retry           RetrySettings
+		retrieveTimeout time.Duration
+		wantErr         string
+	}{
+		{
+			name:            "negative_timeout",
+			retrieveTimeout: -time.Second,
+			wantErr:         "invalid retrieve_timeout for config source tstcfgsrc",
+		},
+		{
+			name:    "negative_max_retries",
+			retry:   RetrySettings{MaxRetries: -1},
+			wantErr: "invalid retry settings for config source tstcfgsrc",
+		},
+		{
+			name:    "multiplier_below_one",
+			retry:   RetrySettings{MaxRetries: 1, Multiplier: 0.5},
+			wantErr: "retry multiplier must be at least 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := &mockCfgSrcSettings{SourceSettings: NewSourceSettings(component.NewID("tstcfgsrc"))}
+			settings.RetrieveTimeout = tt.retrieveTimeout
+			settings.Retry = tt.retry
+			_, err := withRetry(map[string]ConfigSource{"tstcfgsrc": &testConfigSource{}}, map[string]Source{"tstcfgsrc": settings}, zap.NewNop())
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
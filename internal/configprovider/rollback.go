@@ -0,0 +1,86 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"log"
+
+	"go.opencensus.io/stats"
+	"go.opentelemetry.io/collector/otelcol"
+)
+
+// ConfigApplyHook is a Hook notified by the config provider created by NewRollbackConfigProvider
+// about the configurations loaded by the collector, e.g. to notify external automation.
+type ConfigApplyHook interface {
+	Hook
+	// OnConfigApplied is called when a valid configuration is loaded, initial is set for the
+	// configuration loaded when the collector starts.
+	OnConfigApplied(initial bool)
+	// OnConfigRollback is called when a reload fails with the error and the last known good
+	// configuration is loaded instead.
+	OnConfigRollback(err error)
+}
+
+// NewRollbackConfigProvider wraps the collector ConfigProvider so a reload whose configuration can't
+// be resolved, e.g. because a config source fails, or is invalid rolls back to the last configuration
+// successfully loaded instead of shutting the collector down. The rollbacks are logged and counted by
+// the "configprovider/rollbacks" metric. The initial configuration isn't rolled back, and the failures
+// starting the components of a valid configuration still shut the collector down. The hooks
+// implementing ConfigApplyHook are notified about the configurations applied and the rollbacks.
+func NewRollbackConfigProvider(provider otelcol.ConfigProvider, hooks ...Hook) otelcol.ConfigProvider {
+	r := &rollbackConfigProvider{ConfigProvider: provider}
+	for _, h := range hooks {
+		if applyHook, ok := h.(ConfigApplyHook); ok {
+			r.hooks = append(r.hooks, applyHook)
+		}
+	}
+	return r
+}
+
+type rollbackConfigProvider struct {
+	otelcol.ConfigProvider
+	lastGood *otelcol.Config
+	hooks    []ConfigApplyHook
+}
+
+func (r *rollbackConfigProvider) Get(ctx context.Context, factories otelcol.Factories) (*otelcol.Config, error) {
+	cfg, err := r.ConfigProvider.Get(ctx, factories)
+	if err == nil {
+		if err = cfg.Validate(); err == nil {
+			initial := r.lastGood == nil
+			r.lastGood = cfg
+			for _, h := range r.hooks {
+				h.OnConfigApplied(initial)
+			}
+			return cfg, nil
+		}
+		if r.lastGood == nil {
+			// The collector reports the invalid initial configuration.
+			return cfg, nil
+		}
+	}
+	if r.lastGood == nil {
+		return nil, err
+	}
+
+	log.Printf("Failed to reload the configuration, rolling back to the last known good configuration: %v", err)
+	stats.Record(ctx, mRollbacks.M(1))
+	for _, h := range r.hooks {
+		h.OnConfigRollback(err)
+	}
+	return r.lastGood, nil
+}
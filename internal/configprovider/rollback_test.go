@@ -0,0 +1,126 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/otelcol"
+	"go.opentelemetry.io/collector/service"
+)
+
+type fakeConfigProvider struct {
+	otelcol.ConfigProvider
+	cfg *otelcol.Config
+	err error
+}
+
+func (f *fakeConfigProvider) Get(context.Context, otelcol.Factories) (*otelcol.Config, error) {
+	return f.cfg, f.err
+}
+
+func validConfig(endpoint string) *otelcol.Config {
+	nop := component.NewID("nop")
+	return &otelcol.Config{
+		Receivers: map[component.ID]component.Config{nop: &struct{ Endpoint string }{endpoint}},
+		Exporters: map[component.ID]component.Config{nop: &struct{}{}},
+		Service: service.Config{
+			Pipelines: map[component.ID]*service.PipelineConfig{
+				component.NewID("traces"): {Receivers: []component.ID{nop}, Exporters: []component.ID{nop}},
+			},
+		},
+	}
+}
+
+func TestRollbackConfigProvider(t *testing.T) {
+	fake := &fakeConfigProvider{err: errors.New("backend unavailable")}
+	provider := NewRollbackConfigProvider(fake)
+
+	// The initial failures are returned as is.
+	_, err := provider.Get(context.Background(), otelcol.Factories{})
+	assert.EqualError(t, err, "backend unavailable")
+
+	fake.cfg, fake.err = &otelcol.Config{}, nil
+	cfg, err := provider.Get(context.Background(), otelcol.Factories{})
+	require.NoError(t, err)
+	assert.Same(t, fake.cfg, cfg)
+
+	good := validConfig("localhost:4317")
+	fake.cfg = good
+	cfg, err = provider.Get(context.Background(), otelcol.Factories{})
+	require.NoError(t, err)
+	assert.Same(t, good, cfg)
+
+	fake.cfg, fake.err = nil, errors.New("backend unavailable")
+	cfg, err = provider.Get(context.Background(), otelcol.Factories{})
+	require.NoError(t, err)
+	assert.Same(t, good, cfg)
+
+	fake.cfg, fake.err = &otelcol.Config{}, nil
+	cfg, err = provider.Get(context.Background(), otelcol.Factories{})
+	require.NoError(t, err)
+	assert.Same(t, good, cfg)
+
+	next := validConfig("localhost:4318")
+	fake.cfg = next
+	cfg, err = provider.Get(context.Background(), otelcol.Factories{})
+	require.NoError(t, err)
+	assert.Same(t, next, cfg)
+}
+
+type recordingApplyHook struct {
+	events []string
+}
+
+func (h *recordingApplyHook) OnNew() {}
+
+func (h *recordingApplyHook) OnRetrieve(string, map[string]any) {}
+
+func (h *recordingApplyHook) OnShutdown() {}
+
+func (h *recordingApplyHook) OnConfigApplied(initial bool) {
+	if initial {
+		h.events = append(h.events, "initial")
+		return
+	}
+	h.events = append(h.events, "applied")
+}
+
+func (h *recordingApplyHook) OnConfigRollback(err error) {
+	h.events = append(h.events, "rollback: "+err.Error())
+}
+
+func TestRollbackConfigProviderHooks(t *testing.T) {
+	fake := &fakeConfigProvider{cfg: validConfig("localhost:4317")}
+	hook := &recordingApplyHook{}
+	provider := NewRollbackConfigProvider(fake, NewRefresher(), hook)
+
+	_, err := provider.Get(context.Background(), otelcol.Factories{})
+	require.NoError(t, err)
+	fake.cfg = validConfig("localhost:4318")
+	_, err = provider.Get(context.Background(), otelcol.Factories{})
+	require.NoError(t, err)
+	fake.cfg, fake.err = nil, errors.New("backend unavailable")
+	_, err = provider.Get(context.Background(), otelcol.Factories{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"initial", "applied", "rollback: backend unavailable"}, hook.events)
+}
@@ -0,0 +1,71 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+type schemeProvidersCtxKey struct{}
+
+// contextWithSchemeProviders returns a copy of ctx carrying the confmap providers, by scheme,
+// resolving the bracketed "${<scheme>:<opaque>}" references not matching any config source.
+func contextWithSchemeProviders(ctx context.Context, providers map[string]confmap.Provider) context.Context {
+	return context.WithValue(ctx, schemeProvidersCtxKey{}, providers)
+}
+
+func schemeProviderFromContext(ctx context.Context, scheme string) (confmap.Provider, bool) {
+	providers, _ := ctx.Value(schemeProvidersCtxKey{}).(map[string]confmap.Provider)
+	provider, ok := providers[scheme]
+	return provider, ok
+}
+
+// retrieveSchemeData retrieves the value of a "<scheme>:<opaque>" URI from the confmap provider
+// of the scheme, after expanding the references nested in the opaque value. Unlike config source
+// invocations the opaque value is passed as it is, parameters and default values aren't handled.
+func retrieveSchemeData(ctx context.Context, configSources map[string]ConfigSource, provider confmap.Provider, scheme, opaque string, watcher confmap.WatcherFunc) (any, confmap.CloseFunc, error) {
+	uri := scheme + string(configSourceNameDelimChar) + opaque
+	expanded, closeFunc, err := expandStringValue(ctx, configSources, opaque, watcher, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to process uri %q: %w", uri, err)
+	}
+	closeFuncs := []confmap.CloseFunc{closeFunc}
+	var ok bool
+	if opaque, ok = scalarString(unwrapValue(expanded)); !ok {
+		return nil, mergeCloseFuncs(closeFuncs), fmt.Errorf("processed uri must be a string instead got a %T %v", expanded, expanded)
+	}
+	uri = scheme + string(configSourceNameDelimChar) + opaque
+
+	recordProvenance(ctx, scheme, opaque)
+
+	retrieved, err := provider.Retrieve(ctx, uri, watcher)
+	if err != nil {
+		return nil, mergeCloseFuncs(closeFuncs), fmt.Errorf("provider %q failed to retrieve %q: %w", scheme, uri, err)
+	}
+	closeFuncs = append(closeFuncs, retrieved.Close)
+	val, err := retrieved.AsRaw()
+	if err != nil {
+		return nil, mergeCloseFuncs(closeFuncs), err
+	}
+	if s, ok := val.(string); ok {
+		// The providers already parse the values as YAML, keep the strings as they are.
+		val = typedString(s)
+	}
+	return val, mergeCloseFuncs(closeFuncs), nil
+}
@@ -0,0 +1,219 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/knadh/koanf/maps"
+	"go.opentelemetry.io/collector/confmap"
+	"gopkg.in/yaml.v2"
+)
+
+// configSnapshotEnvVar is the env var with the path of the snapshot bundle that enables the
+// offline mode: the values of all the config sources are served from the bundle instead of
+// being retrieved from their backends, e.g. at air-gapped sites.
+const configSnapshotEnvVar = "SPLUNK_CONFIG_SNAPSHOT"
+
+// snapshot is the bundle of the values retrieved by the config sources.
+type snapshot struct {
+	Entries []snapshotEntry `yaml:"entries"`
+}
+
+// snapshotEntry is the value retrieved by an invocation of a config source.
+type snapshotEntry struct {
+	Params       map[string]any `yaml:"params,omitempty"`
+	Value        any            `yaml:"value"`
+	ConfigSource string         `yaml:"config_source"`
+	Selector     string         `yaml:"selector"`
+}
+
+// snapshotKey identifies the invocation of a config source in the snapshot. Unlike the cache the
+// configuration file isn't part of it, so the snapshot can be used where the file has another path.
+func snapshotKey(name, selector string, paramsConfigMap *confmap.Conf) (string, error) {
+	return invocationKey(context.Background(), name, selector, paramsConfigMap)
+}
+
+var _ Hook = (*SnapshotRecorder)(nil)
+
+// SnapshotRecorder records, as a Hook of the config providers, the values retrieved by their
+// config sources to generate the snapshot bundle used in offline mode.
+type SnapshotRecorder struct {
+	entries map[string]snapshotEntry
+	mutex   sync.Mutex
+}
+
+// NewSnapshotRecorder creates a SnapshotRecorder, it must be given to the config providers as a Hook.
+func NewSnapshotRecorder() *SnapshotRecorder {
+	return &SnapshotRecorder{entries: map[string]snapshotEntry{}}
+}
+
+func (r *SnapshotRecorder) OnNew() {}
+
+func (r *SnapshotRecorder) OnRetrieve(string, map[string]any) {}
+
+func (r *SnapshotRecorder) OnShutdown() {}
+
+// snapshotRecorder is promoted to the types embedding the SnapshotRecorder, so they can be given
+// to the config providers instead.
+func (r *SnapshotRecorder) snapshotRecorder() *SnapshotRecorder {
+	return r
+}
+
+func (r *SnapshotRecorder) record(name, selector string, paramsConfigMap *confmap.Conf, value any) {
+	key, err := snapshotKey(name, selector, paramsConfigMap)
+	if err != nil {
+		return
+	}
+	entry := snapshotEntry{ConfigSource: name, Selector: selector, Value: value}
+	if paramsConfigMap != nil {
+		entry.Params = paramsConfigMap.ToStringMap()
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.entries[key] = entry
+}
+
+// WriteFile writes the snapshot bundle of the recorded values. The bundle holds the values of the
+// secrets in clear text so it is only readable by the current user.
+func (r *SnapshotRecorder) WriteFile(path string) error {
+	r.mutex.Lock()
+	keys := make([]string, 0, len(r.entries))
+	for key := range r.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	bundle := snapshot{Entries: make([]snapshotEntry, 0, len(keys))}
+	for _, key := range keys {
+		bundle.Entries = append(bundle.Entries, r.entries[key])
+	}
+	r.mutex.Unlock()
+
+	data, err := yaml.Marshal(bundle)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the config snapshot: %w", err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+type snapshotRecorderCtxKey struct{}
+
+// contextWithSnapshotRecorder returns a copy of ctx carrying the recorder of the retrieved values.
+func contextWithSnapshotRecorder(ctx context.Context, recorder *SnapshotRecorder) context.Context {
+	return context.WithValue(ctx, snapshotRecorderCtxKey{}, recorder)
+}
+
+// withSnapshotRecorder wraps the config sources so their retrieved values are recorded, if ctx
+// carries a recorder.
+func withSnapshotRecorder(ctx context.Context, configSources map[string]ConfigSource) map[string]ConfigSource {
+	recorder, ok := ctx.Value(snapshotRecorderCtxKey{}).(*SnapshotRecorder)
+	if !ok {
+		return configSources
+	}
+	for name, cfgSrc := range configSources {
+		configSources[name] = &recordingConfigSource{ConfigSource: cfgSrc, recorder: recorder, name: name}
+	}
+	return configSources
+}
+
+type recordingConfigSource struct {
+	ConfigSource
+	recorder *SnapshotRecorder
+	name     string
+}
+
+func (r *recordingConfigSource) Retrieve(ctx context.Context, selector string, paramsConfigMap *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	retrieved, err := r.ConfigSource.Retrieve(ctx, selector, paramsConfigMap, watcher)
+	if err != nil {
+		return nil, err
+	}
+	if value, err := retrieved.AsRaw(); err == nil {
+		r.recorder.record(r.name, selector, paramsConfigMap, value)
+	}
+	return retrieved, nil
+}
+
+// loadSnapshot returns the values of the snapshot bundle set by the env var, by invocation,
+// and false if the offline mode is disabled.
+func loadSnapshot() (map[string]any, bool, error) {
+	path := os.Getenv(configSnapshotEnvVar)
+	if path == "" {
+		return nil, false, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read the config snapshot: %w", err)
+	}
+	var bundle snapshot
+	if err = yaml.Unmarshal(data, &bundle); err != nil {
+		return nil, false, fmt.Errorf("failed to parse the config snapshot %q: %w", path, err)
+	}
+	values := make(map[string]any, len(bundle.Entries))
+	for _, entry := range bundle.Entries {
+		var paramsConfigMap *confmap.Conf
+		if len(entry.Params) > 0 {
+			maps.IntfaceKeysToStrings(entry.Params)
+			paramsConfigMap = confmap.NewFromStringMap(entry.Params)
+		}
+		key, err := snapshotKey(entry.ConfigSource, entry.Selector, paramsConfigMap)
+		if err != nil {
+			return nil, false, err
+		}
+		// yaml.Unmarshal returns map[any]any but config map uses map[string]any.
+		value := map[string]any{"value": entry.Value}
+		maps.IntfaceKeysToStrings(value)
+		values[key] = value["value"]
+	}
+	return values, true, nil
+}
+
+// snapshotConfigSources creates, in offline mode, config sources serving the values of the snapshot
+// instead of the ones built from the settings.
+func snapshotConfigSources(settings map[string]Source, values map[string]any) map[string]ConfigSource {
+	configSources := make(map[string]ConfigSource, len(settings))
+	for name := range settings {
+		configSources[name] = &snapshotConfigSource{values: values, name: name}
+	}
+	return configSources
+}
+
+type snapshotConfigSource struct {
+	values map[string]any
+	name   string
+}
+
+var _ ConfigSource = (*snapshotConfigSource)(nil)
+
+func (s *snapshotConfigSource) Retrieve(_ context.Context, selector string, paramsConfigMap *confmap.Conf, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	key, err := snapshotKey(s.name, selector, paramsConfigMap)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := s.values[key]
+	if !ok {
+		return nil, fmt.Errorf("offline mode: the config snapshot %q has no value for the selector %q of the config source %s",
+			os.Getenv(configSnapshotEnvVar), selector, s.name)
+	}
+	return confmap.NewRetrieved(value)
+}
+
+func (s *snapshotConfigSource) Shutdown(context.Context) error {
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/provider/yamlprovider"
+	"go.uber.org/zap"
+)
+
+func TestConfigSnapshotOfflineMode(t *testing.T) {
+	const rotatingConfig = "yaml:config_sources::rotating::cache::ttl: 1h\nkey: ${rotating:secret}"
+	factory := &rotatingCfgSrcFactory{value: "v1"}
+
+	// The snapshot is generated on a connected machine.
+	recorder := NewSnapshotRecorder()
+	provider := NewConfigSourceConfigMapProvider(yamlprovider.New(), zap.NewNop(), component.NewDefaultBuildInfo(),
+		[]Hook{recorder}, nil, factory)
+	retrieved, err := provider.Retrieve(context.Background(), rotatingConfig, nil)
+	require.NoError(t, err)
+	require.NoError(t, retrieved.Close(context.Background()))
+	require.NoError(t, provider.Shutdown(context.Background()))
+	path := filepath.Join(t.TempDir(), "snapshot.yaml")
+	require.NoError(t, recorder.WriteFile(path))
+
+	// The backend isn't reachable from the air-gapped site.
+	factory.setErr(errors.New("backend unreachable"))
+	t.Setenv(configSnapshotEnvVar, path)
+	provider = NewConfigSourceConfigMapProvider(yamlprovider.New(), zap.NewNop(), component.NewDefaultBuildInfo(),
+		nil, nil, factory)
+	retrieved, err = provider.Retrieve(context.Background(), rotatingConfig, nil)
+	require.NoError(t, err)
+	resolved, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"key": "v1"}, resolved)
+	require.NoError(t, retrieved.Close(context.Background()))
+
+	_, err = provider.Retrieve(context.Background(), "yaml:other: ${rotating:other}", nil)
+	assert.ErrorContains(t, err, `has no value for the selector "other" of the config source rotating`)
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	t.Setenv(configSnapshotEnvVar, filepath.Join(t.TempDir(), "missing.yaml"))
+	_, err = NewConfigSourceConfigMapProvider(yamlprovider.New(), zap.NewNop(), component.NewDefaultBuildInfo(),
+		nil, nil, factory).Retrieve(context.Background(), rotatingConfig, nil)
+	assert.ErrorContains(t, err, "failed to read the config snapshot")
+}
@@ -0,0 +1,142 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/knadh/koanf/maps"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+)
+
+// sourceSettingsEnvVar is the env var with the YAML, like the "config_sources" section of the
+// configuration, configuring the config sources retrieving the URIs of the source providers.
+const sourceSettingsEnvVar = "SPLUNK_CONFIG_SOURCES_YAML"
+
+// uriValueKey is the key of the configuration resolving the URI retrieved by a source provider.
+const uriValueKey = "value"
+
+var _ confmap.Provider = (*sourceProvider)(nil)
+
+// sourceProvider is a confmap.Provider retrieving the URIs of a config source.
+type sourceProvider struct {
+	factory Factory
+	options Options
+}
+
+// NewSourceProviders returns a confmap.Provider for each of the config source factories of the
+// options, by the type of the config source, so the config sources can be used like the providers
+// of the collector, e.g. "--config=etcd2://config/collector.yaml". Each provider retrieves the
+// "<type>://<selector>[?<params>][#<key>]" URIs: the value of the selector, retrieved with the
+// parameters of the config source, and the key, if any, extracted from it like with the path
+// parameter, e.g. "vault://secret/data/app#token".
+//
+// The config sources are configured with the SPLUNK_CONFIG_SOURCES_YAML env var, the YAML of a
+// "config_sources" section, and use their default settings if they aren't declared in it.
+func NewSourceProviders(options Options) map[string]confmap.Provider {
+	if options.Logger == nil {
+		options.Logger = zap.NewNop()
+	}
+	providers := make(map[string]confmap.Provider, len(options.Factories))
+	for _, factory := range options.Factories {
+		providers[string(factory.Type())] = &sourceProvider{factory: factory, options: options}
+	}
+	return providers
+}
+
+func (s *sourceProvider) Retrieve(ctx context.Context, uri string, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	scheme := s.Scheme()
+	if !strings.HasPrefix(uri, scheme+string(configSourceNameDelimChar)) {
+		return nil, fmt.Errorf("%q uri is not supported by %q provider", uri, scheme)
+	}
+	invocation, err := invocationFromURI(uri[len(scheme)+1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid uri %q: %w", uri, err)
+	}
+
+	settings, err := sourceSettingsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := settings[scheme]; !ok {
+		settings[scheme] = nil
+	}
+	factories, err := makeFactoryMap(s.options.Factories)
+	if err != nil {
+		return nil, err
+	}
+	configMap := confmap.NewFromStringMap(map[string]any{
+		configSourcesKey: settings,
+		uriValueKey:      "${" + scheme + string(configSourceNameDelimChar) + invocation + "}",
+	})
+	res, closeFunc, err := Resolve(ctx, configMap, s.options.Logger, s.options.BuildInfo, factories, watcher)
+	if err != nil {
+		return nil, err
+	}
+	return confmap.NewRetrieved(res[uriValueKey], confmap.WithRetrievedClose(closeFunc))
+}
+
+func (s *sourceProvider) Scheme() string {
+	return string(s.factory.Type())
+}
+
+func (s *sourceProvider) Shutdown(context.Context) error {
+	return nil
+}
+
+// sourceSettingsFromEnv returns the settings of the config sources of the source providers.
+func sourceSettingsFromEnv() (map[string]any, error) {
+	settings := map[string]any{}
+	if v := os.Getenv(sourceSettingsEnvVar); v != "" {
+		if err := yaml.Unmarshal([]byte(v), &settings); err != nil {
+			return nil, fmt.Errorf("invalid %s env var: %w", sourceSettingsEnvVar, err)
+		}
+		maps.IntfaceKeysToStrings(settings)
+	}
+	return settings, nil
+}
+
+// invocationFromURI converts the opaque part, after the scheme, of a config source URI,
+// "//<selector>[?<params>][#<key>]", to the "<selector>[?<params>]" invocation of the config
+// source, extracting the key with the path parameter.
+func invocationFromURI(opaque string) (string, error) {
+	invocation := strings.TrimPrefix(opaque, "//")
+	i := strings.LastIndexByte(invocation, '#')
+	if i < 0 {
+		return invocation, nil
+	}
+	invocation, key := invocation[:i], invocation[i+1:]
+	if key == "" {
+		return "", fmt.Errorf("empty key after %q", "#")
+	}
+	if strings.ContainsAny(key, "']") {
+		return "", fmt.Errorf("invalid key %q, it can't contain quotes nor closing brackets", key)
+	}
+	delim := "?"
+	if _, params, ok := strings.Cut(invocation, "?"); ok {
+		if values, err := url.ParseQuery(params); err == nil && values.Has(PathParam) {
+			return "", fmt.Errorf("the key can't be combined with the %s parameter", PathParam)
+		}
+		delim = "&"
+	}
+	return invocation + delim + PathParam + "=" + url.QueryEscape("$['"+key+"']"), nil
+}
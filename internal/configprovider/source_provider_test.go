@@ -0,0 +1,162 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+type mapSettings struct {
+	SourceSettings `mapstructure:",squash"`
+	Values         map[string]any `mapstructure:"values"`
+}
+
+// mapCfgSrcFactory creates config sources retrieving the values of their settings by selector.
+type mapCfgSrcFactory struct{}
+
+var _ Factory = (*mapCfgSrcFactory)(nil)
+
+func (f *mapCfgSrcFactory) Type() component.Type {
+	return "map"
+}
+
+func (f *mapCfgSrcFactory) CreateDefaultConfig() Source {
+	return &mapSettings{
+		SourceSettings: NewSourceSettings(component.NewID("map")),
+		Values:         map[string]any{"default": "default_value"},
+	}
+}
+
+func (f *mapCfgSrcFactory) CreateConfigSource(_ context.Context, _ CreateParams, cfg Source) (ConfigSource, error) {
+	valueMap := map[string]valueEntry{}
+	for k, v := range cfg.(*mapSettings).Values {
+		valueMap[k] = valueEntry{Value: v}
+	}
+	return &testConfigSource{ValueMap: valueMap}, nil
+}
+
+func TestSourceProviders(t *testing.T) {
+	providers := NewSourceProviders(Options{Factories: []Factory{&mapCfgSrcFactory{}}})
+	require.Len(t, providers, 1)
+	provider := providers["map"]
+	require.NotNil(t, provider)
+	assert.Equal(t, "map", provider.Scheme())
+
+	retrieved, err := provider.Retrieve(context.Background(), "map://default", nil)
+	require.NoError(t, err)
+	value, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, "default_value", value)
+
+	t.Setenv(sourceSettingsEnvVar, `
+map:
+  values:
+    config: |
+      receivers:
+        otlp:
+    secret:
+      token: s3cr3t
+      port: 8080
+`)
+	retrieved, err = provider.Retrieve(context.Background(), "map://config", nil)
+	require.NoError(t, err)
+	conf, err := retrieved.AsConf()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"receivers": map[string]any{"otlp": nil}}, conf.ToStringMap())
+
+	retrieved, err = provider.Retrieve(context.Background(), "map://secret#port", nil)
+	require.NoError(t, err)
+	value, err = retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, 8080, value)
+
+	_, err = provider.Retrieve(context.Background(), "map://missing", nil)
+	assert.ErrorContains(t, err, `no value for selector "missing"`)
+
+	_, err = provider.Retrieve(context.Background(), "vault://secret", nil)
+	assert.EqualError(t, err, `"vault://secret" uri is not supported by "map" provider`)
+
+	assert.NoError(t, provider.Shutdown(context.Background()))
+}
+
+func TestSourceProvidersInvalidSettings(t *testing.T) {
+	t.Setenv(sourceSettingsEnvVar, "map: [")
+	provider := NewSourceProviders(Options{Factories: []Factory{&mapCfgSrcFactory{}}})["map"]
+	_, err := provider.Retrieve(context.Background(), "map://default", nil)
+	assert.ErrorContains(t, err, "invalid SPLUNK_CONFIG_SOURCES_YAML env var")
+}
+
+func TestInvocationFromURI(t *testing.T) {
+	tests := []struct {
+		opaque     string
+		invocation string
+		err        string
+	}{
+		{opaque: "//secret/data/app", invocation: "secret/data/app"},
+		{opaque: "secret/data/app", invocation: "secret/data/app"},
+		{opaque: "//secret/data/app#token", invocation: "secret/data/app?path=%24%5B%27token%27%5D"},
+		{opaque: "//secret/data/app?timeout=1s#token", invocation: "secret/data/app?timeout=1s&path=%24%5B%27token%27%5D"},
+		{opaque: "//secret/data/app#", err: `empty key after "#"`},
+		{opaque: "//secret/data/app#a']", err: `invalid key "a']", it can't contain quotes nor closing brackets`},
+		{opaque: "//secret/data/app?path=$.data#token", err: "the key can't be combined with the path parameter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.opaque, func(t *testing.T) {
+			invocation, err := invocationFromURI(tt.opaque)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.invocation, invocation)
+		})
+	}
+}
+
+func TestConfigSourceManagerURIInvocation(t *testing.T) {
+	cfgSources := map[string]ConfigSource{
+		"tstcfgsrc": &testConfigSource{
+			ValueMap: map[string]valueEntry{
+				"secret": {Value: map[string]any{"token": "s3cr3t", "app.name": "otel"}},
+			},
+		},
+	}
+	res, closeFunc, err := resolve(context.Background(), cfgSources, confmap.NewFromStringMap(map[string]any{
+		"token":   "${tstcfgsrc://secret#token}",
+		"name":    "${tstcfgsrc://secret#app.name}",
+		"legacy":  "${tstcfgsrc:secret?path=$.token}",
+		"default": "${tstcfgsrc://secret#missing|-none}",
+	}), nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"token":   "s3cr3t",
+		"name":    "otel",
+		"legacy":  "s3cr3t",
+		"default": "none",
+	}, res)
+	assert.NoError(t, callClose(closeFunc))
+
+	_, _, err = resolve(context.Background(), cfgSources, confmap.NewFromStringMap(map[string]any{
+		"invalid": "${tstcfgsrc://secret#}",
+	}), nil)
+	assert.ErrorContains(t, err, `invalid uri for config source "tstcfgsrc"`)
+}
@@ -0,0 +1,92 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"fmt"
+
+	"github.com/spf13/cast"
+	"go.opentelemetry.io/collector/confmap"
+	"gopkg.in/yaml.v2"
+)
+
+// SpliceParam is the parameter, handled by the config provider for every config source, that
+// splices the elements of the retrieved list into the enclosing YAML sequence instead of
+// nesting the list as a single element.
+const SpliceParam = "splice"
+
+// splicedList is a retrieved list whose elements are spliced into the enclosing sequence.
+type splicedList []any
+
+// cutSpliceParam removes the splice parameter from the parameters of an invocation and
+// returns its value.
+func cutSpliceParam(paramsConfigMap *confmap.Conf) (*confmap.Conf, bool, error) {
+	paramsConfigMap, value, found := cutParam(paramsConfigMap, SpliceParam)
+	if !found {
+		return paramsConfigMap, false, nil
+	}
+	splice, ok := value.(bool)
+	if !ok {
+		return nil, false, fmt.Errorf("invalid %s parameter %v, must be true or false", SpliceParam, value)
+	}
+	return paramsConfigMap, splice, nil
+}
+
+// spliceValue returns the retrieved value as a list to be spliced if splice is set. Strings and
+// byte slices are parsed as YAML first, any value other than a list is spliced as a single element,
+// and a nil value, e.g. from an optional invocation, splices no elements.
+func spliceValue(value any, splice bool) any {
+	if !splice {
+		return value
+	}
+
+	switch v := value.(type) {
+	case string:
+		_ = yaml.Unmarshal([]byte(v), &value)
+	case []byte:
+		if err := yaml.Unmarshal(v, &value); err != nil {
+			value = string(v)
+		}
+	}
+	if mapIFace, ok := value.(map[any]any); ok {
+		value = cast.ToStringMap(mapIFace)
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return splicedList{}
+	case []any:
+		return splicedList(v)
+	default:
+		return splicedList{v}
+	}
+}
+
+// unwrapValue returns the spliced lists and merged maps found where they can't be spliced
+// or merged as regular lists and maps, and the strings retrieved with the string type hint
+// as regular strings.
+func unwrapValue(value any) any {
+	switch v := value.(type) {
+	case splicedList:
+		return []any(v)
+	case mergedMap:
+		return map[string]any(v)
+	case typedString:
+		return string(v)
+	default:
+		return value
+	}
+}
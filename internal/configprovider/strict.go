@@ -0,0 +1,74 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// strictResolutionEnvVar is the env var enabling the strict resolution of the configuration,
+// failing on the references to env vars that aren't defined instead of replacing them with
+// an empty string, and reporting the key of the configuration in which any reference failed.
+const strictResolutionEnvVar = "SPLUNK_CONFIG_STRICT_RESOLUTION"
+
+type strictCtxKey struct{}
+
+func strictResolution() bool {
+	v, err := strconv.ParseBool(strings.ToLower(os.Getenv(strictResolutionEnvVar)))
+	return err == nil && v
+}
+
+// contextWithStrict returns a copy of ctx enabling the strict resolution of the configuration.
+func contextWithStrict(ctx context.Context) context.Context {
+	return context.WithValue(ctx, strictCtxKey{}, true)
+}
+
+func isStrict(ctx context.Context) bool {
+	strict, _ := ctx.Value(strictCtxKey{}).(bool)
+	return strict
+}
+
+// checkEnvVarReference returns an error if the reference to the env var, consuming w bytes
+// of s, can't be resolved. Use only in strict mode, see osExpandEnv for the lenient handling.
+func checkEnvVarReference(name string, w int, s string) error {
+	switch {
+	case name == "" && w > 0:
+		return fmt.Errorf("invalid env var reference %q", s)
+	case name == "" || name == "$":
+		return nil
+	}
+	if _, ok := os.LookupEnv(name); !ok {
+		return fmt.Errorf("env var %q referenced by %q is not defined", name, s)
+	}
+	return nil
+}
+
+// keyError adds the key of the configuration to the error of its resolution, in strict mode.
+// The errors of the keys nested in a config source invocation, e.g. its parameters, are
+// reported for the key enclosing them.
+func keyError(ctx context.Context, key string, err error) error {
+	if !isStrict(ctx) {
+		return err
+	}
+	if _, nested := keyFromContext(ctx); nested {
+		return err
+	}
+	return fmt.Errorf("failed to resolve key %q: %w", key, err)
+}
@@ -0,0 +1,146 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+var (
+	configSourceKey = tag.MustNewKey("config_source")
+
+	mRetrieves = stats.Int64(
+		"configprovider/retrieves", "Number of values retrieved from the config sources", stats.UnitDimensionless)
+	mRetrieveErrors = stats.Int64(
+		"configprovider/retrieve_errors", "Number of failed retrieves from the config sources", stats.UnitDimensionless)
+	mRetrieveLatency = stats.Float64(
+		"configprovider/retrieve_latency", "Time taken to retrieve a value from the config sources", stats.UnitMilliseconds)
+	mActiveWatchers = stats.Int64(
+		"configprovider/active_watchers", "Number of retrieved values being watched for updates", stats.UnitDimensionless)
+	mResolutions = stats.Int64(
+		"configprovider/resolutions", "Number of resolutions of the configuration", stats.UnitDimensionless)
+	mRollbacks = stats.Int64(
+		"configprovider/rollbacks", "Number of reloads rolled back to the last known good configuration", stats.UnitDimensionless)
+
+	watchers = &watcherCounts{counts: map[string]int64{}}
+)
+
+// MetricViews returns the views of the metrics about the config sources, they must be
+// registered, e.g. with view.Register, to be reported by the collector own telemetry.
+func MetricViews() []*view.View {
+	tagKeys := []tag.Key{configSourceKey}
+	return []*view.View{
+		{
+			Name:        mRetrieves.Name(),
+			Description: mRetrieves.Description(),
+			Measure:     mRetrieves,
+			TagKeys:     tagKeys,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        mRetrieveErrors.Name(),
+			Description: mRetrieveErrors.Description(),
+			Measure:     mRetrieveErrors,
+			TagKeys:     tagKeys,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        mRetrieveLatency.Name(),
+			Description: mRetrieveLatency.Description(),
+			Measure:     mRetrieveLatency,
+			TagKeys:     tagKeys,
+			Aggregation: view.Distribution(1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000),
+		},
+		{
+			Name:        mActiveWatchers.Name(),
+			Description: mActiveWatchers.Description(),
+			Measure:     mActiveWatchers,
+			TagKeys:     tagKeys,
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        mResolutions.Name(),
+			Description: mResolutions.Description(),
+			Measure:     mResolutions,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        mRollbacks.Name(),
+			Description: mRollbacks.Description(),
+			Measure:     mRollbacks,
+			Aggregation: view.Sum(),
+		},
+	}
+}
+
+// recordRetrieve records the result and the latency of a retrieve of the config source.
+func recordRetrieve(ctx context.Context, name string, latency time.Duration, err error) {
+	measurements := []stats.Measurement{
+		mRetrieves.M(1),
+		mRetrieveLatency.M(float64(latency) / float64(time.Millisecond)),
+	}
+	if err != nil {
+		measurements = append(measurements, mRetrieveErrors.M(1))
+	}
+	_ = stats.RecordWithTags(ctx, []tag.Mutator{tag.Upsert(configSourceKey, name)}, measurements...)
+}
+
+// recordResolution records a resolution of the configuration.
+func recordResolution(ctx context.Context) {
+	stats.Record(ctx, mResolutions.M(1))
+}
+
+// watcherCounts tracks the number of retrieved values being watched for each config source.
+type watcherCounts struct {
+	counts map[string]int64
+	mutex  sync.Mutex
+}
+
+func (w *watcherCounts) add(ctx context.Context, name string, delta int64) {
+	w.mutex.Lock()
+	w.counts[name] += delta
+	count := w.counts[name]
+	w.mutex.Unlock()
+	_ = stats.RecordWithTags(ctx, []tag.Mutator{tag.Upsert(configSourceKey, name)}, mActiveWatchers.M(count))
+}
+
+// ActiveWatchers returns the number of retrieved values being watched for updates for each config source.
+func ActiveWatchers() map[string]int64 {
+	watchers.mutex.Lock()
+	defer watchers.mutex.Unlock()
+	counts := make(map[string]int64, len(watchers.counts))
+	for name, count := range watchers.counts {
+		counts[name] = count
+	}
+	return counts
+}
+
+// trackWatcher counts the retrieved value as watched until closeFunc is called.
+func trackWatcher(name string, closeFunc confmap.CloseFunc) confmap.CloseFunc {
+	watchers.add(context.Background(), name, 1)
+	var once sync.Once
+	return func(ctx context.Context) error {
+		once.Do(func() { watchers.add(context.Background(), name, -1) })
+		return closeFunc(ctx)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+func TestConfigSourceTelemetry(t *testing.T) {
+	views := MetricViews()
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	cfgSources := map[string]ConfigSource{
+		"watched": &testConfigSource{
+			ValueMap: map[string]valueEntry{
+				"selector": {Value: "value", WatchForUpdateCh: make(chan error)},
+			},
+		},
+		"failing": &testConfigSource{ErrOnRetrieve: NotFoundError(errors.New("no such secret"))},
+	}
+	res, closeFunc, err := resolve(context.Background(), cfgSources, confmap.NewFromStringMap(map[string]any{
+		"ok":       "${watched:selector}",
+		"fallback": "${failing:selector|-default}",
+	}), func(*confmap.ChangeEvent) {})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"ok": "value", "fallback": "default"}, res)
+
+	sumByConfigSource := func(viewName string) map[string]float64 {
+		rows, retrieveErr := view.RetrieveData(viewName)
+		require.NoError(t, retrieveErr)
+		sums := map[string]float64{}
+		for _, row := range rows {
+			switch data := row.Data.(type) {
+			case *view.SumData:
+				sums[configSourceTag(row.Tags)] = data.Value
+			case *view.LastValueData:
+				sums[configSourceTag(row.Tags)] = data.Value
+			case *view.DistributionData:
+				sums[configSourceTag(row.Tags)] = float64(data.Count)
+			}
+		}
+		return sums
+	}
+
+	assert.Equal(t, map[string]float64{"watched": 1, "failing": 1}, sumByConfigSource(mRetrieves.Name()))
+	assert.Equal(t, map[string]float64{"watched": 1, "failing": 1}, sumByConfigSource(mRetrieveLatency.Name()))
+	// Falling back to the default value doesn't hide the failure of the config source.
+	assert.Equal(t, map[string]float64{"failing": 1}, sumByConfigSource(mRetrieveErrors.Name()))
+	assert.Equal(t, map[string]float64{"watched": 1}, sumByConfigSource(mActiveWatchers.Name()))
+
+	require.NoError(t, callClose(closeFunc))
+	assert.Equal(t, map[string]float64{"watched": 0}, sumByConfigSource(mActiveWatchers.Name()))
+}
+
+func configSourceTag(tags []tag.Tag) string {
+	for _, t := range tags {
+		if t.Key == configSourceKey {
+			return t.Value
+		}
+	}
+	return ""
+}
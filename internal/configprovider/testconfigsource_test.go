@@ -53,7 +53,7 @@ func (t *testConfigSource) Retrieve(ctx context.Context, selector string, params
 
 	entry, ok := t.ValueMap[selector]
 	if !ok {
-		return nil, fmt.Errorf("no value for selector %q", selector)
+		return nil, NotFoundError(fmt.Errorf("no value for selector %q", selector))
 	}
 
 	if entry.WatchForUpdateCh != nil {
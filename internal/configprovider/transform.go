@@ -0,0 +1,123 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// TransformParam is the parameter, handled by the config provider for every config source,
+// with the comma separated list of transforms applied in order to the retrieved value.
+const TransformParam = "transform"
+
+// transformFunc transforms a retrieved string value.
+type transformFunc func(string) (string, error)
+
+var transforms = map[string]transformFunc{
+	"base64decode": func(s string) (string, error) {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+		return string(decoded), err
+	},
+	"base64encode": func(s string) (string, error) {
+		return base64.StdEncoding.EncodeToString([]byte(s)), nil
+	},
+	"trim": func(s string) (string, error) {
+		return strings.TrimSpace(s), nil
+	},
+	"trimnewline": func(s string) (string, error) {
+		return strings.TrimRight(s, "\r\n"), nil
+	},
+	"lower": func(s string) (string, error) {
+		return strings.ToLower(s), nil
+	},
+	"upper": func(s string) (string, error) {
+		return strings.ToUpper(s), nil
+	},
+}
+
+// cutTransformParam removes the transform parameter from the parameters of an invocation and
+// returns the names of the transforms. The transforms can be given as a comma separated list
+// or, e.g. repeating the parameter or in multi-line invocations, as a list.
+func cutTransformParam(paramsConfigMap *confmap.Conf) (*confmap.Conf, []string, error) {
+	paramsConfigMap, value, found := cutParam(paramsConfigMap, TransformParam)
+	if !found {
+		return paramsConfigMap, nil, nil
+	}
+
+	var names []string
+	switch v := value.(type) {
+	case string:
+		names = strings.Split(v, ",")
+	case []any:
+		for _, elem := range v {
+			name, ok := elem.(string)
+			if !ok {
+				return nil, nil, fmt.Errorf("invalid %s parameter %v, must be a list of transform names", TransformParam, value)
+			}
+			names = append(names, strings.Split(name, ",")...)
+		}
+	default:
+		return nil, nil, fmt.Errorf("invalid %s parameter %v, must be a list of transform names", TransformParam, value)
+	}
+
+	for i, name := range names {
+		names[i] = strings.TrimSpace(name)
+		if _, ok := transforms[names[i]]; !ok {
+			return nil, nil, fmt.Errorf("unknown transform %q, must be one of %s", names[i], strings.Join(transformNames(), ", "))
+		}
+	}
+	return paramsConfigMap, names, nil
+}
+
+// applyTransforms applies the named transforms, in order, to the retrieved value. Only string
+// and byte slice values, which are transformed as strings, are supported.
+func applyTransforms(value any, names []string) (any, error) {
+	if len(names) == 0 || value == nil {
+		return value, nil
+	}
+
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return nil, fmt.Errorf("transforms can only be applied to strings, got a %T", value)
+	}
+
+	var err error
+	for _, name := range names {
+		if s, err = transforms[name](s); err != nil {
+			return nil, fmt.Errorf("transform %q failed: %w", name, err)
+		}
+	}
+	return s, nil
+}
+
+func transformNames() []string {
+	names := make([]string, 0, len(transforms))
+	for name := range transforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,134 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cast"
+	"go.opentelemetry.io/collector/confmap"
+	"gopkg.in/yaml.v2"
+)
+
+// TypeParam is the parameter, handled by the config provider for every config source, with the
+// type the retrieved value is converted to before being injected into the configuration.
+const TypeParam = "type"
+
+// typedString is a string retrieved with the "string" type hint, so it isn't parsed as YAML
+// when it is the whole value of a key.
+type typedString string
+
+// typeHintFunc converts the string representation of a retrieved value.
+type typeHintFunc func(string) (any, error)
+
+var typeHints = map[string]typeHintFunc{
+	"int": func(s string) (any, error) {
+		i, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		return int(i), err
+	},
+	"bool": func(s string) (any, error) {
+		return strconv.ParseBool(strings.TrimSpace(s))
+	},
+	"float": func(s string) (any, error) {
+		return strconv.ParseFloat(strings.TrimSpace(s), 64)
+	},
+	"string": func(s string) (any, error) {
+		return typedString(s), nil
+	},
+	"yaml": func(s string) (any, error) {
+		var parsed any
+		if err := yaml.Unmarshal([]byte(s), &parsed); err != nil {
+			return nil, err
+		}
+		if m, ok := parsed.(map[any]any); ok {
+			// yaml.Unmarshal returns map[any]any but confmap uses map[string]any.
+			return cast.ToStringMap(m), nil
+		}
+		return parsed, nil
+	},
+}
+
+// cutTypeParam removes the type parameter from the parameters of an invocation and returns its value.
+func cutTypeParam(paramsConfigMap *confmap.Conf) (*confmap.Conf, string, error) {
+	paramsConfigMap, value, found := cutParam(paramsConfigMap, TypeParam)
+	if !found {
+		return paramsConfigMap, "", nil
+	}
+	hint, ok := value.(string)
+	if _, known := typeHints[hint]; !ok || !known {
+		return nil, "", fmt.Errorf("invalid %s parameter %v, must be one of %s", TypeParam, value, strings.Join(typeHintNames(), ", "))
+	}
+	return paramsConfigMap, hint, nil
+}
+
+// applyTypeHint converts the retrieved value to the type of the hint. Values that aren't strings
+// are converted from their string representation, except for the "yaml" hint that keeps them,
+// and the elements of lists and maps, e.g. the variables retrieved with a prefix, are converted
+// one by one.
+func applyTypeHint(value any, hint string) (any, error) {
+	if hint == "" || value == nil {
+		return value, nil
+	}
+
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case []any:
+		converted := make([]any, len(v))
+		for i, elem := range v {
+			var err error
+			if converted[i], err = applyTypeHint(elem, hint); err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		return converted, nil
+	case map[string]any:
+		converted := make(map[string]any, len(v))
+		for k, elem := range v {
+			var err error
+			if converted[k], err = applyTypeHint(elem, hint); err != nil {
+				return nil, fmt.Errorf("key %q: %w", k, err)
+			}
+		}
+		return converted, nil
+	default:
+		if hint == "yaml" {
+			return value, nil
+		}
+		s = fmt.Sprint(value)
+	}
+
+	converted, err := typeHints[hint](s)
+	if err != nil {
+		return nil, fmt.Errorf("can't convert %q to %s: %w", s, hint, err)
+	}
+	return converted, nil
+}
+
+func typeHintNames() []string {
+	names := make([]string, 0, len(typeHints))
+	for name := range typeHints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
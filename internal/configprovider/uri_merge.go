@@ -0,0 +1,69 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// URIMerger merges the configurations retrieved for the URIs of the collector, e.g. the values
+// of the "--config" flags, in the order they are retrieved, the keys of the later URIs taking
+// precedence like in the collector. Shared by the config providers of the different schemes, see
+// Options.URIMerger, the config sources of each URI are resolved against the configuration merged
+// from it and the URIs before it, whatever their schemes, e.g. "--config=file:sources.yaml
+// --config=env:COLLECTOR_CONFIG" declares the config sources used by the env var configuration.
+type URIMerger struct {
+	confs map[string]*confmap.Conf
+	uris  []string
+	mu    sync.Mutex
+}
+
+// NewURIMerger creates a URIMerger to share between the config providers of the collector.
+func NewURIMerger() *URIMerger {
+	return &URIMerger{confs: map[string]*confmap.Conf{}}
+}
+
+// merge records the configuration retrieved for the uri and returns the configuration merged
+// from the URIs retrieved up to it, with the URI each of its keys comes from. The URIs
+// retrieved again, e.g. on reloads, keep their order.
+func (m *URIMerger) merge(uri string, conf *confmap.Conf) (*confmap.Conf, map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.confs[uri]; !ok {
+		m.uris = append(m.uris, uri)
+	}
+	m.confs[uri] = conf
+
+	merged := confmap.New()
+	keyOrigins := map[string]string{}
+	for _, u := range m.uris {
+		if err := merged.Merge(m.confs[u]); err != nil {
+			return nil, nil, fmt.Errorf("failed to merge the configuration of %q: %w", u, err)
+		}
+		for _, k := range m.confs[u].AllKeys() {
+			keyOrigins[k] = u
+		}
+		if u == uri {
+			// The URIs after it are merged when they are retrieved again.
+			break
+		}
+	}
+	return merged, keyOrigins, nil
+}
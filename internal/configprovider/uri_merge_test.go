@@ -0,0 +1,67 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/provider/envprovider"
+	"go.opentelemetry.io/collector/confmap/provider/yamlprovider"
+)
+
+func retrieveRaw(t *testing.T, provider confmap.Provider, uri string) any {
+	retrieved, err := provider.Retrieve(context.Background(), uri, nil)
+	require.NoError(t, err)
+	raw, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	require.NoError(t, retrieved.Close(context.Background()))
+	return raw
+}
+
+func TestURIMergerSharedBetweenSchemes(t *testing.T) {
+	t.Setenv("URI_MERGER_TEST_CONFIG", "key: ${rotating:secret}\nother: env")
+	options := Options{
+		Factories: []Factory{&rotatingCfgSrcFactory{value: "v1"}},
+		URIMerger: NewURIMerger(),
+	}
+	yamlProvider := New(yamlprovider.New(), options)
+	envProvider := New(envprovider.New(), options)
+
+	assert.Equal(t, map[string]any{"other": "yaml"},
+		retrieveRaw(t, yamlProvider, "yaml:config_sources::rotating::cache::ttl: 1h\nother: yaml"))
+	// The config sources declared by the previous URI are used by the env var configuration.
+	assert.Equal(t, map[string]any{"key": "v1", "other": "env"},
+		retrieveRaw(t, envProvider, "env:URI_MERGER_TEST_CONFIG"))
+	// The later URIs take precedence whatever their schemes.
+	assert.Equal(t, map[string]any{"key": "v1", "other": "last"},
+		retrieveRaw(t, yamlProvider, "yaml:other: last"))
+
+	require.NoError(t, yamlProvider.Shutdown(context.Background()))
+	require.NoError(t, envProvider.Shutdown(context.Background()))
+}
+
+func TestURIMergerOfScheme(t *testing.T) {
+	provider := New(yamlprovider.New(), Options{})
+	assert.Equal(t, map[string]any{"a": 1, "b": 1}, retrieveRaw(t, provider, "yaml:{a: 1, b: 1}"))
+	assert.Equal(t, map[string]any{"a": 1, "b": 2}, retrieveRaw(t, provider, "yaml:b: 2"))
+	// The URIs retrieved again, e.g. on reloads, keep their order.
+	assert.Equal(t, map[string]any{"a": 1, "b": 1}, retrieveRaw(t, provider, "yaml:{a: 1, b: 1}"))
+	require.NoError(t, provider.Shutdown(context.Background()))
+}
@@ -0,0 +1,184 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+const (
+	// maxWatchWorkers bounds the goroutines waiting for the channels of all the watches.
+	maxWatchWorkers = 16
+	// watchesPerWorker is the number of watches a worker selects on before another one is started,
+	// once maxWatchWorkers are running the watches are spread among them.
+	watchesPerWorker = 256
+)
+
+// WatchChannel calls fn with the first value received from ch, or with ok false if ch is closed,
+// unless the returned stop func is called first. Instead of a goroutine per watch, the waits of all
+// the watches are multiplexed onto a bounded set of goroutines, so the config sources can watch
+// thousands of values. fn is called at most once, on a goroutine of its own.
+func WatchChannel[T any](ch <-chan T, fn func(value T, ok bool)) (stop func()) {
+	if ch == nil {
+		// A nil channel is never ready.
+		return func() {}
+	}
+	watch := &poolWatch{
+		ch: reflect.ValueOf(ch),
+		fn: func(value reflect.Value, ok bool) {
+			var v T
+			if ok {
+				v, _ = value.Interface().(T)
+			}
+			fn(v, ok)
+		},
+	}
+	defaultWatchPool.add(watch)
+	return func() {
+		defaultWatchPool.remove(watch)
+	}
+}
+
+// WatchAfter calls fn after the duration d unless done is closed first. Like WatchChannel no
+// goroutine is waiting for either of them.
+func WatchAfter(d time.Duration, done <-chan struct{}, fn func()) {
+	var mutex sync.Mutex
+	fired := false
+	var stopWatch func()
+	timer := time.AfterFunc(d, func() {
+		mutex.Lock()
+		fired = true
+		if stopWatch != nil {
+			stopWatch()
+		}
+		mutex.Unlock()
+		fn()
+	})
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if !fired {
+		stopWatch = WatchChannel(done, func(struct{}, bool) {
+			timer.Stop()
+		})
+	}
+}
+
+var defaultWatchPool = &watchPool{}
+
+// watchPool spreads the watches among its workers, each one selecting on the channels of its watches.
+type watchPool struct {
+	mutex   sync.Mutex
+	workers []*watchWorker
+}
+
+type poolWatch struct {
+	ch     reflect.Value
+	fn     func(value reflect.Value, ok bool)
+	worker *watchWorker
+}
+
+// watchWorker is a goroutine, running while it has watches, selecting on their channels and on
+// wake, notified once its watches change.
+type watchWorker struct {
+	pool    *watchPool
+	wake    chan struct{}
+	watches map[*poolWatch]struct{}
+	running bool
+}
+
+func (p *watchPool) add(watch *poolWatch) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	worker := p.leastLoadedWorker()
+	worker.watches[watch] = struct{}{}
+	watch.worker = worker
+	if worker.running {
+		worker.notify()
+		return
+	}
+	worker.running = true
+	go worker.run()
+}
+
+// remove removes the watch, it returns false if the watch was already removed.
+func (p *watchPool) remove(watch *poolWatch) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if _, ok := watch.worker.watches[watch]; !ok {
+		return false
+	}
+	delete(watch.worker.watches, watch)
+	watch.worker.notify()
+	return true
+}
+
+func (p *watchPool) leastLoadedWorker() *watchWorker {
+	var leastLoaded *watchWorker
+	for _, worker := range p.workers {
+		if leastLoaded == nil || len(worker.watches) < len(leastLoaded.watches) {
+			leastLoaded = worker
+		}
+	}
+	if leastLoaded == nil || (len(leastLoaded.watches) >= watchesPerWorker && len(p.workers) < maxWatchWorkers) {
+		leastLoaded = &watchWorker{
+			pool:    p,
+			wake:    make(chan struct{}, 1),
+			watches: map[*poolWatch]struct{}{},
+		}
+		p.workers = append(p.workers, leastLoaded)
+	}
+	return leastLoaded
+}
+
+func (w *watchWorker) notify() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *watchWorker) run() {
+	for {
+		w.pool.mutex.Lock()
+		if len(w.watches) == 0 {
+			w.running = false
+			w.pool.mutex.Unlock()
+			return
+		}
+		watches := make([]*poolWatch, 0, len(w.watches))
+		cases := make([]reflect.SelectCase, 1, len(w.watches)+1)
+		cases[0] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(w.wake)}
+		for watch := range w.watches {
+			watches = append(watches, watch)
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: watch.ch})
+		}
+		w.pool.mutex.Unlock()
+
+		chosen, value, ok := reflect.Select(cases)
+		if chosen == 0 {
+			// The watches changed.
+			continue
+		}
+		if watch := watches[chosen-1]; w.pool.remove(watch) {
+			go watch.fn(value, ok)
+		}
+	}
+}
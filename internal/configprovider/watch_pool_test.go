@@ -0,0 +1,85 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchChannel(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+
+	const watches = 5000
+	chs := make([]chan int, watches)
+	stops := make([]func(), watches)
+	var fired, closed atomic.Int64
+	for i := range chs {
+		chs[i] = make(chan int, 1)
+		stops[i] = WatchChannel[int](chs[i], func(value int, ok bool) {
+			if !ok {
+				closed.Add(1)
+				return
+			}
+			fired.Add(value)
+		})
+	}
+	// The watches share a bounded set of goroutines instead of one each.
+	assert.LessOrEqual(t, runtime.NumGoroutine()-goroutines, maxWatchWorkers)
+
+	// Only the first value of each watch is received.
+	chs[0] <- 1
+	require.Eventually(t, func() bool { return fired.Load() == 1 }, 5*time.Second, time.Millisecond)
+	chs[0] <- 1
+	chs[1] <- 2
+	require.Eventually(t, func() bool { return fired.Load() == 3 }, 5*time.Second, time.Millisecond)
+	close(chs[2])
+	require.Eventually(t, func() bool { return closed.Load() == 1 }, 5*time.Second, time.Millisecond)
+
+	// The stopped watches aren't notified and the workers exit once there are no watches left.
+	for i := range chs {
+		stops[i]()
+	}
+	chs[3] <- 4
+	close(chs[4])
+	require.Eventually(t, func() bool { return runtime.NumGoroutine() <= goroutines }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, int64(3), fired.Load())
+	assert.Equal(t, int64(1), closed.Load())
+
+	// Stopping a nil channel watch, or a watch twice, is a no-op.
+	WatchChannel[int](nil, func(int, bool) { t.Fatal("must not be called") })()
+	stops[0]()
+}
+
+func TestWatchAfter(t *testing.T) {
+	firedCh := make(chan struct{})
+	WatchAfter(time.Millisecond, make(chan struct{}), func() { close(firedCh) })
+	select {
+	case <-firedCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the watch didn't fire")
+	}
+
+	done := make(chan struct{})
+	WatchAfter(50*time.Millisecond, done, func() { t.Error("must not be called after done is closed") })
+	close(done)
+	time.Sleep(100 * time.Millisecond)
+}
@@ -0,0 +1,31 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ageconfigsource
+
+import "github.com/signalfx/splunk-otel-collector/internal/configprovider"
+
+// Config holds the configuration for the creation of age config source objects.
+type Config struct {
+	configprovider.SourceSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+	// IdentityFile is the path to the file holding the age identities, i.e. the
+	// private keys, used to decrypt the values. It uses the same format as the
+	// files used with the "-i" flag of the age CLI.
+	IdentityFile string `mapstructure:"identity_file"`
+}
+
+func (*Config) Validate() error {
+	return nil
+}
@@ -0,0 +1,67 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ageconfigsource
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+const (
+	// The "type" of age config sources in configuration.
+	typeStr = "age"
+)
+
+// Private error types to help with testability.
+type (
+	errMissingIdentityFile struct{ error }
+)
+
+type ageFactory struct{}
+
+func (a *ageFactory) Type() component.Type {
+	return typeStr
+}
+
+// Sensitive reports that the decrypted age values are sensitive, so they are redacted.
+func (a *ageFactory) Sensitive() bool {
+	return true
+}
+
+func (a *ageFactory) CreateDefaultConfig() configprovider.Source {
+	return &Config{
+		SourceSettings: configprovider.NewSourceSettings(component.NewID(typeStr)),
+	}
+}
+
+func (a *ageFactory) CreateConfigSource(_ context.Context, params configprovider.CreateParams, cfg configprovider.Source) (configprovider.ConfigSource, error) {
+	ageCfg := cfg.(*Config)
+
+	if ageCfg.IdentityFile == "" {
+		return nil, &errMissingIdentityFile{errors.New("cannot decrypt values without an identity_file")}
+	}
+
+	return newConfigSource(params, ageCfg)
+}
+
+// NewFactory creates a factory for age ConfigSource objects.
+func NewFactory() configprovider.Factory {
+	return &ageFactory{}
+}
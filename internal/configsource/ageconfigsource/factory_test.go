@@ -0,0 +1,80 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ageconfigsource
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestAgeFactory_CreateConfigSource(t *testing.T) {
+	factory := NewFactory()
+	assert.Equal(t, component.Type("age"), factory.Type())
+	createParams := configprovider.CreateParams{
+		Logger: zap.NewNop(),
+	}
+	tests := []struct {
+		config  *Config
+		wantErr error
+		name    string
+	}{
+		{
+			name:    "missing_identity_file",
+			config:  &Config{},
+			wantErr: &errMissingIdentityFile{},
+		},
+		{
+			name: "nonexistent_identity_file",
+			config: &Config{
+				IdentityFile: path.Join("testdata", "nonexistent.txt"),
+			},
+			wantErr: &errInvalidIdentityFile{},
+		},
+		{
+			name: "invalid_identity_file",
+			config: &Config{
+				IdentityFile: path.Join("testdata", "config.yaml"),
+			},
+			wantErr: &errInvalidIdentityFile{},
+		},
+		{
+			name: "valid",
+			config: &Config{
+				IdentityFile: path.Join("testdata", "identity.txt"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := factory.CreateConfigSource(context.Background(), createParams, tt.config)
+			if tt.wantErr != nil {
+				require.IsType(t, tt.wantErr, err)
+				assert.Nil(t, actual)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, actual)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ageconfigsource
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+// armorColumnsPerLine is the number of columns of the base64 lines on armored
+// age payloads, the armor reader rejects payloads with lines of different lengths.
+const armorColumnsPerLine = 64
+
+// Private error types to help with testability.
+type (
+	errInvalidIdentityFile struct{ error }
+	errInvalidCiphertext   struct{ error }
+	errDecrypt             struct{ error }
+)
+
+// ageConfigSource implements the configprovider.ConfigSource interface.
+type ageConfigSource struct {
+	identities []age.Identity
+}
+
+func newConfigSource(_ configprovider.CreateParams, cfg *Config) (configprovider.ConfigSource, error) {
+	identityFile, err := os.Open(cfg.IdentityFile)
+	if err != nil {
+		return nil, &errInvalidIdentityFile{fmt.Errorf("failed to open identity file %q: %w", cfg.IdentityFile, err)}
+	}
+	defer identityFile.Close()
+
+	identities, err := age.ParseIdentities(identityFile)
+	if err != nil {
+		return nil, &errInvalidIdentityFile{fmt.Errorf("failed to parse identity file %q: %w", cfg.IdentityFile, err)}
+	}
+
+	return &ageConfigSource{
+		identities: identities,
+	}, nil
+}
+
+// Retrieve decrypts the age armored ciphertext given as selector. Since the selector
+// of a config source invocation is a single line the line breaks of the armored
+// ciphertext can be replaced by spaces, e.g. by using a YAML folded scalar.
+func (a *ageConfigSource) Retrieve(_ context.Context, selector string, _ *confmap.Conf, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	ciphertext, err := normalizeArmor(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	decrypter, err := age.Decrypt(armor.NewReader(strings.NewReader(ciphertext)), a.identities...)
+	if err != nil {
+		return nil, &errDecrypt{fmt.Errorf("failed to decrypt value: %w", err)}
+	}
+
+	var plaintext bytes.Buffer
+	if _, err = io.Copy(&plaintext, decrypter); err != nil {
+		return nil, &errDecrypt{fmt.Errorf("failed to read decrypted value: %w", err)}
+	}
+
+	return confmap.NewRetrieved(plaintext.String())
+}
+
+func (a *ageConfigSource) Shutdown(context.Context) error {
+	return nil
+}
+
+// normalizeArmor rebuilds the armored ciphertext in the canonical format expected
+// by the armor reader regardless of the whitespace used to separate its lines.
+func normalizeArmor(armored string) (string, error) {
+	payload := strings.TrimSpace(armored)
+	if !strings.HasPrefix(payload, armor.Header) || !strings.HasSuffix(payload, armor.Footer) {
+		return "", &errInvalidCiphertext{fmt.Errorf("value must be an age armored ciphertext starting with %q and ending with %q", armor.Header, armor.Footer)}
+	}
+	payload = strings.TrimSuffix(strings.TrimPrefix(payload, armor.Header), armor.Footer)
+	payload = strings.Join(strings.Fields(payload), "")
+
+	var sb strings.Builder
+	sb.WriteString(armor.Header + "\n")
+	for len(payload) > armorColumnsPerLine {
+		sb.WriteString(payload[:armorColumnsPerLine] + "\n")
+		payload = payload[armorColumnsPerLine:]
+	}
+	if payload != "" {
+		sb.WriteString(payload + "\n")
+	}
+	sb.WriteString(armor.Footer + "\n")
+	return sb.String(), nil
+}
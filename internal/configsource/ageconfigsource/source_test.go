@@ -0,0 +1,105 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ageconfigsource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestAgeConfigSource_Retrieve(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	identityFile := filepath.Join(t.TempDir(), "identity.txt")
+	require.NoError(t, os.WriteFile(identityFile, []byte(identity.String()+"\n"), 0600))
+
+	otherIdentity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	// Long enough to have multiple lines on the armored ciphertext.
+	secret := strings.Repeat("s3cr3t", 20)
+	ciphertext := encrypt(t, secret, identity.Recipient())
+
+	tests := []struct {
+		wantErr  error
+		name     string
+		selector string
+	}{
+		{
+			name:     "armored",
+			selector: ciphertext,
+		},
+		{
+			name:     "line_breaks_as_spaces",
+			selector: strings.ReplaceAll(ciphertext, "\n", " "),
+		},
+		{
+			name:     "not_armored",
+			selector: "some_value",
+			wantErr:  &errInvalidCiphertext{},
+		},
+		{
+			name:     "other_recipient",
+			selector: encrypt(t, secret, otherIdentity.Recipient()),
+			wantErr:  &errDecrypt{},
+		},
+	}
+
+	source, err := newConfigSource(configprovider.CreateParams{Logger: zap.NewNop()}, &Config{IdentityFile: identityFile})
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			r, err := source.Retrieve(ctx, tt.selector, nil, nil)
+			if tt.wantErr != nil {
+				assert.Nil(t, r)
+				require.IsType(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+
+			val, err := r.AsRaw()
+			require.NoError(t, err)
+			assert.Equal(t, secret, val)
+		})
+	}
+
+	assert.NoError(t, source.Shutdown(context.Background()))
+}
+
+func encrypt(t *testing.T, plaintext string, recipient age.Recipient) string {
+	var sb strings.Builder
+	armorWriter := armor.NewWriter(&sb)
+	w, err := age.Encrypt(armorWriter, recipient)
+	require.NoError(t, err)
+	_, err = w.Write([]byte(plaintext))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, armorWriter.Close())
+	return sb.String()
+}
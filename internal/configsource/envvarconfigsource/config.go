@@ -22,6 +22,17 @@ type Config struct {
 	// Defaults specify a map to fallback if a given environment variable is not defined.
 	Defaults map[string]any `mapstructure:"defaults"`
 
+	// DotEnvFiles specify dotenv files, with KEY=VALUE lines, providing fallbacks for
+	// environment variables that are not defined. Values from later files override the
+	// ones from earlier files and all of them take precedence over Defaults.
+	DotEnvFiles []string `mapstructure:"dotenv_files"`
+
+	// WindowsCompat expands Windows-style %VAR% references in the values of the
+	// environment variables, dotenv files, and defaults. On Windows it also makes
+	// the variable names case-insensitive, including the ones of the dotenv files
+	// and defaults. The default value is 'false'.
+	WindowsCompat bool `mapstructure:"windows_compat"`
+
 	configprovider.SourceSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
 }
 
@@ -54,6 +54,13 @@ func TestEnvVarConfigSourceLoadConfig(t *testing.T) {
 				},
 			},
 		},
+		"env/with_dotenv_files": &Config{
+			SourceSettings: configprovider.NewSourceSettings(component.NewIDWithName(typeStr, "with_dotenv_files")),
+			DotEnvFiles:    []string{"testdata/defaults.env", "testdata/override.env"},
+			Defaults: map[string]any{
+				"LOG_LEVEL": "warn",
+			},
+		},
 	}
 
 	require.Equal(t, expectedSettings, actualSettings)
@@ -0,0 +1,111 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envvarconfigsource
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// readDotEnvFiles reads the given dotenv files, in order, and returns the variables
+// defined by them. Variables defined by a file override the ones defined by previous files.
+func readDotEnvFiles(files []string) (map[string]string, error) {
+	vars := make(map[string]string)
+	for _, file := range files {
+		if err := readDotEnvFile(file, vars); err != nil {
+			return nil, err
+		}
+	}
+	return vars, nil
+}
+
+// readDotEnvFile adds the variables defined in a dotenv file to vars. Each non-empty line
+// not starting with '#' must have the form "[export ]KEY=VALUE". Single-quoted values are
+// taken literally, double-quoted values support the \n, \t, \" and \\ escapes, and
+// unquoted values are trimmed and can be followed by a " #" comment. Variable references
+// are not expanded.
+func readDotEnvFile(file string, vars map[string]string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("failed to read dotenv file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" || strings.ContainsAny(key, " \t") {
+			return fmt.Errorf("%s:%d: expected KEY=VALUE", file, lineNum)
+		}
+
+		if vars[key], err = parseDotEnvValue(strings.TrimSpace(value)); err != nil {
+			return fmt.Errorf("%s:%d: %w", file, lineNum, err)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read dotenv file: %w", err)
+	}
+	return nil
+}
+
+func parseDotEnvValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	switch quote := value[0]; quote {
+	case '\'', '"':
+		end := closingQuoteIndex(value)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value")
+		}
+		if rest := strings.TrimSpace(value[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected characters after quoted value")
+		}
+		if quote == '\'' {
+			return value[1:end], nil
+		}
+		return strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`).Replace(value[1:end]), nil
+	}
+
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// closingQuoteIndex returns the index of the quote closing the value starting with a
+// quote, or -1 if there is none. Escaped quotes don't close double-quoted values.
+func closingQuoteIndex(value string) int {
+	quote := value[0]
+	for i := 1; i < len(value); i++ {
+		switch {
+		case quote == '"' && value[i] == '\\':
+			i++
+		case value[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,74 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envvarconfigsource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadDotEnvFiles(t *testing.T) {
+	vars, err := readDotEnvFiles([]string{
+		filepath.Join("testdata", "defaults.env"),
+		filepath.Join("testdata", "override.env"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"LOG_LEVEL": "debug",
+		"ENDPOINT":  "localhost:4317",
+		"TOKEN":     "$NOT_EXPANDED",
+	}, vars)
+
+	_, err = readDotEnvFiles([]string{filepath.Join("testdata", "invalid.env")})
+	assert.ErrorContains(t, err, "invalid.env:1: expected KEY=VALUE")
+
+	_, err = readDotEnvFiles([]string{filepath.Join("testdata", "missing.env")})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestParseDotEnvValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "empty", value: "", expected: ""},
+		{name: "unquoted", value: "some value", expected: "some value"},
+		{name: "unquoted_comment", value: "value # comment", expected: "value"},
+		{name: "unquoted_hash", value: "val#ue", expected: "val#ue"},
+		{name: "single_quoted", value: `'a \n # b'`, expected: `a \n # b`},
+		{name: "double_quoted", value: `"a\n\"b\"" # comment`, expected: "a\n\"b\""},
+		{name: "unterminated", value: `"value`, wantErr: true},
+		{name: "trailing_characters", value: `"value" trailing`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := parseDotEnvValue(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
@@ -41,7 +41,7 @@ func (e *envVarFactory) CreateDefaultConfig() configprovider.Source {
 }
 
 func (e *envVarFactory) CreateConfigSource(_ context.Context, params configprovider.CreateParams, cfg configprovider.Source) (configprovider.ConfigSource, error) {
-	return newConfigSource(params, cfg.(*Config)), nil
+	return newConfigSource(params, cfg.(*Config))
 }
 
 // NewFactory creates a factory for Vault ConfigSource objects.
@@ -33,8 +33,9 @@ func TestEnvVarConfigSourceFactory_CreateConfigSource(t *testing.T) {
 		Logger: zap.NewNop(),
 	}
 	tests := []struct {
-		config *Config
-		name   string
+		config  *Config
+		wantErr error
+		name    string
 	}{
 		{
 			name:   "no_defaults",
@@ -48,10 +49,28 @@ func TestEnvVarConfigSourceFactory_CreateConfigSource(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "with_dotenv_files",
+			config: &Config{
+				DotEnvFiles: []string{"testdata/defaults.env"},
+			},
+		},
+		{
+			name: "invalid_dotenv_file",
+			config: &Config{
+				DotEnvFiles: []string{"testdata/invalid.env"},
+			},
+			wantErr: &errInvalidDotEnvFile{},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			actual, err := factory.CreateConfigSource(context.Background(), createParams, tt.config)
+			if tt.wantErr != nil {
+				assert.IsType(t, tt.wantErr, err)
+				assert.Nil(t, actual)
+				return
+			}
 			assert.NoError(t, err)
 			assert.NotNil(t, actual)
 		})
@@ -0,0 +1,35 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envvarconfigsource
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/signalfx/splunk-otel-collector/pkg/configsource/configsourcetest"
+)
+
+func TestEnvVarConfigSourceGolden(t *testing.T) {
+	t.Setenv("_GOLDEN_TEST_HOST", "collector.example.com")
+
+	factory := NewFactory()
+	configsourcetest.CheckFactory(t, factory)
+	cfg := configsourcetest.LoadConfig(t, factory, "golden", map[string]any{
+		"defaults": map[string]any{"_GOLDEN_TEST_PORT": "4317"},
+	})
+	source := configsourcetest.CreateConfigSource(t, factory, cfg)
+	configsourcetest.RunGolden(t, source, filepath.Join("testdata", "golden.yaml"))
+}
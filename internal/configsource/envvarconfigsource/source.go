@@ -17,8 +17,11 @@ package envvarconfigsource
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 
 	"go.opentelemetry.io/collector/confmap"
 
@@ -29,33 +32,60 @@ import (
 type (
 	errInvalidRetrieveParams struct{ error }
 	errMissingRequiredEnvVar struct{ error }
+	errInvalidDotEnvFile     struct{ error }
+	errInvalidSelector       struct{ error }
 )
 
+// Is makes the missing env vars match configprovider.ErrNotFound so the default values of the
+// references are used for them.
+func (e *errMissingRequiredEnvVar) Is(target error) bool {
+	return target == configprovider.ErrNotFound
+}
+
 type retrieveParams struct {
 	// Optional is used to change the default behavior when an environment variable
 	// requested via the config source is not defined. By default the value of this
 	// field is 'false' which will cause an error if the specified environment variable
 	// is not defined. Set it to 'true' to ignore not defined environment variables.
 	Optional bool `mapstructure:"optional"`
+	// Required causes an error if the environment variable is defined, directly or via
+	// the defaults, but its value is empty. It can't be combined with Optional.
+	Required bool `mapstructure:"required"`
+	// The "type" parameter is handled by the config provider for all config sources, see
+	// configprovider.TypeParam.
 }
 
 // envVarConfigSource implements the configprovider.Session interface.
 type envVarConfigSource struct {
 	defaults map[string]any
+	// expandWindowsVarRefs enables the expansion of %VAR% references in the values.
+	expandWindowsVarRefs bool
+	// caseInsensitive makes the variable names case-insensitive.
+	caseInsensitive bool
 }
 
-func newConfigSource(_ configprovider.CreateParams, cfg *Config) configprovider.ConfigSource {
-	defaults := make(map[string]any)
-	if cfg.Defaults != nil {
-		defaults = cfg.Defaults
+func newConfigSource(_ configprovider.CreateParams, cfg *Config) (configprovider.ConfigSource, error) {
+	defaults := make(map[string]any, len(cfg.Defaults))
+	for k, v := range cfg.Defaults {
+		defaults[k] = v
 	}
 
-	return &envVarConfigSource{
-		defaults: defaults,
+	dotEnvVars, err := readDotEnvFiles(cfg.DotEnvFiles)
+	if err != nil {
+		return nil, &errInvalidDotEnvFile{err}
+	}
+	for k, v := range dotEnvVars {
+		defaults[k] = v
 	}
+
+	return &envVarConfigSource{
+		defaults:             defaults,
+		expandWindowsVarRefs: cfg.WindowsCompat,
+		caseInsensitive:      cfg.WindowsCompat && isWindows,
+	}, nil
 }
 
-func (e *envVarConfigSource) Retrieve(_ context.Context, selector string, paramsConfigMap *confmap.Conf, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
+func (e *envVarConfigSource) Retrieve(ctx context.Context, selector string, paramsConfigMap *confmap.Conf, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
 	actualParams := retrieveParams{}
 	if paramsConfigMap != nil {
 		paramsParser := confmap.NewFromStringMap(paramsConfigMap.ToStringMap())
@@ -64,20 +94,78 @@ func (e *envVarConfigSource) Retrieve(_ context.Context, selector string, params
 		}
 	}
 
-	value, ok := os.LookupEnv(selector)
-	if ok {
-		// Environment variable found, everything is done.
-		return confmap.NewRetrieved(value)
+	// The optional parameter is handled by the config provider and passed via the context.
+	actualParams.Optional = actualParams.Optional || configprovider.IsOptional(ctx)
+	if actualParams.Optional && actualParams.Required {
+		return nil, &errInvalidRetrieveParams{errors.New("optional and required can't be both set")}
+	}
+	if strings.HasSuffix(selector, "*") {
+		return e.retrievePrefix(strings.TrimSuffix(selector, "*"), actualParams)
 	}
 
-	defaultValue, ok := e.defaults[selector]
+	value, ok := e.lookup(selector)
 	if !ok {
 		if !actualParams.Optional {
 			return nil, &errMissingRequiredEnvVar{fmt.Errorf("env var %q is required but not defined and not present on defaults", selector)}
 		}
+		return confmap.NewRetrieved(nil)
+	}
+
+	value, err := checkValue(selector, e.expandWindowsVars(value), actualParams)
+	if err != nil {
+		return nil, err
+	}
+	return confmap.NewRetrieved(value)
+}
+
+// retrievePrefix returns a map with all the environment variables, and defaults, whose name
+// starts with prefix. The map keys are the lowercased variable names without the prefix.
+func (e *envVarConfigSource) retrievePrefix(prefix string, params retrieveParams) (*confmap.Retrieved, error) {
+	if prefix == "" {
+		return nil, &errInvalidSelector{errors.New("a prefix is required before '*'")}
+	}
+
+	vars := make(map[string]any)
+	for name, value := range e.defaults {
+		if len(name) > len(prefix) && e.hasPrefix(name, prefix) {
+			vars[name] = value
+		}
+	}
+	for _, envVar := range os.Environ() {
+		name, value, _ := strings.Cut(envVar, "=")
+		if len(name) > len(prefix) && e.hasPrefix(name, prefix) {
+			vars[name] = value
+		}
 	}
 
-	return confmap.NewRetrieved(defaultValue)
+	if len(vars) == 0 && !params.Optional {
+		return nil, &errMissingRequiredEnvVar{fmt.Errorf("no env vars with prefix %q are defined or present on defaults", prefix)}
+	}
+
+	// Sort the names so the result is deterministic if different names are equal once lowercased.
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	expanded := make(map[string]any, len(vars))
+	for _, name := range names {
+		value, err := checkValue(name, e.expandWindowsVars(vars[name]), params)
+		if err != nil {
+			return nil, err
+		}
+		expanded[strings.ToLower(name[len(prefix):])] = value
+	}
+	return confmap.NewRetrieved(expanded)
+}
+
+// checkValue applies the required retrieve parameter to the value of an env var.
+func checkValue(name string, value any, params retrieveParams) (any, error) {
+	if params.Required && (value == nil || value == "") {
+		return nil, &errMissingRequiredEnvVar{fmt.Errorf("env var %q is required but its value is empty", name)}
+	}
+	return value, nil
 }
 
 func (e *envVarConfigSource) Shutdown(context.Context) error {
@@ -18,11 +18,16 @@ package envvarconfigsource
 import (
 	"context"
 	"os"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
 )
 
 func TestEnvVarConfigSource_Session(t *testing.T) {
@@ -70,6 +75,19 @@ func TestEnvVarConfigSource_Session(t *testing.T) {
 			selector: "FALLBACK_ENV_VAR",
 			expected: "fallback_env_var",
 		},
+		{
+			name:     "required_empty",
+			defaults: map[string]any{"EMPTY_ENV_VAR": ""},
+			selector: "EMPTY_ENV_VAR",
+			params:   map[string]any{"required": true},
+			wantErr:  &errMissingRequiredEnvVar{},
+		},
+		{
+			name:     "required_and_optional",
+			selector: testEnvVarName,
+			params:   map[string]any{"required": true, "optional": true},
+			wantErr:  &errInvalidRetrieveParams{},
+		},
 	}
 
 	require.NoError(t, os.Setenv(testEnvVarName, testEnvVarValue))
@@ -108,3 +126,165 @@ func TestEnvVarConfigSource_Session(t *testing.T) {
 		})
 	}
 }
+
+func TestEnvVarConfigSource_TypeParam(t *testing.T) {
+	t.Setenv("_TEST_TYPED_BATCH_SIZE", " 42 ")
+	t.Setenv("_TEST_TYPED_HOST", "collector")
+	factories := map[component.Type]configprovider.Factory{
+		typeStr: NewFactory(),
+	}
+
+	// The type parameter is handled by the config provider, for the prefixes too.
+	configMap := confmap.NewFromStringMap(map[string]any{
+		"config_sources": map[string]any{"env": nil},
+		"batch_size":     "${env:_TEST_TYPED_BATCH_SIZE?required=true&type=int}",
+		"sizes":          "${env:_TEST_TYPED_BATCH_*?type=int}",
+	})
+	res, closeFunc, err := configprovider.Resolve(context.Background(), configMap, zap.NewNop(), component.NewDefaultBuildInfo(), factories, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"batch_size": 42,
+		"sizes":      map[string]any{"size": 42},
+	}, res)
+	if closeFunc != nil {
+		require.NoError(t, closeFunc(context.Background()))
+	}
+
+	configMap = confmap.NewFromStringMap(map[string]any{
+		"config_sources": map[string]any{"env": nil},
+		"batch_size":     "${env:_TEST_TYPED_HOST?type=int}",
+	})
+	_, _, err = configprovider.Resolve(context.Background(), configMap, zap.NewNop(), component.NewDefaultBuildInfo(), factories, nil)
+	assert.ErrorContains(t, err, `can't convert "collector" to int`)
+}
+
+func TestEnvVarConfigSource_DotEnvPrecedence(t *testing.T) {
+	t.Setenv("ENDPOINT", "collector:4317")
+
+	source, err := newConfigSource(configprovider.CreateParams{}, &Config{
+		Defaults:    map[string]any{"LOG_LEVEL": "warn", "BATCH_SIZE": 512},
+		DotEnvFiles: []string{"testdata/defaults.env", "testdata/override.env"},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for selector, expected := range map[string]any{
+		// Defined in the environment and in defaults.env.
+		"ENDPOINT": "collector:4317",
+		// Defined in defaults, defaults.env and override.env.
+		"LOG_LEVEL": "debug",
+		// Only defined in defaults.
+		"BATCH_SIZE": 512,
+	} {
+		r, err := source.Retrieve(ctx, selector, nil, nil)
+		require.NoError(t, err)
+		val, err := r.AsRaw()
+		require.NoError(t, err)
+		assert.Equal(t, expected, val, selector)
+	}
+	assert.NoError(t, source.Shutdown(ctx))
+}
+
+func TestEnvVarConfigSource_Prefix(t *testing.T) {
+	t.Setenv("_TEST_ATTR_SERVICE_NAME", "checkout")
+	t.Setenv("_TEST_ATTR_Deployment_Environment", "prod")
+	t.Setenv("_TEST_ATTR_REPLICAS", "3")
+
+	source := &envVarConfigSource{
+		defaults: map[string]any{
+			"_TEST_ATTR_REPLICAS": "1",
+			"_TEST_ATTR_REGION":   "us-west-2",
+		},
+	}
+
+	ctx := context.Background()
+	r, err := source.Retrieve(ctx, "_TEST_ATTR_*", nil, nil)
+	require.NoError(t, err)
+	val, err := r.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"service_name":           "checkout",
+		"deployment_environment": "prod",
+		"replicas":               "3",
+		"region":                 "us-west-2",
+	}, val)
+
+	_, err = source.Retrieve(ctx, "_TEST_UNDEFINED_*", nil, nil)
+	assert.IsType(t, &errMissingRequiredEnvVar{}, err)
+
+	r, err = source.Retrieve(ctx, "_TEST_UNDEFINED_*", confmap.NewFromStringMap(map[string]any{"optional": true}), nil)
+	require.NoError(t, err)
+	val, err = r.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{}, val)
+
+	_, err = source.Retrieve(ctx, "*", nil, nil)
+	assert.IsType(t, &errInvalidSelector{}, err)
+
+	assert.NoError(t, source.Shutdown(ctx))
+}
+
+func TestEnvVarConfigSource_WindowsCompat(t *testing.T) {
+	t.Setenv("_TEST_PROGRAM_FILES", `C:\Program Files`)
+	t.Setenv("_TEST_AGENT_DIR", `%_test_program_files%\SignalFx\%_TEST_UNDEFINED%`)
+	t.Setenv("_TEST_WIN_Log_Level", "debug")
+
+	cfg := &Config{
+		WindowsCompat: true,
+		Defaults: map[string]any{
+			"_TEST_BUNDLE_DIR": `%_TEST_AGENT_DIR%\bundle`,
+			"_Test_Win_Port":   "%_TEST_PORT%",
+			"_TEST_PORT":       4317,
+		},
+	}
+
+	tests := []struct {
+		expected  map[string]any
+		name      string
+		isWindows bool
+	}{
+		{
+			name: "windows",
+			expected: map[string]any{
+				// References to undefined variables are kept.
+				"_TEST_AGENT_DIR": `C:\Program Files\SignalFx\%_TEST_UNDEFINED%`,
+				// References aren't expanded recursively, like cmd.exe does.
+				"_TEST_BUNDLE_DIR":    `%_test_program_files%\SignalFx\%_TEST_UNDEFINED%\bundle`,
+				"_test_win_log_level": "debug",
+				"_TEST_WIN_*":         map[string]any{"log_level": "debug", "port": "4317"},
+			},
+			isWindows: true,
+		},
+		{
+			name: "other_os",
+			expected: map[string]any{
+				"_TEST_AGENT_DIR":  `%_test_program_files%\SignalFx\%_TEST_UNDEFINED%`,
+				"_TEST_BUNDLE_DIR": `%_test_program_files%\SignalFx\%_TEST_UNDEFINED%\bundle`,
+				"_TEST_WIN_*":      map[string]any{"log_level": "debug"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.isWindows && runtime.GOOS == "windows" {
+				t.Skip("environment variable names are always case-insensitive on Windows")
+			}
+			defer func(isWin bool) { isWindows = isWin }(isWindows)
+			isWindows = tt.isWindows
+
+			source, err := newConfigSource(configprovider.CreateParams{}, cfg)
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			for selector, expected := range tt.expected {
+				r, err := source.Retrieve(ctx, selector, nil, nil)
+				require.NoError(t, err, selector)
+				val, err := r.AsRaw()
+				require.NoError(t, err)
+				assert.Equal(t, expected, val, selector)
+			}
+			assert.NoError(t, source.Shutdown(ctx))
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envvarconfigsource
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// isWindows is a variable so tests can exercise the Windows behavior on any platform.
+var isWindows = runtime.GOOS == "windows"
+
+// windowsVarRef matches Windows-style %VAR% references. Like cmd.exe the names can
+// contain any character but '%', '=' and white spaces, e.g. %ProgramFiles(x86)%.
+var windowsVarRef = regexp.MustCompile(`%([^%=\s]+)%`)
+
+// lookup returns the value of the environment variable, falling back to the dotenv files
+// and defaults. With Windows compatibility enabled on Windows the name is matched
+// case-insensitively, as Windows itself does for environment variables.
+func (e *envVarConfigSource) lookup(name string) (any, bool) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, true
+	}
+	if value, ok := e.defaults[name]; ok {
+		return value, true
+	}
+	if !e.caseInsensitive {
+		return nil, false
+	}
+
+	for _, envVar := range os.Environ() {
+		if envName, value, _ := strings.Cut(envVar, "="); strings.EqualFold(envName, name) {
+			return value, true
+		}
+	}
+	for defaultName, value := range e.defaults {
+		if strings.EqualFold(defaultName, name) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// hasPrefix reports if the name of a variable starts with prefix, ignoring the case
+// if names are case-insensitive.
+func (e *envVarConfigSource) hasPrefix(name, prefix string) bool {
+	if e.caseInsensitive {
+		return len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix)
+	}
+	return strings.HasPrefix(name, prefix)
+}
+
+// expandWindowsVars replaces the %VAR% references in string values by the values of the
+// referenced variables. Like cmd.exe references to undefined variables are kept as they
+// are and the expansion isn't recursive.
+func (e *envVarConfigSource) expandWindowsVars(value any) any {
+	s, ok := value.(string)
+	if !e.expandWindowsVarRefs || !ok {
+		return value
+	}
+	return windowsVarRef.ReplaceAllStringFunc(s, func(ref string) string {
+		if refValue, found := e.lookup(strings.Trim(ref, "%")); found {
+			return fmt.Sprint(refValue)
+		}
+		return ref
+	})
+}
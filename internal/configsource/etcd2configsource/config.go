@@ -15,7 +15,11 @@
 
 package etcd2configsource
 
-import "github.com/signalfx/splunk-otel-collector/internal/configprovider"
+import (
+	"go.opentelemetry.io/collector/config/configtls"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
 
 // Config defines etcd2configsource configuration
 type Config struct {
@@ -27,6 +31,10 @@ type Config struct {
 	// Endpoints is a list of etcd2 server endpoints the etcd2
 	// config source should try to connect to.
 	Endpoints []string `mapstructure:"endpoints"`
+
+	// TLS configures the TLS connection to https endpoints. Setting a client
+	// certificate and key enables mutual TLS.
+	TLS *configtls.TLSClientSetting `mapstructure:"tls"`
 }
 
 // Authentication holds the authentication configuration for Etcd2 config source objects.
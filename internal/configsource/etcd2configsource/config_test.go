@@ -22,6 +22,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtls"
 	"go.opentelemetry.io/collector/confmap/confmaptest"
 	"go.uber.org/zap"
 
@@ -53,6 +54,11 @@ func TestEtcd2LoadConfig(t *testing.T) {
 				Password: "pass",
 			},
 		},
+		"etcd2/tls": &Config{
+			SourceSettings: configprovider.NewSourceSettings(component.NewIDWithName(typeStr, "tls")),
+			Endpoints:      []string{"https://localhost:2379"},
+			TLS:            &configtls.TLSClientSetting{InsecureSkipVerify: true},
+		},
 	}
 
 	require.Equal(t, expectedSettings, actualSettings)
@@ -34,8 +34,11 @@ const (
 
 // Private error types to help with testability.
 type (
-	errMissingEndpoint struct{ error }
-	errInvalidEndpoint struct{ error }
+	errMissingEndpoint  struct{ error }
+	errInvalidEndpoint  struct{ error }
+	errMissingUsername  struct{ error }
+	errInvalidTLSConfig struct{ error }
+	errInvalidParams    struct{ error }
 )
 
 type etcd2Factory struct{}
@@ -64,6 +67,10 @@ func (v *etcd2Factory) CreateConfigSource(_ context.Context, params configprovid
 		}
 	}
 
+	if etcd2Cfg.Authentication != nil && etcd2Cfg.Authentication.Username == "" {
+		return nil, &errMissingUsername{errors.New("auth requires a username")}
+	}
+
 	return newConfigSource(params, etcd2Cfg)
 }
 
@@ -22,6 +22,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtls"
 	"go.uber.org/zap"
 
 	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
@@ -57,6 +58,31 @@ func TestEtcd2Factory_CreateConfigSource(t *testing.T) {
 			},
 			wantErr: &errInvalidEndpoint{},
 		},
+		{
+			name: "missing_username",
+			config: &Config{
+				Endpoints:      []string{"https://localhost:2379"},
+				Authentication: &Authentication{Password: "pass"},
+			},
+			wantErr: &errMissingUsername{},
+		},
+		{
+			name: "invalid_tls",
+			config: &Config{
+				Endpoints: []string{"https://localhost:2379"},
+				TLS: &configtls.TLSClientSetting{
+					TLSSetting: configtls.TLSSetting{CAFile: "testdata/not-found.pem"},
+				},
+			},
+			wantErr: &errInvalidTLSConfig{},
+		},
+		{
+			name: "success_tls",
+			config: &Config{
+				Endpoints: []string{"https://localhost:2379"},
+				TLS:       &configtls.TLSClientSetting{ServerName: "etcd"},
+			},
+		},
 		{
 			name: "success",
 			config: &Config{
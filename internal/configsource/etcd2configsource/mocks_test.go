@@ -18,6 +18,7 @@ package etcd2configsource
 import (
 	"context"
 	"errors"
+	"strings"
 
 	"go.etcd.io/etcd/client/v2"
 	"go.uber.org/atomic"
@@ -59,6 +60,11 @@ type MockKeysAPI struct {
 }
 
 func (k *MockKeysAPI) Get(ctx context.Context, key string, opts *client.GetOptions) (*client.Response, error) {
+	if opts != nil && opts.Recursive {
+		if node := k.dirNode(key); node != nil {
+			return &client.Response{Node: node}, nil
+		}
+	}
 	if v, ok := k.db[key]; ok {
 		return &client.Response{
 			Node: &client.Node{
@@ -69,6 +75,32 @@ func (k *MockKeysAPI) Get(ctx context.Context, key string, opts *client.GetOptio
 	return nil, errors.New("not found")
 }
 
+// dirNode returns the directory node for dir, the keys in db are treated as
+// the paths of the leaf nodes. It returns nil if dir has no keys under it.
+func (k *MockKeysAPI) dirNode(dir string) *client.Node {
+	prefix := dir + "/"
+	children := make(map[string]bool)
+	for key := range k.db {
+		if strings.HasPrefix(key, prefix) {
+			children[strings.SplitN(strings.TrimPrefix(key, prefix), "/", 2)[0]] = true
+		}
+	}
+	if len(children) == 0 {
+		return nil
+	}
+
+	node := &client.Node{Key: dir, Dir: true}
+	for child := range children {
+		childKey := prefix + child
+		if v, ok := k.db[childKey]; ok {
+			node.Nodes = append(node.Nodes, &client.Node{Key: childKey, Value: v})
+			continue
+		}
+		node.Nodes = append(node.Nodes, k.dirNode(childKey))
+	}
+	return node
+}
+
 func (k *MockKeysAPI) Watcher(key string, opts *client.WatcherOptions) client.Watcher {
 	return k.activeWatcher
 }
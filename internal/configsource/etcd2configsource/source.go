@@ -18,6 +18,10 @@ package etcd2configsource
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -30,6 +34,13 @@ import (
 
 const maxBackoffTime = time.Second * 60
 
+// retrieveParams holds the parameters supported by Retrieve.
+type retrieveParams struct {
+	// Recursive retrieves all the keys under the selected directory as a nested map
+	// keyed by the remaining path segments.
+	Recursive bool `mapstructure:"recursive"`
+}
+
 // etcd2ConfigSource implements the configprovider.Session interface.
 type etcd2ConfigSource struct {
 	logger *zap.Logger
@@ -37,16 +48,32 @@ type etcd2ConfigSource struct {
 }
 
 func newConfigSource(params configprovider.CreateParams, cfg *Config) (configprovider.ConfigSource, error) {
-	var username, password string
+	clientCfg := client.Config{
+		Endpoints: cfg.Endpoints,
+		Transport: client.DefaultTransport,
+	}
+
 	if cfg.Authentication != nil {
-		username = cfg.Authentication.Username
-		password = cfg.Authentication.Password
+		clientCfg.Username = cfg.Authentication.Username
+		clientCfg.Password = cfg.Authentication.Password
+		for _, endpoint := range cfg.Endpoints {
+			if u, err := url.Parse(endpoint); err == nil && u.Scheme == "http" {
+				params.Logger.Warn("etcd2 credentials are sent in plain text to an http endpoint, consider using https", zap.String("endpoint", endpoint))
+			}
+		}
 	}
-	etcdClient, err := client.New(client.Config{
-		Endpoints: cfg.Endpoints,
-		Username:  username,
-		Password:  password,
-	})
+
+	if cfg.TLS != nil {
+		tlsCfg, err := cfg.TLS.LoadTLSConfig()
+		if err != nil {
+			return nil, &errInvalidTLSConfig{fmt.Errorf("failed to load TLS config: %w", err)}
+		}
+		transport := client.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsCfg
+		clientCfg.Transport = transport
+	}
+
+	etcdClient, err := client.New(clientCfg)
 	if err != nil {
 		return nil, err
 	}
@@ -59,31 +86,87 @@ func newConfigSource(params configprovider.CreateParams, cfg *Config) (configpro
 	}, nil
 }
 
-func (s *etcd2ConfigSource) Retrieve(ctx context.Context, selector string, _ *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
-	resp, err := s.kapi.Get(ctx, selector, nil)
+func (s *etcd2ConfigSource) Retrieve(ctx context.Context, selector string, paramsConfigMap *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	var params retrieveParams
+	if paramsConfigMap != nil {
+		if err := paramsConfigMap.Unmarshal(&params, confmap.WithErrorUnused()); err != nil {
+			return nil, &errInvalidParams{fmt.Errorf("failed to unmarshall retrieve params: %w", err)}
+		}
+	}
+
+	resp, err := s.kapi.Get(ctx, selector, &client.GetOptions{Recursive: params.Recursive})
 	if err != nil {
+		if client.IsKeyNotFound(err) {
+			err = configprovider.NotFoundError(err)
+		}
 		return nil, err
 	}
+
+	var value any = resp.Node.Value
+	watcherOpts := &client.WatcherOptions{AfterIndex: resp.Node.ModifiedIndex}
+	if params.Recursive {
+		value = nodeToValue(resp.Node)
+		// Changes to the keys under a directory don't update the directory index,
+		// the watch must start from the etcd2 index of the response instead.
+		watcherOpts = &client.WatcherOptions{AfterIndex: resp.Index, Recursive: true}
+	}
+
 	if watcher == nil {
-		return confmap.NewRetrieved(resp.Node.Value)
+		return confmap.NewRetrieved(value)
+	}
+	return confmap.NewRetrieved(value, confmap.WithRetrievedClose(s.newWatcher(selector, watcherOpts, leafValues(resp.Node), watcher)))
+}
+
+// nodeToValue converts a node into a nested map keyed by the last path segment of each
+// key under it. Nodes that aren't directories are returned as their value.
+func nodeToValue(node *client.Node) any {
+	if !node.Dir {
+		return node.Value
+	}
+	value := make(map[string]any, len(node.Nodes))
+	for _, child := range node.Nodes {
+		value[path.Base(child.Key)] = nodeToValue(child)
+	}
+	return value
+}
+
+// leafValues returns the values of all the keys, that aren't directories, under node.
+func leafValues(node *client.Node) map[string]string {
+	leaves := make(map[string]string)
+	var collect func(*client.Node)
+	collect = func(n *client.Node) {
+		if !n.Dir {
+			leaves[n.Key] = n.Value
+			return
+		}
+		for _, child := range n.Nodes {
+			collect(child)
+		}
 	}
-	return confmap.NewRetrieved(resp.Node.Value, confmap.WithRetrievedClose(s.newWatcher(selector, resp.Node.ModifiedIndex, watcher)))
+	collect(node)
+	return leaves
 }
 
 func (s *etcd2ConfigSource) Shutdown(context.Context) error {
 	return nil
 }
 
-func (s *etcd2ConfigSource) newWatcher(selector string, index uint64, watcherFunc confmap.WatcherFunc) confmap.CloseFunc {
+// newWatcher watches the selected key with the given options and calls watcherFunc once the
+// value of any of the watched keys changes. Writes that leave a retrieved value, held by
+// leaves, as it was, e.g. TTL refreshes, don't trigger watcherFunc.
+func (s *etcd2ConfigSource) newWatcher(selector string, opts *client.WatcherOptions, leaves map[string]string, watcherFunc confmap.WatcherFunc) confmap.CloseFunc {
 	watchCtx, cancel := context.WithCancel(context.Background())
-	watcher := s.kapi.Watcher(selector, &client.WatcherOptions{AfterIndex: index})
+	watcher := s.kapi.Watcher(selector, opts)
 	ebo := backoff.NewExponentialBackOff()
 	ebo.MaxElapsedTime = maxBackoffTime
 
 	go func() {
 		for {
-			_, err := watcher.Next(watchCtx)
+			resp, err := watcher.Next(watchCtx)
 			if err == nil {
+				if isValueUnchanged(resp, leaves) {
+					continue
+				}
 				// Value updated
 				watcherFunc(&confmap.ChangeEvent{Error: nil})
 				return
@@ -93,15 +176,25 @@ func (s *etcd2ConfigSource) newWatcher(selector string, index uint64, watcherFun
 				return
 			}
 
+			// The index the watch started from is no longer in the etcd2 event history,
+			// changes may have been missed so the value must be retrieved again.
+			var etcdErr client.Error
+			if errors.As(err, &etcdErr) && etcdErr.Code == client.ErrorCodeEventIndexCleared {
+				watcherFunc(&confmap.ChangeEvent{Error: nil})
+				return
+			}
+
 			s.logger.Info("error watching", zap.String("selector", selector), zap.Error(err))
 			// if error is recoverable, try again with backoff
 			cErr := &client.ClusterError{}
 			if errors.As(err, &cErr) {
-				select {
-				case <-time.After(ebo.NextBackOff()):
-					continue
-				case <-watchCtx.Done():
-					return
+				if wait := ebo.NextBackOff(); wait != backoff.Stop {
+					select {
+					case <-time.After(wait):
+						continue
+					case <-watchCtx.Done():
+						return
+					}
 				}
 			}
 			watcherFunc(&confmap.ChangeEvent{Error: err})
@@ -114,3 +207,15 @@ func (s *etcd2ConfigSource) newWatcher(selector string, index uint64, watcherFun
 		return nil
 	}
 }
+
+func isValueUnchanged(resp *client.Response, leaves map[string]string) bool {
+	if resp == nil || resp.Node == nil || resp.Node.Dir {
+		return false
+	}
+	switch resp.Action {
+	case "delete", "compareAndDelete", "expire":
+		return false
+	}
+	value, ok := leaves[resp.Node.Key]
+	return ok && resp.Node.Value == value
+}
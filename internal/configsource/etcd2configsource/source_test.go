@@ -23,6 +23,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/client/v2"
 	"go.opentelemetry.io/collector/confmap"
 	"go.uber.org/zap"
 )
@@ -80,6 +81,7 @@ func TestWatcher(t *testing.T) {
 		{name: "updated", close: false, result: "v", err: nil},
 		{name: "source-closed", close: true, result: "", err: nil},
 		{name: "client-error", close: false, result: "", err: errors.New("client error")},
+		{name: "index-cleared", close: false, result: "", err: client.Error{Code: client.ErrorCodeEventIndexCleared}},
 	}
 
 	for _, c := range testsCases {
@@ -108,7 +110,12 @@ func TestWatcher(t *testing.T) {
 			case c.err != nil:
 				watcher.errors <- c.err
 				ce := <-watchChannel
-				assert.ErrorIs(t, ce.Error, c.err)
+				var etcdErr client.Error
+				if errors.As(c.err, &etcdErr) && etcdErr.Code == client.ErrorCodeEventIndexCleared {
+					assert.NoError(t, ce.Error)
+				} else {
+					assert.ErrorIs(t, ce.Error, c.err)
+				}
 				assert.NoError(t, retrieved.Close(context.Background()))
 			case c.result != "":
 				watcher.values <- c.result
@@ -120,3 +127,83 @@ func TestWatcher(t *testing.T) {
 		})
 	}
 }
+
+func TestWatcherSkipsUnchangedValue(t *testing.T) {
+	watcher := newMockWatcher()
+	kapi := &MockKeysAPI{db: map[string]string{"k1": "v1"}, activeWatcher: watcher}
+	source := &etcd2ConfigSource{logger: zap.NewNop(), kapi: kapi}
+
+	watchChannel := make(chan *confmap.ChangeEvent, 1)
+	retrieved, err := source.Retrieve(context.Background(), "k1", nil, func(ce *confmap.ChangeEvent) {
+		watchChannel <- ce
+	})
+	require.NoError(t, err)
+
+	// A write with the same value, e.g. a TTL refresh, must not trigger a reload.
+	watcher.values <- "v1"
+	select {
+	case <-watchChannel:
+		t.Fatal("unexpected change event for an unchanged value")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	watcher.values <- "v2"
+	ce := <-watchChannel
+	assert.NoError(t, ce.Error)
+
+	assert.NoError(t, retrieved.Close(context.Background()))
+	assert.NoError(t, source.Shutdown(context.Background()))
+}
+
+func TestRecursiveRetrieve(t *testing.T) {
+	watcher := newMockWatcher()
+	kapi := &MockKeysAPI{
+		db: map[string]string{
+			"/receivers/prometheus/endpoint":            "localhost:9090",
+			"/receivers/prometheus/collection_interval": "10s",
+			"/receivers/jaeger/protocols/grpc/endpoint": "0.0.0.0:14250",
+			"/token": "secret",
+		},
+		activeWatcher: watcher,
+	}
+	source := &etcd2ConfigSource{logger: zap.NewNop(), kapi: kapi}
+	params := confmap.NewFromStringMap(map[string]any{"recursive": true})
+
+	watchChannel := make(chan *confmap.ChangeEvent, 1)
+	retrieved, err := source.Retrieve(context.Background(), "/receivers", params, func(ce *confmap.ChangeEvent) {
+		watchChannel <- ce
+	})
+	require.NoError(t, err)
+	val, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"prometheus": map[string]any{
+			"endpoint":            "localhost:9090",
+			"collection_interval": "10s",
+		},
+		"jaeger": map[string]any{
+			"protocols": map[string]any{
+				"grpc": map[string]any{
+					"endpoint": "0.0.0.0:14250",
+				},
+			},
+		},
+	}, val)
+
+	watcher.values <- "localhost:9091"
+	ce := <-watchChannel
+	assert.NoError(t, ce.Error)
+	require.NoError(t, retrieved.Close(context.Background()))
+
+	// A key that isn't a directory is retrieved as its value.
+	retrieved, err = source.Retrieve(context.Background(), "/token", params, nil)
+	require.NoError(t, err)
+	val, err = retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, "secret", val)
+
+	_, err = source.Retrieve(context.Background(), "/receivers", confmap.NewFromStringMap(map[string]any{"unknown": true}), nil)
+	assert.IsType(t, &errInvalidParams{}, err)
+
+	assert.NoError(t, source.Shutdown(context.Background()))
+}
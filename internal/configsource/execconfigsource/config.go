@@ -0,0 +1,51 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package execconfigsource
+
+import (
+	"time"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+// Config holds the configuration for the creation of exec config source objects.
+type Config struct {
+	configprovider.SourceSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+	// Commands are the commands that can be run by the config source, keyed by the
+	// name used as selector. Only the commands listed here can be run.
+	Commands map[string]CommandConfig `mapstructure:"commands"`
+	// Timeout is the maximum amount of time a command can run. Defaults to 10s.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// CommandConfig describes a command that can be run by the config source.
+type CommandConfig struct {
+	// Path is the path to the executable, if it doesn't contain a path separator it
+	// is looked up via the PATH environment variable.
+	Path string `mapstructure:"path"`
+	// Format is how the standard output of the command is interpreted: "raw", the
+	// default, injects it as a string without trailing line breaks; "yaml" and
+	// "json" parse it and inject the parsed value.
+	Format string `mapstructure:"format"`
+	// Args are the arguments passed to the command.
+	Args []string `mapstructure:"args"`
+	// Timeout overrides the timeout of the config source for this command.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+func (*Config) Validate() error {
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package execconfigsource
+
+import (
+	"context"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestExecLoadConfig(t *testing.T) {
+	fileName := path.Join("testdata", "config.yaml")
+	v, err := confmaptest.LoadConf(fileName)
+	require.NoError(t, err)
+
+	factories := map[component.Type]configprovider.Factory{
+		typeStr: NewFactory(),
+	}
+
+	actualSettings, err := configprovider.Load(context.Background(), v, factories)
+	require.NoError(t, err)
+
+	expectedSettings := map[string]configprovider.Source{
+		"exec": &Config{
+			SourceSettings: configprovider.NewSourceSettings(component.NewID(typeStr)),
+			Timeout:        defaultTimeout,
+			Commands: map[string]CommandConfig{
+				"gcloud_token": {
+					Path: "gcloud",
+					Args: []string{"auth", "print-access-token"},
+				},
+			},
+		},
+		"exec/custom": &Config{
+			SourceSettings: configprovider.NewSourceSettings(component.NewIDWithName(typeStr, "custom")),
+			Timeout:        30 * time.Second,
+			Commands: map[string]CommandConfig{
+				"credentials": {
+					Path:    "/usr/local/bin/credential-helper",
+					Args:    []string{"--output", "json"},
+					Format:  formatJSON,
+					Timeout: time.Minute,
+				},
+			},
+		},
+	}
+
+	require.Equal(t, expectedSettings, actualSettings)
+
+	params := configprovider.CreateParams{
+		Logger: zap.NewNop(),
+	}
+	_, err = configprovider.Build(context.Background(), actualSettings, params, factories)
+	require.NoError(t, err)
+}
@@ -0,0 +1,98 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package execconfigsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+const (
+	// The "type" of exec config sources in configuration.
+	typeStr = "exec"
+
+	defaultTimeout = 10 * time.Second
+
+	formatRaw  = "raw"
+	formatYAML = "yaml"
+	formatJSON = "json"
+)
+
+// Private error types to help with testability.
+type (
+	errNoCommands         struct{ error }
+	errMissingCommandPath struct{ error }
+	errInvalidFormat      struct{ error }
+	errInvalidTimeout     struct{ error }
+)
+
+type execFactory struct{}
+
+func (e *execFactory) Type() component.Type {
+	return typeStr
+}
+
+// Sensitive reports that the values retrieved by the credential commands are sensitive, so they are redacted.
+func (e *execFactory) Sensitive() bool {
+	return true
+}
+
+func (e *execFactory) CreateDefaultConfig() configprovider.Source {
+	return &Config{
+		SourceSettings: configprovider.NewSourceSettings(component.NewID(typeStr)),
+		Timeout:        defaultTimeout,
+	}
+}
+
+func (e *execFactory) CreateConfigSource(_ context.Context, params configprovider.CreateParams, cfg configprovider.Source) (configprovider.ConfigSource, error) {
+	execCfg := cfg.(*Config)
+
+	if len(execCfg.Commands) == 0 {
+		return nil, &errNoCommands{errors.New("at least one command must be specified")}
+	}
+
+	if execCfg.Timeout <= 0 {
+		return nil, &errInvalidTimeout{fmt.Errorf("timeout must be positive, got %v", execCfg.Timeout)}
+	}
+
+	for name, command := range execCfg.Commands {
+		if command.Path == "" {
+			return nil, &errMissingCommandPath{fmt.Errorf("command %q: path cannot be empty", name)}
+		}
+		switch command.Format {
+		case "", formatRaw, formatYAML, formatJSON:
+		default:
+			return nil, &errInvalidFormat{fmt.Errorf("command %q: unsupported format %q, it must be one of %q, %q, or %q",
+				name, command.Format, formatRaw, formatYAML, formatJSON)}
+		}
+		if command.Timeout < 0 {
+			return nil, &errInvalidTimeout{fmt.Errorf("command %q: timeout cannot be negative, got %v", name, command.Timeout)}
+		}
+	}
+
+	return newConfigSource(params, execCfg), nil
+}
+
+// NewFactory creates a factory for exec ConfigSource objects.
+func NewFactory() configprovider.Factory {
+	return &execFactory{}
+}
@@ -0,0 +1,101 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package execconfigsource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestExecFactory_CreateConfigSource(t *testing.T) {
+	factory := NewFactory()
+	assert.Equal(t, component.Type("exec"), factory.Type())
+	createParams := configprovider.CreateParams{
+		Logger: zap.NewNop(),
+	}
+	tests := []struct {
+		config  *Config
+		wantErr error
+		name    string
+	}{
+		{
+			name:    "no_commands",
+			config:  &Config{Timeout: defaultTimeout},
+			wantErr: &errNoCommands{},
+		},
+		{
+			name: "missing_path",
+			config: &Config{
+				Timeout:  defaultTimeout,
+				Commands: map[string]CommandConfig{"token": {}},
+			},
+			wantErr: &errMissingCommandPath{},
+		},
+		{
+			name: "invalid_format",
+			config: &Config{
+				Timeout:  defaultTimeout,
+				Commands: map[string]CommandConfig{"token": {Path: "helper", Format: "toml"}},
+			},
+			wantErr: &errInvalidFormat{},
+		},
+		{
+			name: "invalid_timeout",
+			config: &Config{
+				Commands: map[string]CommandConfig{"token": {Path: "helper"}},
+			},
+			wantErr: &errInvalidTimeout{},
+		},
+		{
+			name: "negative_command_timeout",
+			config: &Config{
+				Timeout:  defaultTimeout,
+				Commands: map[string]CommandConfig{"token": {Path: "helper", Timeout: -time.Second}},
+			},
+			wantErr: &errInvalidTimeout{},
+		},
+		{
+			name: "valid",
+			config: &Config{
+				Timeout: defaultTimeout,
+				Commands: map[string]CommandConfig{
+					"token":       {Path: "helper"},
+					"credentials": {Path: "helper", Args: []string{"--json"}, Format: formatJSON},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := factory.CreateConfigSource(context.Background(), createParams, tt.config)
+			if tt.wantErr != nil {
+				assert.Nil(t, actual)
+				require.IsType(t, tt.wantErr, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, actual)
+			}
+		})
+	}
+}
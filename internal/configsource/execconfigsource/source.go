@@ -0,0 +1,138 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package execconfigsource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/knadh/koanf/maps"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+// maxStderrInError limits how much of the standard error of a failed command is
+// added to the returned error.
+const maxStderrInError = 512
+
+// Private error types to help with testability.
+type (
+	errUnknownCommand struct{ error }
+	errCommandFailed  struct{ error }
+	errParseOutput    struct{ error }
+)
+
+// execConfigSource implements the configprovider.ConfigSource interface.
+type execConfigSource struct {
+	logger   *zap.Logger
+	commands map[string]CommandConfig
+	timeout  time.Duration
+}
+
+func newConfigSource(params configprovider.CreateParams, cfg *Config) configprovider.ConfigSource {
+	return &execConfigSource{
+		logger:   params.Logger,
+		commands: cfg.Commands,
+		timeout:  cfg.Timeout,
+	}
+}
+
+// Retrieve runs the command with the name given by the selector and returns its output.
+func (e *execConfigSource) Retrieve(ctx context.Context, selector string, _ *confmap.Conf, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	command, ok := e.commands[selector]
+	if !ok {
+		return nil, &errUnknownCommand{fmt.Errorf("command %q is not on the list of allowed commands", selector)}
+	}
+
+	output, err := e.run(ctx, selector, command)
+	if err != nil {
+		return nil, err
+	}
+
+	switch command.Format {
+	case formatYAML, formatJSON:
+		value, err := parseOutput(command.Format, output)
+		if err != nil {
+			return nil, &errParseOutput{fmt.Errorf("failed to parse output of command %q as %s: %w", selector, command.Format, err)}
+		}
+		return confmap.NewRetrieved(value)
+	default:
+		return confmap.NewRetrieved(strings.TrimRight(string(output), "\r\n"))
+	}
+}
+
+func (e *execConfigSource) Shutdown(context.Context) error {
+	return nil
+}
+
+func (e *execConfigSource) run(ctx context.Context, name string, command CommandConfig) ([]byte, error) {
+	timeout := e.timeout
+	if command.Timeout > 0 {
+		timeout = command.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command.Path, command.Args...) // #nosec G204 commands are allow-listed by the configuration
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	e.logger.Debug("Running command", zap.String("command", name), zap.String("path", command.Path))
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %v", timeout)
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > maxStderrInError {
+			msg = msg[:maxStderrInError] + "..."
+		}
+		if msg != "" {
+			return nil, &errCommandFailed{fmt.Errorf("command %q failed: %w: %s", name, err, msg)}
+		}
+		return nil, &errCommandFailed{fmt.Errorf("command %q failed: %w", name, err)}
+	}
+
+	return stdout.Bytes(), nil
+}
+
+func parseOutput(format string, output []byte) (any, error) {
+	var value any
+	if format == formatJSON {
+		if err := json.Unmarshal(output, &value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	}
+
+	if err := yaml.Unmarshal(output, &value); err != nil {
+		return nil, err
+	}
+	// yaml.v2 decodes maps as map[any]any, wrap the value so nested maps at any
+	// level get string keys as expected by confmap.
+	wrapper := map[string]any{"value": value}
+	maps.IntfaceKeysToStrings(wrapper)
+	return wrapper["value"], nil
+}
@@ -0,0 +1,168 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package execconfigsource
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestExecConfigSource_Retrieve(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("tests rely on a POSIX shell")
+	}
+
+	commands := map[string]CommandConfig{
+		"token": {
+			Path: "sh",
+			Args: []string{"-c", "echo my-token"},
+		},
+		"yaml": {
+			Path:   "sh",
+			Args:   []string{"-c", "printf 'endpoint: https://example.com\nport: 8080\nnested:\n  key: value\n'"},
+			Format: formatYAML,
+		},
+		"json": {
+			Path:   "sh",
+			Args:   []string{"-c", "echo '{\"token\": \"abc\", \"scopes\": [\"read\"]}'"},
+			Format: formatJSON,
+		},
+		"invalid_json": {
+			Path:   "sh",
+			Args:   []string{"-c", "echo not json"},
+			Format: formatJSON,
+		},
+		"failure": {
+			Path: "sh",
+			Args: []string{"-c", "echo helper failed >&2; exit 3"},
+		},
+		"slow": {
+			Path:    "sh",
+			Args:    []string{"-c", "sleep 5"},
+			Timeout: 100 * time.Millisecond,
+		},
+		"missing_executable": {
+			Path: "./testdata/does-not-exist",
+		},
+	}
+
+	tests := []struct {
+		expected any
+		wantErr  error
+		name     string
+		selector string
+	}{
+		{
+			name:     "raw",
+			selector: "token",
+			expected: "my-token",
+		},
+		{
+			name:     "yaml",
+			selector: "yaml",
+			expected: map[string]any{
+				"endpoint": "https://example.com",
+				"port":     8080,
+				"nested":   map[string]any{"key": "value"},
+			},
+		},
+		{
+			name:     "json",
+			selector: "json",
+			expected: map[string]any{
+				"token":  "abc",
+				"scopes": []any{"read"},
+			},
+		},
+		{
+			name:     "not_allowed",
+			selector: "rm",
+			wantErr:  &errUnknownCommand{},
+		},
+		{
+			name:     "invalid_output",
+			selector: "invalid_json",
+			wantErr:  &errParseOutput{},
+		},
+		{
+			name:     "failure",
+			selector: "failure",
+			wantErr:  &errCommandFailed{},
+		},
+		{
+			name:     "timeout",
+			selector: "slow",
+			wantErr:  &errCommandFailed{},
+		},
+		{
+			name:     "missing_executable",
+			selector: "missing_executable",
+			wantErr:  &errCommandFailed{},
+		},
+	}
+
+	source := &execConfigSource{
+		logger:   zap.NewNop(),
+		commands: commands,
+		timeout:  defaultTimeout,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retrieved, err := source.Retrieve(context.Background(), tt.selector, nil, nil)
+			if tt.wantErr != nil {
+				assert.Nil(t, retrieved)
+				require.IsType(t, tt.wantErr, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, retrieved)
+			value, err := retrieved.AsRaw()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+
+	require.NoError(t, source.Shutdown(context.Background()))
+}
+
+func TestExecConfigSource_FailureIncludesStderr(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("tests rely on a POSIX shell")
+	}
+
+	source := &execConfigSource{
+		logger: zap.NewNop(),
+		commands: map[string]CommandConfig{
+			"failure": {
+				Path: "sh",
+				Args: []string{"-c", "echo not logged in >&2; exit 1"},
+			},
+		},
+		timeout: defaultTimeout,
+	}
+
+	_, err := source.Retrieve(context.Background(), "failure", nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not logged in")
+}
@@ -0,0 +1,34 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fallbackconfigsource
+
+import "github.com/signalfx/splunk-otel-collector/internal/configprovider"
+
+// Config holds the configuration for the creation of fallback config source objects.
+type Config struct {
+	configprovider.SourceSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+	// Default is the value used if none of the Sources can retrieve the value. It is taken
+	// literally and parsed as YAML like the default values of the config source invocations.
+	Default *string `mapstructure:"default"`
+	// Sources are the config source invocations, without the brackets, tried in order until
+	// one retrieves the value, e.g. "vault:secret/data/app?path=$.token" and "env:TOKEN". The
+	// selector of the fallback config source, if any, is appended to each of them.
+	Sources []string `mapstructure:"sources"`
+}
+
+func (*Config) Validate() error {
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fallbackconfigsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+const (
+	// The "type" of fallback config sources in configuration.
+	typeStr = "fallback"
+)
+
+// Private error types to help with testability.
+type (
+	errNoSources      struct{ error }
+	errInvalidSource  struct{ error }
+	errInvalidDefault struct{ error }
+)
+
+type fallbackFactory struct{}
+
+func (f *fallbackFactory) Type() component.Type {
+	return typeStr
+}
+
+func (f *fallbackFactory) CreateDefaultConfig() configprovider.Source {
+	return &Config{
+		SourceSettings: configprovider.NewSourceSettings(component.NewID(typeStr)),
+	}
+}
+
+func (f *fallbackFactory) CreateConfigSource(_ context.Context, _ configprovider.CreateParams, cfg configprovider.Source) (configprovider.ConfigSource, error) {
+	fallbackCfg := cfg.(*Config)
+	if len(fallbackCfg.Sources) == 0 {
+		return nil, &errNoSources{errors.New("at least one source must be specified")}
+	}
+	for _, source := range fallbackCfg.Sources {
+		if name, _, found := strings.Cut(source, ":"); !found || name == "" {
+			return nil, &errInvalidSource{fmt.Errorf("invalid source %q, it must be a config source invocation like \"env:TOKEN\"", source)}
+		}
+		if strings.ContainsAny(source, "{}") || strings.Contains(source, "|-") {
+			return nil, &errInvalidSource{fmt.Errorf("invalid source %q, it can't contain references nor default values", source)}
+		}
+	}
+	if fallbackCfg.Default != nil && strings.Contains(*fallbackCfg.Default, "}") {
+		return nil, &errInvalidDefault{fmt.Errorf("invalid default %q, it can't contain a closing bracket", *fallbackCfg.Default)}
+	}
+	return newConfigSource(fallbackCfg), nil
+}
+
+// NewFactory creates a factory for fallback ConfigSource objects.
+func NewFactory() configprovider.Factory {
+	return &fallbackFactory{}
+}
@@ -0,0 +1,84 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fallbackconfigsource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestFallbackFactory_CreateConfigSource(t *testing.T) {
+	factory := NewFactory()
+	assert.Equal(t, component.Type("fallback"), factory.Type())
+	createParams := configprovider.CreateParams{
+		Logger: zap.NewNop(),
+	}
+	invalidDefault := "{a: 1}"
+	tests := []struct {
+		config  *Config
+		wantErr error
+		name    string
+	}{
+		{
+			name:    "no_sources",
+			config:  &Config{},
+			wantErr: &errNoSources{},
+		},
+		{
+			name:    "missing_name",
+			config:  &Config{Sources: []string{"TOKEN"}},
+			wantErr: &errInvalidSource{},
+		},
+		{
+			name:    "reference",
+			config:  &Config{Sources: []string{"env:${TOKEN_VAR}"}},
+			wantErr: &errInvalidSource{},
+		},
+		{
+			name:    "default_value",
+			config:  &Config{Sources: []string{"env:TOKEN|-dev"}},
+			wantErr: &errInvalidSource{},
+		},
+		{
+			name:    "invalid_default",
+			config:  &Config{Sources: []string{"env:TOKEN"}, Default: &invalidDefault},
+			wantErr: &errInvalidDefault{},
+		},
+		{
+			name:   "valid",
+			config: &Config{Sources: []string{"vault:secret/data/app?path=$.token", "env:TOKEN"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := factory.CreateConfigSource(context.Background(), createParams, tt.config)
+			if tt.wantErr != nil {
+				assert.IsType(t, tt.wantErr, err)
+				assert.Nil(t, actual)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, actual)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fallbackconfigsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+// Private error types to help with testability.
+type (
+	errInvalidParams   struct{ error }
+	errNoResolver      struct{ error }
+	errInvalidSelector struct{ error }
+)
+
+// fallbackConfigSource implements the configprovider.ConfigSource interface. It retrieves the
+// value from the first of its sources able to provide it, chaining their invocations through
+// their default values, so the sources after it aren't retrieved.
+type fallbackConfigSource struct {
+	defaultValue *string
+	sources      []string
+}
+
+var _ configprovider.ConfigSource = (*fallbackConfigSource)(nil)
+
+func newConfigSource(cfg *Config) *fallbackConfigSource {
+	return &fallbackConfigSource{
+		sources:      append([]string(nil), cfg.Sources...),
+		defaultValue: cfg.Default,
+	}
+}
+
+func (fs *fallbackConfigSource) Retrieve(ctx context.Context, selector string, paramsConfigMap *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	if paramsConfigMap != nil && len(paramsConfigMap.AllKeys()) > 0 {
+		return nil, &errInvalidParams{errors.New("the fallback config source doesn't accept parameters, set them on its sources")}
+	}
+	if strings.ContainsAny(selector, "{}") || strings.Contains(selector, "|-") {
+		return nil, &errInvalidSelector{fmt.Errorf("invalid selector %q, it can't contain brackets nor default values", selector)}
+	}
+	resolver, ok := configprovider.ResolverFromContext(ctx)
+	if !ok {
+		return nil, &errNoResolver{errors.New("the fallback config source can only be used in a configuration being resolved")}
+	}
+
+	value, closeFunc, err := resolver(ctx, fs.chain(selector), watcher)
+	if err != nil {
+		return nil, err
+	}
+	if b, ok := value.([]byte); ok {
+		value = string(b)
+	}
+	return confmap.NewRetrieved(value, confmap.WithRetrievedClose(func(ctx context.Context) error {
+		if closeFunc == nil {
+			return nil
+		}
+		return closeFunc(ctx)
+	}))
+}
+
+// chain returns the reference to the sources, each one the default value of the previous one.
+func (fs *fallbackConfigSource) chain(selector string) string {
+	var chain string
+	hasDefault := fs.defaultValue != nil
+	if hasDefault {
+		chain = *fs.defaultValue
+	}
+	for i := len(fs.sources) - 1; i >= 0; i-- {
+		invocation := fs.sources[i] + selector
+		if hasDefault {
+			invocation += "|-" + chain
+		}
+		chain = "${" + invocation + "}"
+		hasDefault = true
+	}
+	return chain
+}
+
+func (fs *fallbackConfigSource) Shutdown(context.Context) error {
+	return nil
+}
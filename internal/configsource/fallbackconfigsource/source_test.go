@@ -0,0 +1,95 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fallbackconfigsource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+	"github.com/signalfx/splunk-otel-collector/internal/configsource/envvarconfigsource"
+)
+
+func TestFallbackConfigSourceChain(t *testing.T) {
+	defaultValue := "dev-token"
+	fs := newConfigSource(&Config{Sources: []string{"vault:secret/data/app?path=$.", "env:APP_"}})
+	assert.Equal(t, "${vault:secret/data/app?path=$.token|-${env:APP_token}}", fs.chain("token"))
+
+	fs = newConfigSource(&Config{Sources: []string{"vault:secret/data/app?path=$.token", "env:TOKEN"}, Default: &defaultValue})
+	assert.Equal(t, "${vault:secret/data/app?path=$.token|-${env:TOKEN|-dev-token}}", fs.chain(""))
+}
+
+func TestFallbackConfigSourceResolve(t *testing.T) {
+	t.Setenv("FALLBACK_TEST_HOST", "collector.local")
+	factories := configprovider.Factories{
+		typeStr:                                NewFactory(),
+		envvarconfigsource.NewFactory().Type(): envvarconfigsource.NewFactory(),
+	}
+	configMap := confmap.NewFromStringMap(map[string]any{
+		"config_sources": map[string]any{
+			"env": nil,
+			"env/defaults": map[string]any{
+				"defaults": map[string]any{"FALLBACK_TEST_TOKEN": "env_token"},
+			},
+			"fallback": map[string]any{
+				"sources": []any{"env:FALLBACK_TEST_", "env/defaults:FALLBACK_TEST_"},
+			},
+			"fallback/default": map[string]any{
+				"sources": []any{"env:FALLBACK_TEST_MISSING"},
+				"default": "dev",
+			},
+		},
+		"host":    "${fallback:HOST}",
+		"token":   "${fallback:TOKEN}",
+		"missing": "${fallback:MISSING|-none}",
+		"default": "${fallback/default:}",
+	})
+
+	res, closeFunc, err := configprovider.Resolve(context.Background(), configMap, zap.NewNop(), component.NewDefaultBuildInfo(), factories, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"host":    "collector.local",
+		"token":   "env_token",
+		"missing": "none",
+		"default": "dev",
+	}, res)
+	if closeFunc != nil {
+		require.NoError(t, closeFunc(context.Background()))
+	}
+
+	configMap = confmap.NewFromStringMap(map[string]any{
+		"config_sources": map[string]any{
+			"env":      nil,
+			"fallback": map[string]any{"sources": []any{"env:FALLBACK_TEST_"}},
+		},
+		"params": "${fallback:HOST?required=true}",
+	})
+	_, _, err = configprovider.Resolve(context.Background(), configMap, zap.NewNop(), component.NewDefaultBuildInfo(), factories, nil)
+	var errParams *errInvalidParams
+	assert.ErrorAs(t, err, &errParams)
+}
+
+func TestFallbackConfigSourceWithoutResolver(t *testing.T) {
+	fs := newConfigSource(&Config{Sources: []string{"env:TOKEN"}})
+	_, err := fs.Retrieve(context.Background(), "", nil, nil)
+	assert.IsType(t, &errNoResolver{}, err)
+}
@@ -0,0 +1,60 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcpluginconfigsource
+
+import (
+	"time"
+
+	"go.opentelemetry.io/collector/config/configtls"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+// Config holds the configuration for the creation of grpcplugin config source objects.
+type Config struct {
+	configprovider.SourceSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// TLS configures the connection to the server. The connection is in plain text
+	// if it isn't set, as expected for servers running as local sidecars.
+	TLS *configtls.TLSClientSetting `mapstructure:"tls"`
+
+	// Endpoint is the gRPC target of the config source server, e.g. "localhost:4320"
+	// or "unix:///var/run/otel/config-source.sock".
+	Endpoint string `mapstructure:"endpoint"`
+
+	// Timeout limits the time to retrieve a value from the server. Defaults to 5 seconds.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+func (*Config) Validate() error {
+	return nil
+}
+
+// PluginConfig holds the configuration for the creation of config sources served by go-plugin
+// binaries, see PluginFactories.
+type PluginConfig struct {
+	configprovider.SourceSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// Settings are passed to the plugin, see ServePlugin.
+	Settings map[string]any `mapstructure:"settings"`
+
+	// Timeout limits the time to retrieve a value from the plugin. Defaults to 5 seconds.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+func (*PluginConfig) Validate() error {
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcpluginconfigsource
+
+import (
+	"context"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestGRPCPluginLoadConfig(t *testing.T) {
+	fileName := path.Join("testdata", "config.yaml")
+	v, err := confmaptest.LoadConf(fileName)
+	require.NoError(t, err)
+
+	factories := map[component.Type]configprovider.Factory{
+		typeStr: NewFactory(),
+	}
+
+	actualSettings, err := configprovider.Load(context.Background(), v, factories)
+	require.NoError(t, err)
+
+	expectedSettings := map[string]configprovider.Source{
+		"grpcplugin": &Config{
+			SourceSettings: configprovider.NewSourceSettings(component.NewID(typeStr)),
+			Endpoint:       "localhost:4320",
+			Timeout:        defaultTimeout,
+		},
+		"grpcplugin/secrets": &Config{
+			SourceSettings: configprovider.NewSourceSettings(component.NewIDWithName(typeStr, "secrets")),
+			Endpoint:       "unix:///var/run/otel/secrets.sock",
+			Timeout:        time.Second,
+			TLS: &configtls.TLSClientSetting{
+				TLSSetting: configtls.TLSSetting{CAFile: "/etc/otel/ca.pem"},
+			},
+		},
+	}
+
+	require.Equal(t, expectedSettings, actualSettings)
+}
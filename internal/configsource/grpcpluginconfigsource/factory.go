@@ -0,0 +1,75 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcpluginconfigsource
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+const (
+	// The "type" of grpcplugin config sources in configuration.
+	typeStr = "grpcplugin"
+
+	defaultTimeout = 5 * time.Second
+)
+
+// Private error types to help with testability.
+type (
+	errMissingEndpoint  struct{ error }
+	errInvalidTimeout   struct{ error }
+	errInvalidTLSConfig struct{ error }
+	errInvalidParams    struct{ error }
+	errInvalidValue     struct{ error }
+	errRetrieveFailed   struct{ error }
+	errPluginStart      struct{ error }
+)
+
+type grpcPluginFactory struct{}
+
+func (f *grpcPluginFactory) Type() component.Type {
+	return typeStr
+}
+
+func (f *grpcPluginFactory) CreateDefaultConfig() configprovider.Source {
+	return &Config{
+		SourceSettings: configprovider.NewSourceSettings(component.NewID(typeStr)),
+		Timeout:        defaultTimeout,
+	}
+}
+
+func (f *grpcPluginFactory) CreateConfigSource(_ context.Context, params configprovider.CreateParams, cfg configprovider.Source) (configprovider.ConfigSource, error) {
+	pluginCfg := cfg.(*Config)
+
+	if pluginCfg.Endpoint == "" {
+		return nil, &errMissingEndpoint{errors.New("cannot connect to a grpcplugin server without an endpoint")}
+	}
+	if pluginCfg.Timeout <= 0 {
+		return nil, &errInvalidTimeout{errors.New("timeout must be positive")}
+	}
+
+	return newConfigSource(params, pluginCfg)
+}
+
+// NewFactory creates a factory for grpcplugin ConfigSource objects.
+func NewFactory() configprovider.Factory {
+	return &grpcPluginFactory{}
+}
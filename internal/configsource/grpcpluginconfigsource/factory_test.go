@@ -0,0 +1,91 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcpluginconfigsource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestGRPCPluginFactory_CreateConfigSource(t *testing.T) {
+	factory := NewFactory()
+	assert.Equal(t, component.Type("grpcplugin"), factory.Type())
+	createParams := configprovider.CreateParams{
+		Logger: zap.NewNop(),
+	}
+	tests := []struct {
+		wantErr error
+		config  *Config
+		name    string
+	}{
+		{
+			name:    "missing_endpoint",
+			config:  &Config{Timeout: defaultTimeout},
+			wantErr: &errMissingEndpoint{},
+		},
+		{
+			name:    "invalid_timeout",
+			config:  &Config{Endpoint: "localhost:4320"},
+			wantErr: &errInvalidTimeout{},
+		},
+		{
+			name: "invalid_tls",
+			config: &Config{
+				Endpoint: "localhost:4320",
+				Timeout:  defaultTimeout,
+				TLS: &configtls.TLSClientSetting{
+					TLSSetting: configtls.TLSSetting{CAFile: "testdata/not-found.pem"},
+				},
+			},
+			wantErr: &errInvalidTLSConfig{},
+		},
+		{
+			name: "success_tls",
+			config: &Config{
+				Endpoint: "localhost:4320",
+				Timeout:  defaultTimeout,
+				TLS:      &configtls.TLSClientSetting{ServerName: "plugin"},
+			},
+		},
+		{
+			name: "success",
+			config: &Config{
+				Endpoint: "localhost:4320",
+				Timeout:  defaultTimeout,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := factory.CreateConfigSource(context.Background(), createParams, tt.config)
+			require.IsType(t, tt.wantErr, err)
+			if tt.wantErr == nil {
+				require.NotNil(t, actual)
+				assert.NoError(t, actual.Shutdown(context.Background()))
+			} else {
+				assert.Nil(t, actual)
+			}
+		})
+	}
+}
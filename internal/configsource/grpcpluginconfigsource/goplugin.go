@@ -0,0 +1,194 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcpluginconfigsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	"go.opentelemetry.io/collector/component"
+	"google.golang.org/grpc"
+	"gopkg.in/yaml.v3"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+const (
+	// PluginPrefix is the prefix of the names of the go-plugin binaries, followed by the
+	// type of the config source, e.g. "otelcol-configsource-consul" for "consul".
+	PluginPrefix = "otelcol-configsource-"
+
+	// pluginName is the name of the config source in the go-plugin plugin sets.
+	pluginName = "configsource"
+	// pluginSettingsEnvVar passes the YAML encoded settings of the config source to the plugin.
+	pluginSettingsEnvVar = "SPLUNK_CONFIG_SOURCE_PLUGIN_SETTINGS"
+)
+
+// Handshake is the go-plugin handshake of the config source plugins. Its protocol version is the
+// one of the plugin.proto contract, binaries built for an incompatible version are rejected.
+var Handshake = plugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "SPLUNK_OTEL_CONFIG_SOURCE_PLUGIN",
+	MagicCookieValue: "8c9e2f47-3b8a-4d0e-a5b4-0f1c6e7d2a91",
+}
+
+var pluginTypeRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+// ServePlugin serves the config source created by newServer in a go-plugin binary, it must be
+// called by the main function of the binary. newServer receives the settings of the config
+// source from the collector configuration.
+func ServePlugin(newServer func(settings map[string]any) (PluginServer, error)) {
+	settings := map[string]any{}
+	if err := yaml.Unmarshal([]byte(os.Getenv(pluginSettingsEnvVar)), &settings); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid config source settings: %v\n", err)
+		os.Exit(1)
+	}
+	server, err := newServer(settings)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create the config source: %v\n", err)
+		os.Exit(1)
+	}
+
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig:  Handshake,
+		VersionedPlugins: pluginSets(server),
+		GRPCServer: func(opts []grpc.ServerOption) *grpc.Server {
+			return grpc.NewServer(append(opts, grpc.ForceServerCodec(codec{}))...)
+		},
+	})
+}
+
+// PluginFactories returns the factories of the config sources served by the go-plugin binaries
+// named with the PluginPrefix in the directory, see ServePlugin. The plugin processes are started
+// when the config sources are created.
+func PluginFactories(dir string) ([]configprovider.Factory, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the config source plugins directory: %w", err)
+	}
+
+	var factories []configprovider.Factory
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, PluginPrefix) {
+			continue
+		}
+		typ := strings.TrimPrefix(name, PluginPrefix)
+		if runtime.GOOS == "windows" {
+			if !strings.HasSuffix(typ, ".exe") {
+				continue
+			}
+			typ = strings.TrimSuffix(typ, ".exe")
+		} else if info, err := entry.Info(); err != nil || info.Mode()&0o111 == 0 {
+			// Not executable
+			continue
+		}
+		if !pluginTypeRe.MatchString(typ) {
+			return nil, fmt.Errorf("invalid config source plugin %q, the type after %q must be alphanumeric", name, PluginPrefix)
+		}
+		factories = append(factories, &pluginFactory{typ: component.Type(typ), path: filepath.Join(dir, name)})
+	}
+	return factories, nil
+}
+
+type pluginFactory struct {
+	typ  component.Type
+	path string
+}
+
+func (f *pluginFactory) Type() component.Type {
+	return f.typ
+}
+
+func (f *pluginFactory) CreateDefaultConfig() configprovider.Source {
+	return &PluginConfig{
+		SourceSettings: configprovider.NewSourceSettings(component.NewID(f.typ)),
+		Timeout:        defaultTimeout,
+	}
+}
+
+func (f *pluginFactory) CreateConfigSource(_ context.Context, params configprovider.CreateParams, cfg configprovider.Source) (configprovider.ConfigSource, error) {
+	pluginCfg := cfg.(*PluginConfig)
+	if pluginCfg.Timeout <= 0 {
+		return nil, &errInvalidTimeout{errors.New("timeout must be positive")}
+	}
+
+	settings, err := yaml.Marshal(pluginCfg.Settings)
+	if err != nil {
+		return nil, &errInvalidParams{fmt.Errorf("failed to marshal the plugin settings: %w", err)}
+	}
+	cmd := exec.Command(f.path)
+	cmd.Env = append(os.Environ(), pluginSettingsEnvVar+"="+string(settings))
+
+	client := plugin.NewClient(&plugin.ClientConfig{
+		HandshakeConfig:  Handshake,
+		VersionedPlugins: pluginSets(nil),
+		Cmd:              cmd,
+		AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
+		Logger:           hclog.New(&hclog.LoggerOptions{Name: "configsource." + string(f.typ), Level: hclog.Info}),
+	})
+	rpcClient, err := client.Client()
+	if err != nil {
+		client.Kill()
+		return nil, &errPluginStart{fmt.Errorf("failed to start the config source plugin %q: %w", f.path, err)}
+	}
+	raw, err := rpcClient.Dispense(pluginName)
+	if err != nil {
+		client.Kill()
+		return nil, &errPluginStart{fmt.Errorf("failed to start the config source plugin %q: %w", f.path, err)}
+	}
+
+	return &grpcPluginConfigSource{
+		logger: params.Logger,
+		client: raw.(*pluginClient),
+		shutdown: func() error {
+			client.Kill()
+			return nil
+		},
+		timeout: pluginCfg.Timeout,
+	}, nil
+}
+
+// pluginSets returns the plugin sets by protocol version, server is nil on the collector side.
+func pluginSets(server PluginServer) map[int]plugin.PluginSet {
+	return map[int]plugin.PluginSet{
+		1: {pluginName: &configSourcePlugin{server: server}},
+	}
+}
+
+// configSourcePlugin implements plugin.GRPCPlugin for the ConfigSource service.
+type configSourcePlugin struct {
+	plugin.NetRPCUnsupportedPlugin
+	server PluginServer
+}
+
+func (p *configSourcePlugin) GRPCServer(_ *plugin.GRPCBroker, s *grpc.Server) error {
+	s.RegisterService(&serviceDesc, p.server)
+	return nil
+}
+
+func (p *configSourcePlugin) GRPCClient(_ context.Context, _ *plugin.GRPCBroker, conn *grpc.ClientConn) (any, error) {
+	return &pluginClient{conn: conn}, nil
+}
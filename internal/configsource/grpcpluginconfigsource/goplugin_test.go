@@ -0,0 +1,117 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcpluginconfigsource
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+// pluginHelperEnvVar makes the test binary serve a config source plugin, see TestMain.
+const pluginHelperEnvVar = "GRPCPLUGIN_TEST_PLUGIN_HELPER"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(pluginHelperEnvVar) == "1" {
+		ServePlugin(func(settings map[string]any) (PluginServer, error) {
+			return &mockPluginServer{
+				values: map[string]string{"greeting": fmt.Sprint(settings["greeting"])},
+				closed: make(chan string, 1),
+			}, nil
+		})
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestPluginFactories(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test binary is linked as a plugin")
+	}
+	testBinary, err := os.Executable()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.Symlink(testBinary, filepath.Join(dir, PluginPrefix+"greeter")))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, PluginPrefix+"not_executable"), nil, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), nil, 0o600))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, PluginPrefix+"dir"), 0o700))
+	t.Setenv(pluginHelperEnvVar, "1")
+
+	factories, err := PluginFactories(dir)
+	require.NoError(t, err)
+	require.Len(t, factories, 1)
+	factory := factories[0]
+	assert.Equal(t, component.Type("greeter"), factory.Type())
+
+	cfg := factory.CreateDefaultConfig().(*PluginConfig)
+	assert.Equal(t, defaultTimeout, cfg.Timeout)
+	cfg.Settings = map[string]any{"greeting": "hello"}
+	source, err := factory.CreateConfigSource(context.Background(), configprovider.CreateParams{Logger: zap.NewNop()}, cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, source.Shutdown(context.Background()))
+	})
+
+	retrieved, err := source.Retrieve(context.Background(), "greeting", nil, nil)
+	require.NoError(t, err)
+	value, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", value)
+
+	_, err = source.Retrieve(context.Background(), "missing", nil, nil)
+	require.IsType(t, &errRetrieveFailed{}, err)
+}
+
+func TestPluginFactoriesErrors(t *testing.T) {
+	_, err := PluginFactories(filepath.Join(t.TempDir(), "missing"))
+	require.ErrorContains(t, err, "failed to read the config source plugins directory")
+
+	if runtime.GOOS == "windows" {
+		t.Skip("the test plugins are shell scripts")
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, PluginPrefix+"invalid-type"), nil, 0o700))
+	_, err = PluginFactories(dir)
+	require.ErrorContains(t, err, "invalid config source plugin")
+
+	dir = t.TempDir()
+	// The plugin exits without the go-plugin handshake.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, PluginPrefix+"broken"), []byte("#!/bin/sh\nexit 1\n"), 0o700))
+	factories, err := PluginFactories(dir)
+	require.NoError(t, err)
+	require.Len(t, factories, 1)
+
+	cfg := factories[0].CreateDefaultConfig()
+	source, err := factories[0].CreateConfigSource(context.Background(), configprovider.CreateParams{Logger: zap.NewNop()}, cfg)
+	require.IsType(t, &errPluginStart{}, err)
+	assert.Nil(t, source)
+
+	cfg.(*PluginConfig).Timeout = 0
+	_, err = factories[0].CreateConfigSource(context.Background(), configprovider.CreateParams{Logger: zap.NewNop()}, cfg)
+	require.IsType(t, &errInvalidTimeout{}, err)
+}
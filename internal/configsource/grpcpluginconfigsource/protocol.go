@@ -0,0 +1,328 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcpluginconfigsource
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/proto" // registers the default proto codec
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The messages below mirror the ones in plugin.proto and are encoded in the protobuf wire
+// format by codec, so servers generated from plugin.proto in any language are compatible.
+
+const serviceName = "splunk.otel.configsource.v1.ConfigSource"
+
+// RetrieveRequest is the request of the ConfigSource.Retrieve RPC.
+type RetrieveRequest struct {
+	Selector string
+	// Params is the YAML, or JSON, encoded map of the params, empty if there are none.
+	Params []byte
+	Watch  bool
+}
+
+// RetrieveResponse is the response of the ConfigSource.Retrieve RPC.
+type RetrieveResponse struct {
+	// Value is the YAML, or JSON, encoded value.
+	Value   []byte
+	WatchID string
+}
+
+// WatchRequest is the request of the ConfigSource.Watch RPC.
+type WatchRequest struct {
+	WatchID string
+}
+
+// WatchEvent is streamed by the ConfigSource.Watch RPC.
+type WatchEvent struct {
+	Error string
+}
+
+// CloseRequest is the request of the ConfigSource.Close RPC.
+type CloseRequest struct {
+	WatchID string
+}
+
+// CloseResponse is the response of the ConfigSource.Close RPC.
+type CloseResponse struct{}
+
+// PluginServer is implemented by the config source servers written in Go, see NewServer.
+type PluginServer interface {
+	Retrieve(context.Context, *RetrieveRequest) (*RetrieveResponse, error)
+	// Watch sends a single event to the stream once the watched value changes or the watch
+	// fails, and returns. The context of the stream is canceled if the value isn't used anymore.
+	Watch(*WatchRequest, WatchStream) error
+	Close(context.Context, *CloseRequest) (*CloseResponse, error)
+}
+
+// WatchStream is the server side of the ConfigSource.Watch stream.
+type WatchStream interface {
+	Context() context.Context
+	Send(*WatchEvent) error
+}
+
+// NewServer returns a gRPC server serving the ConfigSource service implemented by srv.
+func NewServer(srv PluginServer, opts ...grpc.ServerOption) *grpc.Server {
+	server := grpc.NewServer(append(opts, grpc.ForceServerCodec(codec{}))...)
+	server.RegisterService(&serviceDesc, srv)
+	return server
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*PluginServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Retrieve",
+			Handler: func(srv any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+				req := &RetrieveRequest{}
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				return srv.(PluginServer).Retrieve(ctx, req)
+			},
+		},
+		{
+			MethodName: "Close",
+			Handler: func(srv any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+				req := &CloseRequest{}
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				return srv.(PluginServer).Close(ctx, req)
+			},
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			ServerStreams: true,
+			Handler: func(srv any, stream grpc.ServerStream) error {
+				req := &WatchRequest{}
+				if err := stream.RecvMsg(req); err != nil {
+					return err
+				}
+				return srv.(PluginServer).Watch(req, &watchServerStream{stream})
+			},
+		},
+	},
+	Metadata: "plugin.proto",
+}
+
+type watchServerStream struct {
+	grpc.ServerStream
+}
+
+func (s *watchServerStream) Send(event *WatchEvent) error {
+	return s.ServerStream.SendMsg(event)
+}
+
+// pluginClient is the client side of the ConfigSource service.
+type pluginClient struct {
+	conn *grpc.ClientConn
+}
+
+func (c *pluginClient) Retrieve(ctx context.Context, req *RetrieveRequest) (*RetrieveResponse, error) {
+	resp := &RetrieveResponse{}
+	if err := c.conn.Invoke(ctx, "/"+serviceName+"/Retrieve", req, resp, grpc.ForceCodec(codec{})); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Watch blocks until the first event of the watch is received.
+func (c *pluginClient) Watch(ctx context.Context, req *WatchRequest) (*WatchEvent, error) {
+	stream, err := c.conn.NewStream(ctx, &serviceDesc.Streams[0], "/"+serviceName+"/Watch", grpc.ForceCodec(codec{}))
+	if err != nil {
+		return nil, err
+	}
+	if err = stream.SendMsg(req); err != nil {
+		return nil, err
+	}
+	if err = stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	event := &WatchEvent{}
+	if err = stream.RecvMsg(event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (c *pluginClient) Close(ctx context.Context, req *CloseRequest) error {
+	return c.conn.Invoke(ctx, "/"+serviceName+"/Close", req, &CloseResponse{}, grpc.ForceCodec(codec{}))
+}
+
+// wireMessage is implemented by the messages to encode them in the protobuf wire format.
+type wireMessage interface {
+	marshal([]byte) []byte
+	unmarshal(num protowire.Number, typ protowire.Type, b []byte) int
+}
+
+// codec is a gRPC codec for the messages, registered under the "proto" name so the content
+// type of the requests is the one expected by the servers generated from plugin.proto. Other
+// messages, e.g. the ones of the go-plugin internal services, use the default proto codec.
+type codec struct{}
+
+func (codec) Name() string {
+	return "proto"
+}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	msg, ok := v.(wireMessage)
+	if !ok {
+		return encoding.GetCodec("proto").Marshal(v)
+	}
+	return msg.marshal(nil), nil
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	msg, ok := v.(wireMessage)
+	if !ok {
+		return encoding.GetCodec("proto").Unmarshal(data, v)
+	}
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		n = msg.unmarshal(num, typ, data)
+		if n == 0 {
+			// Unknown fields are skipped.
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+func (r *RetrieveRequest) marshal(b []byte) []byte {
+	b = appendString(b, 1, r.Selector)
+	b = appendBytes(b, 2, r.Params)
+	if r.Watch {
+		b = protowire.AppendTag(b, 3, protowire.VarintType)
+		b = protowire.AppendVarint(b, 1)
+	}
+	return b
+}
+
+func (r *RetrieveRequest) unmarshal(num protowire.Number, typ protowire.Type, b []byte) (n int) {
+	switch {
+	case num == 1 && typ == protowire.BytesType:
+		r.Selector, n = consumeString(b)
+	case num == 2 && typ == protowire.BytesType:
+		r.Params, n = consumeBytes(b)
+	case num == 3 && typ == protowire.VarintType:
+		var v uint64
+		v, n = protowire.ConsumeVarint(b)
+		r.Watch = v != 0
+	}
+	return n
+}
+
+func (r *RetrieveResponse) marshal(b []byte) []byte {
+	b = appendBytes(b, 1, r.Value)
+	return appendString(b, 2, r.WatchID)
+}
+
+func (r *RetrieveResponse) unmarshal(num protowire.Number, typ protowire.Type, b []byte) (n int) {
+	switch {
+	case num == 1 && typ == protowire.BytesType:
+		r.Value, n = consumeBytes(b)
+	case num == 2 && typ == protowire.BytesType:
+		r.WatchID, n = consumeString(b)
+	}
+	return n
+}
+
+func (r *WatchRequest) marshal(b []byte) []byte {
+	return appendString(b, 1, r.WatchID)
+}
+
+func (r *WatchRequest) unmarshal(num protowire.Number, typ protowire.Type, b []byte) (n int) {
+	if num == 1 && typ == protowire.BytesType {
+		r.WatchID, n = consumeString(b)
+	}
+	return n
+}
+
+func (e *WatchEvent) marshal(b []byte) []byte {
+	return appendString(b, 1, e.Error)
+}
+
+func (e *WatchEvent) unmarshal(num protowire.Number, typ protowire.Type, b []byte) (n int) {
+	if num == 1 && typ == protowire.BytesType {
+		e.Error, n = consumeString(b)
+	}
+	return n
+}
+
+func (r *CloseRequest) marshal(b []byte) []byte {
+	return appendString(b, 1, r.WatchID)
+}
+
+func (r *CloseRequest) unmarshal(num protowire.Number, typ protowire.Type, b []byte) (n int) {
+	if num == 1 && typ == protowire.BytesType {
+		r.WatchID, n = consumeString(b)
+	}
+	return n
+}
+
+func (*CloseResponse) marshal(b []byte) []byte {
+	return b
+}
+
+func (*CloseResponse) unmarshal(protowire.Number, protowire.Type, []byte) int {
+	return 0
+}
+
+// appendString appends a string field, omitted if empty as in proto3.
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+// appendBytes appends a bytes field, omitted if empty as in proto3.
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func consumeString(b []byte) (string, int) {
+	v, n := protowire.ConsumeBytes(b)
+	return string(v), n
+}
+
+func consumeBytes(b []byte) ([]byte, int) {
+	v, n := protowire.ConsumeBytes(b)
+	if n < 0 {
+		return nil, n
+	}
+	return append([]byte(nil), v...), n
+}
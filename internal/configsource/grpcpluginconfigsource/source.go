@@ -0,0 +1,130 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcpluginconfigsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"gopkg.in/yaml.v3"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+// grpcPluginConfigSource implements the configprovider.ConfigSource interface
+// by forwarding the calls to an out-of-process config source server.
+type grpcPluginConfigSource struct {
+	logger *zap.Logger
+	client *pluginClient
+	// shutdown closes the connection to the server, or stops the plugin process.
+	shutdown func() error
+	timeout  time.Duration
+}
+
+func newConfigSource(params configprovider.CreateParams, cfg *Config) (configprovider.ConfigSource, error) {
+	creds := insecure.NewCredentials()
+	if cfg.TLS != nil {
+		tlsCfg, err := cfg.TLS.LoadTLSConfig()
+		if err != nil {
+			return nil, &errInvalidTLSConfig{fmt.Errorf("failed to load TLS config: %w", err)}
+		}
+		if tlsCfg != nil {
+			creds = credentials.NewTLS(tlsCfg)
+		}
+	}
+
+	// The connection is established lazily, the server may start after the collector.
+	conn, err := grpc.Dial(cfg.Endpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+
+	return &grpcPluginConfigSource{
+		logger:   params.Logger,
+		client:   &pluginClient{conn: conn},
+		shutdown: conn.Close,
+		timeout:  cfg.Timeout,
+	}, nil
+}
+
+func (s *grpcPluginConfigSource) Retrieve(ctx context.Context, selector string, paramsConfigMap *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	req := &RetrieveRequest{Selector: selector, Watch: watcher != nil}
+	if paramsConfigMap != nil {
+		params, err := yaml.Marshal(paramsConfigMap.ToStringMap())
+		if err != nil {
+			return nil, &errInvalidParams{fmt.Errorf("failed to marshal retrieve params: %w", err)}
+		}
+		req.Params = params
+	}
+
+	retrieveCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	resp, err := s.client.Retrieve(retrieveCtx, req)
+	if err != nil {
+		return nil, &errRetrieveFailed{fmt.Errorf("failed to retrieve %q: %w", selector, err)}
+	}
+
+	var value any
+	if err = yaml.Unmarshal(resp.Value, &value); err != nil {
+		return nil, &errInvalidValue{fmt.Errorf("failed to unmarshal the value of %q: %w", selector, err)}
+	}
+
+	if watcher == nil || resp.WatchID == "" {
+		return confmap.NewRetrieved(value)
+	}
+	return confmap.NewRetrieved(value, confmap.WithRetrievedClose(s.newWatcher(selector, resp.WatchID, watcher)))
+}
+
+func (s *grpcPluginConfigSource) Shutdown(context.Context) error {
+	return s.shutdown()
+}
+
+func (s *grpcPluginConfigSource) newWatcher(selector, watchID string, watcherFunc confmap.WatcherFunc) confmap.CloseFunc {
+	watchCtx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		event, err := s.client.Watch(watchCtx, &WatchRequest{WatchID: watchID})
+		switch {
+		case watchCtx.Err() != nil:
+			return
+		case err != nil:
+			s.logger.Info("error watching", zap.String("selector", selector), zap.Error(err))
+			watcherFunc(&confmap.ChangeEvent{Error: fmt.Errorf("failed to watch %q: %w", selector, err)})
+		case event.Error != "":
+			watcherFunc(&confmap.ChangeEvent{Error: errors.New(event.Error)})
+		default:
+			// Value updated
+			watcherFunc(&confmap.ChangeEvent{Error: nil})
+		}
+	}()
+
+	return func(ctx context.Context) error {
+		cancel()
+		closeCtx, closeCancel := context.WithTimeout(ctx, s.timeout)
+		defer closeCancel()
+		if err := s.client.Close(closeCtx, &CloseRequest{WatchID: watchID}); err != nil {
+			return fmt.Errorf("failed to close the watch of %q: %w", selector, err)
+		}
+		return nil
+	}
+}
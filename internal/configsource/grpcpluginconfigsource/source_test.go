@@ -0,0 +1,198 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcpluginconfigsource
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+type mockPluginServer struct {
+	values   map[string]string
+	changes  chan string
+	closed   chan string
+	requests []*RetrieveRequest
+	mu       sync.Mutex
+}
+
+func (m *mockPluginServer) Retrieve(_ context.Context, req *RetrieveRequest) (*RetrieveResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, req)
+	value, ok := m.values[req.Selector]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	resp := &RetrieveResponse{Value: []byte(value)}
+	if req.Watch {
+		resp.WatchID = req.Selector
+	}
+	return resp, nil
+}
+
+func (m *mockPluginServer) Watch(req *WatchRequest, stream WatchStream) error {
+	select {
+	case event := <-m.changes:
+		return stream.Send(&WatchEvent{Error: event})
+	case <-stream.Context().Done():
+		return nil
+	}
+}
+
+func (m *mockPluginServer) Close(_ context.Context, req *CloseRequest) (*CloseResponse, error) {
+	m.closed <- req.WatchID
+	return &CloseResponse{}, nil
+}
+
+func startPluginServer(t *testing.T, m *mockPluginServer) configprovider.ConfigSource {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	server := NewServer(m)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	source, err := newConfigSource(
+		configprovider.CreateParams{Logger: zap.NewNop()},
+		&Config{Endpoint: listener.Addr().String(), Timeout: 5 * time.Second},
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, source.Shutdown(context.Background()))
+	})
+	return source
+}
+
+func TestGRPCPluginConfigSourceRetrieve(t *testing.T) {
+	m := &mockPluginServer{
+		values: map[string]string{
+			"token":    "abc",
+			"endpoint": `{"host": "localhost", "port": 8080}`,
+			"empty":    "",
+		},
+	}
+	source := startPluginServer(t, m)
+
+	retrieved, err := source.Retrieve(context.Background(), "token", nil, nil)
+	require.NoError(t, err)
+	value, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, "abc", value)
+
+	params := confmap.NewFromStringMap(map[string]any{"version": 2})
+	retrieved, err = source.Retrieve(context.Background(), "endpoint", params, nil)
+	require.NoError(t, err)
+	value, err = retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"host": "localhost", "port": 8080}, value)
+
+	retrieved, err = source.Retrieve(context.Background(), "empty", nil, nil)
+	require.NoError(t, err)
+	value, err = retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	_, err = source.Retrieve(context.Background(), "missing", nil, nil)
+	require.IsType(t, &errRetrieveFailed{}, err)
+	assert.ErrorContains(t, err, "not found")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	require.Len(t, m.requests, 4)
+	assert.Equal(t, &RetrieveRequest{Selector: "endpoint", Params: []byte("version: 2\n")}, m.requests[1])
+}
+
+func TestGRPCPluginConfigSourceInvalidValue(t *testing.T) {
+	source := startPluginServer(t, &mockPluginServer{values: map[string]string{"bad": "{"}})
+	_, err := source.Retrieve(context.Background(), "bad", nil, nil)
+	require.IsType(t, &errInvalidValue{}, err)
+}
+
+func TestGRPCPluginConfigSourceWatch(t *testing.T) {
+	m := &mockPluginServer{
+		values:  map[string]string{"token": "abc"},
+		changes: make(chan string, 1),
+		closed:  make(chan string, 1),
+	}
+	source := startPluginServer(t, m)
+
+	events := make(chan *confmap.ChangeEvent, 1)
+	watcher := func(event *confmap.ChangeEvent) {
+		events <- event
+	}
+
+	retrieved, err := source.Retrieve(context.Background(), "token", nil, watcher)
+	require.NoError(t, err)
+	m.changes <- ""
+	select {
+	case event := <-events:
+		assert.NoError(t, event.Error)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "no change event")
+	}
+	require.NoError(t, retrieved.Close(context.Background()))
+	assert.Equal(t, "token", <-m.closed)
+
+	retrieved, err = source.Retrieve(context.Background(), "token", nil, watcher)
+	require.NoError(t, err)
+	m.changes <- "lost connection to the backend"
+	select {
+	case event := <-events:
+		assert.EqualError(t, event.Error, "lost connection to the backend")
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "no change event")
+	}
+	require.NoError(t, retrieved.Close(context.Background()))
+	<-m.closed
+
+	// Closing before any change cancels the watch without events.
+	retrieved, err = source.Retrieve(context.Background(), "token", nil, watcher)
+	require.NoError(t, err)
+	require.NoError(t, retrieved.Close(context.Background()))
+	<-m.closed
+	select {
+	case event := <-events:
+		assert.Fail(t, "unexpected change event", "%v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestCodecSkipsUnknownFields(t *testing.T) {
+	b := (&RetrieveResponse{Value: []byte("abc"), WatchID: "id"}).marshal(nil)
+	b = protowire.AppendTag(b, 10, protowire.VarintType)
+	b = protowire.AppendVarint(b, 42)
+
+	resp := &RetrieveResponse{}
+	require.NoError(t, codec{}.Unmarshal(b, resp))
+	assert.Equal(t, &RetrieveResponse{Value: []byte("abc"), WatchID: "id"}, resp)
+
+	require.Error(t, codec{}.Unmarshal([]byte{0x0a, 0x05}, resp))
+	_, err := codec{}.Marshal("not a message")
+	require.Error(t, err)
+}
@@ -15,7 +15,11 @@
 
 package includeconfigsource
 
-import "github.com/signalfx/splunk-otel-collector/internal/configprovider"
+import (
+	"time"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
 
 // Config holds the configuration for the creation of include config source objects.
 type Config struct {
@@ -29,6 +33,42 @@ type Config struct {
 	// be watched for updates or not. The default value is 'false'.
 	// Set it to 'true' to watch the referenced files for changes.
 	WatchFiles bool `mapstructure:"watch_files"`
+	// RelativeToConfigFile is used to resolve relative selectors from the
+	// directory of the configuration file referencing them instead of the
+	// current working directory. The default value is 'false'. Selectors
+	// referenced from configurations that aren't files, e.g. provided via
+	// env vars, are always resolved from the current working directory.
+	RelativeToConfigFile bool `mapstructure:"relative_to_config_file"`
+	// NestedResolution is used to resolve the config source invocations,
+	// including further includes, in the included files. The default value
+	// is 'false'. When set to 'true' the included content is always parsed
+	// as YAML, unless another format is selected, before being resolved.
+	NestedResolution bool `mapstructure:"nested_resolution"`
+	// MaxIncludeDepth is the maximum number of files in a chain of nested
+	// includes when NestedResolution is 'true'. The default value is 10.
+	MaxIncludeDepth int `mapstructure:"max_include_depth"`
+	// WatchMode selects how files are watched when WatchFiles is 'true':
+	// "auto", the default, uses file system notifications and falls back to
+	// polling if they aren't available, "fsnotify" only uses notifications,
+	// and "poll" only uses polling, e.g. for network file systems.
+	WatchMode string `mapstructure:"watch_mode"`
+	// WatchPollInterval is the interval at which the files are checked for
+	// changes when they are polled. The default value is 5s.
+	WatchPollInterval time.Duration `mapstructure:"watch_poll_interval"`
+	// Verify, when set, requires every included file to have a valid
+	// signature in a file next to it. Files without one are refused.
+	Verify *VerifyConfig `mapstructure:"verify"`
+}
+
+// VerifyConfig holds the public key used to verify the signatures of the
+// included files. Exactly one of the keys must be set.
+type VerifyConfig struct {
+	// MinisignPublicKey is the base64 minisign public key, as found on the
+	// second line of the ".pub" file. Signatures are read from "<file>.minisig".
+	MinisignPublicKey string `mapstructure:"minisign_public_key"`
+	// CosignPublicKeyFile is the path of the PEM encoded ECDSA public key
+	// used by "cosign sign-blob". Signatures are read from "<file>.sig".
+	CosignPublicKeyFile string `mapstructure:"cosign_public_key_file"`
 }
 
 func (*Config) Validate() error {
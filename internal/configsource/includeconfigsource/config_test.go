@@ -19,6 +19,7 @@ import (
 	"context"
 	"path"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -43,15 +44,38 @@ func TestIncludeConfigSourceLoadConfig(t *testing.T) {
 
 	expectedSettings := map[string]configprovider.Source{
 		"include": &Config{
-			SourceSettings: configprovider.NewSourceSettings(component.NewID(typeStr)),
+			SourceSettings:    configprovider.NewSourceSettings(component.NewID(typeStr)),
+			WatchMode:         watchModeAuto,
+			WatchPollInterval: defaultWatchPollInterval,
+			MaxIncludeDepth:   defaultMaxIncludeDepth,
 		},
 		"include/delete_files": &Config{
-			SourceSettings: configprovider.NewSourceSettings(component.NewIDWithName(typeStr, "delete_files")),
-			DeleteFiles:    true,
+			SourceSettings:    configprovider.NewSourceSettings(component.NewIDWithName(typeStr, "delete_files")),
+			DeleteFiles:       true,
+			WatchMode:         watchModeAuto,
+			WatchPollInterval: defaultWatchPollInterval,
+			MaxIncludeDepth:   defaultMaxIncludeDepth,
 		},
 		"include/watch_files": &Config{
-			SourceSettings: configprovider.NewSourceSettings(component.NewIDWithName(typeStr, "watch_files")),
-			WatchFiles:     true,
+			SourceSettings:    configprovider.NewSourceSettings(component.NewIDWithName(typeStr, "watch_files")),
+			WatchFiles:        true,
+			WatchMode:         watchModeAuto,
+			WatchPollInterval: defaultWatchPollInterval,
+			MaxIncludeDepth:   defaultMaxIncludeDepth,
+		},
+		"include/watch_files_poll": &Config{
+			SourceSettings:    configprovider.NewSourceSettings(component.NewIDWithName(typeStr, "watch_files_poll")),
+			WatchFiles:        true,
+			WatchMode:         watchModePoll,
+			WatchPollInterval: 30 * time.Second,
+			MaxIncludeDepth:   defaultMaxIncludeDepth,
+		},
+		"include/nested": &Config{
+			SourceSettings:    configprovider.NewSourceSettings(component.NewIDWithName(typeStr, "nested")),
+			NestedResolution:  true,
+			WatchMode:         watchModeAuto,
+			WatchPollInterval: defaultWatchPollInterval,
+			MaxIncludeDepth:   3,
 		},
 	}
 
@@ -17,6 +17,7 @@ package includeconfigsource
 
 import (
 	"context"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 
@@ -26,6 +27,12 @@ import (
 const (
 	// The "type" of file config sources in configuration.
 	typeStr = "include"
+
+	watchModeAuto     = "auto"
+	watchModeFSNotify = "fsnotify"
+	watchModePoll     = "poll"
+
+	defaultWatchPollInterval = 5 * time.Second
 )
 
 type includeFactory struct{}
@@ -36,7 +43,10 @@ func (f *includeFactory) Type() component.Type {
 
 func (f *includeFactory) CreateDefaultConfig() configprovider.Source {
 	return &Config{
-		SourceSettings: configprovider.NewSourceSettings(component.NewID(typeStr)),
+		SourceSettings:    configprovider.NewSourceSettings(component.NewID(typeStr)),
+		WatchMode:         watchModeAuto,
+		WatchPollInterval: defaultWatchPollInterval,
+		MaxIncludeDepth:   defaultMaxIncludeDepth,
 	}
 }
 
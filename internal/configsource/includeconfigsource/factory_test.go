@@ -18,6 +18,7 @@ package includeconfigsource
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.opentelemetry.io/collector/component"
@@ -42,26 +43,67 @@ func TestIncludeConfigSourceFactory_CreateConfigSource(t *testing.T) {
 		{
 			name: "default",
 			expected: &includeConfigSource{
-				Config:       &Config{},
-				watchedFiles: make(map[string]struct{}),
+				Config: &Config{},
+				logger: zap.NewNop(),
 			},
 		},
 		{
 			name:   "delete_files",
 			config: Config{DeleteFiles: true},
 			expected: &includeConfigSource{
-				Config:       &Config{DeleteFiles: true},
-				watchedFiles: make(map[string]struct{}),
+				Config: &Config{DeleteFiles: true},
+				logger: zap.NewNop(),
 			},
 		},
 		{
 			name:   "watch_files",
 			config: Config{WatchFiles: true},
 			expected: &includeConfigSource{
-				Config:       &Config{WatchFiles: true},
-				watchedFiles: make(map[string]struct{}),
+				Config: &Config{WatchFiles: true},
+				logger: zap.NewNop(),
 			},
 		},
+		{
+			name:   "watch_files_poll",
+			config: Config{WatchFiles: true, WatchMode: "poll", WatchPollInterval: time.Second},
+			expected: &includeConfigSource{
+				Config: &Config{WatchFiles: true, WatchMode: "poll", WatchPollInterval: time.Second},
+				logger: zap.NewNop(),
+			},
+		},
+		{
+			name:   "nested_resolution",
+			config: Config{NestedResolution: true, MaxIncludeDepth: 5},
+			expected: &includeConfigSource{
+				Config: &Config{NestedResolution: true, MaxIncludeDepth: 5},
+				logger: zap.NewNop(),
+			},
+		},
+		{
+			name:    "err_on_negative_max_include_depth",
+			config:  Config{NestedResolution: true, MaxIncludeDepth: -1},
+			wantErr: true,
+		},
+		{
+			name:    "err_on_verify_without_key",
+			config:  Config{Verify: &VerifyConfig{}},
+			wantErr: true,
+		},
+		{
+			name:    "err_on_invalid_verify_key",
+			config:  Config{Verify: &VerifyConfig{MinisignPublicKey: "not-a-key"}},
+			wantErr: true,
+		},
+		{
+			name:    "err_on_invalid_watch_mode",
+			config:  Config{WatchFiles: true, WatchMode: "inotify"},
+			wantErr: true,
+		},
+		{
+			name:    "err_on_negative_poll_interval",
+			config:  Config{WatchFiles: true, WatchPollInterval: -time.Second},
+			wantErr: true,
+		},
 		{
 			name: "err_on_delete_and_watch",
 			config: Config{
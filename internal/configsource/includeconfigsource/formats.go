@@ -0,0 +1,108 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package includeconfigsource
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/knadh/koanf/maps"
+	"github.com/magiconair/properties"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/ini.v1"
+	"gopkg.in/yaml.v2"
+)
+
+// Supported values for the format retrieve parameter.
+const (
+	formatYAML       = "yaml"
+	formatJSON       = "json"
+	formatTOML       = "toml"
+	formatINI        = "ini"
+	formatProperties = "properties"
+	formatJsonnet    = "jsonnet"
+)
+
+// parseContent parses the content of an included file according to the given format.
+func parseContent(format string, content []byte) (any, error) {
+	switch format {
+	case "", formatYAML:
+		var value any
+		if err := yaml.Unmarshal(content, &value); err != nil {
+			return nil, err
+		}
+		// yaml.v2 decodes maps as map[any]any, wrap the value so nested maps at any
+		// level get string keys as expected by confmap.
+		wrapper := map[string]any{"value": value}
+		maps.IntfaceKeysToStrings(wrapper)
+		return wrapper["value"], nil
+	case formatJSON:
+		var value any
+		if err := json.Unmarshal(content, &value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case formatTOML:
+		value := map[string]any{}
+		if err := toml.Unmarshal(content, &value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case formatINI:
+		return parseINI(content)
+	case formatProperties:
+		props, err := properties.Load(content, properties.UTF8)
+		if err != nil {
+			return nil, err
+		}
+		value := make(map[string]any, props.Len())
+		for k, v := range props.Map() {
+			value[k] = v
+		}
+		return value, nil
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// parseINI returns the keys of the default section at the top level of the map and
+// the ones of the other sections under a key with the section name.
+func parseINI(content []byte) (map[string]any, error) {
+	file, err := ini.Load(content)
+	if err != nil {
+		return nil, err
+	}
+
+	value := make(map[string]any)
+	for _, section := range file.Sections() {
+		keys := value
+		if section.Name() != ini.DefaultSection {
+			keys = make(map[string]any, len(section.Keys()))
+			value[section.Name()] = keys
+		}
+		for _, key := range section.Keys() {
+			keys[key.Name()] = key.Value()
+		}
+	}
+	return value, nil
+}
+
+func isValidFormat(format string) bool {
+	switch format {
+	case "", formatYAML, formatJSON, formatTOML, formatINI, formatProperties, formatJsonnet:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package includeconfigsource
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/google/go-jsonnet"
+)
+
+// jsonnetExt is the extension of the files evaluated as Jsonnet when no format is given.
+const jsonnetExt = ".jsonnet"
+
+// formatFor returns the format used to read the file: the given format if any, otherwise
+// Jsonnet for files with the Jsonnet extension, or empty.
+func formatFor(file, format string) string {
+	if format == "" && filepath.Ext(file) == jsonnetExt {
+		return formatJsonnet
+	}
+	return format
+}
+
+// evaluateJsonnet evaluates the content of the Jsonnet file. The params, other than the reserved ones,
+// are available to it as external variables: strings via std.extVar("name") directly,
+// other values, e.g. numbers, lists, and maps, are passed as their JSON encoding, so
+// std.extVar returns the same type. Imports are relative to the file.
+func evaluateJsonnet(file string, content []byte, params map[string]any) (any, error) {
+	vm := jsonnet.MakeVM()
+	for name, value := range params {
+		if isReservedParam(name) {
+			continue
+		}
+		if s, ok := value.(string); ok {
+			vm.ExtVar(name, s)
+			continue
+		}
+		code, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode param %q: %w", name, err)
+		}
+		vm.ExtCode(name, string(code))
+	}
+
+	// The file name is used to resolve the imports relative to the file.
+	output, err := vm.EvaluateAnonymousSnippet(file, string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	if err = json.Unmarshal([]byte(output), &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
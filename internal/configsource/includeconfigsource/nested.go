@@ -0,0 +1,87 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package includeconfigsource
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+const defaultMaxIncludeDepth = 10
+
+// includeChainCtxKey is the context key for the absolute paths of the files being
+// included, from the outermost to the innermost one.
+type includeChainCtxKey struct{}
+
+// includeFile reads the file according to format. If nested resolution is enabled the content
+// is parsed as YAML, if no format is given and it isn't a Jsonnet file, and the config source invocations in it, including
+// other includes, are resolved. The returned function closes the config sources used by the file.
+func (is *includeConfigSource) includeFile(ctx context.Context, file string, params map[string]any, format string, watcher confmap.WatcherFunc) (any, confmap.CloseFunc, error) {
+	resolver, ok := configprovider.ResolverFromContext(ctx)
+	if !is.NestedResolution || !ok {
+		value, err := is.readFile(file, params, format)
+		return value, nil, err
+	}
+
+	ctx, err := is.enterInclude(ctx, file)
+	if err != nil {
+		return nil, nil, err
+	}
+	if format = formatFor(file, format); format == "" {
+		format = formatYAML
+	}
+	value, err := is.readFile(file, params, format)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Relative paths in the included file are relative to it, not to the file including it.
+	return resolver(configprovider.ContextWithConfigFile(ctx, file), value, watcher)
+}
+
+// enterInclude returns a copy of ctx with the file appended to the include chain. It fails
+// if the file is already part of the chain or if the chain reached the maximum depth.
+func (is *includeConfigSource) enterInclude(ctx context.Context, file string) (context.Context, error) {
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+
+	chain, _ := ctx.Value(includeChainCtxKey{}).([]string)
+	// Copy the chain so sibling includes don't share the backing array.
+	newChain := append(append(make([]string, 0, len(chain)+1), chain...), absFile)
+	for _, included := range chain {
+		if included == absFile {
+			return nil, &errIncludeCycle{fmt.Errorf("include cycle detected: %s", strings.Join(newChain, " -> "))}
+		}
+	}
+	if len(chain) >= is.maxIncludeDepth() {
+		return nil, &errMaxIncludeDepth{fmt.Errorf("maximum include depth of %d exceeded: %s", is.maxIncludeDepth(), strings.Join(newChain, " -> "))}
+	}
+	return context.WithValue(ctx, includeChainCtxKey{}, newChain), nil
+}
+
+func (is *includeConfigSource) maxIncludeDepth() int {
+	if is.MaxIncludeDepth <= 0 {
+		return defaultMaxIncludeDepth
+	}
+	return is.MaxIncludeDepth
+}
@@ -20,11 +20,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"text/template"
 
-	"github.com/fsnotify/fsnotify"
 	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
 
 	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
 )
@@ -32,115 +37,355 @@ import (
 // Private error types to help with testability.
 type (
 	errFailedToDeleteFile struct{ error }
+	errNoFilesFound       struct{ error }
+	errInvalidFragment    struct{ error }
+	errInvalidWatchMode   struct{ error }
+	errInvalidFormat      struct{ error }
+	errInvalidParams      struct{ error }
+	errIncludeCycle       struct{ error }
+	errMaxIncludeDepth    struct{ error }
+	errInvalidVerifyCfg   struct{ error }
+	errUnverifiedFile     struct{ error }
 )
 
+// Reserved retrieve parameters, like any other parameter they are also available to
+// the templates.
+const (
+	// formatParam selects how included files are parsed. It is not named "format"
+	// so it doesn't collide with the parameters of existing templates.
+	formatParam = "parse_as"
+	// optionalParam makes a selector not matching any file resolve to an empty map.
+	optionalParam = "optional"
+)
+
+// isReservedParam reports if the retrieve parameter is interpreted by the config source.
+func isReservedParam(name string) bool {
+	return name == formatParam || name == optionalParam || name == sha256Param
+}
+
 // includeConfigSource implements the configprovider.Session interface.
 type includeConfigSource struct {
 	*Config
-	watcher      *fsnotify.Watcher
-	watchedFiles map[string]struct{}
+	logger   *zap.Logger
+	verifier *verifier
 }
 
-func newConfigSource(_ configprovider.CreateParams, config *Config) (configprovider.ConfigSource, error) {
+func newConfigSource(params configprovider.CreateParams, config *Config) (configprovider.ConfigSource, error) {
 	if config.DeleteFiles && config.WatchFiles {
 		return nil, errors.New(`cannot be configured with "delete_files" and "watch_files" at the same time`)
 	}
 
-	return &includeConfigSource{
-		Config:       config,
-		watchedFiles: make(map[string]struct{}),
-	}, nil
-}
+	switch config.WatchMode {
+	case "", watchModeAuto, watchModeFSNotify, watchModePoll:
+	default:
+		return nil, &errInvalidWatchMode{fmt.Errorf("invalid watch_mode %q, must be one of %q, %q, or %q", config.WatchMode, watchModeAuto, watchModeFSNotify, watchModePoll)}
+	}
+	if config.WatchPollInterval < 0 {
+		return nil, &errInvalidWatchMode{errors.New("watch_poll_interval must not be negative")}
+	}
+	if config.MaxIncludeDepth < 0 {
+		return nil, &errMaxIncludeDepth{errors.New("max_include_depth must not be negative")}
+	}
 
-func (is *includeConfigSource) Retrieve(_ context.Context, selector string, paramsConfigMap *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
-	tmpl, err := template.ParseFiles(selector)
+	verifier, err := newVerifier(config.Verify)
 	if err != nil {
-		return nil, err
+		return nil, &errInvalidVerifyCfg{err}
 	}
 
-	var buf bytes.Buffer
+	return &includeConfigSource{
+		Config:   config,
+		logger:   params.Logger,
+		verifier: verifier,
+	}, nil
+}
+
+func (is *includeConfigSource) Retrieve(ctx context.Context, selector string, paramsConfigMap *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
 	var params map[string]any
 	if paramsConfigMap != nil {
 		params = paramsConfigMap.ToStringMap()
 	} else {
 		params = map[string]any{}
 	}
-	if err = tmpl.Execute(&buf, params); err != nil {
+
+	format, ok := params[formatParam].(string)
+	if _, set := params[formatParam]; set && (!ok || !isValidFormat(format)) {
+		return nil, &errInvalidFormat{fmt.Errorf("invalid %s %v, must be one of %q, %q, %q, %q, %q, or %q",
+			formatParam, params[formatParam], formatYAML, formatJSON, formatTOML, formatINI, formatProperties, formatJsonnet)}
+	}
+
+	if is.RelativeToConfigFile && !filepath.IsAbs(selector) {
+		if configFile, ok := configprovider.ConfigFileFromContext(ctx); ok {
+			selector = filepath.Join(filepath.Dir(configFile), selector)
+		}
+	}
+
+	optional, ok := params[optionalParam].(bool)
+	if _, set := params[optionalParam]; set && !ok {
+		return nil, &errInvalidParams{fmt.Errorf("invalid optional %v, must be true or false", params[optionalParam])}
+	}
+	// The optional parameter is handled by the config provider and passed via the context.
+	optional = optional || configprovider.IsOptional(ctx)
+
+	checksum, ok := params[sha256Param].(string)
+	if _, set := params[sha256Param]; set && !ok {
+		return nil, &errInvalidParams{fmt.Errorf("invalid %s %v, must be a hex encoded checksum", sha256Param, params[sha256Param])}
+	}
+
+	files, isDir, err := is.matchFiles(selector)
+	if err != nil {
+		var errNoFiles *errNoFilesFound
+		if optional && (errors.Is(err, fs.ErrNotExist) || errors.As(err, &errNoFiles)) {
+			return is.retrieveMissing(selector, isDir, watcher)
+		}
 		return nil, err
 	}
+	if checksum != "" && (len(files) != 1 || files[0] != selector) {
+		return nil, &errInvalidParams{fmt.Errorf("%s can only be used to include a single file", sha256Param)}
+	}
+
+	var value any
+	var closeFunc confmap.CloseFunc
+	if len(files) == 1 && files[0] == selector {
+		value, closeFunc, err = is.includeFile(ctx, selector, params, format, watcher)
+	} else {
+		value, closeFunc, err = is.mergeFiles(ctx, files, params, format, watcher)
+	}
+	if err != nil {
+		return nil, err
+	}
+	closeFuncs := []confmap.CloseFunc{closeFunc}
 
 	if is.DeleteFiles {
-		if err = os.Remove(selector); err != nil {
-			return nil, &errFailedToDeleteFile{fmt.Errorf("failed to delete file %q as requested: %w", selector, err)}
+		for _, file := range files {
+			if err = os.Remove(file); err != nil {
+				err = &errFailedToDeleteFile{fmt.Errorf("failed to delete file %q as requested: %w", file, err)}
+				return nil, multierr.Append(err, callClose(ctx, closeFunc))
+			}
 		}
 	}
 
+	if is.WatchFiles && watcher != nil {
+		if isDir {
+			// Also watch the directory itself so files added to or removed from it are noticed.
+			files = append(files, selector)
+		}
+		watchCloseFunc, watchErr := is.watchFiles(files, watcher)
+		if watchErr != nil {
+			return nil, multierr.Append(watchErr, callClose(ctx, closeFunc))
+		}
+		closeFuncs = append(closeFuncs, watchCloseFunc)
+	}
+	return confmap.NewRetrieved(value, confmap.WithRetrievedClose(mergeCloseFuncs(closeFuncs)))
+}
+
+// retrieveMissing returns an empty map for an optional selector that doesn't match any file.
+// If files are watched the directory in which the files are expected is watched, so creating
+// them triggers a reload.
+func (is *includeConfigSource) retrieveMissing(selector string, isDir bool, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	value := map[string]any{}
 	if !is.WatchFiles || watcher == nil {
-		return confmap.NewRetrieved(buf.String())
+		return confmap.NewRetrieved(value)
 	}
 
-	closeFunc, err := is.watchFile(selector, watcher)
+	dir := selector
+	if !isDir {
+		dir = filepath.Dir(selector)
+	}
+	if strings.ContainsAny(dir, "*?[") {
+		// The files can't be watched if the pattern matches directories.
+		return confmap.NewRetrieved(value)
+	}
+	closeFunc, err := is.watchFiles([]string{dir}, watcher)
 	if err != nil {
 		return nil, err
 	}
-	return confmap.NewRetrieved(buf.String(), confmap.WithRetrievedClose(closeFunc))
+	return confmap.NewRetrieved(value, confmap.WithRetrievedClose(closeFunc))
 }
 
-func (is *includeConfigSource) Shutdown(context.Context) error {
-	return nil
-}
+// matchFiles returns the files referenced by the selector, leaving out the signature
+// files if the signatures are verified.
+func (is *includeConfigSource) matchFiles(selector string) ([]string, bool, error) {
+	files, isDir, err := matchFiles(selector)
+	if err != nil || is.verifier == nil || (len(files) == 1 && files[0] == selector) {
+		return files, isDir, err
+	}
 
-func (is *includeConfigSource) watchFile(file string, watcherFunc confmap.WatcherFunc) (confmap.CloseFunc, error) {
-	if _, watched := is.watchedFiles[file]; watched {
-		// This file is already watched another watch function is not needed.
-		return nil, nil
+	included := files[:0]
+	for _, file := range files {
+		if !strings.HasSuffix(file, is.verifier.signatureExt()) {
+			included = append(included, file)
+		}
+	}
+	if len(included) == 0 {
+		return nil, isDir, &errNoFilesFound{fmt.Errorf("no files other than signatures match %q", selector)}
 	}
+	return included, isDir, nil
+}
 
-	if is.watcher == nil {
-		// First watcher create a real watch for update function.
-		var err error
-		is.watcher, err = fsnotify.NewWatcher()
-		if err != nil {
-			return nil, err
-		}
-
-		go func() {
-			for {
-				select {
-				case event, ok := <-is.watcher.Events:
-					if !ok {
-						return
-					}
-					if event.Op&fsnotify.Write == fsnotify.Write {
-						watcherFunc(&confmap.ChangeEvent{Error: nil})
-						return
-					}
-				case watcherErr, ok := <-is.watcher.Errors:
-					if !ok {
-						return
-					}
-					watcherFunc(&confmap.ChangeEvent{Error: watcherErr})
-					return
-				}
+// matchFiles returns the files referenced by the selector in lexicographic order. The
+// selector can be a file, a glob pattern, or a directory in which case all its regular
+// files are returned. Hidden files are skipped when matching a glob pattern or listing a
+// directory. isDir reports if the selector is a directory.
+func matchFiles(selector string) (files []string, isDir bool, err error) {
+	if strings.ContainsAny(selector, "*?[") {
+		var matches []string
+		if matches, err = filepath.Glob(selector); err != nil {
+			return nil, false, err
+		}
+		for _, match := range matches {
+			if strings.HasPrefix(filepath.Base(match), ".") {
+				continue
+			}
+			if info, statErr := os.Stat(match); statErr == nil && info.Mode().IsRegular() {
+				files = append(files, match)
 			}
-		}()
+		}
+		if len(files) == 0 {
+			return nil, false, &errNoFilesFound{fmt.Errorf("no files match %q", selector)}
+		}
+		sort.Strings(files)
+		return files, false, nil
 	}
 
-	// Now just add the file.
-	if err := is.watcher.Add(file); err != nil {
+	info, err := os.Stat(selector)
+	if err != nil {
+		return nil, false, err
+	}
+	if !info.IsDir() {
+		return []string{selector}, false, nil
+	}
+
+	entries, err := os.ReadDir(selector)
+	if err != nil {
+		return nil, true, err
+	}
+	// os.ReadDir returns the entries sorted by filename.
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && !strings.HasPrefix(entry.Name(), ".") {
+			files = append(files, filepath.Join(selector, entry.Name()))
+		}
+	}
+	if len(files) == 0 {
+		return nil, true, &errNoFilesFound{fmt.Errorf("no files found in directory %q", selector)}
+	}
+	return files, true, nil
+}
+
+// renderFile executes the content of the file as a template with the given params.
+func renderFile(file string, content []byte, params map[string]any) ([]byte, error) {
+	tmpl, err := template.New(filepath.Base(file)).Funcs(templateFuncs).Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, params); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// readFile verifies the file, if requested, renders it and parses it according to format.
+// Without a format the rendered content is returned as a string, to be parsed as YAML by
+// the config provider. Jsonnet files aren't rendered as templates, they are evaluated with
+// the params.
+func (is *includeConfigSource) readFile(file string, params map[string]any, format string) (any, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
 		return nil, err
 	}
+	if checksum, ok := params[sha256Param].(string); ok && checksum != "" {
+		if err = verifyChecksum(content, checksum); err != nil {
+			return nil, &errUnverifiedFile{fmt.Errorf("refusing to include file %q: %w", file, err)}
+		}
+	}
+	if is.verifier != nil {
+		if err = is.verifier.verify(file, content); err != nil {
+			return nil, &errUnverifiedFile{fmt.Errorf("refusing to include file %q: %w", file, err)}
+		}
+	}
 
-	is.watchedFiles[file] = struct{}{}
+	format = formatFor(file, format)
+	if format == formatJsonnet {
+		value, jsonnetErr := evaluateJsonnet(file, content, params)
+		if jsonnetErr != nil {
+			return nil, &errInvalidFragment{fmt.Errorf("failed to evaluate Jsonnet file %q: %w", file, jsonnetErr)}
+		}
+		return value, nil
+	}
 
-	return func(ctx context.Context) error {
-		err := is.watcher.Remove(file)
+	if content, err = renderFile(file, content, params); err != nil {
+		return nil, err
+	}
+	if format == "" {
+		return string(content), nil
+	}
+
+	value, err := parseContent(format, content)
+	if err != nil {
+		return nil, &errInvalidFragment{fmt.Errorf("failed to parse file %q as %s: %w", file, format, err)}
+	}
+	return value, nil
+}
+
+// mergeFiles includes each file, parsed as a map according to format, by default YAML or
+// Jsonnet for Jsonnet files, and deep merges the maps in order: values from later files
+// override the ones from earlier files.
+func (is *includeConfigSource) mergeFiles(ctx context.Context, files []string, params map[string]any, format string, watcher confmap.WatcherFunc) (map[string]any, confmap.CloseFunc, error) {
+	merged := confmap.New()
+	var closeFuncs []confmap.CloseFunc
+	for _, file := range files {
+		fileFormat := formatFor(file, format)
+		if fileFormat == "" {
+			fileFormat = formatYAML
+		}
+		value, closeFunc, err := is.includeFile(ctx, file, params, fileFormat, watcher)
+		closeFuncs = append(closeFuncs, closeFunc)
+		if err == nil && value != nil {
+			fragment, ok := value.(map[string]any)
+			if !ok {
+				err = &errInvalidFragment{fmt.Errorf("file %q must contain a map to be merged", file)}
+			} else {
+				err = merged.Merge(confmap.NewFromStringMap(fragment))
+			}
+		}
 		if err != nil {
-			return err
+			return nil, nil, multierr.Append(err, callClose(ctx, mergeCloseFuncs(closeFuncs)))
+		}
+	}
+	return merged.ToStringMap(), mergeCloseFuncs(closeFuncs), nil
+}
+
+// mergeCloseFuncs returns a function calling all the non-nil given functions.
+func mergeCloseFuncs(closeFuncs []confmap.CloseFunc) confmap.CloseFunc {
+	var funcs []confmap.CloseFunc
+	for _, closeFunc := range closeFuncs {
+		if closeFunc != nil {
+			funcs = append(funcs, closeFunc)
 		}
-		if len(is.watcher.WatchList()) == 0 {
-			return is.watcher.Close()
+	}
+	switch len(funcs) {
+	case 0:
+		return nil
+	case 1:
+		return funcs[0]
+	}
+	return func(ctx context.Context) error {
+		var errs error
+		for _, closeFunc := range funcs {
+			errs = multierr.Append(errs, closeFunc(ctx))
 		}
+		return errs
+	}
+}
+
+func callClose(ctx context.Context, closeFunc confmap.CloseFunc) error {
+	if closeFunc == nil {
 		return nil
-	}, nil
+	}
+	return closeFunc(ctx)
+}
+
+func (is *includeConfigSource) Shutdown(context.Context) error {
+	return nil
 }
@@ -21,10 +21,13 @@ import (
 	"path"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
 
 	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
 )
@@ -61,8 +64,225 @@ func TestIncludeConfigSource_Session(t *testing.T) {
 			},
 			expected: "logs_path: myPattern",
 		},
+		{
+			name:     "format_template_param",
+			selector: "component_template",
+			params: map[string]any{
+				"glob_pattern": "myPattern",
+				"format":       "json",
+			},
+			expected: "logs_path: myPattern\nlog_format: json\n",
+		},
+		{
+			name:     "directory",
+			selector: "conf.d",
+			params: map[string]any{
+				"verbosity": "detailed",
+			},
+			expected: map[string]any{
+				"receivers": map[string]any{
+					"otlp": map[string]any{
+						"protocols": map[string]any{
+							"grpc": nil,
+							"http": nil,
+						},
+					},
+				},
+				"exporters": map[string]any{
+					"logging": map[string]any{
+						"verbosity": "detailed",
+					},
+					"otlp": map[string]any{
+						"endpoint": "localhost:4317",
+					},
+				},
+			},
+		},
+		{
+			name:     "glob",
+			selector: "conf.d/*.yaml",
+			params: map[string]any{
+				"verbosity": "basic",
+			},
+			expected: map[string]any{
+				"receivers": map[string]any{
+					"otlp": map[string]any{
+						"protocols": map[string]any{
+							"grpc": nil,
+						},
+					},
+				},
+				"exporters": map[string]any{
+					"logging": map[string]any{
+						"verbosity": "basic",
+					},
+					"otlp": map[string]any{
+						"endpoint": "localhost:4317",
+					},
+				},
+			},
+		},
+		{
+			name:     "functions_template",
+			selector: "functions_template",
+			params: map[string]any{
+				"token": "czNjcjN0",
+				"headers": map[string]any{
+					"x-scope": "tenant-a",
+					"x-zone":  "us-west-2a",
+				},
+				"tls": map[string]any{
+					"insecure": true,
+				},
+			},
+			expected: "endpoint: localhost:4317\n" +
+				"token: \"s3cr3t\"\n" +
+				"user: COLLECTOR\n" +
+				"headers:\n" +
+				"  x-scope: tenant-a\n" +
+				"  x-zone: us-west-2a\n" +
+				"tls:\n" +
+				"  insecure: true\n",
+		},
+		{
+			name:     "json_format",
+			selector: "json_data_file",
+			params:   map[string]any{"parse_as": "json"},
+			expected: map[string]any{
+				"exporters": map[string]any{
+					"otlp": map[string]any{
+						"endpoint": "localhost:4317",
+						"tls":      map[string]any{"insecure": true},
+					},
+				},
+			},
+		},
+		{
+			name:     "toml_format",
+			selector: "toml_data_file",
+			params:   map[string]any{"parse_as": "toml"},
+			expected: map[string]any{
+				"title": "collector",
+				"exporters": map[string]any{
+					"otlp": map[string]any{
+						"endpoint": "localhost:4317",
+						"timeout":  int64(5),
+					},
+				},
+			},
+		},
+		{
+			name:     "ini_format",
+			selector: "ini_data_file",
+			params:   map[string]any{"parse_as": "ini"},
+			expected: map[string]any{
+				"level": "info",
+				"otlp":  map[string]any{"endpoint": "localhost:4317"},
+			},
+		},
+		{
+			name:     "properties_format",
+			selector: "properties_data_file",
+			params:   map[string]any{"parse_as": "properties"},
+			expected: map[string]any{
+				"otlp.endpoint": "localhost:4317",
+				"level":         "info",
+			},
+		},
+		{
+			name:     "yaml_format",
+			selector: "yaml_data_file",
+			params:   map[string]any{"parse_as": "yaml"},
+			expected: map[string]any{
+				"field": "value",
+				"map":   map[string]any{"k0": 42, "k1": "v1"},
+			},
+		},
+		{
+			name:     "jsonnet_file",
+			selector: "jsonnet/gateway.jsonnet",
+			params:   map[string]any{"env": "prod", "replicas": 2},
+			expected: map[string]any{
+				"exporters": map[string]any{
+					"otlp/0": map[string]any{"endpoint": "gateway-0.prod.example.com:4317"},
+					"otlp/1": map[string]any{"endpoint": "gateway-1.prod.example.com:4317"},
+				},
+			},
+		},
+		{
+			name:     "jsonnet_missing_ext_var",
+			selector: "jsonnet/gateway.jsonnet",
+			params:   map[string]any{"env": "prod"},
+			wantErr:  &errInvalidFragment{},
+		},
+		{
+			name:     "invalid_format_content",
+			selector: "yaml_data_file",
+			params:   map[string]any{"parse_as": "json"},
+			wantErr:  &errInvalidFragment{},
+		},
+		{
+			name:     "unsupported_format",
+			selector: "yaml_data_file",
+			params:   map[string]any{"parse_as": "xml"},
+			wantErr:  &errInvalidFormat{},
+		},
+		{
+			name:     "optional_missing_file",
+			selector: "not_to_be_found",
+			params:   map[string]any{"optional": true},
+			expected: map[string]any{},
+		},
+		{
+			name:     "optional_glob_no_match",
+			selector: "conf.d/*.json",
+			params:   map[string]any{"optional": true},
+			expected: map[string]any{},
+		},
+		{
+			name:     "optional_present_file",
+			selector: "scalar_data_file",
+			params:   map[string]any{"optional": true},
+			expected: "42",
+		},
+		{
+			name:     "invalid_optional",
+			selector: "scalar_data_file",
+			params:   map[string]any{"optional": "maybe"},
+			wantErr:  &errInvalidParams{},
+		},
+		{
+			name:     "sha256_match",
+			selector: "scalar_data_file",
+			params:   map[string]any{"sha256": "73475cb40a568e8da8a045ced110137e159f890ac4da883b6b17dc651b3a8049"},
+			expected: "42",
+		},
+		{
+			name:     "sha256_mismatch",
+			selector: "scalar_data_file",
+			params:   map[string]any{"sha256": "0000000000000000000000000000000000000000000000000000000000000000"},
+			wantErr:  &errUnverifiedFile{},
+		},
+		{
+			name:     "sha256_multiple_files",
+			selector: "conf.d",
+			params:   map[string]any{"sha256": "73475cb40a568e8da8a045ced110137e159f890ac4da883b6b17dc651b3a8049"},
+			wantErr:  &errInvalidParams{},
+		},
+		{
+			name:     "glob_no_match",
+			selector: "conf.d/*.json",
+			wantErr:  &errNoFilesFound{},
+		},
+		{
+			name:     "glob_not_a_map",
+			selector: "list_data_*",
+			wantErr:  &errInvalidFragment{},
+		},
 	}
 
+	t.Setenv("_TEST_INCLUDE_USER", "collector")
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s, err := newConfigSource(configprovider.CreateParams{}, &Config{})
@@ -90,7 +310,7 @@ func TestIncludeConfigSource_Session(t *testing.T) {
 }
 
 func TestIncludeConfigSourceWatchFileClose(t *testing.T) {
-	s, err := newConfigSource(configprovider.CreateParams{}, &Config{WatchFiles: true})
+	s, err := newConfigSource(configprovider.CreateParams{Logger: zap.NewNop()}, &Config{WatchFiles: true})
 	require.NoError(t, err)
 	require.NotNil(t, s)
 
@@ -121,7 +341,15 @@ func TestIncludeConfigSourceWatchFileClose(t *testing.T) {
 }
 
 func TestIncludeConfigSource_WatchFileUpdate(t *testing.T) {
-	s, err := newConfigSource(configprovider.CreateParams{}, &Config{WatchFiles: true})
+	for _, mode := range []string{watchModeAuto, watchModeFSNotify, watchModePoll} {
+		t.Run(mode, func(t *testing.T) {
+			testWatchFileUpdate(t, &Config{WatchFiles: true, WatchMode: mode, WatchPollInterval: 10 * time.Millisecond})
+		})
+	}
+}
+
+func testWatchFileUpdate(t *testing.T, cfg *Config) {
+	s, err := newConfigSource(configprovider.CreateParams{Logger: zap.NewNop()}, cfg)
 	require.NoError(t, err)
 	require.NotNil(t, s)
 
@@ -142,8 +370,9 @@ func TestIncludeConfigSource_WatchFileUpdate(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "val1", val)
 
-	// Write update to file
-	require.NoError(t, os.WriteFile(dst, []byte("val2"), 0600))
+	// Write update to file, changing its size so polling notices it even if the
+	// modification time resolution is coarse.
+	require.NoError(t, os.WriteFile(dst, []byte("value2"), 0600))
 
 	ce := <-watchChannel
 	assert.NoError(t, ce.Error)
@@ -156,7 +385,7 @@ func TestIncludeConfigSource_WatchFileUpdate(t *testing.T) {
 
 	val, err = r.AsRaw()
 	require.NoError(t, err)
-	assert.Equal(t, "val2", val)
+	assert.Equal(t, "value2", val)
 	require.NoError(t, r.Close(context.Background()))
 	require.NoError(t, s.Shutdown(ctx))
 }
@@ -216,3 +445,132 @@ func TestIncludeConfigSource_DeleteFileError(t *testing.T) {
 
 	require.NoError(t, s.Shutdown(ctx))
 }
+
+func TestIncludeConfigSource_WatchDirectory(t *testing.T) {
+	s, err := newConfigSource(configprovider.CreateParams{Logger: zap.NewNop()}, &Config{WatchFiles: true})
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(dir, "00.yaml"), []byte("k0: v0"), 0600))
+
+	watchChannel := make(chan *confmap.ChangeEvent, 1)
+	ctx := context.Background()
+	r, err := s.Retrieve(ctx, dir, nil, func(event *confmap.ChangeEvent) {
+		watchChannel <- event
+	})
+	require.NoError(t, err)
+
+	// Adding a file to the directory is a change.
+	require.NoError(t, os.WriteFile(path.Join(dir, "10.yaml"), []byte("k1: v1"), 0600))
+	ce := <-watchChannel
+	assert.NoError(t, ce.Error)
+
+	require.NoError(t, r.Close(ctx))
+	require.NoError(t, s.Shutdown(ctx))
+}
+
+func TestIncludeConfigSource_RelativeToConfigFile(t *testing.T) {
+	ctx := configprovider.ContextWithConfigFile(context.Background(), path.Join("testdata", "config.yaml"))
+
+	s, err := newConfigSource(configprovider.CreateParams{}, &Config{RelativeToConfigFile: true})
+	require.NoError(t, err)
+	r, err := s.Retrieve(ctx, "scalar_data_file", nil, nil)
+	require.NoError(t, err)
+	val, err := r.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, "42", val)
+	require.NoError(t, r.Close(ctx))
+
+	// Without a config file in the context the current working directory is used.
+	_, err = s.Retrieve(context.Background(), "scalar_data_file", nil, nil)
+	assert.IsType(t, &os.PathError{}, err)
+	require.NoError(t, s.Shutdown(ctx))
+
+	// By default the current working directory is used.
+	s, err = newConfigSource(configprovider.CreateParams{}, &Config{})
+	require.NoError(t, err)
+	_, err = s.Retrieve(ctx, "scalar_data_file", nil, nil)
+	assert.IsType(t, &os.PathError{}, err)
+	require.NoError(t, s.Shutdown(ctx))
+}
+
+func TestIncludeConfigSource_WatchOptionalMissingFile(t *testing.T) {
+	s, err := newConfigSource(configprovider.CreateParams{Logger: zap.NewNop()}, &Config{WatchFiles: true})
+	require.NoError(t, err)
+
+	dst := path.Join(t.TempDir(), "override.yaml")
+	watchChannel := make(chan *confmap.ChangeEvent, 1)
+	ctx := context.Background()
+	r, err := s.Retrieve(ctx, dst, confmap.NewFromStringMap(map[string]any{"optional": true}), func(event *confmap.ChangeEvent) {
+		watchChannel <- event
+	})
+	require.NoError(t, err)
+	val, err := r.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{}, val)
+
+	// Creating the missing file is a change.
+	require.NoError(t, os.WriteFile(dst, []byte("k0: v0"), 0600))
+	ce := <-watchChannel
+	assert.NoError(t, ce.Error)
+
+	require.NoError(t, r.Close(ctx))
+	require.NoError(t, s.Shutdown(ctx))
+}
+
+func TestIncludeConfigSource_NestedResolution(t *testing.T) {
+	t.Setenv("NESTED_INCLUDE_ENDPOINT", "localhost:4317")
+	factories := configprovider.Factories{typeStr: NewFactory()}
+	configMap := confmap.NewFromStringMap(map[string]any{
+		"config_sources": map[string]any{
+			"include": map[string]any{
+				"nested_resolution":       true,
+				"relative_to_config_file": true,
+			},
+		},
+		"exporter": "${include:" + path.Join("testdata", "nested", "outer.yaml") + "}",
+	})
+
+	res, closeFunc, err := configprovider.Resolve(context.Background(), configMap, zap.NewNop(), component.NewDefaultBuildInfo(), factories, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"exporter": map[string]any{
+			"endpoint": "localhost:4317",
+			"headers": map[string]any{
+				"x-scope":  "all",
+				"x-tenant": "acme",
+			},
+		},
+	}, res)
+	if closeFunc != nil {
+		require.NoError(t, closeFunc(context.Background()))
+	}
+}
+
+func TestIncludeConfigSource_NestedResolutionErrors(t *testing.T) {
+	resolve := func(maxIncludeDepth int) error {
+		factories := configprovider.Factories{typeStr: NewFactory()}
+		configMap := confmap.NewFromStringMap(map[string]any{
+			"config_sources": map[string]any{
+				"include": map[string]any{
+					"nested_resolution":       true,
+					"relative_to_config_file": true,
+					"max_include_depth":       maxIncludeDepth,
+				},
+			},
+			"cycle": "${include:" + path.Join("testdata", "nested", "cycle_a.yaml") + "}",
+		})
+		_, _, err := configprovider.Resolve(context.Background(), configMap, zap.NewNop(), component.NewDefaultBuildInfo(), factories, nil)
+		return err
+	}
+
+	err := resolve(0)
+	var errCycle *errIncludeCycle
+	require.ErrorAs(t, err, &errCycle)
+	assert.Regexp(t, `include cycle detected: \S+cycle_a\.yaml -> \S+cycle_b\.yaml -> \S+cycle_a\.yaml`, err.Error())
+
+	err = resolve(1)
+	var errDepth *errMaxIncludeDepth
+	require.ErrorAs(t, err, &errDepth)
+	assert.Regexp(t, `maximum include depth of 1 exceeded: \S+cycle_a\.yaml -> \S+cycle_b\.yaml`, err.Error())
+}
@@ -0,0 +1,62 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package includeconfigsource
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	tests := []struct {
+		params   map[string]any
+		name     string
+		tmpl     string
+		expected string
+		wantErr  bool
+	}{
+		{name: "default_missing", tmpl: `{{ .port | default 8080 }}`, expected: "8080"},
+		{name: "default_empty", tmpl: `{{ .port | default 8080 }}`, params: map[string]any{"port": ""}, expected: "8080"},
+		{name: "default_set", tmpl: `{{ .port | default 8080 }}`, params: map[string]any{"port": 9090}, expected: "9090"},
+		{name: "b64", tmpl: `{{ "value" | b64enc | b64dec }}`, expected: "value"},
+		{name: "b64dec_invalid", tmpl: `{{ "!" | b64dec }}`, wantErr: true},
+		{name: "toJson", tmpl: `{{ .m | toJson }}`, params: map[string]any{"m": map[string]any{"k": "v"}}, expected: `{"k":"v"}`},
+		{name: "indent", tmpl: `{{ "a\nb" | indent 2 }}`, expected: "  a\n  b"},
+		{name: "split_join", tmpl: `{{ .l | join "," }}|{{ index (split "," "a,b") 1 }}`, params: map[string]any{"l": []any{"x", 1}}, expected: "x,1|b"},
+		{name: "required_set", tmpl: `{{ .v | required "v is required" }}`, params: map[string]any{"v": "ok"}, expected: "ok"},
+		{name: "required_missing", tmpl: `{{ .v | required "v is required" }}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := template.New(tt.name).Funcs(templateFuncs).Parse(tt.tmpl)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			err = tmpl.Execute(&buf, tt.params)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
@@ -0,0 +1,5 @@
+{
+  exporter(env, i):: {
+    endpoint: 'gateway-%d.%s.example.com:4317' % [i, env],
+  },
+}
@@ -0,0 +1,205 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package includeconfigsource
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	// sha256Param is the reserved retrieve parameter with the expected SHA-256 checksum
+	// of the included file.
+	sha256Param = "sha256"
+
+	minisignSignatureExt = ".minisig"
+	cosignSignatureExt   = ".sig"
+)
+
+// verifier checks the signatures of the included files before they are used.
+type verifier struct {
+	minisignKey *minisignPublicKey
+	cosignKey   *ecdsa.PublicKey
+}
+
+func newVerifier(cfg *VerifyConfig) (*verifier, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	if (cfg.MinisignPublicKey == "") == (cfg.CosignPublicKeyFile == "") {
+		return nil, errors.New("exactly one of minisign_public_key or cosign_public_key_file must be set")
+	}
+
+	v := &verifier{}
+	var err error
+	if cfg.MinisignPublicKey != "" {
+		if v.minisignKey, err = parseMinisignPublicKey(cfg.MinisignPublicKey); err != nil {
+			return nil, fmt.Errorf("invalid minisign_public_key: %w", err)
+		}
+		return v, nil
+	}
+	if v.cosignKey, err = readCosignPublicKey(cfg.CosignPublicKeyFile); err != nil {
+		return nil, fmt.Errorf("invalid cosign_public_key_file: %w", err)
+	}
+	return v, nil
+}
+
+// signatureExt returns the extension of the signature files next to the included files.
+func (v *verifier) signatureExt() string {
+	if v.minisignKey != nil {
+		return minisignSignatureExt
+	}
+	return cosignSignatureExt
+}
+
+// verify checks the content of the file against its signature file.
+func (v *verifier) verify(file string, content []byte) error {
+	sigFile := file + v.signatureExt()
+	signature, err := os.ReadFile(sigFile)
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %w", err)
+	}
+	if v.minisignKey != nil {
+		return v.minisignKey.verify(content, signature)
+	}
+	return verifyCosign(v.cosignKey, content, signature)
+}
+
+// verifyChecksum checks the content against the hex encoded SHA-256 checksum.
+func verifyChecksum(content []byte, checksum string) error {
+	expected, err := hex.DecodeString(checksum)
+	if err != nil || len(expected) != sha256.Size {
+		return fmt.Errorf("invalid %s %q, it must be a hex encoded SHA-256 checksum", sha256Param, checksum)
+	}
+	actual := sha256.Sum256(content)
+	if subtle.ConstantTimeCompare(actual[:], expected) != 1 {
+		return fmt.Errorf("%s checksum mismatch, got %x", sha256Param, actual)
+	}
+	return nil
+}
+
+// minisignPublicKey is an Ed25519 public key in the minisign format, see
+// https://jedisct1.github.io/minisign/.
+type minisignPublicKey struct {
+	key   ed25519.PublicKey
+	keyID []byte
+}
+
+// parseMinisignPublicKey parses a base64 encoded minisign public key, optionally preceded
+// by the untrusted comment line of the .pub files.
+func parseMinisignPublicKey(s string) (*minisignPublicKey, error) {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[len(lines)-1]))
+	if err != nil {
+		return nil, err
+	}
+	// Signature algorithm (2 bytes), key ID (8 bytes), and Ed25519 public key.
+	if len(raw) != 10+ed25519.PublicKeySize || string(raw[:2]) != "Ed" {
+		return nil, errors.New("not an Ed25519 minisign public key")
+	}
+	return &minisignPublicKey{keyID: raw[2:10], key: raw[10:]}, nil
+}
+
+// verify checks a minisign signature file: the signature of the content, either legacy
+// or pre-hashed with BLAKE2b-512, and the global signature of the trusted comment.
+func (k *minisignPublicKey) verify(content, sigFile []byte) error {
+	lines := strings.Split(strings.ReplaceAll(strings.TrimSpace(string(sigFile)), "\r\n", "\n"), "\n")
+	if len(lines) < 4 {
+		return errors.New("invalid minisign signature file")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(sig) != 10+ed25519.SignatureSize {
+		return errors.New("invalid minisign signature")
+	}
+	if subtle.ConstantTimeCompare(sig[2:10], k.keyID) != 1 {
+		return errors.New("minisign signature was created with a different key")
+	}
+
+	message := content
+	switch string(sig[:2]) {
+	case "Ed":
+	case "ED":
+		digest := blake2b.Sum512(content)
+		message = digest[:]
+	default:
+		return fmt.Errorf("unsupported minisign signature algorithm %q", sig[:2])
+	}
+	signature := sig[10:]
+	if !ed25519.Verify(k.key, message, signature) {
+		return errors.New("minisign signature verification failed")
+	}
+
+	const trustedCommentPrefix = "trusted comment: "
+	if !strings.HasPrefix(lines[2], trustedCommentPrefix) {
+		return errors.New("invalid minisign trusted comment")
+	}
+	globalSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return errors.New("invalid minisign global signature")
+	}
+	trustedComment := strings.TrimPrefix(strings.TrimRight(lines[2], "\r"), trustedCommentPrefix)
+	if !ed25519.Verify(k.key, append(append([]byte{}, signature...), trustedComment...), globalSig) {
+		return errors.New("minisign trusted comment verification failed")
+	}
+	return nil
+}
+
+// readCosignPublicKey reads a PEM-encoded ECDSA public key as generated by "cosign generate-key-pair".
+func readCosignPublicKey(file string) (*ecdsa.PublicKey, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	ecdsaKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported public key type %T, only ECDSA keys are supported", key)
+	}
+	return ecdsaKey, nil
+}
+
+// verifyCosign checks a base64 encoded signature as created by "cosign sign-blob --key".
+func verifyCosign(key *ecdsa.PublicKey, content, sigFile []byte) error {
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigFile)))
+	if err != nil {
+		return fmt.Errorf("invalid cosign signature: %w", err)
+	}
+	digest := sha256.Sum256(content)
+	if !ecdsa.VerifyASN1(key, digest[:], signature) {
+		return errors.New("cosign signature verification failed")
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package includeconfigsource
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+const signedContent = "exporters:\n  otlp:\n    endpoint: localhost:4317\n"
+
+// minisignSign creates a minisign signature file for the content, pre-hashed if prehash is set.
+func minisignSign(priv ed25519.PrivateKey, keyID []byte, content []byte, prehash bool) []byte {
+	algorithm, message := "Ed", content
+	if prehash {
+		digest := blake2b.Sum512(content)
+		algorithm, message = "ED", digest[:]
+	}
+	signature := ed25519.Sign(priv, message)
+	trustedComment := "timestamp:1672531200\tfile:exporters.yaml"
+	globalSignature := ed25519.Sign(priv, append(append([]byte{}, signature...), trustedComment...))
+
+	sig := append(append([]byte(algorithm), keyID...), signature...)
+	return []byte("untrusted comment: signature from minisign secret key\n" +
+		base64.StdEncoding.EncodeToString(sig) + "\n" +
+		"trusted comment: " + trustedComment + "\n" +
+		base64.StdEncoding.EncodeToString(globalSignature) + "\n")
+}
+
+func retrieveVerified(t *testing.T, cfg *VerifyConfig, file string, params map[string]any) (any, error) {
+	s, err := newConfigSource(configprovider.CreateParams{}, &Config{Verify: cfg})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	r, err := s.Retrieve(ctx, file, confmap.NewFromStringMap(params), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		assert.NoError(t, r.Close(ctx))
+		assert.NoError(t, s.Shutdown(ctx))
+	}()
+	return r.AsRaw()
+}
+
+func TestIncludeConfigSource_VerifyMinisign(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keyID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	cfg := &VerifyConfig{
+		MinisignPublicKey: "untrusted comment: minisign public key 0807060504030201\n" +
+			base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), pub...)),
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "exporters.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(signedContent), 0600))
+
+	for _, prehash := range []bool{false, true} {
+		require.NoError(t, os.WriteFile(file+".minisig", minisignSign(priv, keyID, []byte(signedContent), prehash), 0600))
+		val, err := retrieveVerified(t, cfg, file, nil)
+		require.NoError(t, err)
+		assert.Equal(t, signedContent, val)
+	}
+
+	// Signature files are left out of the directories.
+	val, err := retrieveVerified(t, cfg, dir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"exporters": map[string]any{"otlp": map[string]any{"endpoint": "localhost:4317"}},
+	}, val)
+
+	// Signed with another key.
+	_, otherPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(file+".minisig", minisignSign(otherPriv, keyID, []byte(signedContent), true), 0600))
+	_, err = retrieveVerified(t, cfg, file, nil)
+	assert.IsType(t, &errUnverifiedFile{}, err)
+
+	// Tampered file.
+	require.NoError(t, os.WriteFile(file+".minisig", minisignSign(priv, keyID, []byte(signedContent), true), 0600))
+	require.NoError(t, os.WriteFile(file, []byte(signedContent+"    insecure: true\n"), 0600))
+	_, err = retrieveVerified(t, cfg, file, nil)
+	assert.IsType(t, &errUnverifiedFile{}, err)
+
+	// Missing signature.
+	require.NoError(t, os.Remove(file+".minisig"))
+	_, err = retrieveVerified(t, cfg, file, nil)
+	assert.IsType(t, &errUnverifiedFile{}, err)
+}
+
+func TestIncludeConfigSource_VerifyCosign(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "cosign.pub")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+	cfg := &VerifyConfig{CosignPublicKeyFile: keyFile}
+
+	file := filepath.Join(dir, "exporters.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(signedContent), 0600))
+	digest := sha256.Sum256([]byte(signedContent))
+	signature, err := ecdsa.SignASN1(rand.Reader, priv, digest[:])
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(file+".sig", []byte(base64.StdEncoding.EncodeToString(signature)), 0600))
+
+	val, err := retrieveVerified(t, cfg, file, map[string]any{"sha256": hex.EncodeToString(digest[:])})
+	require.NoError(t, err)
+	assert.Equal(t, signedContent, val)
+
+	require.NoError(t, os.WriteFile(file, []byte(signedContent+"    insecure: true\n"), 0600))
+	_, err = retrieveVerified(t, cfg, file, nil)
+	assert.IsType(t, &errUnverifiedFile{}, err)
+}
@@ -0,0 +1,166 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package includeconfigsource
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+// watchFiles calls watcherFunc once any of the given files, or directories, changes. The
+// returned function stops watching the files.
+func (is *includeConfigSource) watchFiles(files []string, watcherFunc confmap.WatcherFunc) (confmap.CloseFunc, error) {
+	if is.WatchMode == watchModePoll {
+		return is.pollFiles(files, watcherFunc), nil
+	}
+
+	closeFunc, err := notifyFiles(files, watcherFunc)
+	if err != nil && is.WatchMode != watchModeFSNotify {
+		// Notifications can be unavailable, e.g. due to inotify limits, fallback to polling.
+		is.logger.Warn("Failed to watch files for notifications, polling them instead",
+			zap.Strings("files", files), zap.Duration("interval", is.pollInterval()), zap.Error(err))
+		return is.pollFiles(files, watcherFunc), nil
+	}
+	return closeFunc, err
+}
+
+func notifyFiles(files []string, watcherFunc confmap.WatcherFunc) (confmap.CloseFunc, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if err = watcher.Add(file); err != nil {
+			_ = watcher.Close()
+			return nil, err
+		}
+	}
+
+	// The channels are waited for by the goroutines shared by all the config sources, only the
+	// first change, or error, is notified.
+	var mutex sync.Mutex
+	done := false
+	var stopEvents, stopErrors func()
+	notify := func(event *confmap.ChangeEvent) {
+		mutex.Lock()
+		if done {
+			mutex.Unlock()
+			return
+		}
+		done = true
+		stopEvents()
+		stopErrors()
+		mutex.Unlock()
+		watcherFunc(event)
+	}
+	var watchEvents func()
+	watchEvents = func() {
+		stopEvents = configprovider.WatchChannel[fsnotify.Event](watcher.Events, func(event fsnotify.Event, ok bool) {
+			switch {
+			case !ok:
+			case event.Op == fsnotify.Chmod:
+				// Changes of permissions and access times don't affect the content.
+				mutex.Lock()
+				if !done {
+					watchEvents()
+				}
+				mutex.Unlock()
+			default:
+				notify(&confmap.ChangeEvent{Error: nil})
+			}
+		})
+	}
+
+	mutex.Lock()
+	watchEvents()
+	stopErrors = configprovider.WatchChannel[error](watcher.Errors, func(watcherErr error, ok bool) {
+		if ok {
+			notify(&confmap.ChangeEvent{Error: watcherErr})
+		}
+	})
+	mutex.Unlock()
+
+	return func(context.Context) error {
+		mutex.Lock()
+		done = true
+		stopEvents()
+		stopErrors()
+		mutex.Unlock()
+		return watcher.Close()
+	}, nil
+}
+
+// fileState is the state of a file, or directory, compared between polls.
+type fileState struct {
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+func statFile(file string) fileState {
+	info, err := os.Stat(file)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size(), exists: true}
+}
+
+func (is *includeConfigSource) pollFiles(files []string, watcherFunc confmap.WatcherFunc) confmap.CloseFunc {
+	states := make([]fileState, len(files))
+	for i, file := range files {
+		states[i] = statFile(file)
+	}
+
+	// The polls are scheduled with timers instead of a goroutine waiting for each one.
+	doneCh := make(chan struct{})
+	var poll func()
+	poll = func() {
+		select {
+		case <-doneCh:
+			return
+		default:
+		}
+		for i, file := range files {
+			if statFile(file) != states[i] {
+				watcherFunc(&confmap.ChangeEvent{Error: nil})
+				return
+			}
+		}
+		configprovider.WatchAfter(is.pollInterval(), doneCh, poll)
+	}
+	configprovider.WatchAfter(is.pollInterval(), doneCh, poll)
+
+	var once sync.Once
+	return func(context.Context) error {
+		once.Do(func() { close(doneCh) })
+		return nil
+	}
+}
+
+func (is *includeConfigSource) pollInterval() time.Duration {
+	if is.WatchPollInterval <= 0 {
+		return defaultWatchPollInterval
+	}
+	return is.WatchPollInterval
+}
@@ -0,0 +1,50 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package o11yconfigsource
+
+import (
+	"time"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+// Config holds the configuration for the creation of Splunk Observability Cloud config source objects.
+type Config struct {
+	configprovider.SourceSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// Realm of the organization, e.g. "us0". The API URL is derived from it unless
+	// APIURL is set.
+	Realm string `mapstructure:"realm"`
+
+	// APIURL overrides the URL of the Splunk Observability Cloud API, e.g.
+	// "https://api.us0.signalfx.com".
+	APIURL string `mapstructure:"api_url"`
+
+	// AccessToken is the API access token used to read the metric rulesets and the
+	// tokens. This is required unless only the realm is retrieved.
+	AccessToken string `mapstructure:"access_token"`
+
+	// Timeout limits the time of each request to the API. The default value is 10s.
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// PollInterval is the interval at which the retrieved values are fetched again to
+	// watch them for changes. The default value is 5m, 0 disables the watch.
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+}
+
+func (*Config) Validate() error {
+	return nil
+}
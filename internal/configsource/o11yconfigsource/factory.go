@@ -0,0 +1,84 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package o11yconfigsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+const (
+	// The "type" of Splunk Observability Cloud config sources in configuration.
+	typeStr = "o11y"
+
+	defaultTimeout      = 10 * time.Second
+	defaultPollInterval = 5 * time.Minute
+)
+
+// Private error types to help with testability.
+type (
+	errMissingRealm    struct{ error }
+	errInvalidAPIURL   struct{ error }
+	errInvalidTimeout  struct{ error }
+	errInvalidSelector struct{ error }
+	errInvalidParams   struct{ error }
+	errMissingToken    struct{ error }
+	errRequestFailed   struct{ error }
+)
+
+type o11yFactory struct{}
+
+func (f *o11yFactory) Type() component.Type {
+	return typeStr
+}
+
+func (f *o11yFactory) CreateDefaultConfig() configprovider.Source {
+	return &Config{
+		SourceSettings: configprovider.NewSourceSettings(component.NewID(typeStr)),
+		Timeout:        defaultTimeout,
+		PollInterval:   defaultPollInterval,
+	}
+}
+
+func (f *o11yFactory) CreateConfigSource(_ context.Context, params configprovider.CreateParams, cfg configprovider.Source) (configprovider.ConfigSource, error) {
+	o11yCfg := cfg.(*Config)
+
+	if o11yCfg.Realm == "" && o11yCfg.APIURL == "" {
+		return nil, &errMissingRealm{errors.New("realm or api_url must be specified")}
+	}
+	if o11yCfg.APIURL != "" {
+		if u, err := url.ParseRequestURI(o11yCfg.APIURL); err != nil || u.Host == "" {
+			return nil, &errInvalidAPIURL{fmt.Errorf("invalid api_url %q", o11yCfg.APIURL)}
+		}
+	}
+	if o11yCfg.Timeout <= 0 || o11yCfg.PollInterval < 0 {
+		return nil, &errInvalidTimeout{errors.New("timeout must be positive and poll_interval can't be negative")}
+	}
+
+	return newConfigSource(params, o11yCfg), nil
+}
+
+// NewFactory creates a factory for Splunk Observability Cloud ConfigSource objects.
+func NewFactory() configprovider.Factory {
+	return &o11yFactory{}
+}
@@ -0,0 +1,81 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package o11yconfigsource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestO11yFactory_CreateConfigSource(t *testing.T) {
+	factory := NewFactory()
+	assert.Equal(t, component.Type("o11y"), factory.Type())
+	createParams := configprovider.CreateParams{
+		Logger: zap.NewNop(),
+	}
+	tests := []struct {
+		wantErr error
+		config  *Config
+		name    string
+	}{
+		{
+			name:    "missing_realm",
+			config:  &Config{Timeout: defaultTimeout},
+			wantErr: &errMissingRealm{},
+		},
+		{
+			name:    "invalid_api_url",
+			config:  &Config{APIURL: "api.us0.signalfx.com", Timeout: defaultTimeout},
+			wantErr: &errInvalidAPIURL{},
+		},
+		{
+			name:    "invalid_timeout",
+			config:  &Config{Realm: "us0"},
+			wantErr: &errInvalidTimeout{},
+		},
+		{
+			name:    "invalid_poll_interval",
+			config:  &Config{Realm: "us0", Timeout: defaultTimeout, PollInterval: -1},
+			wantErr: &errInvalidTimeout{},
+		},
+		{
+			name:   "success_realm",
+			config: &Config{Realm: "us0", Timeout: defaultTimeout},
+		},
+		{
+			name:   "success_api_url",
+			config: &Config{APIURL: "https://api.example.com", AccessToken: "token", Timeout: defaultTimeout},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := factory.CreateConfigSource(context.Background(), createParams, tt.config)
+			require.IsType(t, tt.wantErr, err)
+			if tt.wantErr == nil {
+				assert.NotNil(t, actual)
+			} else {
+				assert.Nil(t, actual)
+			}
+		})
+	}
+}
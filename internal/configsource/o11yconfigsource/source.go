@@ -0,0 +1,283 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package o11yconfigsource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+// Supported selectors, the ones with a trailing slash are followed by an id or a name.
+const (
+	selectorRealm          = "realm"
+	selectorMetricRulesets = "metricrulesets"
+	selectorMetricRuleset  = "metricruleset/"
+	selectorToken          = "token/"
+)
+
+type retrieveParams struct {
+	// MetricName keeps only the metric rulesets of the metric, for the "metricrulesets" selector.
+	MetricName string `mapstructure:"metric_name"`
+}
+
+// o11yConfigSource implements the configprovider.ConfigSource interface.
+type o11yConfigSource struct {
+	logger       *zap.Logger
+	client       *http.Client
+	realm        string
+	apiURL       string
+	accessToken  string
+	pollInterval time.Duration
+}
+
+func newConfigSource(params configprovider.CreateParams, cfg *Config) *o11yConfigSource {
+	apiURL := strings.TrimSuffix(cfg.APIURL, "/")
+	if apiURL == "" {
+		apiURL = fmt.Sprintf("https://api.%s.signalfx.com", cfg.Realm)
+	}
+	return &o11yConfigSource{
+		logger:       params.Logger,
+		client:       &http.Client{Timeout: cfg.Timeout},
+		realm:        cfg.Realm,
+		apiURL:       apiURL,
+		accessToken:  cfg.AccessToken,
+		pollInterval: cfg.PollInterval,
+	}
+}
+
+func (s *o11yConfigSource) Retrieve(ctx context.Context, selector string, paramsConfigMap *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	var params retrieveParams
+	if paramsConfigMap != nil {
+		if err := paramsConfigMap.Unmarshal(&params, confmap.WithErrorUnused()); err != nil {
+			return nil, &errInvalidParams{fmt.Errorf("failed to unmarshall retrieve params: %w", err)}
+		}
+	}
+
+	if selector == selectorRealm {
+		if s.realm == "" {
+			return nil, &errInvalidSelector{errors.New("the realm isn't configured")}
+		}
+		return confmap.NewRetrieved(realmMetadata(s.realm))
+	}
+
+	fetch, err := s.fetcher(selector, params)
+	if err != nil {
+		return nil, err
+	}
+	if s.accessToken == "" {
+		return nil, &errMissingToken{fmt.Errorf("access_token is required to retrieve %q", selector)}
+	}
+	value, err := fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if watcher == nil || s.pollInterval == 0 {
+		return confmap.NewRetrieved(value)
+	}
+	return confmap.NewRetrieved(value, confmap.WithRetrievedClose(s.poll(selector, fetch, value, watcher)))
+}
+
+func (s *o11yConfigSource) Shutdown(context.Context) error {
+	return nil
+}
+
+// fetcher returns the function fetching the value of the selector from the API.
+func (s *o11yConfigSource) fetcher(selector string, params retrieveParams) (func(context.Context) (any, error), error) {
+	if params.MetricName != "" && selector != selectorMetricRulesets {
+		return nil, &errInvalidParams{fmt.Errorf("metric_name is only supported by the %q selector", selectorMetricRulesets)}
+	}
+
+	switch {
+	case selector == selectorMetricRulesets:
+		return func(ctx context.Context) (any, error) {
+			rulesets, err := s.get(ctx, "/v2/metricruleset")
+			if err != nil {
+				return nil, err
+			}
+			return filterRulesets(unwrapResults(rulesets), params.MetricName), nil
+		}, nil
+	case strings.HasPrefix(selector, selectorMetricRuleset) && len(selector) > len(selectorMetricRuleset):
+		path := "/v2/metricruleset/" + url.PathEscape(strings.TrimPrefix(selector, selectorMetricRuleset))
+		return func(ctx context.Context) (any, error) {
+			return s.get(ctx, path)
+		}, nil
+	case strings.HasPrefix(selector, selectorToken) && len(selector) > len(selectorToken):
+		path := "/v2/token/" + url.PathEscape(strings.TrimPrefix(selector, selectorToken))
+		return func(ctx context.Context) (any, error) {
+			token, err := s.get(ctx, path)
+			if err != nil {
+				return nil, err
+			}
+			return tokenMetadata(token), nil
+		}, nil
+	}
+	return nil, &errInvalidSelector{fmt.Errorf("invalid selector %q, must be %q, %q, %q<id>, or %q<name>",
+		selector, selectorRealm, selectorMetricRulesets, selectorMetricRuleset, selectorToken)}
+}
+
+// get returns the JSON response of the API for the path.
+func (s *o11yConfigSource) get(ctx context.Context, path string) (any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.apiURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-SF-Token", s.accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, &errRequestFailed{err}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &errRequestFailed{err}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &errRequestFailed{fmt.Errorf("GET %s returned status %d", path, resp.StatusCode)}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err = decoder.Decode(&value); err != nil {
+		return nil, &errRequestFailed{fmt.Errorf("GET %s returned invalid JSON: %w", path, err)}
+	}
+	return normalizeNumbers(value), nil
+}
+
+// poll fetches the value again at every poll interval and notifies the watcher once it changed.
+// Failed fetches are logged and retried at the next poll, they don't fail the configuration.
+func (s *o11yConfigSource) poll(selector string, fetch func(context.Context) (any, error), value any, watcherFunc confmap.WatcherFunc) confmap.CloseFunc {
+	// The polls are scheduled with timers instead of a goroutine waiting for each one.
+	doneCh := make(chan struct{})
+	var poll func()
+	poll = func() {
+		select {
+		case <-doneCh:
+			return
+		default:
+		}
+		current, err := fetch(context.Background())
+		switch {
+		case err != nil:
+			s.logger.Warn("failed to poll", zap.String("selector", selector), zap.Error(err))
+		case !reflect.DeepEqual(current, value):
+			watcherFunc(&confmap.ChangeEvent{Error: nil})
+			return
+		}
+		configprovider.WatchAfter(s.pollInterval, doneCh, poll)
+	}
+	configprovider.WatchAfter(s.pollInterval, doneCh, poll)
+
+	var once sync.Once
+	return func(context.Context) error {
+		once.Do(func() { close(doneCh) })
+		return nil
+	}
+}
+
+// realmMetadata returns the URLs of the realm, the same ones set by default by the collector.
+func realmMetadata(realm string) map[string]any {
+	return map[string]any{
+		"realm":      realm,
+		"api_url":    fmt.Sprintf("https://api.%s.signalfx.com", realm),
+		"ingest_url": fmt.Sprintf("https://ingest.%s.signalfx.com", realm),
+		"trace_url":  fmt.Sprintf("https://ingest.%s.signalfx.com/v2/trace", realm),
+		"hec_url":    fmt.Sprintf("https://ingest.%s.signalfx.com/v1/log", realm),
+	}
+}
+
+// unwrapResults returns the results of the paginated responses, or the response itself.
+func unwrapResults(value any) any {
+	if m, ok := value.(map[string]any); ok {
+		if results, ok := m["results"].([]any); ok {
+			return results
+		}
+	}
+	return value
+}
+
+// filterRulesets keeps the rulesets of the metric, all of them if metricName is empty.
+func filterRulesets(rulesets any, metricName string) any {
+	list, ok := rulesets.([]any)
+	if !ok || metricName == "" {
+		return rulesets
+	}
+	filtered := []any{}
+	for _, ruleset := range list {
+		if m, ok := ruleset.(map[string]any); ok && m["metricName"] == metricName {
+			filtered = append(filtered, ruleset)
+		}
+	}
+	return filtered
+}
+
+// tokenMetadata returns the token without its secret, only its metadata is exposed.
+func tokenMetadata(token any) any {
+	if list, ok := unwrapResults(token).([]any); ok && len(list) == 1 {
+		token = list[0]
+	}
+	m, ok := token.(map[string]any)
+	if !ok {
+		return token
+	}
+	metadata := make(map[string]any, len(m))
+	for k, v := range m {
+		if k != "secret" {
+			metadata[k] = v
+		}
+	}
+	return metadata
+}
+
+// normalizeNumbers converts the json.Number values to int64, or float64 if they aren't integers.
+func normalizeNumbers(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		for k, e := range v {
+			v[k] = normalizeNumbers(e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = normalizeNumbers(e)
+		}
+	}
+	return value
+}
@@ -0,0 +1,204 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package o11yconfigsource
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+type mockAPI struct {
+	responses map[string]string
+	mutex     sync.Mutex
+}
+
+func (m *mockAPI) set(path, response string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.responses[path] = response
+}
+
+func (m *mockAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-SF-Token") != "my-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	response, ok := m.responses[r.URL.EscapedPath()]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	_, _ = w.Write([]byte(response))
+}
+
+func newTestSource(t *testing.T, api *mockAPI, cfg Config) *o11yConfigSource {
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	cfg.APIURL = server.URL
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	return newConfigSource(configprovider.CreateParams{Logger: zap.NewNop()}, &cfg)
+}
+
+func TestO11yConfigSourceRetrieve(t *testing.T) {
+	api := &mockAPI{responses: map[string]string{
+		"/v2/metricruleset": `{"count": 2, "results": [
+			{"id": "r1", "metricName": "cpu.utilization", "version": 3, "routingRule": {"destination": "RealTime"}},
+			{"id": "r2", "metricName": "memory.used", "version": 1, "routingRule": {"destination": "Drop"}}
+		]}`,
+		"/v2/metricruleset/r1":    `{"id": "r1", "metricName": "cpu.utilization", "aggregationRules": [{"name": "by-host", "enabled": true}], "weight": 0.5}`,
+		"/v2/token/ingest%2Fprod": `{"name": "ingest/prod", "secret": "s3cr3t", "authScopes": ["INGEST"], "disabled": false}`,
+	}}
+	source := newTestSource(t, api, Config{Realm: "us1", AccessToken: "my-token"})
+
+	tests := []struct {
+		params   map[string]any
+		expected any
+		wantErr  error
+		name     string
+		selector string
+	}{
+		{
+			name:     "realm",
+			selector: "realm",
+			expected: map[string]any{
+				"realm":      "us1",
+				"api_url":    "https://api.us1.signalfx.com",
+				"ingest_url": "https://ingest.us1.signalfx.com",
+				"trace_url":  "https://ingest.us1.signalfx.com/v2/trace",
+				"hec_url":    "https://ingest.us1.signalfx.com/v1/log",
+			},
+		},
+		{
+			name:     "metricrulesets",
+			selector: "metricrulesets",
+			params:   map[string]any{"metric_name": "memory.used"},
+			expected: []any{
+				map[string]any{"id": "r2", "metricName": "memory.used", "version": int64(1), "routingRule": map[string]any{"destination": "Drop"}},
+			},
+		},
+		{
+			name:     "metricruleset",
+			selector: "metricruleset/r1",
+			expected: map[string]any{
+				"id":               "r1",
+				"metricName":       "cpu.utilization",
+				"aggregationRules": []any{map[string]any{"name": "by-host", "enabled": true}},
+				"weight":           0.5,
+			},
+		},
+		{
+			name:     "token_without_secret",
+			selector: "token/ingest/prod",
+			expected: map[string]any{"name": "ingest/prod", "authScopes": []any{"INGEST"}, "disabled": false},
+		},
+		{
+			name:     "not_found",
+			selector: "metricruleset/missing",
+			wantErr:  &errRequestFailed{},
+		},
+		{
+			name:     "invalid_selector",
+			selector: "metricruleset/",
+			wantErr:  &errInvalidSelector{},
+		},
+		{
+			name:     "metric_name_on_ruleset",
+			selector: "metricruleset/r1",
+			params:   map[string]any{"metric_name": "cpu.utilization"},
+			wantErr:  &errInvalidParams{},
+		},
+		{
+			name:     "unknown_param",
+			selector: "metricrulesets",
+			params:   map[string]any{"unknown": true},
+			wantErr:  &errInvalidParams{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var params *confmap.Conf
+			if tt.params != nil {
+				params = confmap.NewFromStringMap(tt.params)
+			}
+			retrieved, err := source.Retrieve(context.Background(), tt.selector, params, nil)
+			require.IsType(t, tt.wantErr, err)
+			if tt.wantErr != nil {
+				return
+			}
+			value, err := retrieved.AsRaw()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestO11yConfigSourceRequiresToken(t *testing.T) {
+	source := newTestSource(t, &mockAPI{responses: map[string]string{}}, Config{Realm: "us0"})
+
+	_, err := source.Retrieve(context.Background(), "metricrulesets", nil, nil)
+	require.IsType(t, &errMissingToken{}, err)
+
+	// The realm doesn't need the API.
+	_, err = source.Retrieve(context.Background(), "realm", nil, nil)
+	require.NoError(t, err)
+
+	source = newTestSource(t, &mockAPI{responses: map[string]string{}}, Config{})
+	_, err = source.Retrieve(context.Background(), "realm", nil, nil)
+	require.IsType(t, &errInvalidSelector{}, err)
+}
+
+func TestO11yConfigSourceWatch(t *testing.T) {
+	api := &mockAPI{responses: map[string]string{
+		"/v2/metricruleset/r1": `{"id": "r1", "version": 1}`,
+	}}
+	source := newTestSource(t, api, Config{AccessToken: "my-token", PollInterval: 10 * time.Millisecond})
+
+	events := make(chan *confmap.ChangeEvent, 1)
+	retrieved, err := source.Retrieve(context.Background(), "metricruleset/r1", nil, func(event *confmap.ChangeEvent) {
+		events <- event
+	})
+	require.NoError(t, err)
+
+	// Unchanged values and failed polls don't notify the watcher.
+	time.Sleep(50 * time.Millisecond)
+	api.set("/v2/metricruleset/r1", "invalid")
+	time.Sleep(50 * time.Millisecond)
+	require.Empty(t, events)
+
+	api.set("/v2/metricruleset/r1", `{"id": "r1", "version": 2}`)
+	select {
+	case event := <-events:
+		assert.NoError(t, event.Error)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "no change event")
+	}
+	require.NoError(t, retrieved.Close(context.Background()))
+}
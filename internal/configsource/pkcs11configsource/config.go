@@ -0,0 +1,41 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkcs11configsource
+
+import "github.com/signalfx/splunk-otel-collector/internal/configprovider"
+
+// Config holds the configuration for the creation of PKCS#11 config source objects.
+type Config struct {
+	configprovider.SourceSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+	// SlotID is the ID of the slot of the token to be used. Exactly one of
+	// SlotID and TokenLabel must be specified.
+	SlotID *uint `mapstructure:"slot_id"`
+	// Module is the path to the PKCS#11 library provided by the HSM vendor,
+	// e.g.: /usr/lib/softhsm/libsofthsm2.so.
+	Module string `mapstructure:"module"`
+	// TokenLabel is the label of the token to be used. Exactly one of
+	// SlotID and TokenLabel must be specified.
+	TokenLabel string `mapstructure:"token_label"`
+	// Pin is the user PIN used to log in to the token. Use PinFile to avoid
+	// having the PIN directly on the configuration.
+	Pin string `mapstructure:"pin"`
+	// PinFile is the path to a file containing the user PIN used to log in to the token.
+	PinFile string `mapstructure:"pin_file"`
+}
+
+func (*Config) Validate() error {
+	return nil
+}
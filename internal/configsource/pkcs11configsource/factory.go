@@ -0,0 +1,87 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkcs11configsource
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+const (
+	// The "type" of PKCS#11 config sources in configuration.
+	typeStr = "pkcs11"
+)
+
+// Private error types to help with testability.
+type (
+	errMissingModule      struct{ error }
+	errMissingToken       struct{ error }
+	errMultipleTokens     struct{ error }
+	errMissingPin         struct{ error }
+	errMultiplePinSources struct{ error }
+)
+
+type pkcs11Factory struct{}
+
+func (p *pkcs11Factory) Type() component.Type {
+	return typeStr
+}
+
+// Sensitive reports that the secrets unwrapped with the PKCS#11 tokens are sensitive, so they are redacted.
+func (p *pkcs11Factory) Sensitive() bool {
+	return true
+}
+
+func (p *pkcs11Factory) CreateDefaultConfig() configprovider.Source {
+	return &Config{
+		SourceSettings: configprovider.NewSourceSettings(component.NewID(typeStr)),
+	}
+}
+
+func (p *pkcs11Factory) CreateConfigSource(_ context.Context, params configprovider.CreateParams, cfg configprovider.Source) (configprovider.ConfigSource, error) {
+	pkcs11Cfg := cfg.(*Config)
+
+	if pkcs11Cfg.Module == "" {
+		return nil, &errMissingModule{errors.New("cannot access the HSM without the path to its PKCS#11 module")}
+	}
+
+	if pkcs11Cfg.TokenLabel == "" && pkcs11Cfg.SlotID == nil {
+		return nil, &errMissingToken{errors.New("either token_label or slot_id must be specified")}
+	}
+
+	if pkcs11Cfg.TokenLabel != "" && pkcs11Cfg.SlotID != nil {
+		return nil, &errMultipleTokens{errors.New("token_label and slot_id were set, use only one")}
+	}
+
+	if pkcs11Cfg.Pin == "" && pkcs11Cfg.PinFile == "" {
+		return nil, &errMissingPin{errors.New("either pin or pin_file must be specified")}
+	}
+
+	if pkcs11Cfg.Pin != "" && pkcs11Cfg.PinFile != "" {
+		return nil, &errMultiplePinSources{errors.New("pin and pin_file were set, use only one")}
+	}
+
+	return newConfigSource(params, pkcs11Cfg)
+}
+
+// NewFactory creates a factory for PKCS#11 ConfigSource objects.
+func NewFactory() configprovider.Factory {
+	return &pkcs11Factory{}
+}
@@ -0,0 +1,112 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkcs11configsource
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestPKCS11Factory_CreateConfigSource(t *testing.T) {
+	factory := NewFactory()
+	assert.Equal(t, component.Type("pkcs11"), factory.Type())
+	createParams := configprovider.CreateParams{
+		Logger: zap.NewNop(),
+	}
+	slotID := uint(1)
+	tests := []struct {
+		config  *Config
+		wantErr error
+		name    string
+	}{
+		{
+			name:    "missing_module",
+			config:  &Config{},
+			wantErr: &errMissingModule{},
+		},
+		{
+			name: "missing_token",
+			config: &Config{
+				Module: "libsofthsm2.so",
+			},
+			wantErr: &errMissingToken{},
+		},
+		{
+			name: "multiple_tokens",
+			config: &Config{
+				Module:     "libsofthsm2.so",
+				TokenLabel: "collector",
+				SlotID:     &slotID,
+			},
+			wantErr: &errMultipleTokens{},
+		},
+		{
+			name: "missing_pin",
+			config: &Config{
+				Module:     "libsofthsm2.so",
+				TokenLabel: "collector",
+			},
+			wantErr: &errMissingPin{},
+		},
+		{
+			name: "multiple_pin_sources",
+			config: &Config{
+				Module:     "libsofthsm2.so",
+				TokenLabel: "collector",
+				Pin:        "1234",
+				PinFile:    path.Join("testdata", "pin"),
+			},
+			wantErr: &errMultiplePinSources{},
+		},
+		{
+			name: "invalid_pin_file",
+			config: &Config{
+				Module:     "libsofthsm2.so",
+				TokenLabel: "collector",
+				PinFile:    path.Join("testdata", "nonexistent"),
+			},
+			wantErr: &errInvalidPinFile{},
+		},
+		{
+			name: "valid",
+			config: &Config{
+				Module: "libsofthsm2.so",
+				SlotID: &slotID,
+				Pin:    "1234",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := factory.CreateConfigSource(context.Background(), createParams, tt.config)
+			if tt.wantErr != nil {
+				require.IsType(t, tt.wantErr, err)
+				assert.Nil(t, actual)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, actual)
+		})
+	}
+}
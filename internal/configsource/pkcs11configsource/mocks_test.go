@@ -0,0 +1,46 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkcs11configsource
+
+import (
+	"errors"
+	"fmt"
+)
+
+type mockSession struct {
+	dataObjects map[string]string
+	keys        map[string]func(string, []byte) ([]byte, error)
+	closed      bool
+}
+
+func (m *mockSession) dataObjectValue(label string) ([]byte, error) {
+	if value, ok := m.dataObjects[label]; ok {
+		return []byte(value), nil
+	}
+	return nil, fmt.Errorf("no object with label %q found", label)
+}
+
+func (m *mockSession) decrypt(keyLabel, mechanism string, ciphertext []byte) ([]byte, error) {
+	if decrypt, ok := m.keys[keyLabel]; ok {
+		return decrypt(mechanism, ciphertext)
+	}
+	return nil, errors.New("no key found")
+}
+
+func (m *mockSession) close() error {
+	m.closed = true
+	return nil
+}
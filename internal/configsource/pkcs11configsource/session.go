@@ -0,0 +1,162 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build cgo
+// +build cgo
+
+package pkcs11configsource
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/miekg/pkcs11"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+var _ session = (*pkcs11Session)(nil)
+
+// pkcs11Session is a session, logged in as user, with a token accessed via a PKCS#11 module.
+type pkcs11Session struct {
+	ctx    *pkcs11.Ctx
+	handle pkcs11.SessionHandle
+}
+
+func openSession(module, tokenLabel string, slotID *uint, pin string) (session, error) {
+	ctx := pkcs11.New(module)
+	if ctx == nil {
+		return nil, fmt.Errorf("failed to load PKCS#11 module %q", module)
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, fmt.Errorf("failed to initialize PKCS#11 module %q: %w", module, err)
+	}
+
+	s, err := login(ctx, tokenLabel, slotID, pin)
+	if err != nil {
+		_ = ctx.Finalize()
+		ctx.Destroy()
+		return nil, err
+	}
+	return s, nil
+}
+
+func login(ctx *pkcs11.Ctx, tokenLabel string, slotID *uint, pin string) (*pkcs11Session, error) {
+	slot, err := findSlot(ctx, tokenLabel, slotID)
+	if err != nil {
+		return nil, err
+	}
+
+	handle, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session on slot %d: %w", slot, err)
+	}
+
+	if err = ctx.Login(handle, pkcs11.CKU_USER, pin); err != nil {
+		_ = ctx.CloseSession(handle)
+		return nil, fmt.Errorf("failed to login on slot %d: %w", slot, err)
+	}
+
+	return &pkcs11Session{ctx: ctx, handle: handle}, nil
+}
+
+func findSlot(ctx *pkcs11.Ctx, tokenLabel string, slotID *uint) (uint, error) {
+	if slotID != nil {
+		return *slotID, nil
+	}
+
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list slots: %w", err)
+	}
+	for _, slot := range slots {
+		tokenInfo, err := ctx.GetTokenInfo(slot)
+		if err != nil {
+			continue
+		}
+		if tokenInfo.Label == tokenLabel {
+			return slot, nil
+		}
+	}
+	return 0, fmt.Errorf("no token with label %q found", tokenLabel)
+}
+
+func (s *pkcs11Session) dataObjectValue(label string) ([]byte, error) {
+	object, err := s.findObject(pkcs11.CKO_DATA, label)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes, err := s.ctx.GetAttributeValue(s.handle, object, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_VALUE, nil),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(attributes) == 0 {
+		return nil, errors.New("data object has no value")
+	}
+	return attributes[0].Value, nil
+}
+
+func (s *pkcs11Session) decrypt(keyLabel, mechanism string, ciphertext []byte) ([]byte, error) {
+	key, err := s.findObject(pkcs11.CKO_PRIVATE_KEY, keyLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	var mech *pkcs11.Mechanism
+	switch mechanism {
+	case mechanismRSAPKCS:
+		mech = pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS, nil)
+	default:
+		params := pkcs11.NewOAEPParams(pkcs11.CKM_SHA256, pkcs11.CKG_MGF1_SHA256, pkcs11.CKZ_DATA_SPECIFIED, nil)
+		mech = pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS_OAEP, params)
+	}
+
+	if err = s.ctx.DecryptInit(s.handle, []*pkcs11.Mechanism{mech}, key); err != nil {
+		return nil, err
+	}
+	return s.ctx.Decrypt(s.handle, ciphertext)
+}
+
+func (s *pkcs11Session) findObject(class uint, label string) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+	if err := s.ctx.FindObjectsInit(s.handle, template); err != nil {
+		return 0, err
+	}
+	objects, _, err := s.ctx.FindObjects(s.handle, 1)
+	if finalErr := s.ctx.FindObjectsFinal(s.handle); err == nil {
+		err = finalErr
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(objects) == 0 {
+		return 0, configprovider.NotFoundError(fmt.Errorf("no object with label %q found", label))
+	}
+	return objects[0], nil
+}
+
+func (s *pkcs11Session) close() error {
+	defer s.ctx.Destroy()
+	_ = s.ctx.Logout(s.handle)
+	_ = s.ctx.CloseSession(s.handle)
+	return s.ctx.Finalize()
+}
@@ -0,0 +1,26 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !cgo
+// +build !cgo
+
+package pkcs11configsource
+
+import "errors"
+
+// openSession requires cgo to load the PKCS#11 module.
+func openSession(string, string, *uint, string) (session, error) {
+	return nil, errors.New("the PKCS#11 config source requires the collector to be built with cgo enabled")
+}
@@ -0,0 +1,141 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkcs11configsource
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+const (
+	mechanismRSAPKCS     = "rsa_pkcs"
+	mechanismRSAPKCSOAEP = "rsa_pkcs_oaep"
+)
+
+// Private error types to help with testability.
+type (
+	errInvalidPinFile        struct{ error }
+	errInvalidRetrieveParams struct{ error }
+	errSession               struct{ error }
+)
+
+type retrieveParams struct {
+	// Ciphertext is the base64 encoded value to be unwrapped, i.e. decrypted, by the
+	// key with the label given by the selector. If empty, the selector is the label
+	// of the data object holding the value to be retrieved.
+	Ciphertext string `mapstructure:"ciphertext"`
+	// Mechanism is the mechanism used to unwrap the ciphertext, either "rsa_pkcs_oaep",
+	// the default, or "rsa_pkcs".
+	Mechanism string `mapstructure:"mechanism"`
+}
+
+// session abstracts the operations performed on the token so the config source
+// can be tested without an actual HSM.
+type session interface {
+	// dataObjectValue returns the value of the data object with the given label.
+	dataObjectValue(label string) ([]byte, error)
+	// decrypt decrypts the ciphertext with the key with the given label using the given mechanism.
+	decrypt(keyLabel, mechanism string, ciphertext []byte) ([]byte, error)
+	close() error
+}
+
+// pkcs11ConfigSource implements the configprovider.ConfigSource interface.
+type pkcs11ConfigSource struct {
+	logger     *zap.Logger
+	session    session
+	newSession func() (session, error)
+}
+
+func newConfigSource(params configprovider.CreateParams, cfg *Config) (configprovider.ConfigSource, error) {
+	pin := cfg.Pin
+	if cfg.PinFile != "" {
+		content, err := os.ReadFile(cfg.PinFile)
+		if err != nil {
+			return nil, &errInvalidPinFile{fmt.Errorf("failed to read pin_file %q: %w", cfg.PinFile, err)}
+		}
+		pin = strings.TrimSpace(string(content))
+	}
+
+	return &pkcs11ConfigSource{
+		logger: params.Logger,
+		newSession: func() (session, error) {
+			return openSession(cfg.Module, cfg.TokenLabel, cfg.SlotID, pin)
+		},
+	}, nil
+}
+
+func (p *pkcs11ConfigSource) Retrieve(_ context.Context, selector string, paramsConfigMap *confmap.Conf, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	actualParams := retrieveParams{}
+	if paramsConfigMap != nil {
+		if err := paramsConfigMap.Unmarshal(&actualParams, confmap.WithErrorUnused()); err != nil {
+			return nil, &errInvalidRetrieveParams{fmt.Errorf("failed to unmarshall retrieve params: %w", err)}
+		}
+	}
+
+	if actualParams.Mechanism == "" {
+		actualParams.Mechanism = mechanismRSAPKCSOAEP
+	}
+	if actualParams.Mechanism != mechanismRSAPKCSOAEP && actualParams.Mechanism != mechanismRSAPKCS {
+		return nil, &errInvalidRetrieveParams{fmt.Errorf("unsupported mechanism %q, must be either %q or %q", actualParams.Mechanism, mechanismRSAPKCSOAEP, mechanismRSAPKCS)}
+	}
+
+	// The session is only opened when needed and kept open for the remaining retrievals.
+	if p.session == nil {
+		s, err := p.newSession()
+		if err != nil {
+			return nil, &errSession{fmt.Errorf("failed to open PKCS#11 session: %w", err)}
+		}
+		p.session = s
+	}
+
+	if actualParams.Ciphertext == "" {
+		value, err := p.session.dataObjectValue(selector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve data object %q: %w", selector, err)
+		}
+		return confmap.NewRetrieved(string(value))
+	}
+
+	// A '+' on the single-line invocation params is decoded as a space, undo it
+	// since spaces are not valid base64 characters.
+	ciphertext, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(actualParams.Ciphertext, " ", "+"))
+	if err != nil {
+		return nil, &errInvalidRetrieveParams{fmt.Errorf("ciphertext must be base64 encoded: %w", err)}
+	}
+
+	value, err := p.session.decrypt(selector, actualParams.Mechanism, ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap value with key %q: %w", selector, err)
+	}
+	return confmap.NewRetrieved(string(value))
+}
+
+func (p *pkcs11ConfigSource) Shutdown(context.Context) error {
+	if p.session == nil {
+		return nil
+	}
+	err := p.session.close()
+	p.session = nil
+	return err
+}
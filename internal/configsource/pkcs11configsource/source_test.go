@@ -0,0 +1,151 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkcs11configsource
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+)
+
+func TestPKCS11ConfigSource_Retrieve(t *testing.T) {
+	// The mocked key "reverses" the ciphertext as decryption.
+	reverse := func(mechanism string, ciphertext []byte) ([]byte, error) {
+		plaintext := make([]byte, len(ciphertext))
+		for i, b := range ciphertext {
+			plaintext[len(ciphertext)-1-i] = b
+		}
+		return append([]byte(mechanism+":"), plaintext...), nil
+	}
+	ciphertext := base64.StdEncoding.EncodeToString([]byte("nekot>?"))
+
+	tests := []struct {
+		params     map[string]any
+		wantErr    error
+		name       string
+		selector   string
+		expected   string
+		wantAnyErr bool
+	}{
+		{
+			name:     "data_object",
+			selector: "ingest_token",
+			expected: "s3cr3t",
+		},
+		{
+			name:       "missing_data_object",
+			selector:   "missing",
+			wantAnyErr: true,
+		},
+		{
+			name:     "unwrap_default_mechanism",
+			selector: "wrapping_key",
+			params:   map[string]any{"ciphertext": ciphertext},
+			expected: "rsa_pkcs_oaep:?>token",
+		},
+		{
+			name:     "unwrap_ciphertext_from_url_query",
+			selector: "wrapping_key",
+			// "Pj8+" with the '+' decoded as space as it happens for URL query params.
+			params:   map[string]any{"ciphertext": "Pj8 ", "mechanism": "rsa_pkcs"},
+			expected: "rsa_pkcs:>?>",
+		},
+		{
+			name:       "unwrap_missing_key",
+			selector:   "missing",
+			params:     map[string]any{"ciphertext": ciphertext},
+			wantAnyErr: true,
+		},
+		{
+			name:     "invalid_ciphertext",
+			selector: "wrapping_key",
+			params:   map[string]any{"ciphertext": "not base64!"},
+			wantErr:  &errInvalidRetrieveParams{},
+		},
+		{
+			name:     "unsupported_mechanism",
+			selector: "wrapping_key",
+			params:   map[string]any{"ciphertext": ciphertext, "mechanism": "aes_gcm"},
+			wantErr:  &errInvalidRetrieveParams{},
+		},
+		{
+			name:     "unknown_param",
+			selector: "wrapping_key",
+			params:   map[string]any{"unknown": true},
+			wantErr:  &errInvalidRetrieveParams{},
+		},
+	}
+
+	session := &mockSession{
+		dataObjects: map[string]string{"ingest_token": "s3cr3t"},
+		keys:        map[string]func(string, []byte) ([]byte, error){"wrapping_key": reverse},
+	}
+	sessionsOpened := 0
+	source := &pkcs11ConfigSource{
+		logger: zap.NewNop(),
+		newSession: func() (session, error) {
+			sessionsOpened++
+			return session, nil
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var params *confmap.Conf
+			if tt.params != nil {
+				params = confmap.NewFromStringMap(tt.params)
+			}
+			r, err := source.Retrieve(context.Background(), tt.selector, params, nil)
+			if tt.wantErr != nil || tt.wantAnyErr {
+				assert.Nil(t, r)
+				require.Error(t, err)
+				if tt.wantErr != nil {
+					require.IsType(t, tt.wantErr, err)
+				}
+				return
+			}
+			require.NoError(t, err)
+
+			val, err := r.AsRaw()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, val)
+		})
+	}
+
+	assert.Equal(t, 1, sessionsOpened)
+	require.NoError(t, source.Shutdown(context.Background()))
+	assert.True(t, session.closed)
+}
+
+func TestPKCS11ConfigSource_SessionError(t *testing.T) {
+	source := &pkcs11ConfigSource{
+		logger: zap.NewNop(),
+		newSession: func() (session, error) {
+			return nil, errors.New("no module")
+		},
+	}
+
+	r, err := source.Retrieve(context.Background(), "ingest_token", nil, nil)
+	assert.Nil(t, r)
+	require.IsType(t, &errSession{}, err)
+	require.NoError(t, source.Shutdown(context.Background()))
+}
@@ -0,0 +1,32 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sopsconfigsource
+
+import "github.com/signalfx/splunk-otel-collector/internal/configprovider"
+
+// Config holds the configuration for the creation of sops config source objects.
+type Config struct {
+	configprovider.SourceSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+	// File is the path to the sops encrypted file to be decrypted.
+	File string `mapstructure:"file"`
+	// Format is the format of the encrypted file, either "yaml" or "json". If not
+	// specified it is inferred from the file extension, defaulting to "yaml".
+	Format string `mapstructure:"format"`
+}
+
+func (*Config) Validate() error {
+	return nil
+}
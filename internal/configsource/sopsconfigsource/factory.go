@@ -0,0 +1,96 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sopsconfigsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+const (
+	// The "type" of sops config sources in configuration.
+	typeStr = "sops"
+
+	formatJSON = "json"
+	formatYAML = "yaml"
+)
+
+// Private error types to help with testability.
+type (
+	errMissingFile       struct{ error }
+	errUnsupportedFormat struct{ error }
+)
+
+type sopsFactory struct{}
+
+func (s *sopsFactory) Type() component.Type {
+	return typeStr
+}
+
+// Sensitive reports that the decrypted SOPS values are sensitive, so they are redacted.
+func (s *sopsFactory) Sensitive() bool {
+	return true
+}
+
+func (s *sopsFactory) CreateDefaultConfig() configprovider.Source {
+	return &Config{
+		SourceSettings: configprovider.NewSourceSettings(component.NewID(typeStr)),
+	}
+}
+
+func (s *sopsFactory) CreateConfigSource(_ context.Context, params configprovider.CreateParams, cfg configprovider.Source) (configprovider.ConfigSource, error) {
+	sopsCfg := cfg.(*Config)
+
+	if sopsCfg.File == "" {
+		return nil, &errMissingFile{errors.New("cannot decrypt an empty file path")}
+	}
+
+	format, err := fileFormat(sopsCfg.File, sopsCfg.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	return newConfigSource(params, sopsCfg.File, format), nil
+}
+
+// NewFactory creates a factory for sops ConfigSource objects.
+func NewFactory() configprovider.Factory {
+	return &sopsFactory{}
+}
+
+// fileFormat returns the format to be used when decrypting the file, using the
+// file extension if the format was not explicitly configured.
+func fileFormat(file, format string) (string, error) {
+	switch strings.ToLower(format) {
+	case formatJSON:
+		return formatJSON, nil
+	case formatYAML, "yml":
+		return formatYAML, nil
+	case "":
+		if strings.EqualFold(filepath.Ext(file), ".json") {
+			return formatJSON, nil
+		}
+		return formatYAML, nil
+	}
+	return "", &errUnsupportedFormat{fmt.Errorf("unsupported format %q, must be either %q or %q", format, formatYAML, formatJSON)}
+}
@@ -0,0 +1,91 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sopsconfigsource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestSopsFactory_CreateConfigSource(t *testing.T) {
+	factory := NewFactory()
+	assert.Equal(t, component.Type("sops"), factory.Type())
+	createParams := configprovider.CreateParams{
+		Logger: zap.NewNop(),
+	}
+	tests := []struct {
+		config         *Config
+		wantErr        error
+		name           string
+		expectedFormat string
+	}{
+		{
+			name:    "missing_file",
+			config:  &Config{},
+			wantErr: &errMissingFile{},
+		},
+		{
+			name: "unsupported_format",
+			config: &Config{
+				File:   "secrets.enc",
+				Format: "ini",
+			},
+			wantErr: &errUnsupportedFormat{},
+		},
+		{
+			name: "yaml_by_default",
+			config: &Config{
+				File: "secrets.enc",
+			},
+			expectedFormat: formatYAML,
+		},
+		{
+			name: "json_from_extension",
+			config: &Config{
+				File: "secrets.enc.JSON",
+			},
+			expectedFormat: formatJSON,
+		},
+		{
+			name: "explicit_format",
+			config: &Config{
+				File:   "secrets.enc.json",
+				Format: "yaml",
+			},
+			expectedFormat: formatYAML,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := factory.CreateConfigSource(context.Background(), createParams, tt.config)
+			if tt.wantErr != nil {
+				require.IsType(t, tt.wantErr, err)
+				assert.Nil(t, actual)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, actual)
+			assert.Equal(t, tt.expectedFormat, actual.(*sopsConfigSource).format)
+		})
+	}
+}
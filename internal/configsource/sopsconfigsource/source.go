@@ -0,0 +1,129 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sopsconfigsource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/knadh/koanf/maps"
+	"go.mozilla.org/sops/v3/decrypt"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+// Error wrapper types to help with testability
+type (
+	errDecrypt     struct{ error }
+	errBadSelector struct{ error }
+)
+
+// Is makes the missing keys match configprovider.ErrNotFound so the default values of the
+// references are used for them.
+func (e *errBadSelector) Is(target error) bool {
+	return target == configprovider.ErrNotFound
+}
+
+// sopsConfigSource implements the configprovider.ConfigSource interface.
+type sopsConfigSource struct {
+	logger *zap.Logger
+	// data holds the decrypted contents of the file, it is only kept in memory.
+	data map[string]any
+	// mu serializes the lazy decryption of the file across concurrent retrieves.
+	mu sync.Mutex
+
+	file   string
+	format string
+}
+
+func newConfigSource(params configprovider.CreateParams, file, format string) configprovider.ConfigSource {
+	return &sopsConfigSource{
+		logger: params.Logger,
+		file:   file,
+		format: format,
+	}
+}
+
+func (s *sopsConfigSource) Retrieve(_ context.Context, selector string, _ *confmap.Conf, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	// All keys come from the same file, decrypt it only once.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data == nil {
+		if err := s.decryptFile(); err != nil {
+			return nil, err
+		}
+	}
+
+	value := traverseToKey(s.data, selector)
+	if value == nil {
+		return nil, &errBadSelector{fmt.Errorf("no value in file %q for key %q", s.file, selector)}
+	}
+
+	return confmap.NewRetrieved(value)
+}
+
+func (s *sopsConfigSource) Shutdown(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = nil
+	return nil
+}
+
+// decryptFile decrypts the file of the sopsConfigSource using the key backend
+// recorded on its sops metadata and stores the resulting data on the data field.
+// It must be called with mu held.
+func (s *sopsConfigSource) decryptFile() error {
+	cleartext, err := decrypt.File(s.file, s.format)
+	if err != nil {
+		return &errDecrypt{fmt.Errorf("failed to decrypt file %q: %w", s.file, err)}
+	}
+
+	// YAML is a superset of JSON so the same unmarshalling handles both formats.
+	data := map[string]any{}
+	if err = yaml.Unmarshal(cleartext, &data); err != nil {
+		return &errDecrypt{fmt.Errorf("failed to parse decrypted file %q: %w", s.file, err)}
+	}
+	maps.IntfaceKeysToStrings(data)
+
+	s.logger.Debug("sops file decrypted", zap.String("file", s.file))
+	s.data = data
+	return nil
+}
+
+// Allows key to be dot-delimited to traverse nested maps.
+func traverseToKey(data map[string]any, key string) any {
+	// Since strings.Split is called with a non-empty separator it will always return
+	// a slice with at least one element.
+	parts := strings.Split(key, ".")
+
+	for i := 0; ; i++ {
+		partVal := data[parts[i]]
+		if i == len(parts)-1 {
+			return partVal
+		}
+
+		var ok bool
+		data, ok = partVal.(map[string]any)
+		if !ok {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sopsconfigsource
+
+import (
+	"context"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestSopsConfigSource_Retrieve(t *testing.T) {
+	data := map[string]any{
+		"k0": "v0",
+		"m0": map[string]any{
+			"k1": 42,
+			"m1": map[string]any{
+				"k2": "v2",
+			},
+		},
+	}
+
+	tests := []struct {
+		expected any
+		wantErr  error
+		name     string
+		selector string
+	}{
+		{
+			name:     "top_level_key",
+			selector: "k0",
+			expected: "v0",
+		},
+		{
+			name:     "nested_key",
+			selector: "m0.m1.k2",
+			expected: "v2",
+		},
+		{
+			name:     "map",
+			selector: "m0.m1",
+			expected: map[string]any{"k2": "v2"},
+		},
+		{
+			name:     "missing_key",
+			selector: "m0.missing",
+			wantErr:  &errBadSelector{},
+		},
+		{
+			name:     "traverse_scalar",
+			selector: "k0.k1",
+			wantErr:  &errBadSelector{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &sopsConfigSource{
+				logger: zap.NewNop(),
+				data:   data,
+				file:   "secrets.enc.yaml",
+				format: formatYAML,
+			}
+
+			ctx := context.Background()
+			r, err := source.Retrieve(ctx, tt.selector, nil, nil)
+			if tt.wantErr != nil {
+				assert.Nil(t, r)
+				require.IsType(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+
+			val, err := r.AsRaw()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, val)
+		})
+	}
+}
+
+func TestSopsConfigSource_DecryptErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+	}{
+		{
+			name: "missing_file",
+			file: path.Join("testdata", "missing.yaml"),
+		},
+		{
+			name: "not_encrypted",
+			file: path.Join("testdata", "not_encrypted.yaml"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := newConfigSource(configprovider.CreateParams{Logger: zap.NewNop()}, tt.file, formatYAML)
+
+			ctx := context.Background()
+			r, err := source.Retrieve(ctx, "k0", nil, nil)
+			assert.Nil(t, r)
+			require.IsType(t, &errDecrypt{}, err)
+			assert.NoError(t, source.Shutdown(ctx))
+		})
+	}
+}
+
+func TestSopsConfigSource_DecryptAgeFile(t *testing.T) {
+	// The fixture was encrypted with sops for the age recipient on testdata/age_key.txt.
+	t.Setenv("SOPS_AGE_KEY_FILE", path.Join("testdata", "age_key.txt"))
+
+	source := newConfigSource(configprovider.CreateParams{Logger: zap.NewNop()}, path.Join("testdata", "secrets.enc.yaml"), formatYAML)
+
+	ctx := context.Background()
+	expected := map[string]any{
+		"k0":       "v0",
+		"m0.k1":    "v1",
+		"m0.m1.k2": "v2",
+		"m0.m1":    map[string]any{"k2": "v2"},
+	}
+	for selector, want := range expected {
+		r, err := source.Retrieve(ctx, selector, nil, nil)
+		require.NoError(t, err, selector)
+		val, err := r.AsRaw()
+		require.NoError(t, err)
+		assert.Equal(t, want, val, selector)
+	}
+
+	r, err := source.Retrieve(ctx, "sops", nil, nil)
+	assert.Nil(t, r)
+	assert.IsType(t, &errBadSelector{}, err)
+
+	assert.NoError(t, source.Shutdown(ctx))
+}
+
+func TestSopsConfigSource_ConcurrentRetrieve(t *testing.T) {
+	t.Setenv("SOPS_AGE_KEY_FILE", path.Join("testdata", "age_key.txt"))
+
+	source := newConfigSource(configprovider.CreateParams{Logger: zap.NewNop()}, path.Join("testdata", "secrets.enc.yaml"), formatYAML)
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := source.Retrieve(ctx, "m0.k1", nil, nil)
+			if assert.NoError(t, err) {
+				val, err := r.AsRaw()
+				assert.NoError(t, err)
+				assert.Equal(t, "v1", val)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.NoError(t, source.Shutdown(ctx))
+}
@@ -0,0 +1,161 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfstateconfigsource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// stateReader reads the raw Terraform state from a backend.
+type stateReader interface {
+	read(ctx context.Context) ([]byte, error)
+}
+
+var (
+	_ stateReader = (*localStateReader)(nil)
+	_ stateReader = (*s3StateReader)(nil)
+	_ stateReader = (*remoteStateReader)(nil)
+)
+
+type localStateReader struct {
+	path string
+}
+
+func (l *localStateReader) read(context.Context) ([]byte, error) {
+	return os.ReadFile(l.path)
+}
+
+type s3StateReader struct {
+	cfg S3Config
+}
+
+func (s *s3StateReader) read(ctx context.Context) ([]byte, error) {
+	awsCfg := aws.Config{}
+	if s.cfg.Region != "" {
+		awsCfg.Region = aws.String(s.cfg.Region)
+	}
+	if s.cfg.Endpoint != "" {
+		awsCfg.Endpoint = aws.String(s.cfg.Endpoint)
+		awsCfg.S3ForcePathStyle = aws.Bool(true)
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            awsCfg,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := s3.New(sess).GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.cfg.Bucket),
+		Key:    aws.String(s.cfg.Key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get s3://%s/%s: %w", s.cfg.Bucket, s.cfg.Key, err)
+	}
+	defer out.Body.Close()
+
+	return io.ReadAll(out.Body)
+}
+
+// remoteStateReader downloads the current state of a Terraform Cloud or
+// Terraform Enterprise workspace via its API.
+type remoteStateReader struct {
+	client  *http.Client
+	baseURL string
+	cfg     RemoteConfig
+}
+
+func newRemoteStateReader(cfg RemoteConfig) *remoteStateReader {
+	hostname := cfg.Hostname
+	if hostname == "" {
+		hostname = defaultRemoteHostname
+	}
+	return &remoteStateReader{
+		client:  http.DefaultClient,
+		baseURL: "https://" + hostname,
+		cfg:     cfg,
+	}
+}
+
+func (r *remoteStateReader) read(ctx context.Context) ([]byte, error) {
+	var workspace struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	workspaceURL := fmt.Sprintf("%s/api/v2/organizations/%s/workspaces/%s",
+		r.baseURL, url.PathEscape(r.cfg.Organization), url.PathEscape(r.cfg.Workspace))
+	if err := r.getJSON(ctx, workspaceURL, &workspace); err != nil {
+		return nil, err
+	}
+
+	var stateVersion struct {
+		Data struct {
+			Attributes struct {
+				DownloadURL string `json:"hosted-state-download-url"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	stateVersionURL := fmt.Sprintf("%s/api/v2/workspaces/%s/current-state-version", r.baseURL, url.PathEscape(workspace.Data.ID))
+	if err := r.getJSON(ctx, stateVersionURL, &stateVersion); err != nil {
+		return nil, err
+	}
+	if stateVersion.Data.Attributes.DownloadURL == "" {
+		return nil, fmt.Errorf("workspace %q has no state available for download", r.cfg.Workspace)
+	}
+
+	return r.get(ctx, stateVersion.Data.Attributes.DownloadURL)
+}
+
+func (r *remoteStateReader) getJSON(ctx context.Context, target string, v any) error {
+	body, err := r.get(ctx, target)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+func (r *remoteStateReader) get(ctx context.Context, target string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+r.cfg.Token)
+	req.Header.Set("Content-Type", "application/vnd.api+json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned status %d", req.URL.Path, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
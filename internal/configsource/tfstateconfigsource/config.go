@@ -0,0 +1,64 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfstateconfigsource
+
+import (
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+// Config holds the configuration for the creation of Terraform state config source objects.
+type Config struct {
+	configprovider.SourceSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+	// Backend is where the state is stored: "local", the default, "s3", or "remote"
+	// for Terraform Cloud and Terraform Enterprise.
+	Backend string `mapstructure:"backend"`
+	// Path is the path to the state file when using the "local" backend.
+	Path string `mapstructure:"path"`
+	// S3 is the location of the state when using the "s3" backend.
+	S3 S3Config `mapstructure:"s3"`
+	// Remote is the location of the state when using the "remote" backend.
+	Remote RemoteConfig `mapstructure:"remote"`
+}
+
+// S3Config is the location of a state stored on S3. Credentials are obtained via
+// the standard AWS credential chain.
+type S3Config struct {
+	// Bucket is the name of the S3 bucket. This is required.
+	Bucket string `mapstructure:"bucket"`
+	// Key is the path of the state file on the bucket. This is required.
+	Key string `mapstructure:"key"`
+	// Region is the AWS region of the bucket. If not specified it is obtained from
+	// the environment, e.g. AWS_REGION.
+	Region string `mapstructure:"region"`
+	// Endpoint overrides the S3 endpoint, e.g. for S3 compatible services.
+	Endpoint string `mapstructure:"endpoint"`
+}
+
+// RemoteConfig is the location of a state stored on Terraform Cloud or Terraform Enterprise.
+type RemoteConfig struct {
+	// Hostname of the Terraform Cloud or Terraform Enterprise instance. Defaults to "app.terraform.io".
+	Hostname string `mapstructure:"hostname"`
+	// Organization is the name of the organization owning the workspace. This is required.
+	Organization string `mapstructure:"organization"`
+	// Workspace is the name of the workspace. This is required.
+	Workspace string `mapstructure:"workspace"`
+	// Token is the API token used to read the state. This is required.
+	Token string `mapstructure:"token"`
+}
+
+func (*Config) Validate() error {
+	return nil
+}
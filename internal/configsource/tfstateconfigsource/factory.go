@@ -0,0 +1,98 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfstateconfigsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+const (
+	// The "type" of Terraform state config sources in configuration.
+	typeStr = "tfstate"
+
+	backendLocal  = "local"
+	backendS3     = "s3"
+	backendRemote = "remote"
+
+	defaultRemoteHostname = "app.terraform.io"
+)
+
+// Private error types to help with testability.
+type (
+	errInvalidBackend     struct{ error }
+	errMissingPath        struct{ error }
+	errMissingS3Location  struct{ error }
+	errMissingWorkspace   struct{ error }
+	errMissingRemoteToken struct{ error }
+)
+
+type tfstateFactory struct{}
+
+func (t *tfstateFactory) Type() component.Type {
+	return typeStr
+}
+
+func (t *tfstateFactory) CreateDefaultConfig() configprovider.Source {
+	return &Config{
+		SourceSettings: configprovider.NewSourceSettings(component.NewID(typeStr)),
+		Backend:        backendLocal,
+		Remote: RemoteConfig{
+			Hostname: defaultRemoteHostname,
+		},
+	}
+}
+
+func (t *tfstateFactory) CreateConfigSource(_ context.Context, params configprovider.CreateParams, cfg configprovider.Source) (configprovider.ConfigSource, error) {
+	tfCfg := cfg.(*Config)
+
+	var reader stateReader
+	switch tfCfg.Backend {
+	case backendLocal:
+		if tfCfg.Path == "" {
+			return nil, &errMissingPath{errors.New("path cannot be empty for the local backend")}
+		}
+		reader = &localStateReader{path: tfCfg.Path}
+	case backendS3:
+		if tfCfg.S3.Bucket == "" || tfCfg.S3.Key == "" {
+			return nil, &errMissingS3Location{errors.New("s3 bucket and key must be specified for the s3 backend")}
+		}
+		reader = &s3StateReader{cfg: tfCfg.S3}
+	case backendRemote:
+		if tfCfg.Remote.Organization == "" || tfCfg.Remote.Workspace == "" {
+			return nil, &errMissingWorkspace{errors.New("remote organization and workspace must be specified for the remote backend")}
+		}
+		if tfCfg.Remote.Token == "" {
+			return nil, &errMissingRemoteToken{errors.New("remote token cannot be empty for the remote backend")}
+		}
+		reader = newRemoteStateReader(tfCfg.Remote)
+	default:
+		return nil, &errInvalidBackend{fmt.Errorf("unsupported backend %q, it must be one of %q, %q, or %q",
+			tfCfg.Backend, backendLocal, backendS3, backendRemote)}
+	}
+
+	return newConfigSource(params, reader), nil
+}
+
+// NewFactory creates a factory for Terraform state ConfigSource objects.
+func NewFactory() configprovider.Factory {
+	return &tfstateFactory{}
+}
@@ -0,0 +1,109 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfstateconfigsource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestTFStateFactory_CreateConfigSource(t *testing.T) {
+	factory := NewFactory()
+	assert.Equal(t, component.Type("tfstate"), factory.Type())
+	createParams := configprovider.CreateParams{
+		Logger: zap.NewNop(),
+	}
+	tests := []struct {
+		config  *Config
+		wantErr error
+		name    string
+	}{
+		{
+			name:    "invalid_backend",
+			config:  &Config{Backend: "consul"},
+			wantErr: &errInvalidBackend{},
+		},
+		{
+			name:    "missing_path",
+			config:  &Config{Backend: backendLocal},
+			wantErr: &errMissingPath{},
+		},
+		{
+			name: "missing_s3_key",
+			config: &Config{
+				Backend: backendS3,
+				S3:      S3Config{Bucket: "terraform-states"},
+			},
+			wantErr: &errMissingS3Location{},
+		},
+		{
+			name: "missing_workspace",
+			config: &Config{
+				Backend: backendRemote,
+				Remote:  RemoteConfig{Organization: "splunk", Token: "my-token"},
+			},
+			wantErr: &errMissingWorkspace{},
+		},
+		{
+			name: "missing_remote_token",
+			config: &Config{
+				Backend: backendRemote,
+				Remote:  RemoteConfig{Organization: "splunk", Workspace: "collector"},
+			},
+			wantErr: &errMissingRemoteToken{},
+		},
+		{
+			name: "local",
+			config: &Config{
+				Backend: backendLocal,
+				Path:    "terraform.tfstate",
+			},
+		},
+		{
+			name: "s3",
+			config: &Config{
+				Backend: backendS3,
+				S3:      S3Config{Bucket: "terraform-states", Key: "terraform.tfstate"},
+			},
+		},
+		{
+			name: "remote",
+			config: &Config{
+				Backend: backendRemote,
+				Remote:  RemoteConfig{Organization: "splunk", Workspace: "collector", Token: "my-token"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := factory.CreateConfigSource(context.Background(), createParams, tt.config)
+			if tt.wantErr != nil {
+				assert.Nil(t, actual)
+				require.IsType(t, tt.wantErr, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfstateconfigsource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+// Private error types to help with testability.
+type (
+	errReadState      struct{ error }
+	errParseState     struct{ error }
+	errOutputNotFound struct{ error }
+)
+
+// Is makes the missing outputs match configprovider.ErrNotFound so the default values of the
+// references are used for them.
+func (e *errOutputNotFound) Is(target error) bool {
+	return target == configprovider.ErrNotFound
+}
+
+// state is the subset of the Terraform state format used by the config source.
+type state struct {
+	Outputs map[string]struct {
+		Value any `json:"value"`
+	} `json:"outputs"`
+}
+
+// tfstateConfigSource implements the configprovider.ConfigSource interface.
+type tfstateConfigSource struct {
+	logger  *zap.Logger
+	reader  stateReader
+	outputs map[string]any
+}
+
+func newConfigSource(params configprovider.CreateParams, reader stateReader) configprovider.ConfigSource {
+	return &tfstateConfigSource{
+		logger: params.Logger,
+		reader: reader,
+	}
+}
+
+// Retrieve returns the value of the output named by the selector. Values of
+// nested fields of object outputs can be retrieved by using "." to separate
+// the output name from the field names, e.g. "cluster.endpoint".
+func (t *tfstateConfigSource) Retrieve(ctx context.Context, selector string, _ *confmap.Conf, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	// The state is only read once and shared by all retrievals using the same configuration.
+	if t.outputs == nil {
+		if err := t.load(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	value := traverseToKey(t.outputs, selector)
+	if value == nil {
+		return nil, &errOutputNotFound{fmt.Errorf("output %q not found on the Terraform state", selector)}
+	}
+
+	return confmap.NewRetrieved(value)
+}
+
+func (t *tfstateConfigSource) Shutdown(context.Context) error {
+	return nil
+}
+
+func (t *tfstateConfigSource) load(ctx context.Context) error {
+	raw, err := t.reader.read(ctx)
+	if err != nil {
+		return &errReadState{fmt.Errorf("failed to read Terraform state: %w", err)}
+	}
+
+	var s state
+	if err = json.Unmarshal(raw, &s); err != nil {
+		return &errParseState{fmt.Errorf("failed to parse Terraform state: %w", err)}
+	}
+
+	outputs := make(map[string]any, len(s.Outputs))
+	for name, output := range s.Outputs {
+		outputs[name] = output.Value
+	}
+
+	t.logger.Debug("Terraform state read", zap.Int("outputs", len(outputs)))
+	t.outputs = outputs
+	return nil
+}
+
+// Allows key to be dot-delimited to traverse nested maps.
+func traverseToKey(data map[string]any, key string) any {
+	// Since strings.Split is called with a non-empty separator it will always return
+	// a slice with at least one element.
+	parts := strings.Split(key, ".")
+
+	for i := 0; ; i++ {
+		partVal := data[parts[i]]
+		if i == len(parts)-1 {
+			return partVal
+		}
+
+		var ok bool
+		data, ok = partVal.(map[string]any)
+		if !ok {
+			return nil
+		}
+	}
+}
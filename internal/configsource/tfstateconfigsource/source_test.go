@@ -0,0 +1,175 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfstateconfigsource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestTFStateConfigSource_Retrieve(t *testing.T) {
+	tests := []struct {
+		expected any
+		wantErr  error
+		name     string
+		selector string
+	}{
+		{
+			name:     "string",
+			selector: "ingest_token",
+			expected: "s3cr3t",
+		},
+		{
+			name:     "number",
+			selector: "replicas",
+			expected: float64(3),
+		},
+		{
+			name:     "object",
+			selector: "cluster",
+			expected: map[string]any{
+				"endpoint": "https://cluster.example.com",
+				"zones":    []any{"us-east-1a", "us-east-1b"},
+			},
+		},
+		{
+			name:     "nested",
+			selector: "cluster.endpoint",
+			expected: "https://cluster.example.com",
+		},
+		{
+			name:     "missing_output",
+			selector: "missing",
+			wantErr:  &errOutputNotFound{},
+		},
+		{
+			name:     "missing_nested",
+			selector: "realm.endpoint",
+			wantErr:  &errOutputNotFound{},
+		},
+	}
+
+	source := newConfigSource(
+		configprovider.CreateParams{Logger: zap.NewNop()},
+		&localStateReader{path: path.Join("testdata", "terraform.tfstate")},
+	)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retrieved, err := source.Retrieve(context.Background(), tt.selector, nil, nil)
+			if tt.wantErr != nil {
+				assert.Nil(t, retrieved)
+				require.IsType(t, tt.wantErr, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, retrieved)
+			value, err := retrieved.AsRaw()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+
+	require.NoError(t, source.Shutdown(context.Background()))
+}
+
+func TestTFStateConfigSource_InvalidState(t *testing.T) {
+	tests := []struct {
+		wantErr error
+		name    string
+		path    string
+	}{
+		{
+			name:    "missing_file",
+			path:    path.Join("testdata", "missing.tfstate"),
+			wantErr: &errReadState{},
+		},
+		{
+			name:    "invalid_file",
+			path:    path.Join("testdata", "invalid.tfstate"),
+			wantErr: &errParseState{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := newConfigSource(
+				configprovider.CreateParams{Logger: zap.NewNop()},
+				&localStateReader{path: tt.path},
+			)
+			retrieved, err := source.Retrieve(context.Background(), "realm", nil, nil)
+			assert.Nil(t, retrieved)
+			require.IsType(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestTFStateRemoteStateReader(t *testing.T) {
+	state, err := os.ReadFile(path.Join("testdata", "terraform.tfstate"))
+	require.NoError(t, err)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer my-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v2/organizations/splunk/workspaces/collector":
+			fmt.Fprint(w, `{"data": {"id": "ws-123", "type": "workspaces"}}`)
+		case "/api/v2/workspaces/ws-123/current-state-version":
+			fmt.Fprintf(w, `{"data": {"id": "sv-456", "attributes": {"hosted-state-download-url": "%s/state/sv-456"}}}`, server.URL)
+		case "/state/sv-456":
+			_, _ = w.Write(state)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	newReader := func(workspace, token string) *remoteStateReader {
+		reader := newRemoteStateReader(RemoteConfig{
+			Organization: "splunk",
+			Workspace:    workspace,
+			Token:        token,
+		})
+		reader.client = server.Client()
+		reader.baseURL = server.URL
+		return reader
+	}
+
+	raw, err := newReader("collector", "my-token").read(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, state, raw)
+
+	_, err = newReader("other", "my-token").read(context.Background())
+	assert.ErrorContains(t, err, "404")
+
+	_, err = newReader("collector", "wrong-token").read(context.Background())
+	assert.ErrorContains(t, err, "401")
+}
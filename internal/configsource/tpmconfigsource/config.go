@@ -0,0 +1,36 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tpmconfigsource
+
+import "github.com/signalfx/splunk-otel-collector/internal/configprovider"
+
+// Config holds the configuration for the creation of TPM config source objects.
+type Config struct {
+	configprovider.SourceSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+	// Device is the path to the TPM device. Defaults to "/dev/tpmrm0", the TPM
+	// resource manager on Linux. It is ignored on Windows.
+	Device string `mapstructure:"device"`
+	// PCRs is the list of SHA-256 PCR indices that the values were sealed to. If
+	// empty the values are expected to be sealed without a PCR policy.
+	PCRs []int `mapstructure:"pcrs"`
+	// ParentHandle is the persistent handle of the key under which the values were
+	// sealed. Defaults to 0x81000001, the conventional handle of the storage root key.
+	ParentHandle uint32 `mapstructure:"parent_handle"`
+}
+
+func (*Config) Validate() error {
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tpmconfigsource
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+const nonceSize = 16
+
+var _ device = (*tpmDevice)(nil)
+
+// tpmDevice is a TPM 2.0 accessed via the go-tpm library.
+type tpmDevice struct {
+	rw           io.ReadWriteCloser
+	pcrs         []int
+	parentHandle tpmutil.Handle
+}
+
+func openDevice(path string, parentHandle uint32, pcrs []int) (device, error) {
+	rw, err := tpm2.OpenTPM(path)
+	if err != nil {
+		return nil, err
+	}
+	return &tpmDevice{
+		rw:           rw,
+		pcrs:         pcrs,
+		parentHandle: tpmutil.Handle(parentHandle),
+	}, nil
+}
+
+func (d *tpmDevice) unseal(public, private []byte) ([]byte, error) {
+	objectHandle, _, err := tpm2.Load(d.rw, d.parentHandle, "", public, private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sealed object: %w", err)
+	}
+	defer func() {
+		_ = tpm2.FlushContext(d.rw, objectHandle)
+	}()
+
+	if len(d.pcrs) == 0 {
+		return tpm2.Unseal(d.rw, objectHandle, "")
+	}
+
+	session, err := d.pcrPolicySession()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tpm2.FlushContext(d.rw, session)
+	}()
+
+	return tpm2.UnsealWithSession(d.rw, session, objectHandle, "")
+}
+
+// pcrPolicySession starts a policy session satisfied by the current values of the PCRs.
+func (d *tpmDevice) pcrPolicySession() (tpmutil.Handle, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return tpm2.HandleNull, err
+	}
+
+	session, _, err := tpm2.StartAuthSession(d.rw, tpm2.HandleNull, tpm2.HandleNull, nonce, nil, tpm2.SessionPolicy, tpm2.AlgNull, tpm2.AlgSHA256)
+	if err != nil {
+		return tpm2.HandleNull, fmt.Errorf("failed to start policy session: %w", err)
+	}
+
+	if err = tpm2.PolicyPCR(d.rw, session, nil, tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: d.pcrs}); err != nil {
+		_ = tpm2.FlushContext(d.rw, session)
+		return tpm2.HandleNull, fmt.Errorf("failed to satisfy PCR policy: %w", err)
+	}
+
+	return session, nil
+}
+
+func (d *tpmDevice) close() error {
+	return d.rw.Close()
+}
@@ -0,0 +1,90 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tpmconfigsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+const (
+	// The "type" of TPM config sources in configuration.
+	typeStr = "tpm"
+
+	defaultDevice       = "/dev/tpmrm0"
+	defaultParentHandle = 0x81000001
+
+	// Persistent handles are on the range 0x81000000-0x81FFFFFF.
+	persistentHandleFirst = 0x81000000
+	persistentHandleLast  = 0x81FFFFFF
+	maxPCRIndex           = 23
+)
+
+// Private error types to help with testability.
+type (
+	errMissingDevice       struct{ error }
+	errInvalidParentHandle struct{ error }
+	errInvalidPCR          struct{ error }
+)
+
+type tpmFactory struct{}
+
+func (t *tpmFactory) Type() component.Type {
+	return typeStr
+}
+
+// Sensitive reports that the secrets unsealed with the TPM are sensitive, so they are redacted.
+func (t *tpmFactory) Sensitive() bool {
+	return true
+}
+
+func (t *tpmFactory) CreateDefaultConfig() configprovider.Source {
+	return &Config{
+		SourceSettings: configprovider.NewSourceSettings(component.NewID(typeStr)),
+		Device:         defaultDevice,
+		ParentHandle:   defaultParentHandle,
+	}
+}
+
+func (t *tpmFactory) CreateConfigSource(_ context.Context, params configprovider.CreateParams, cfg configprovider.Source) (configprovider.ConfigSource, error) {
+	tpmCfg := cfg.(*Config)
+
+	if tpmCfg.Device == "" {
+		return nil, &errMissingDevice{errors.New("device cannot be empty")}
+	}
+
+	if tpmCfg.ParentHandle < persistentHandleFirst || tpmCfg.ParentHandle > persistentHandleLast {
+		return nil, &errInvalidParentHandle{fmt.Errorf("parent_handle 0x%x is not a persistent handle", tpmCfg.ParentHandle)}
+	}
+
+	for _, pcr := range tpmCfg.PCRs {
+		if pcr < 0 || pcr > maxPCRIndex {
+			return nil, &errInvalidPCR{fmt.Errorf("invalid PCR index %d, it must be between 0 and %d", pcr, maxPCRIndex)}
+		}
+	}
+
+	return newConfigSource(params, tpmCfg), nil
+}
+
+// NewFactory creates a factory for TPM ConfigSource objects.
+func NewFactory() configprovider.Factory {
+	return &tpmFactory{}
+}
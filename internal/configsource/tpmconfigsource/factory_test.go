@@ -0,0 +1,90 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tpmconfigsource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestTPMFactory_CreateConfigSource(t *testing.T) {
+	factory := NewFactory()
+	assert.Equal(t, component.Type("tpm"), factory.Type())
+	createParams := configprovider.CreateParams{
+		Logger: zap.NewNop(),
+	}
+	tests := []struct {
+		config  *Config
+		wantErr error
+		name    string
+	}{
+		{
+			name: "missing_device",
+			config: &Config{
+				ParentHandle: defaultParentHandle,
+			},
+			wantErr: &errMissingDevice{},
+		},
+		{
+			name: "transient_parent_handle",
+			config: &Config{
+				Device:       defaultDevice,
+				ParentHandle: 0x80000000,
+			},
+			wantErr: &errInvalidParentHandle{},
+		},
+		{
+			name: "invalid_pcr",
+			config: &Config{
+				Device:       defaultDevice,
+				ParentHandle: defaultParentHandle,
+				PCRs:         []int{7, 24},
+			},
+			wantErr: &errInvalidPCR{},
+		},
+		{
+			name:   "default_config",
+			config: factory.CreateDefaultConfig().(*Config),
+		},
+		{
+			name: "pcrs",
+			config: &Config{
+				Device:       defaultDevice,
+				ParentHandle: defaultParentHandle,
+				PCRs:         []int{0, 7},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := factory.CreateConfigSource(context.Background(), createParams, tt.config)
+			if tt.wantErr != nil {
+				assert.Nil(t, actual)
+				require.IsType(t, tt.wantErr, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tpmconfigsource
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+const (
+	publicBlobExtension  = ".pub"
+	privateBlobExtension = ".priv"
+)
+
+// Private error types to help with testability.
+type (
+	errReadSealedObject struct{ error }
+	errOpenDevice       struct{ error }
+	errUnseal           struct{ error }
+)
+
+// device abstracts the TPM operations so the config source can be tested without a TPM.
+type device interface {
+	// unseal loads the sealed object, given by its public and private parts, under
+	// the parent key and returns its unsealed data.
+	unseal(public, private []byte) ([]byte, error)
+	close() error
+}
+
+// tpmConfigSource implements the configprovider.ConfigSource interface.
+type tpmConfigSource struct {
+	logger    *zap.Logger
+	device    device
+	newDevice func() (device, error)
+}
+
+func newConfigSource(params configprovider.CreateParams, cfg *Config) configprovider.ConfigSource {
+	return &tpmConfigSource{
+		logger: params.Logger,
+		newDevice: func() (device, error) {
+			return openDevice(cfg.Device, cfg.ParentHandle, cfg.PCRs)
+		},
+	}
+}
+
+// Retrieve unseals the object whose public and private parts are on the files
+// "<selector>.pub" and "<selector>.priv", the files created via tpm2_create.
+func (t *tpmConfigSource) Retrieve(_ context.Context, selector string, _ *confmap.Conf, _ confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	public, err := readBlob(selector + publicBlobExtension)
+	if err != nil {
+		return nil, err
+	}
+	private, err := readBlob(selector + privateBlobExtension)
+	if err != nil {
+		return nil, err
+	}
+
+	// The device is only opened when needed and kept open for the remaining retrievals.
+	if t.device == nil {
+		d, err := t.newDevice()
+		if err != nil {
+			return nil, &errOpenDevice{fmt.Errorf("failed to open TPM: %w", err)}
+		}
+		t.device = d
+	}
+
+	value, err := t.device.unseal(public, private)
+	if err != nil {
+		return nil, &errUnseal{fmt.Errorf("failed to unseal %q: %w", selector, err)}
+	}
+
+	return confmap.NewRetrieved(string(value))
+}
+
+func (t *tpmConfigSource) Shutdown(context.Context) error {
+	if t.device == nil {
+		return nil
+	}
+	err := t.device.close()
+	t.device = nil
+	return err
+}
+
+// readBlob reads a sealed object part. The files created by tpm2-tools have the
+// size of the structure as prefix, this prefix is removed if present.
+func readBlob(file string) ([]byte, error) {
+	blob, err := os.ReadFile(file)
+	if err != nil {
+		return nil, &errReadSealedObject{fmt.Errorf("failed to read sealed object file: %w", err)}
+	}
+	if len(blob) > 2 && int(binary.BigEndian.Uint16(blob)) == len(blob)-2 {
+		blob = blob[2:]
+	}
+	return blob, nil
+}
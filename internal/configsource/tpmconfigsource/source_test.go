@@ -0,0 +1,174 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tpmconfigsource
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// mockDevice "unseals" objects whose private part is the sealed value and
+// public part is the name of the object.
+type mockDevice struct {
+	objects map[string]string
+	closed  bool
+}
+
+func (m *mockDevice) unseal(public, private []byte) ([]byte, error) {
+	if value, ok := m.objects[string(public)]; ok && value == string(private) {
+		return private, nil
+	}
+	return nil, errors.New("integrity check failed")
+}
+
+func (m *mockDevice) close() error {
+	m.closed = true
+	return nil
+}
+
+func writeSealedObject(t *testing.T, dir, name, public, private string, sizePrefix bool) string {
+	write := func(file, content string) {
+		blob := []byte(content)
+		if sizePrefix {
+			blob = binary.BigEndian.AppendUint16(nil, uint16(len(content)))
+			blob = append(blob, content...)
+		}
+		require.NoError(t, os.WriteFile(file, blob, 0600))
+	}
+	base := filepath.Join(dir, name)
+	write(base+publicBlobExtension, public)
+	write(base+privateBlobExtension, private)
+	return base
+}
+
+func TestTPMConfigSource_Retrieve(t *testing.T) {
+	dir := t.TempDir()
+	tokenObject := writeSealedObject(t, dir, "token", "token", "s3cr3t", false)
+	prefixedObject := writeSealedObject(t, dir, "prefixed", "prefixed", "other-s3cr3t", true)
+	tamperedObject := writeSealedObject(t, dir, "tampered", "token", "not-the-secret", false)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "public_only"+publicBlobExtension), []byte("token"), 0600))
+
+	tests := []struct {
+		wantErr  error
+		name     string
+		selector string
+		expected string
+	}{
+		{
+			name:     "sealed_object",
+			selector: tokenObject,
+			expected: "s3cr3t",
+		},
+		{
+			name:     "size_prefixed_blobs",
+			selector: prefixedObject,
+			expected: "other-s3cr3t",
+		},
+		{
+			name:     "missing_object",
+			selector: filepath.Join(dir, "missing"),
+			wantErr:  &errReadSealedObject{},
+		},
+		{
+			name:     "missing_private_part",
+			selector: filepath.Join(dir, "public_only"),
+			wantErr:  &errReadSealedObject{},
+		},
+		{
+			name:     "unseal_failure",
+			selector: tamperedObject,
+			wantErr:  &errUnseal{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dev := &mockDevice{objects: map[string]string{
+				"token":    "s3cr3t",
+				"prefixed": "other-s3cr3t",
+			}}
+			source := &tpmConfigSource{
+				logger: zap.NewNop(),
+				newDevice: func() (device, error) {
+					return dev, nil
+				},
+			}
+
+			retrieved, err := source.Retrieve(context.Background(), tt.selector, nil, nil)
+			if tt.wantErr != nil {
+				assert.Nil(t, retrieved)
+				require.IsType(t, tt.wantErr, err)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, retrieved)
+				value, err := retrieved.AsRaw()
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, value)
+			}
+
+			require.NoError(t, source.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestTPMConfigSource_DeviceLifecycle(t *testing.T) {
+	dir := t.TempDir()
+	tokenObject := writeSealedObject(t, dir, "token", "token", "s3cr3t", false)
+
+	opened := 0
+	dev := &mockDevice{objects: map[string]string{"token": "s3cr3t"}}
+	source := &tpmConfigSource{
+		logger: zap.NewNop(),
+		newDevice: func() (device, error) {
+			opened++
+			return dev, nil
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := source.Retrieve(context.Background(), tokenObject, nil, nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, opened)
+
+	require.NoError(t, source.Shutdown(context.Background()))
+	assert.True(t, dev.closed)
+}
+
+func TestTPMConfigSource_OpenDeviceFailure(t *testing.T) {
+	dir := t.TempDir()
+	tokenObject := writeSealedObject(t, dir, "token", "token", "s3cr3t", false)
+
+	source := &tpmConfigSource{
+		logger: zap.NewNop(),
+		newDevice: func() (device, error) {
+			return nil, errors.New("no such file or directory")
+		},
+	}
+
+	retrieved, err := source.Retrieve(context.Background(), tokenObject, nil, nil)
+	assert.Nil(t, retrieved)
+	require.IsType(t, &errOpenDevice{}, err)
+	require.NoError(t, source.Shutdown(context.Background()))
+}
@@ -0,0 +1,83 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vaultconfigsource
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+const defaultAppRoleMount = "approle"
+
+type AppRoleAuthentication struct {
+	// RoleID is the role ID of the AppRole. It is required.
+	RoleID *string `mapstructure:"role_id"`
+	// SecretID is the secret ID used to log in. At most one of SecretID, SecretIDFile,
+	// and SecretIDEnv can be specified, if none is specified the login is done only with
+	// the role ID, this requires the AppRole to be configured with "bind_secret_id=false".
+	SecretID *string `mapstructure:"secret_id"`
+	// SecretIDFile is the path to a file containing the secret ID. The file is read
+	// on every login so the secret ID can be rotated without restarting the collector.
+	SecretIDFile *string `mapstructure:"secret_id_file"`
+	// SecretIDEnv is the name of the environment variable containing the secret ID.
+	SecretIDEnv *string `mapstructure:"secret_id_env"`
+	// Mount is the path where the AppRole auth method is mounted. The default value is "approle".
+	Mount *string `mapstructure:"mount"`
+}
+
+func (a *AppRoleAuthentication) Token(client *api.Client) (string, error) {
+	data := map[string]any{
+		"role_id": *a.RoleID,
+	}
+
+	secretID, err := a.secretID()
+	if err != nil {
+		return "", err
+	}
+	if secretID != "" {
+		data["secret_id"] = secretID
+	}
+
+	mount := defaultAppRoleMount
+	if a.Mount != nil {
+		mount = *a.Mount
+	}
+
+	return loginToken(client, fmt.Sprintf("auth/%s/login", mount), data)
+}
+
+func (a *AppRoleAuthentication) secretID() (string, error) {
+	switch {
+	case a.SecretID != nil:
+		return *a.SecretID, nil
+	case a.SecretIDFile != nil:
+		content, err := os.ReadFile(*a.SecretIDFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read approle secret_id_file: %w", err)
+		}
+		return strings.TrimSpace(string(content)), nil
+	case a.SecretIDEnv != nil:
+		secretID, ok := os.LookupEnv(*a.SecretIDEnv)
+		if !ok {
+			return "", fmt.Errorf("approle secret_id_env %q is not defined", *a.SecretIDEnv)
+		}
+		return secretID, nil
+	}
+	return "", nil
+}
@@ -0,0 +1,157 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vaultconfigsource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+// newAppRoleTestServer returns a fake Vault server issuing a new token on each AppRole
+// login. Only the latest token is accepted to read the "secret/kv" path.
+func newAppRoleTestServer(t *testing.T, roleID, secretID string) (*httptest.Server, *int32) {
+	var logins int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/approle/login":
+			var body map[string]string
+			err := json.NewDecoder(r.Body).Decode(&body)
+			if err != nil || r.Header.Get("X-Vault-Token") != "" || body["role_id"] != roleID || body["secret_id"] != secretID {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			n := atomic.AddInt32(&logins, 1)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"auth": map[string]any{"client_token": tokenForLogin(n)},
+			})
+		case "/v1/secret/kv":
+			if r.Header.Get("X-Vault-Token") != tokenForLogin(atomic.LoadInt32(&logins)) {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"data": map[string]any{"k0": "v0"},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &logins
+}
+
+func tokenForLogin(n int32) string {
+	return fmt.Sprintf("token-%d", n)
+}
+
+func TestVaultAppRoleAuthentication(t *testing.T) {
+	roleID := "my-role"
+	secretID := "my-secret"
+	server, logins := newAppRoleTestServer(t, roleID, secretID)
+
+	secretIDFile := filepath.Join(t.TempDir(), "secret_id")
+	require.NoError(t, os.WriteFile(secretIDFile, []byte(secretID+"\n"), 0600))
+	secretIDEnv := "VAULT_TEST_APPROLE_SECRET_ID"
+	t.Setenv(secretIDEnv, secretID)
+	wrongSecretID := "wrong"
+
+	tests := []struct {
+		appRole *AppRoleAuthentication
+		name    string
+		wantErr bool
+	}{
+		{
+			name:    "secret_id",
+			appRole: &AppRoleAuthentication{RoleID: &roleID, SecretID: &secretID},
+		},
+		{
+			name:    "secret_id_file",
+			appRole: &AppRoleAuthentication{RoleID: &roleID, SecretIDFile: &secretIDFile},
+		},
+		{
+			name:    "secret_id_env",
+			appRole: &AppRoleAuthentication{RoleID: &roleID, SecretIDEnv: &secretIDEnv},
+		},
+		{
+			name:    "wrong_secret_id",
+			appRole: &AppRoleAuthentication{RoleID: &roleID, SecretID: &wrongSecretID},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := api.NewClient(&api.Config{Address: server.URL})
+			require.NoError(t, err)
+			client.SetToken("stale-token")
+
+			token, err := tt.appRole.Token(client)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tokenForLogin(atomic.LoadInt32(logins)), token)
+		})
+	}
+}
+
+func TestVaultAppRoleReloginOnExpiredToken(t *testing.T) {
+	roleID := "my-role"
+	secretID := "my-secret"
+	server, logins := newAppRoleTestServer(t, roleID, secretID)
+
+	config := Config{
+		Endpoint: server.URL,
+		Authentication: &Authentication{
+			AppRoleAuthentication: &AppRoleAuthentication{RoleID: &roleID, SecretID: &secretID},
+		},
+		Path:         "secret/kv",
+		PollInterval: time.Minute,
+	}
+
+	source, err := newConfigSource(configprovider.CreateParams{Logger: zap.NewNop()}, &config)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(logins))
+
+	// Simulate the expiration of the token issued on creation.
+	atomic.AddInt32(logins, 1)
+
+	retrieved, err := source.Retrieve(context.Background(), "k0", nil, nil)
+	require.NoError(t, err)
+	val, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, "v0", val)
+	assert.Equal(t, int32(3), atomic.LoadInt32(logins))
+
+	require.NoError(t, source.Shutdown(context.Background()))
+}
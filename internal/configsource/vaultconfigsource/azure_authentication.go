@@ -0,0 +1,165 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vaultconfigsource
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+const (
+	defaultAzureMount    = "azure"
+	defaultAzureResource = "https://management.azure.com/"
+
+	azureIMDSTimeout = 10 * time.Second
+)
+
+// azureIMDSEndpoint is the address of the Azure Instance Metadata Service, it is a
+// variable so tests can replace it.
+var azureIMDSEndpoint = "http://169.254.169.254"
+
+type AzureAuthentication struct {
+	// Role is the name of the Vault role to request a token against. It is required.
+	Role *string `mapstructure:"role"`
+	// Mount is the path where the Azure auth method is mounted. The default value is "azure".
+	Mount *string `mapstructure:"mount"`
+	// Resource is the resource of the managed identity access token. It must match the
+	// resource configured on the Vault Azure auth method. The default value is
+	// "https://management.azure.com/".
+	Resource *string `mapstructure:"resource"`
+	// ClientID is the client ID of an user-assigned managed identity. If not specified
+	// the system-assigned managed identity is used.
+	ClientID *string `mapstructure:"client_id"`
+	// SubscriptionID, ResourceGroupName, VMName, and VMSSName identify the VM or virtual
+	// machine scale set of the collector. They are obtained from the instance metadata
+	// service if not specified.
+	SubscriptionID    *string `mapstructure:"subscription_id"`
+	ResourceGroupName *string `mapstructure:"resource_group_name"`
+	VMName            *string `mapstructure:"vm_name"`
+	VMSSName          *string `mapstructure:"vmss_name"`
+}
+
+func (az *AzureAuthentication) Token(client *api.Client) (string, error) {
+	httpClient := &http.Client{Timeout: azureIMDSTimeout}
+
+	jwt, err := az.accessToken(httpClient)
+	if err != nil {
+		return "", err
+	}
+
+	data := map[string]any{
+		"role": *az.Role,
+		"jwt":  jwt,
+	}
+
+	// Only query the instance metadata if any of the VM identifiers is missing.
+	var compute azureComputeMetadata
+	if az.SubscriptionID == nil || az.ResourceGroupName == nil || (az.VMName == nil && az.VMSSName == nil) {
+		if compute, err = instanceMetadata(httpClient); err != nil {
+			return "", err
+		}
+	}
+	data["subscription_id"] = valueOrDefault(az.SubscriptionID, compute.SubscriptionID)
+	data["resource_group_name"] = valueOrDefault(az.ResourceGroupName, compute.ResourceGroupName)
+	switch {
+	case az.VMSSName != nil:
+		data["vmss_name"] = *az.VMSSName
+	case az.VMName != nil:
+		data["vm_name"] = *az.VMName
+	case compute.VMScaleSetName != "":
+		data["vmss_name"] = compute.VMScaleSetName
+	default:
+		data["vm_name"] = compute.Name
+	}
+
+	mount := defaultAzureMount
+	if az.Mount != nil {
+		mount = *az.Mount
+	}
+
+	return loginToken(client, fmt.Sprintf("auth/%s/login", mount), data)
+}
+
+// accessToken gets an access token for the managed identity from the instance metadata service.
+func (az *AzureAuthentication) accessToken(httpClient *http.Client) (string, error) {
+	query := url.Values{}
+	query.Set("api-version", "2018-02-01")
+	query.Set("resource", valueOrDefault(az.Resource, defaultAzureResource))
+	if az.ClientID != nil {
+		query.Set("client_id", *az.ClientID)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := getIMDS(httpClient, "/metadata/identity/oauth2/token?"+query.Encode(), &token); err != nil {
+		return "", fmt.Errorf("failed to get azure managed identity token: %w", err)
+	}
+	return token.AccessToken, nil
+}
+
+type azureComputeMetadata struct {
+	Name              string `json:"name"`
+	ResourceGroupName string `json:"resourceGroupName"`
+	SubscriptionID    string `json:"subscriptionId"`
+	VMScaleSetName    string `json:"vmScaleSetName"`
+}
+
+func instanceMetadata(httpClient *http.Client) (azureComputeMetadata, error) {
+	var metadata struct {
+		Compute azureComputeMetadata `json:"compute"`
+	}
+	if err := getIMDS(httpClient, "/metadata/instance?api-version=2021-02-01", &metadata); err != nil {
+		return azureComputeMetadata{}, fmt.Errorf("failed to get azure instance metadata: %w", err)
+	}
+	return metadata.Compute, nil
+}
+
+func getIMDS(httpClient *http.Client, path string, v any) error {
+	req, err := http.NewRequest(http.MethodGet, azureIMDSEndpoint+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Metadata", "true")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("instance metadata service returned status %d: %s", resp.StatusCode, body)
+	}
+	return json.Unmarshal(body, v)
+}
+
+func valueOrDefault(value *string, defaultValue string) string {
+	if value != nil {
+		return *value
+	}
+	return defaultValue
+}
@@ -0,0 +1,133 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vaultconfigsource
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultAzureAuthentication(t *testing.T) {
+	imds := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata") != "true" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/metadata/identity/oauth2/token":
+			token := "system-assigned-jwt"
+			if clientID := r.URL.Query().Get("client_id"); clientID != "" {
+				token = clientID + "-jwt"
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"access_token": token + "@" + r.URL.Query().Get("resource")})
+		case "/metadata/instance":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"compute": map[string]any{
+					"name":              "aks-nodepool1-12345-vmss_0",
+					"resourceGroupName": "MC_rg_aks_eastus",
+					"subscriptionId":    "00000000-0000-0000-0000-000000000001",
+					"vmScaleSetName":    "aks-nodepool1-12345-vmss",
+				},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer imds.Close()
+
+	originalIMDSEndpoint := azureIMDSEndpoint
+	azureIMDSEndpoint = imds.URL
+	defer func() { azureIMDSEndpoint = originalIMDSEndpoint }()
+
+	var loginData map[string]string
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		loginData = nil
+		if err := json.NewDecoder(r.Body).Decode(&loginData); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"auth": map[string]any{"client_token": "token-from-" + r.URL.Path},
+		})
+	}))
+	defer vault.Close()
+
+	role := "collector"
+	clientID := "user-assigned"
+	mount := "azure-prod"
+	resource := "https://vault.example.com"
+	subscriptionID := "00000000-0000-0000-0000-000000000002"
+	resourceGroup := "collectors"
+	vmName := "collector-vm"
+
+	tests := []struct {
+		auth          *AzureAuthentication
+		expectedLogin map[string]string
+		name          string
+		expectedToken string
+	}{
+		{
+			name: "from_instance_metadata",
+			auth: &AzureAuthentication{Role: &role},
+			expectedLogin: map[string]string{
+				"role":                "collector",
+				"jwt":                 "system-assigned-jwt@https://management.azure.com/",
+				"subscription_id":     "00000000-0000-0000-0000-000000000001",
+				"resource_group_name": "MC_rg_aks_eastus",
+				"vmss_name":           "aks-nodepool1-12345-vmss",
+			},
+			expectedToken: "token-from-/v1/auth/azure/login",
+		},
+		{
+			name: "explicit_settings",
+			auth: &AzureAuthentication{
+				Role:              &role,
+				ClientID:          &clientID,
+				Mount:             &mount,
+				Resource:          &resource,
+				SubscriptionID:    &subscriptionID,
+				ResourceGroupName: &resourceGroup,
+				VMName:            &vmName,
+			},
+			expectedLogin: map[string]string{
+				"role":                "collector",
+				"jwt":                 "user-assigned-jwt@https://vault.example.com",
+				"subscription_id":     "00000000-0000-0000-0000-000000000002",
+				"resource_group_name": "collectors",
+				"vm_name":             "collector-vm",
+			},
+			expectedToken: "token-from-/v1/auth/azure-prod/login",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := api.NewClient(&api.Config{Address: vault.URL})
+			require.NoError(t, err)
+
+			token, err := tt.auth.Token(client)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedToken, token)
+			assert.Equal(t, tt.expectedLogin, loginData)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vaultconfigsource
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/vault/api"
+)
+
+const defaultCertMount = "cert"
+
+// CertAuthentication logs in via the TLS certificate auth method, using the client
+// certificate and key from the TLS settings of the config source.
+type CertAuthentication struct {
+	// Name is the name of the certificate role to authenticate against. If not
+	// specified Vault tries all the roles matching the client certificate.
+	Name *string `mapstructure:"name"`
+	// Mount is the path where the TLS certificate auth method is mounted. The default value is "cert".
+	Mount *string `mapstructure:"mount"`
+}
+
+func (c *CertAuthentication) Token(client *api.Client) (string, error) {
+	data := map[string]any{}
+	if c.Name != nil {
+		data["name"] = *c.Name
+	}
+
+	mount := defaultCertMount
+	if c.Mount != nil {
+		mount = *c.Mount
+	}
+
+	return loginToken(client, fmt.Sprintf("auth/%s/login", mount), data)
+}
@@ -0,0 +1,202 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vaultconfigsource
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func newTestCert(t *testing.T, template *x509.Certificate, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	parentCert, parentKey := template, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+func (c *testCert) writePEM(t *testing.T, dir, name string) (certFile, keyFile string) {
+	certFile = filepath.Join(dir, name+".crt")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0600))
+
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	keyFile = filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+func TestVaultCertAuthentication(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+	// The server certificate is only valid for a name that is not the server address, so
+	// the connection only succeeds if the configured server name is used.
+	server := newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "vault.example.com"},
+		DNSNames:    []string{"vault.example.com"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+	client := newTestCert(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "collector"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+
+	caFile, _ := ca.writePEM(t, dir, "ca")
+	clientCertFile, clientKeyFile := client.writePEM(t, dir, "client")
+
+	caPool := x509.NewCertPool()
+	caPool.AddCert(ca.cert)
+
+	vault := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/cert/login":
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["name"] != "collector" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"auth": map[string]any{"client_token": "token-for-" + r.TLS.PeerCertificates[0].Subject.CommonName},
+			})
+		case "/v1/secret/kv":
+			if r.Header.Get("X-Vault-Token") != "token-for-collector" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"data": map[string]any{"k0": "v0"},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	vault.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{server.der}, PrivateKey: server.key}},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    caPool,
+		MinVersion:   tls.VersionTLS12,
+	}
+	vault.StartTLS()
+	defer vault.Close()
+
+	name := "collector"
+	factory := NewFactory()
+	params := configprovider.CreateParams{Logger: zap.NewNop()}
+
+	tests := []struct {
+		tls     *TLSConfig
+		name    string
+		wantErr bool
+	}{
+		{
+			name: "client_cert_and_sni",
+			tls: &TLSConfig{
+				CACert:     caFile,
+				ClientCert: clientCertFile,
+				ClientKey:  clientKeyFile,
+				ServerName: "vault.example.com",
+			},
+		},
+		{
+			name: "wrong_server_name",
+			tls: &TLSConfig{
+				CACert:     caFile,
+				ClientCert: clientCertFile,
+				ClientKey:  clientKeyFile,
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown_ca",
+			tls: &TLSConfig{
+				ClientCert: clientCertFile,
+				ClientKey:  clientKeyFile,
+				ServerName: "vault.example.com",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Endpoint: vault.URL,
+				TLS:      tt.tls,
+				Authentication: &Authentication{
+					CertAuthentication: &CertAuthentication{Name: &name},
+				},
+				Path:         "secret/kv",
+				PollInterval: time.Minute,
+			}
+
+			source, err := factory.CreateConfigSource(context.Background(), params, cfg)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			retrieved, err := source.Retrieve(context.Background(), "k0", nil, nil)
+			require.NoError(t, err)
+			val, err := retrieved.AsRaw()
+			require.NoError(t, err)
+			assert.Equal(t, "v0", val)
+			require.NoError(t, source.Shutdown(context.Background()))
+		})
+	}
+}
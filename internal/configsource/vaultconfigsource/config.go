@@ -29,12 +29,42 @@ type Config struct {
 	// Endpoint is the address of the Vault server, typically it is set via the
 	// VAULT_ADDR environment variable for the Vault CLI.
 	Endpoint string `mapstructure:"endpoint"`
-	// Path is the Vault path where the secret to be retrieved is located.
+	// TLS holds the TLS settings used to connect to the Vault server.
+	TLS *TLSConfig `mapstructure:"tls"`
+	// Path is the Vault path where the secret to be retrieved is located. When
+	// Mode is "transit" or "pki" it is the path where the transit or PKI secrets
+	// engine is mounted.
 	Path string `mapstructure:"path"`
+	// Mode defines how values are retrieved: "secret", the default, reads the
+	// secret at Path and selects values from it; "transit" decrypts ciphertext
+	// given as parameter with the transit key named by the selector; "pki" issues
+	// certificates for the PKI role named by the selector.
+	Mode string `mapstructure:"mode"`
+	// CertsDir is the directory where the certificates issued in "pki" mode, their
+	// private keys, and CA chains are written. It is required in "pki" mode.
+	CertsDir string `mapstructure:"certs_dir"`
 	// PollInterval is the interval in which the config source will check for
 	// changes on the data on the given Vault path. This is only used for
 	// non-dynamic secret stores. Defaults to 1 minute if not specified.
 	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// RenewIncrement is the lease duration requested when renewing the lease of
+	// dynamic secrets. If not specified the lease duration of the secret is requested.
+	RenewIncrement time.Duration `mapstructure:"renew_increment"`
+	// RefreshBeforeExpiry is how long before the lease of a non-renewable dynamic
+	// secret expires that the config source will re-fetch it. It is capped to half
+	// of the lease duration. Defaults to 30 seconds if not specified.
+	RefreshBeforeExpiry time.Duration `mapstructure:"refresh_before_expiry"`
+	// Timeout is the timeout of each request to the Vault server, including retries.
+	// Defaults to 60 seconds if not specified.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// MaxRetries is the maximum number of times a request is retried on connection
+	// errors and server errors (5xx status codes). Set it to 0 to disable retries.
+	// Defaults to 2.
+	MaxRetries *int `mapstructure:"max_retries"`
+	// MinRetryWait and MaxRetryWait are the bounds of the exponential backoff
+	// between retries. They default to 1s and 1.5s respectively.
+	MinRetryWait time.Duration `mapstructure:"min_retry_wait"`
+	MaxRetryWait time.Duration `mapstructure:"max_retry_wait"`
 }
 
 // Authentication holds the authentication configuration for Vault config source objects.
@@ -42,12 +72,49 @@ type Authentication struct {
 	// Token is the token to be used to access the Vault server, typically is set
 	// via the VAULT_TOKEN environment variable for the Vault CLI.
 	Token *string `mapstructure:"token"`
+	// TokenFile is the path to a file containing the token to be used to access the
+	// Vault server, e.g. the sink file written by Vault Agent. The file is watched and
+	// when a new token is written to it the values are retrieved again with the new token.
+	TokenFile *string `mapstructure:"token_file"`
 	// IAMAuthentication holds the authentication options for AWS IAM. The options
 	// are the same as the vault CLI tool, see https://github.com/hashicorp/vault/blob/v1.1.0/builtin/credential/aws/cli.go#L148.
 	IAMAuthentication *IAMAuthentication `mapstructure:"iam"`
 	// GCPAuthentication holds the authentication options for GCP. The options
 	// are the same as the vault CLI tool, see https://github.com/hashicorp/vault-plugin-auth-gcp/blob/e1f6784b379d277038ca0661606aa8d23791e392/plugin/cli.go#L120.
 	GCPAuthentication *GCPAuthentication `mapstructure:"gcp"`
+	// AppRoleAuthentication holds the authentication options for AppRole, see
+	// https://developer.hashicorp.com/vault/docs/auth/approle.
+	AppRoleAuthentication *AppRoleAuthentication `mapstructure:"approle"`
+	// KubernetesAuthentication holds the authentication options for Kubernetes, see
+	// https://developer.hashicorp.com/vault/docs/auth/kubernetes.
+	KubernetesAuthentication *KubernetesAuthentication `mapstructure:"kubernetes"`
+	// AzureAuthentication holds the authentication options for Azure managed identities, see
+	// https://developer.hashicorp.com/vault/docs/auth/azure.
+	AzureAuthentication *AzureAuthentication `mapstructure:"azure"`
+	// CertAuthentication holds the authentication options for TLS certificates, see
+	// https://developer.hashicorp.com/vault/docs/auth/cert. It requires the client
+	// certificate and key to be set on the TLS settings.
+	CertAuthentication *CertAuthentication `mapstructure:"cert"`
+}
+
+// TLSConfig holds the TLS settings used to connect to the Vault server, they are
+// equivalent to the VAULT_CACERT, VAULT_CAPATH, VAULT_CLIENT_CERT, VAULT_CLIENT_KEY,
+// VAULT_TLS_SERVER_NAME, and VAULT_SKIP_VERIFY environment variables of the Vault CLI.
+type TLSConfig struct {
+	// CACert is the path to a PEM-encoded CA certificate file used to verify the server certificate.
+	CACert string `mapstructure:"ca_cert"`
+	// CAPath is the path to a directory of PEM-encoded CA certificate files used to verify
+	// the server certificate.
+	CAPath string `mapstructure:"ca_path"`
+	// ClientCert is the path to the PEM-encoded client certificate.
+	ClientCert string `mapstructure:"client_cert"`
+	// ClientKey is the path to the PEM-encoded private key of the client certificate.
+	ClientKey string `mapstructure:"client_key"`
+	// ServerName is the name used for SNI and to verify the server certificate.
+	ServerName string `mapstructure:"server_name"`
+	// InsecureSkipVerify disables the verification of the server certificate. It
+	// should be used only for testing.
+	InsecureSkipVerify bool `mapstructure:"insecure_skip_verify"`
 }
 
 func (*Config) Validate() error {
@@ -45,19 +45,44 @@ func TestVaultLoadConfig(t *testing.T) {
 	otherToken := "other_token"
 	expectedSettings := map[string]configprovider.Source{
 		"vault": &Config{
-			SourceSettings: configprovider.NewSourceSettings(component.NewID(typeStr)),
-			Endpoint:       "http://localhost:8200",
-			Path:           "secret/kv",
-			PollInterval:   1 * time.Minute,
+			SourceSettings:      configprovider.NewSourceSettings(component.NewID(typeStr)),
+			Endpoint:            "http://localhost:8200",
+			Path:                "secret/kv",
+			Mode:                modeSecret,
+			PollInterval:        1 * time.Minute,
+			RefreshBeforeExpiry: 30 * time.Second,
+			Timeout:             60 * time.Second,
+			MinRetryWait:        1 * time.Second,
+			MaxRetryWait:        1500 * time.Millisecond,
 			Authentication: &Authentication{
 				Token: &devToken,
 			},
 		},
 		"vault/poll_interval": &Config{
-			SourceSettings: configprovider.NewSourceSettings(component.NewIDWithName(typeStr, "poll_interval")),
-			Endpoint:       "https://localhost:8200",
-			Path:           "other/path/kv",
-			PollInterval:   10 * time.Second,
+			SourceSettings:      configprovider.NewSourceSettings(component.NewIDWithName(typeStr, "poll_interval")),
+			Endpoint:            "https://localhost:8200",
+			Path:                "other/path/kv",
+			Mode:                modeSecret,
+			PollInterval:        10 * time.Second,
+			RefreshBeforeExpiry: 30 * time.Second,
+			Timeout:             60 * time.Second,
+			MinRetryWait:        1 * time.Second,
+			MaxRetryWait:        1500 * time.Millisecond,
+			Authentication: &Authentication{
+				Token: &otherToken,
+			},
+		},
+		"vault/pki": &Config{
+			SourceSettings:      configprovider.NewSourceSettings(component.NewIDWithName(typeStr, "pki")),
+			Endpoint:            "https://localhost:8200",
+			Path:                "pki_int",
+			Mode:                modePKI,
+			CertsDir:            "/var/lib/otelcol/certs",
+			PollInterval:        1 * time.Minute,
+			RefreshBeforeExpiry: 30 * time.Second,
+			Timeout:             60 * time.Second,
+			MinRetryWait:        1 * time.Second,
+			MaxRetryWait:        1500 * time.Millisecond,
 			Authentication: &Authentication{
 				Token: &otherToken,
 			},
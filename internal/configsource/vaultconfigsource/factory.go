@@ -30,19 +30,37 @@ const (
 	// The "type" of Vault config sources in configuration.
 	typeStr = "vault"
 
-	defaultPollInterval = 1 * time.Minute
+	modeSecret  = "secret"
+	modeTransit = "transit"
+	modePKI     = "pki"
+
+	defaultPollInterval        = 1 * time.Minute
+	defaultRefreshBeforeExpiry = 30 * time.Second
+	defaultTimeout             = 60 * time.Second
+	defaultMaxRetries          = 2
+	defaultMinRetryWait        = 1 * time.Second
+	defaultMaxRetryWait        = 1500 * time.Millisecond
 )
 
 // Private error types to help with testability.
 type (
-	errEmptyAuth               struct{ error }
-	errEmptyToken              struct{ error }
-	errInvalidEndpoint         struct{ error }
-	errMissingAuthentication   struct{ error }
-	errMissingEndpoint         struct{ error }
-	errMissingPath             struct{ error }
-	errMultipleAuthMethods     struct{ error }
-	errNonPositivePollInterval struct{ error }
+	errEmptyAuth                struct{ error }
+	errEmptyToken               struct{ error }
+	errIncompleteAWSCredentials struct{ error }
+	errInvalidEndpoint          struct{ error }
+	errInvalidMode              struct{ error }
+	errInvalidRetryPolicy       struct{ error }
+	errMissingAuthentication    struct{ error }
+	errMissingCertsDir          struct{ error }
+	errMissingClientCert        struct{ error }
+	errMissingEndpoint          struct{ error }
+	errMissingPath              struct{ error }
+	errMissingRole              struct{ error }
+	errMissingRoleID            struct{ error }
+	errMultipleAuthMethods      struct{ error }
+	errMultipleSecretIDSources  struct{ error }
+	errNegativeLeaseDuration    struct{ error }
+	errNonPositivePollInterval  struct{ error }
 )
 
 type vaultFactory struct{}
@@ -51,10 +69,20 @@ func (v *vaultFactory) Type() component.Type {
 	return typeStr
 }
 
+// Sensitive reports that the Vault secrets are sensitive, so they are redacted.
+func (v *vaultFactory) Sensitive() bool {
+	return true
+}
+
 func (v *vaultFactory) CreateDefaultConfig() configprovider.Source {
 	return &Config{
-		SourceSettings: configprovider.NewSourceSettings(component.NewID(typeStr)),
-		PollInterval:   defaultPollInterval,
+		SourceSettings:      configprovider.NewSourceSettings(component.NewID(typeStr)),
+		Mode:                modeSecret,
+		PollInterval:        defaultPollInterval,
+		RefreshBeforeExpiry: defaultRefreshBeforeExpiry,
+		Timeout:             defaultTimeout,
+		MinRetryWait:        defaultMinRetryWait,
+		MaxRetryWait:        defaultMaxRetryWait,
 	}
 }
 
@@ -73,14 +101,36 @@ func (v *vaultFactory) CreateConfigSource(_ context.Context, params configprovid
 		return nil, &errMissingPath{errors.New("cannot connect to vault with an empty path")}
 	}
 
+	switch vaultCfg.Mode {
+	case "", modeSecret, modeTransit:
+	case modePKI:
+		if vaultCfg.CertsDir == "" {
+			return nil, &errMissingCertsDir{errors.New("certs_dir cannot be empty in pki mode")}
+		}
+	default:
+		return nil, &errInvalidMode{fmt.Errorf("invalid mode %q, it must be one of %q, %q, or %q", vaultCfg.Mode, modeSecret, modeTransit, modePKI)}
+	}
+
 	if err := validateAuth(vaultCfg.Authentication); err != nil {
 		return nil, err
 	}
 
+	if err := validateTLS(vaultCfg.TLS, vaultCfg.Authentication.CertAuthentication != nil); err != nil {
+		return nil, err
+	}
+
 	if vaultCfg.PollInterval <= 0 {
 		return nil, &errNonPositivePollInterval{errors.New("poll_interval must to be positive")}
 	}
 
+	if err := validateRetryPolicy(vaultCfg); err != nil {
+		return nil, err
+	}
+
+	if vaultCfg.RenewIncrement < 0 || vaultCfg.RefreshBeforeExpiry < 0 {
+		return nil, &errNegativeLeaseDuration{errors.New("renew_increment and refresh_before_expiry cannot be negative")}
+	}
+
 	return newConfigSource(params, vaultCfg)
 }
 
@@ -102,14 +152,49 @@ func validateAuth(auth *Authentication) error {
 		}
 	}
 
+	if auth.TokenFile != nil {
+		countMethods++
+		if *auth.TokenFile == "" {
+			return &errEmptyToken{errors.New("token_file cannot be empty")}
+		}
+	}
+
 	if auth.IAMAuthentication != nil {
 		countMethods++
+		if (auth.IAMAuthentication.AWSAccessKeyID == nil) != (auth.IAMAuthentication.AWSSecretAccessKey == nil) {
+			return &errIncompleteAWSCredentials{errors.New("iam aws_access_key_id and aws_secret_access_key must be specified together")}
+		}
 	}
 
 	if auth.GCPAuthentication != nil {
 		countMethods++
 	}
 
+	if auth.AppRoleAuthentication != nil {
+		countMethods++
+		if err := validateAppRole(auth.AppRoleAuthentication); err != nil {
+			return err
+		}
+	}
+
+	if auth.KubernetesAuthentication != nil {
+		countMethods++
+		if role := auth.KubernetesAuthentication.Role; role == nil || *role == "" {
+			return &errMissingRole{errors.New("kubernetes role cannot be empty")}
+		}
+	}
+
+	if auth.AzureAuthentication != nil {
+		countMethods++
+		if role := auth.AzureAuthentication.Role; role == nil || *role == "" {
+			return &errMissingRole{errors.New("azure role cannot be empty")}
+		}
+	}
+
+	if auth.CertAuthentication != nil {
+		countMethods++
+	}
+
 	if countMethods == 0 {
 		return &errEmptyAuth{errors.New("auth cannot be empty, exactly one method must be used")}
 	}
@@ -120,3 +205,49 @@ func validateAuth(auth *Authentication) error {
 
 	return nil
 }
+
+func validateAppRole(appRole *AppRoleAuthentication) error {
+	if appRole.RoleID == nil || *appRole.RoleID == "" {
+		return &errMissingRoleID{errors.New("approle role_id cannot be empty")}
+	}
+
+	countSecretIDSources := 0
+	for _, source := range []*string{appRole.SecretID, appRole.SecretIDFile, appRole.SecretIDEnv} {
+		if source != nil {
+			countSecretIDSources++
+		}
+	}
+	if countSecretIDSources > 1 {
+		return &errMultipleSecretIDSources{errors.New("only one of approle secret_id, secret_id_file, or secret_id_env can be used")}
+	}
+
+	return nil
+}
+
+func validateTLS(tls *TLSConfig, certAuth bool) error {
+	hasCert := tls != nil && tls.ClientCert != ""
+	hasKey := tls != nil && tls.ClientKey != ""
+	if hasCert != hasKey {
+		return &errMissingClientCert{errors.New("tls client_cert and client_key must be specified together")}
+	}
+	if certAuth && !hasCert {
+		return &errMissingClientCert{errors.New("cert auth requires tls client_cert and client_key")}
+	}
+	return nil
+}
+
+func validateRetryPolicy(cfg *Config) error {
+	if cfg.Timeout < 0 {
+		return &errInvalidRetryPolicy{errors.New("timeout cannot be negative")}
+	}
+	if cfg.MaxRetries != nil && *cfg.MaxRetries < 0 {
+		return &errInvalidRetryPolicy{errors.New("max_retries cannot be negative")}
+	}
+	if cfg.MinRetryWait < 0 || cfg.MaxRetryWait < 0 {
+		return &errInvalidRetryPolicy{errors.New("min_retry_wait and max_retry_wait cannot be negative")}
+	}
+	if cfg.MaxRetryWait != 0 && cfg.MinRetryWait > cfg.MaxRetryWait {
+		return &errInvalidRetryPolicy{errors.New("min_retry_wait cannot be greater than max_retry_wait")}
+	}
+	return nil
+}
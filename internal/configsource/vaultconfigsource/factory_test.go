@@ -52,6 +52,24 @@ func TestVaultFactory_CreateConfigSource(t *testing.T) {
 			},
 			wantErr: &errInvalidEndpoint{},
 		},
+		{
+			name: "invalid_mode",
+			config: &Config{
+				Endpoint: "http://localhost:8200",
+				Path:     "some/path",
+				Mode:     "database",
+			},
+			wantErr: &errInvalidMode{},
+		},
+		{
+			name: "pki_missing_certs_dir",
+			config: &Config{
+				Endpoint: "http://localhost:8200",
+				Path:     "pki",
+				Mode:     modePKI,
+			},
+			wantErr: &errMissingCertsDir{},
+		},
 		{
 			name: "missing_auth",
 			config: &Config{
@@ -93,6 +111,101 @@ func TestVaultFactory_CreateConfigSource(t *testing.T) {
 			},
 			wantErr: &errEmptyToken{},
 		},
+		{
+			name: "approle_missing_role_id",
+			config: &Config{
+				Endpoint: "http://localhost:8200",
+				Path:     "some/path",
+				Authentication: &Authentication{
+					AppRoleAuthentication: &AppRoleAuthentication{},
+				},
+			},
+			wantErr: &errMissingRoleID{},
+		},
+		{
+			name: "approle_multiple_secret_id_sources",
+			config: &Config{
+				Endpoint: "http://localhost:8200",
+				Path:     "some/path",
+				Authentication: &Authentication{
+					AppRoleAuthentication: &AppRoleAuthentication{
+						RoleID:       &tokenStr,
+						SecretID:     &tokenStr,
+						SecretIDFile: &tokenStr,
+					},
+				},
+			},
+			wantErr: &errMultipleSecretIDSources{},
+		},
+		{
+			name: "kubernetes_missing_role",
+			config: &Config{
+				Endpoint: "http://localhost:8200",
+				Path:     "some/path",
+				Authentication: &Authentication{
+					KubernetesAuthentication: &KubernetesAuthentication{},
+				},
+			},
+			wantErr: &errMissingRole{},
+		},
+		{
+			name: "iam_incomplete_credentials",
+			config: &Config{
+				Endpoint: "http://localhost:8200",
+				Path:     "some/path",
+				Authentication: &Authentication{
+					IAMAuthentication: &IAMAuthentication{
+						AWSAccessKeyID: &tokenStr,
+					},
+				},
+			},
+			wantErr: &errIncompleteAWSCredentials{},
+		},
+		{
+			name: "azure_missing_role",
+			config: &Config{
+				Endpoint: "http://localhost:8200",
+				Path:     "some/path",
+				Authentication: &Authentication{
+					AzureAuthentication: &AzureAuthentication{},
+				},
+			},
+			wantErr: &errMissingRole{},
+		},
+		{
+			name: "cert_missing_client_cert",
+			config: &Config{
+				Endpoint: "https://localhost:8200",
+				Path:     "some/path",
+				Authentication: &Authentication{
+					CertAuthentication: &CertAuthentication{},
+				},
+			},
+			wantErr: &errMissingClientCert{},
+		},
+		{
+			name: "tls_client_key_without_cert",
+			config: &Config{
+				Endpoint: "https://localhost:8200",
+				Path:     "some/path",
+				TLS:      &TLSConfig{ClientKey: "client.key"},
+				Authentication: &Authentication{
+					Token: &tokenStr,
+				},
+			},
+			wantErr: &errMissingClientCert{},
+		},
+		{
+			name: "empty_token_file",
+			config: &Config{
+				Endpoint: "http://localhost:8200",
+				Path:     "some/path",
+				Authentication: &Authentication{
+					TokenFile: &emptyStr,
+				},
+			},
+			wantErr: &errEmptyToken{},
+		},
 		{
 			name: "missing_path",
 			config: &Config{
@@ -114,6 +227,33 @@ func TestVaultFactory_CreateConfigSource(t *testing.T) {
 			},
 			wantErr: &errNonPositivePollInterval{},
 		},
+		{
+			name: "negative_refresh_before_expiry",
+			config: &Config{
+				Endpoint: "http://localhost:8200",
+				Authentication: &Authentication{
+					Token: &tokenStr,
+				},
+				Path:                "some/path",
+				PollInterval:        2 * time.Minute,
+				RefreshBeforeExpiry: -time.Second,
+			},
+			wantErr: &errNegativeLeaseDuration{},
+		},
+		{
+			name: "invalid_retry_wait",
+			config: &Config{
+				Endpoint: "http://localhost:8200",
+				Authentication: &Authentication{
+					Token: &tokenStr,
+				},
+				Path:         "some/path",
+				PollInterval: 2 * time.Minute,
+				MinRetryWait: 2 * time.Second,
+				MaxRetryWait: time.Second,
+			},
+			wantErr: &errInvalidRetryPolicy{},
+		},
 		{
 			name: "success",
 			config: &Config{
@@ -22,6 +22,10 @@ import (
 
 // IAMAuthentication holds the authentication options for AWS IAM. The options
 // are the same as the vault CLI tool, see https://github.com/hashicorp/vault/blob/v1.1.0/builtin/credential/aws/cli.go#L148.
+// IAMAuthentication logs in via the AWS IAM auth method, signing a STS GetCallerIdentity
+// request. If no static credentials are given the request is signed with the credentials
+// from the default AWS credential chain: environment variables, shared credentials file,
+// web identity (e.g. IRSA on EKS), and the ECS task or EC2 instance role.
 type IAMAuthentication struct {
 	// AWSAccessKeyID is the AWS access key ID.
 	AWSAccessKeyID *string `mapstructure:"aws_access_key_id"`
@@ -35,6 +39,9 @@ type IAMAuthentication struct {
 	Mount *string `mapstructure:"mount"`
 	// Role is the name of the Vault role to request a token against.
 	Role *string `mapstructure:"role"`
+	// Region is the region of the STS endpoint used to sign the request. The default
+	// value is "us-east-1", i.e. the global STS endpoint.
+	Region *string `mapstructure:"region"`
 }
 
 func (iam *IAMAuthentication) Token(client *api.Client) (string, error) {
@@ -59,6 +66,9 @@ func (iam *IAMAuthentication) Token(client *api.Client) (string, error) {
 	if iam.HeaderValue != nil {
 		data["header_value"] = *iam.HeaderValue
 	}
+	if iam.Region != nil {
+		data["region"] = *iam.Region
+	}
 
 	h := aws.CLIHandler{}
 	secret, err := h.Auth(client, data)
@@ -0,0 +1,61 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vaultconfigsource
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+const (
+	defaultKubernetesMount     = "kubernetes"
+	defaultKubernetesTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token" // #nosec G101
+)
+
+type KubernetesAuthentication struct {
+	// Role is the name of the Vault role bound to the service account of the collector. It is required.
+	Role *string `mapstructure:"role"`
+	// Mount is the path where the Kubernetes auth method is mounted. The default value is "kubernetes".
+	Mount *string `mapstructure:"mount"`
+	// TokenFile is the path to the service account token used as JWT to log in. The file is
+	// read on every login so projected tokens rotated by the kubelet are picked up. The default
+	// value is "/var/run/secrets/kubernetes.io/serviceaccount/token".
+	TokenFile *string `mapstructure:"token_file"`
+}
+
+func (k *KubernetesAuthentication) Token(client *api.Client) (string, error) {
+	tokenFile := defaultKubernetesTokenFile
+	if k.TokenFile != nil {
+		tokenFile = *k.TokenFile
+	}
+	jwt, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read kubernetes service account token: %w", err)
+	}
+
+	mount := defaultKubernetesMount
+	if k.Mount != nil {
+		mount = *k.Mount
+	}
+
+	return loginToken(client, fmt.Sprintf("auth/%s/login", mount), map[string]any{
+		"role": *k.Role,
+		"jwt":  strings.TrimSpace(string(jwt)),
+	})
+}
@@ -0,0 +1,95 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vaultconfigsource
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultKubernetesAuthentication(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil || body["role"] != "collector" || body["jwt"] != "service-account-jwt" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// The mount path is part of the login path.
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"auth": map[string]any{"client_token": "token-from-" + r.URL.Path},
+		})
+	}))
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("service-account-jwt\n"), 0600))
+	missingTokenFile := filepath.Join(t.TempDir(), "missing")
+	role := "collector"
+	otherRole := "other"
+	mount := "k8s-cluster-1"
+
+	tests := []struct {
+		auth     *KubernetesAuthentication
+		name     string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "default_mount",
+			auth:     &KubernetesAuthentication{Role: &role, TokenFile: &tokenFile},
+			expected: "token-from-/v1/auth/kubernetes/login",
+		},
+		{
+			name:     "custom_mount",
+			auth:     &KubernetesAuthentication{Role: &role, TokenFile: &tokenFile, Mount: &mount},
+			expected: "token-from-/v1/auth/k8s-cluster-1/login",
+		},
+		{
+			name:    "missing_token_file",
+			auth:    &KubernetesAuthentication{Role: &role, TokenFile: &missingTokenFile},
+			wantErr: true,
+		},
+		{
+			name:    "wrong_role",
+			auth:    &KubernetesAuthentication{Role: &otherRole, TokenFile: &tokenFile},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := api.NewClient(&api.Config{Address: server.URL})
+			require.NoError(t, err)
+
+			token, err := tt.auth.Token(client)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, token)
+		})
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vaultconfigsource
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+// Private error types to help with testability.
+type (
+	errPKIIssue struct{ error }
+)
+
+// Values of the file param selecting a single file of an issued certificate.
+const (
+	pkiFileCert = "cert"
+	pkiFileKey  = "key"
+	pkiFileCA   = "ca"
+)
+
+// pkiParams holds the parameters supported by Retrieve in pki mode.
+type pkiParams struct {
+	// CommonName is the common name of the certificate to be issued. It is required.
+	CommonName string `mapstructure:"common_name"`
+	// AltNames is a comma separated list of DNS and email subject alternative names.
+	AltNames string `mapstructure:"alt_names"`
+	// IPSANs is a comma separated list of IP subject alternative names.
+	IPSANs string `mapstructure:"ip_sans"`
+	// TTL is the requested time to live of the certificate, e.g. "24h". If not
+	// specified the TTL of the role is used.
+	TTL string `mapstructure:"ttl"`
+	// File selects a single file of the certificate, "cert", "key", or "ca". If not
+	// specified a map with the paths of all the files is returned.
+	File string `mapstructure:"file"`
+}
+
+// issuedCert holds the files of a certificate issued by the PKI secrets engine.
+type issuedCert struct {
+	files    map[string]string
+	notAfter time.Time
+	watched  bool
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// issue requests a certificate for the PKI role named by the selector and writes the
+// certificate, its private key, and the CA chain to files under the certs directory.
+// The certificates are issued once per role and params, so the files referenced by
+// a configuration always match. If a watcher is given it is called shortly before the
+// certificate expires, so the configuration is resolved again with a new certificate.
+func (v *vaultConfigSource) issue(ctx context.Context, role string, paramsConfigMap *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	var params pkiParams
+	if paramsConfigMap != nil {
+		if err := paramsConfigMap.Unmarshal(&params, confmap.WithErrorUnused()); err != nil {
+			return nil, &errInvalidParams{fmt.Errorf("failed to unmarshall retrieve params: %w", err)}
+		}
+	}
+	if params.CommonName == "" {
+		return nil, &errInvalidParams{fmt.Errorf("pki role %q: common_name param cannot be empty", role)}
+	}
+	switch params.File {
+	case "", pkiFileCert, pkiFileKey, pkiFileCA:
+	default:
+		return nil, &errInvalidParams{fmt.Errorf("invalid file %q, it must be one of %q, %q, or %q", params.File, pkiFileCert, pkiFileKey, pkiFileCA)}
+	}
+
+	file := params.File
+	params.File = ""
+	certKey := fmt.Sprintf("%s|%+v", role, params)
+	cert, ok := v.certs[certKey]
+	if !ok {
+		var err error
+		if cert, err = v.issueCert(ctx, role, params); err != nil {
+			return nil, err
+		}
+		v.certs[certKey] = cert
+	}
+
+	var closeFunc confmap.CloseFunc
+	if watcher != nil && !cert.watched {
+		cert.watched = true
+		closeFunc = v.watchCertExpiry(role, cert.notAfter, watcher)
+	}
+
+	if file != "" {
+		return confmap.NewRetrieved(cert.files[file+"_file"], confmap.WithRetrievedClose(closeFunc))
+	}
+	files := make(map[string]any, len(cert.files))
+	for k, path := range cert.files {
+		files[k] = path
+	}
+	return confmap.NewRetrieved(files, confmap.WithRetrievedClose(closeFunc))
+}
+
+func (v *vaultConfigSource) issueCert(ctx context.Context, role string, params pkiParams) (*issuedCert, error) {
+	data := map[string]any{
+		"common_name": params.CommonName,
+	}
+	if params.AltNames != "" {
+		data["alt_names"] = params.AltNames
+	}
+	if params.IPSANs != "" {
+		data["ip_sans"] = params.IPSANs
+	}
+	if params.TTL != "" {
+		data["ttl"] = params.TTL
+	}
+
+	path := fmt.Sprintf("%s/issue/%s", strings.TrimSuffix(v.path, "/"), role)
+	secret, err := v.withRelogin(path, func() (*api.Secret, error) {
+		return v.client.Logical().WriteWithContext(ctx, path, data)
+	})
+	if err != nil {
+		return nil, &errPKIIssue{fmt.Errorf("failed to issue certificate for pki role %q: %w", role, err)}
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, &errPKIIssue{fmt.Errorf("no data returned by %q", path)}
+	}
+
+	certPEM, _ := secret.Data["certificate"].(string)
+	keyPEM, _ := secret.Data["private_key"].(string)
+	if certPEM == "" || keyPEM == "" {
+		return nil, &errPKIIssue{fmt.Errorf("no certificate or private key returned by %q", path)}
+	}
+	caPEM := caChainPEM(secret.Data)
+
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil {
+		return nil, &errPKIIssue{fmt.Errorf("invalid certificate returned by %q", path)}
+	}
+	x509Cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, &errPKIIssue{fmt.Errorf("invalid certificate returned by %q: %w", path, err)}
+	}
+
+	baseName := unsafeFileNameChars.ReplaceAllString(role+"-"+params.CommonName, "_")
+	files := map[string]string{
+		"cert_file": filepath.Join(v.certsDir, baseName+".crt"),
+		"key_file":  filepath.Join(v.certsDir, baseName+".key"),
+		"ca_file":   filepath.Join(v.certsDir, baseName+"-ca.crt"),
+	}
+	contents := map[string]string{
+		"cert_file": certPEM,
+		"key_file":  keyPEM,
+		"ca_file":   caPEM,
+	}
+	if err = os.MkdirAll(v.certsDir, 0o700); err != nil {
+		return nil, &errPKIIssue{fmt.Errorf("failed to create certs directory: %w", err)}
+	}
+	for k, file := range files {
+		if err = writeFileAtomically(file, []byte(contents[k]+"\n")); err != nil {
+			return nil, &errPKIIssue{fmt.Errorf("failed to write %q: %w", file, err)}
+		}
+	}
+
+	v.logger.Debug("vault pki certificate issued",
+		zap.String("role", role),
+		zap.String("serial_number", fmt.Sprint(secret.Data["serial_number"])),
+		zap.Time("not_after", x509Cert.NotAfter))
+	return &issuedCert{files: files, notAfter: x509Cert.NotAfter}, nil
+}
+
+// caChainPEM returns the CA chain of an issued certificate, falling back to the issuing
+// CA for PKI engines not returning the chain.
+func caChainPEM(data map[string]any) string {
+	if chain, ok := data["ca_chain"].([]any); ok && len(chain) > 0 {
+		certs := make([]string, 0, len(chain))
+		for _, cert := range chain {
+			if s, ok := cert.(string); ok {
+				certs = append(certs, s)
+			}
+		}
+		return strings.Join(certs, "\n")
+	}
+	issuingCA, _ := data["issuing_ca"].(string)
+	return issuingCA
+}
+
+// writeFileAtomically writes the file via a temporary file so components reading it
+// never see partial content.
+func writeFileAtomically(file string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// watchCertExpiry calls the watcher shortly before the certificate expires, the same way
+// non-renewable leases are refreshed.
+func (v *vaultConfigSource) watchCertExpiry(role string, notAfter time.Time, watcher confmap.WatcherFunc) confmap.CloseFunc {
+	doneCh := make(chan struct{})
+	updateWait := leaseRefreshWait(time.Until(notAfter), v.refreshBeforeExpiry)
+	configprovider.WatchAfter(updateWait, doneCh, func() {
+		v.logger.Debug("vault pki certificate about to expire", zap.String("role", role))
+		watcher(&confmap.ChangeEvent{Error: nil})
+	})
+
+	return func(context.Context) error {
+		close(doneCh)
+		return nil
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vaultconfigsource
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestVaultPKIIssue(t *testing.T) {
+	var issued atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		role := strings.TrimPrefix(r.URL.Path, "/v1/pki-int/issue/")
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || r.Method != http.MethodPut || role != "collector" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":["unknown role"]}`))
+			return
+		}
+		issued.Add(1)
+		certPEM, keyPEM := newTestCert(t, body["common_name"], 2*time.Second)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{
+				"certificate":   certPEM,
+				"private_key":   keyPEM,
+				"issuing_ca":    certPEM,
+				"ca_chain":      []string{certPEM},
+				"serial_number": "01",
+			},
+		})
+	}))
+	defer server.Close()
+
+	certsDir := filepath.Join(t.TempDir(), "certs")
+	config := Config{
+		Endpoint: server.URL,
+		Authentication: &Authentication{
+			Token: &tokenStr,
+		},
+		Path:                "pki-int",
+		Mode:                modePKI,
+		CertsDir:            certsDir,
+		PollInterval:        time.Minute,
+		RefreshBeforeExpiry: time.Second,
+	}
+
+	source, err := newConfigSource(configprovider.CreateParams{Logger: zap.NewNop()}, &config)
+	require.NoError(t, err)
+
+	watchCh := make(chan *confmap.ChangeEvent, 1)
+	params := confmap.NewFromStringMap(map[string]any{"common_name": "collector.example.com"})
+	retrieved, err := source.Retrieve(context.Background(), "collector", params, func(event *confmap.ChangeEvent) {
+		watchCh <- event
+	})
+	require.NoError(t, err)
+	val, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	files := map[string]any{
+		"cert_file": filepath.Join(certsDir, "collector-collector.example.com.crt"),
+		"key_file":  filepath.Join(certsDir, "collector-collector.example.com.key"),
+		"ca_file":   filepath.Join(certsDir, "collector-collector.example.com-ca.crt"),
+	}
+	assert.Equal(t, files, val)
+	for _, file := range files {
+		info, statErr := os.Stat(file.(string))
+		require.NoError(t, statErr)
+		assert.NotZero(t, info.Size())
+	}
+
+	// Retrieving a single file of the same certificate doesn't issue a new one.
+	keyParams := confmap.NewFromStringMap(map[string]any{"common_name": "collector.example.com", "file": "key"})
+	keyRetrieved, err := source.Retrieve(context.Background(), "collector", keyParams, nil)
+	require.NoError(t, err)
+	val, err = keyRetrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, files["key_file"], val)
+	assert.Equal(t, int32(1), issued.Load())
+
+	// The watcher is called before the certificate expires.
+	select {
+	case event := <-watchCh:
+		assert.NoError(t, event.Error)
+	case <-time.After(5 * time.Second):
+		t.Fatal("watcher not called before the certificate expired")
+	}
+	require.NoError(t, retrieved.Close(context.Background()))
+
+	for _, tt := range []struct {
+		params  map[string]any
+		wantErr error
+		name    string
+		role    string
+	}{
+		{
+			name:    "missing_common_name",
+			role:    "collector",
+			wantErr: &errInvalidParams{},
+		},
+		{
+			name:    "invalid_file",
+			role:    "collector",
+			params:  map[string]any{"common_name": "collector.example.com", "file": "pem"},
+			wantErr: &errInvalidParams{},
+		},
+		{
+			name:    "unknown_role",
+			role:    "unknown",
+			params:  map[string]any{"common_name": "collector.example.com"},
+			wantErr: &errPKIIssue{},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var params *confmap.Conf
+			if tt.params != nil {
+				params = confmap.NewFromStringMap(tt.params)
+			}
+			retrieved, err := source.Retrieve(context.Background(), tt.role, params, nil)
+			assert.Nil(t, retrieved)
+			require.IsType(t, tt.wantErr, err)
+		})
+	}
+
+	require.NoError(t, source.Shutdown(context.Background()))
+}
+
+// newTestCert returns a self-signed PEM encoded certificate, and its private key, valid for the given duration.
+func newTestCert(t *testing.T, commonName string, validFor time.Duration) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(validFor),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return string(certPEM), string(keyPEM)
+}
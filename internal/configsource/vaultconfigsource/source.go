@@ -20,6 +20,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -34,68 +36,176 @@ var errInvalidPollInterval = errors.New("poll interval must be greater than zero
 
 // Error wrapper types to help with testability
 type (
-	errClientRead    struct{ error }
-	errNilSecret     struct{ error }
-	errNilSecretData struct{ error }
-	errBadSelector   struct{ error }
+	errClientRead       struct{ error }
+	errInvalidTLSConfig struct{ error }
+	errNilSecret        struct{ error }
+	errNilSecretData    struct{ error }
+	errBadSelector      struct{ error }
+	errInvalidParams    struct{ error }
 )
 
+// Is makes the missing secrets match configprovider.ErrNotFound so the default values of the
+// references are used for them.
+func (e *errNilSecret) Is(target error) bool {
+	return target == configprovider.ErrNotFound
+}
+
+// Is makes the secrets without data match configprovider.ErrNotFound.
+func (e *errNilSecretData) Is(target error) bool {
+	return target == configprovider.ErrNotFound
+}
+
+// Is makes the missing keys match configprovider.ErrNotFound.
+func (e *errBadSelector) Is(target error) bool {
+	return target == configprovider.ErrNotFound
+}
+
+// retrieveParams holds the parameters supported by Retrieve.
+type retrieveParams struct {
+	// Version pins the version of a KV v2 secret to be retrieved. If not specified
+	// the latest version is retrieved.
+	Version int `mapstructure:"version"`
+}
+
 // vaultConfigSource implements the configprovider.Session interface.
 type vaultConfigSource struct {
 	logger *zap.Logger
 	client *api.Client
 	secret *api.Secret
-
-	path string
-
-	pollInterval time.Duration
+	// versions holds the KV v2 secrets retrieved with a pinned version.
+	versions map[int]*api.Secret
+	// certs holds the certificates issued in pki mode by role and params.
+	certs map[string]*issuedCert
+	// login obtains a new token for the client, it is nil if the authentication
+	// uses a static token.
+	login func() error
+
+	path      string
+	mode      string
+	tokenFile string
+	certsDir  string
+
+	pollInterval        time.Duration
+	renewIncrement      time.Duration
+	refreshBeforeExpiry time.Duration
 }
 
 func newConfigSource(params configprovider.CreateParams, cfg *Config) (configprovider.ConfigSource, error) {
 	// Client doesn't connect on creation and can't be closed. Keeping the same instance
 	// for all sessions is ok.
-	client, err := api.NewClient(&api.Config{
-		Address: cfg.Endpoint,
-	})
-	if err != nil {
-		return nil, err
+	maxRetries := defaultMaxRetries
+	if cfg.MaxRetries != nil {
+		maxRetries = *cfg.MaxRetries
+	}
+	apiCfg := &api.Config{
+		Address:      cfg.Endpoint,
+		Timeout:      valueOrDefaultDuration(cfg.Timeout, defaultTimeout),
+		MaxRetries:   maxRetries,
+		MinRetryWait: valueOrDefaultDuration(cfg.MinRetryWait, defaultMinRetryWait),
+		MaxRetryWait: valueOrDefaultDuration(cfg.MaxRetryWait, defaultMaxRetryWait),
+	}
+	if cfg.TLS != nil {
+		apiCfg.HttpClient = &http.Client{
+			Transport: http.DefaultTransport.(*http.Transport).Clone(),
+		}
+		if err := apiCfg.ConfigureTLS(&api.TLSConfig{
+			CACert:        cfg.TLS.CACert,
+			CAPath:        cfg.TLS.CAPath,
+			ClientCert:    cfg.TLS.ClientCert,
+			ClientKey:     cfg.TLS.ClientKey,
+			TLSServerName: cfg.TLS.ServerName,
+			Insecure:      cfg.TLS.InsecureSkipVerify,
+		}); err != nil {
+			return nil, &errInvalidTLSConfig{fmt.Errorf("failed to configure TLS: %w", err)}
+		}
 	}
 
-	token, err := getClientToken(client, *cfg.Authentication)
+	client, err := api.NewClient(apiCfg)
 	if err != nil {
 		return nil, err
 	}
 
-	client.SetToken(token)
+	login := func() error {
+		token, err := getClientToken(client, *cfg.Authentication)
+		if err != nil {
+			return err
+		}
+		client.SetToken(token)
+		return nil
+	}
+
+	if err = login(); err != nil {
+		return nil, err
+	}
 
 	if cfg.PollInterval <= 0 {
 		return nil, errInvalidPollInterval
 	}
 
-	return &vaultConfigSource{
-		logger:       params.Logger,
-		client:       client,
-		path:         cfg.Path,
-		pollInterval: cfg.PollInterval,
-	}, nil
+	source := &vaultConfigSource{
+		logger:              params.Logger,
+		client:              client,
+		path:                cfg.Path,
+		mode:                cfg.Mode,
+		pollInterval:        cfg.PollInterval,
+		renewIncrement:      cfg.RenewIncrement,
+		refreshBeforeExpiry: cfg.RefreshBeforeExpiry,
+		versions:            map[int]*api.Secret{},
+		certsDir:            cfg.CertsDir,
+		certs:               map[string]*issuedCert{},
+	}
+	if cfg.Authentication.Token == nil {
+		source.login = login
+	}
+	if cfg.Authentication.TokenFile != nil {
+		source.tokenFile = *cfg.Authentication.TokenFile
+	}
+
+	return source, nil
 }
 
-func (v *vaultConfigSource) Retrieve(_ context.Context, selector string, _ *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+func (v *vaultConfigSource) Retrieve(ctx context.Context, selector string, paramsConfigMap *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	switch v.mode {
+	case modeTransit:
+		return v.decrypt(ctx, selector, paramsConfigMap)
+	case modePKI:
+		return v.issue(ctx, selector, paramsConfigMap, watcher)
+	}
+
+	var params retrieveParams
+	if paramsConfigMap != nil {
+		if err := paramsConfigMap.Unmarshal(&params, confmap.WithErrorUnused()); err != nil {
+			return nil, &errInvalidParams{fmt.Errorf("failed to unmarshall retrieve params: %w", err)}
+		}
+	}
+
+	if params.Version != 0 {
+		return v.retrieveVersion(ctx, selector, params.Version)
+	}
+
 	// By default assume that watcher is not supported. The exception will be the first
 	// value read from the vault secret.
 	var closeFunc confmap.CloseFunc
 
 	// The keys come all from the same secret so creating a watcher only for the first is fine.
 	if v.secret == nil {
-		if err := v.readSecret(); err != nil {
+		secret, err := v.readSecret(ctx, nil)
+		if err != nil {
 			return nil, err
 		}
+		v.secret = secret
 
 		if watcher != nil {
 			doneCh := make(chan struct{})
 			if err := v.buildWatcherFn(watcher, doneCh); err != nil {
 				return nil, err
 			}
+			if v.tokenFile != "" {
+				if err := v.watchTokenFile(watcher, doneCh); err != nil {
+					close(doneCh)
+					return nil, err
+				}
+			}
 
 			closeFunc = func(ctx context.Context) error {
 				close(doneCh)
@@ -104,7 +214,34 @@ func (v *vaultConfigSource) Retrieve(_ context.Context, selector string, _ *conf
 		}
 	}
 
-	value := traverseToKey(v.secret.Data, selector)
+	return v.retrievedValue(v.secret, selector, closeFunc)
+}
+
+// retrieveVersion retrieves the selector from the given version of a KV v2 secret.
+// Versions are immutable so no watcher is created for them.
+func (v *vaultConfigSource) retrieveVersion(ctx context.Context, selector string, version int) (*confmap.Retrieved, error) {
+	if version < 0 {
+		return nil, &errInvalidParams{fmt.Errorf("invalid version %d, it must be positive", version)}
+	}
+	if !strings.Contains(v.path, "/data/") {
+		return nil, &errInvalidParams{fmt.Errorf("version can only be used with KV v2 paths, i.e. containing \"/data/\", got %q", v.path)}
+	}
+
+	secret, ok := v.versions[version]
+	if !ok {
+		var err error
+		secret, err = v.readSecret(ctx, map[string][]string{"version": {strconv.Itoa(version)}})
+		if err != nil {
+			return nil, err
+		}
+		v.versions[version] = secret
+	}
+
+	return v.retrievedValue(secret, selector, nil)
+}
+
+func (v *vaultConfigSource) retrievedValue(secret *api.Secret, selector string, closeFunc confmap.CloseFunc) (*confmap.Retrieved, error) {
+	value := traverseToKey(secret.Data, selector)
 	if value == nil {
 		return nil, &errBadSelector{fmt.Errorf("no value at path %q for key %q", v.path, selector)}
 	}
@@ -128,26 +265,54 @@ func (v *vaultConfigSource) Shutdown(context.Context) error {
 	return nil
 }
 
-// readSecret reads the secret from the vaultConfigSource path and if successful
-// it stores the secret on the vaultConfigSource secret field.
-func (v *vaultConfigSource) readSecret() error {
-	secret, err := v.client.Logical().Read(v.path)
+// readSecret reads the secret from the vaultConfigSource path with the given
+// request data, e.g. the version of KV v2 secrets.
+func (v *vaultConfigSource) readSecret(ctx context.Context, data map[string][]string) (*api.Secret, error) {
+	secret, err := v.read(ctx, v.path, data)
 	if err != nil {
-		return &errClientRead{err}
+		return nil, &errClientRead{err}
 	}
 
 	// Invalid path does not return error but a nil secret.
 	if secret == nil {
-		return &errNilSecret{fmt.Errorf("no secret found at %q", v.path)}
+		return nil, &errNilSecret{fmt.Errorf("no secret found at %q", v.path)}
 	}
 
 	// Incorrect path for v2 return nil data and warnings.
 	if secret.Data == nil {
-		return &errNilSecretData{fmt.Errorf("no data at %q warnings: %v", v.path, secret.Warnings)}
+		return nil, &errNilSecretData{fmt.Errorf("no data at %q warnings: %v", v.path, secret.Warnings)}
 	}
 
-	v.secret = secret
-	return nil
+	return secret, nil
+}
+
+// read reads the given path, giving up once ctx is done.
+func (v *vaultConfigSource) read(ctx context.Context, path string, data map[string][]string) (*api.Secret, error) {
+	return v.withRelogin(path, func() (*api.Secret, error) {
+		return v.client.Logical().ReadWithDataWithContext(ctx, path, data)
+	})
+}
+
+// withRelogin runs the request to the given path. If the token was rejected, typically
+// because it expired, and the authentication method supports it, it logs in again and
+// retries the request.
+func (v *vaultConfigSource) withRelogin(path string, request func() (*api.Secret, error)) (*api.Secret, error) {
+	secret, err := request()
+	if err == nil || v.login == nil || !isPermissionDenied(err) {
+		return secret, err
+	}
+
+	v.logger.Debug("vault token rejected, logging in again", zap.String("path", path))
+	if loginErr := v.login(); loginErr != nil {
+		return nil, fmt.Errorf("failed to log in again after %v: %w", err, loginErr)
+	}
+
+	return request()
+}
+
+func isPermissionDenied(err error) bool {
+	var respErr *api.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusForbidden
 }
 
 func (v *vaultConfigSource) buildWatcherFn(watcher confmap.WatcherFunc, doneCh chan struct{}) error {
@@ -155,6 +320,9 @@ func (v *vaultConfigSource) buildWatcherFn(watcher confmap.WatcherFunc, doneCh c
 	case v.secret.Renewable:
 		// Dynamic secret supporting renewal.
 		return v.buildLifetimeWatcher(watcher, doneCh)
+	case v.secret.LeaseID != "" && v.secret.LeaseDuration > 0:
+		// Dynamic secret that can't be renewed: re-fetch it before it expires.
+		return v.buildLeaseExpiryWatcher(watcher, doneCh)
 	case v.secret.LeaseDuration > 0:
 		// Version 1 lease: re-fetch it periodically.
 		return v.buildV1LeaseWatcher(watcher, doneCh)
@@ -165,8 +333,12 @@ func (v *vaultConfigSource) buildWatcherFn(watcher confmap.WatcherFunc, doneCh c
 }
 
 func (v *vaultConfigSource) buildLifetimeWatcher(watcher confmap.WatcherFunc, doneCh chan struct{}) error {
+	// The lifetime watcher renews the lease in the background until it can't be
+	// extended anymore, e.g. the max TTL was reached, and then it reports done shortly
+	// before the lease expires so the configuration is re-fetched with new credentials.
 	vaultWatcher, err := v.client.NewLifetimeWatcher(&api.RenewerInput{
-		Secret: v.secret,
+		Secret:    v.secret,
+		Increment: int(v.renewIncrement.Seconds()),
 	})
 	if err != nil {
 		return err
@@ -178,8 +350,10 @@ func (v *vaultConfigSource) buildLifetimeWatcher(watcher confmap.WatcherFunc, do
 
 		for {
 			select {
-			case <-vaultWatcher.RenewCh():
-				v.logger.Debug("vault secret renewed", zap.String("path", v.path))
+			case renewal := <-vaultWatcher.RenewCh():
+				v.logger.Debug("vault secret renewed",
+					zap.String("path", v.path),
+					zap.Int("lease_duration_seconds", renewal.Secret.LeaseDuration))
 			case err := <-vaultWatcher.DoneCh():
 				// Renewal stopped, error or not the client needs to re-fetch the configuration.
 				watcher(&confmap.ChangeEvent{Error: err})
@@ -193,24 +367,42 @@ func (v *vaultConfigSource) buildLifetimeWatcher(watcher confmap.WatcherFunc, do
 	return nil
 }
 
+// buildLeaseExpiryWatcher builds a watcher function that triggers the re-fetch of a
+// non-renewable dynamic secret, e.g. AWS STS credentials, shortly before its lease
+// expires.
+func (v *vaultConfigSource) buildLeaseExpiryWatcher(watcher confmap.WatcherFunc, doneCh chan struct{}) error {
+	updateWait := leaseRefreshWait(time.Duration(v.secret.LeaseDuration)*time.Second, v.refreshBeforeExpiry)
+	configprovider.WatchAfter(updateWait, doneCh, func() {
+		v.logger.Debug("vault secret lease about to expire", zap.String("path", v.path))
+		watcher(&confmap.ChangeEvent{Error: nil})
+	})
+
+	return nil
+}
+
+// leaseRefreshWait returns how long to wait before re-fetching a secret with the given lease
+// duration. It never waits less than half of the lease, so short leases are not re-fetched in
+// a tight loop.
+func leaseRefreshWait(leaseDuration, refreshBeforeExpiry time.Duration) time.Duration {
+	wait := leaseDuration - refreshBeforeExpiry
+	if wait < leaseDuration/2 {
+		wait = leaseDuration / 2
+	}
+	return wait
+}
+
 // buildV1LeaseWatcher builds a watcher function that takes the TTL given
 // by Vault and triggers the re-fetch of the secret when half of the TTl
 // has passed. In principle, this could be changed to actually check if the
 // values of the secret were actually changed or not.
 func (v *vaultConfigSource) buildV1LeaseWatcher(watcher confmap.WatcherFunc, doneCh chan struct{}) error {
-	go func() {
-		// The lease duration is a hint of time to re-fetch the values.
-		// The SmartAgent waits for half ot the lease duration.
-		updateWait := time.Duration(v.secret.LeaseDuration/2) * time.Second
-		select {
-		case <-time.After(updateWait):
-			// This is triggering a re-fetch. In principle this could actually check for changes in the values.
-			watcher(&confmap.ChangeEvent{Error: nil})
-			return
-		case <-doneCh:
-			return
-		}
-	}()
+	// The lease duration is a hint of time to re-fetch the values.
+	// The SmartAgent waits for half ot the lease duration.
+	updateWait := time.Duration(v.secret.LeaseDuration/2) * time.Second
+	configprovider.WatchAfter(updateWait, doneCh, func() {
+		// This is triggering a re-fetch. In principle this could actually check for changes in the values.
+		watcher(&confmap.ChangeEvent{Error: nil})
+	})
 
 	return nil
 }
@@ -245,49 +437,52 @@ func (v *vaultConfigSource) buildPollingWatcher(watcher confmap.WatcherFunc, don
 		return nil
 	}
 
-	go func() {
-		metadataPath := strings.Replace(v.path, "/data/", "/metadata/", 1)
-		ticker := time.NewTicker(v.pollInterval)
-		defer ticker.Stop()
+	// Closing the watcher also cancels the poll in progress. The polls are scheduled with timers
+	// instead of a goroutine waiting for each one.
+	ctx, cancel := context.WithCancel(context.Background())
+	configprovider.WatchChannel[struct{}](doneCh, func(struct{}, bool) {
+		cancel()
+	})
 
-		for {
-			select {
-			case <-ticker.C:
-				metadataSecret, err := v.client.Logical().Read(metadataPath)
-				if err != nil {
-					// Docs are not clear about how to differentiate between temporary and permanent errors.
-					// Assume that the configuration needs to be re-fetched.
-					watcher(&confmap.ChangeEvent{Error: fmt.Errorf("failed to read secret metadata at %q: %w", metadataPath, err)})
-					return
-				}
+	metadataPath := strings.Replace(v.path, "/data/", "/metadata/", 1)
+	var poll func()
+	poll = func() {
+		metadataSecret, err := v.read(ctx, metadataPath, nil)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			// Docs are not clear about how to differentiate between temporary and permanent errors.
+			// Assume that the configuration needs to be re-fetched.
+			watcher(&confmap.ChangeEvent{Error: fmt.Errorf("failed to read secret metadata at %q: %w", metadataPath, err)})
+			return
+		}
 
-				if metadataSecret == nil || metadataSecret.Data == nil {
-					watcher(&confmap.ChangeEvent{Error: fmt.Errorf("no secret metadata found at %q", metadataPath)})
-					return
-				}
+		if metadataSecret == nil || metadataSecret.Data == nil {
+			watcher(&confmap.ChangeEvent{Error: fmt.Errorf("no secret metadata found at %q", metadataPath)})
+			return
+		}
 
-				const timestampKey = "updated_time"
-				const versionKey = "current_version"
-				latestVersion := v.extractVersionMetadata(metadataSecret.Data, timestampKey, versionKey)
-				if latestVersion == nil {
-					watcher(&confmap.ChangeEvent{
-						Error: fmt.Errorf("secret metadata is not in the expected format for keys %q and %q", timestampKey, versionKey),
-					})
-					return
-				}
+		const timestampKey = "updated_time"
+		const versionKey = "current_version"
+		latestVersion := v.extractVersionMetadata(metadataSecret.Data, timestampKey, versionKey)
+		if latestVersion == nil {
+			watcher(&confmap.ChangeEvent{
+				Error: fmt.Errorf("secret metadata is not in the expected format for keys %q and %q", timestampKey, versionKey),
+			})
+			return
+		}
 
-				// Per SmartAgent code this is enough to trigger an update but it is also possible to check if the
-				// the valued of the retrieved keys was changed. The current criteria may trigger updates even for
-				// addition of new keys to the secret.
-				if originalVersion.Timestamp != latestVersion.Timestamp || originalVersion.Version != latestVersion.Version {
-					watcher(&confmap.ChangeEvent{Error: nil})
-					return
-				}
-			case <-doneCh:
-				return
-			}
+		// Per SmartAgent code this is enough to trigger an update but it is also possible to check if the
+		// the valued of the retrieved keys was changed. The current criteria may trigger updates even for
+		// addition of new keys to the secret.
+		if originalVersion.Timestamp != latestVersion.Timestamp || originalVersion.Version != latestVersion.Version {
+			watcher(&confmap.ChangeEvent{Error: nil})
+			return
 		}
-	}()
+		configprovider.WatchAfter(v.pollInterval, doneCh, poll)
+	}
+	configprovider.WatchAfter(v.pollInterval, doneCh, poll)
 
 	return nil
 }
@@ -346,10 +541,47 @@ func getClientToken(client *api.Client, auth Authentication) (string, error) {
 	switch {
 	case auth.Token != nil:
 		return *auth.Token, nil
+	case auth.TokenFile != nil:
+		return readTokenFile(*auth.TokenFile)
 	case auth.IAMAuthentication != nil:
 		return auth.IAMAuthentication.Token(client)
 	case auth.GCPAuthentication != nil:
 		return auth.GCPAuthentication.Token(client)
+	case auth.AppRoleAuthentication != nil:
+		return auth.AppRoleAuthentication.Token(client)
+	case auth.KubernetesAuthentication != nil:
+		return auth.KubernetesAuthentication.Token(client)
+	case auth.AzureAuthentication != nil:
+		return auth.AzureAuthentication.Token(client)
+	case auth.CertAuthentication != nil:
+		return auth.CertAuthentication.Token(client)
 	}
 	return "", &errEmptyAuth{errors.New("auth cannot be empty, exactly one method must be used")}
 }
+
+// loginToken writes the login data to the given auth path and returns the client token
+// issued by Vault.
+func loginToken(client *api.Client, path string, data map[string]any) (string, error) {
+	// Login requests must not carry the token from a previous, possibly expired, login.
+	loginClient, err := client.Clone()
+	if err != nil {
+		return "", err
+	}
+	loginClient.ClearToken()
+
+	secret, err := loginClient.Logical().Write(path, data)
+	if err != nil {
+		return "", err
+	}
+	if secret == nil || secret.Auth == nil {
+		return "", fmt.Errorf("login at %q returned no authentication data", path)
+	}
+	return secret.Auth.ClientToken, nil
+}
+
+func valueOrDefaultDuration(value, defaultValue time.Duration) time.Duration {
+	if value == 0 {
+		return defaultValue
+	}
+	return value
+}
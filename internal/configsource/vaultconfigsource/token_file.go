@@ -0,0 +1,129 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vaultconfigsource
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func readTokenFile(tokenFile string) (string, error) {
+	content, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token_file: %w", err)
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", fmt.Errorf("token_file %q is empty", tokenFile)
+	}
+	return token, nil
+}
+
+// watchTokenFile monitors the token file and, when a new token is written to it,
+// switches the client to the new token and triggers the watcher so the values and
+// their watchers are retrieved again with the new token.
+func (v *vaultConfigSource) watchTokenFile(watcher confmap.WatcherFunc, doneCh chan struct{}) error {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	// Watch the directory since tools like Vault Agent replace the file instead of
+	// writing to it.
+	if err = fsWatcher.Add(filepath.Dir(v.tokenFile)); err != nil {
+		_ = fsWatcher.Close()
+		return err
+	}
+
+	// The channels are waited for by the goroutines shared by all the config sources.
+	tokenFile := filepath.Clean(v.tokenFile)
+	var mutex sync.Mutex
+	stopped := false
+	var stopEvents, stopErrors func()
+	// stop must be called with the mutex held.
+	stop := func() {
+		stopped = true
+		stopEvents()
+		stopErrors()
+		_ = fsWatcher.Close()
+	}
+
+	var watchEvents, watchErrors func()
+	watchEvents = func() {
+		stopEvents = configprovider.WatchChannel[fsnotify.Event](fsWatcher.Events, func(event fsnotify.Event, ok bool) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if !ok || stopped {
+				return
+			}
+			if filepath.Clean(event.Name) != tokenFile || event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				watchEvents()
+				return
+			}
+
+			token, err := readTokenFile(tokenFile)
+			if err != nil {
+				// The file may be in the middle of an update, wait for the next event.
+				v.logger.Debug("failed to read vault token file", zap.String("token_file", tokenFile), zap.Error(err))
+				watchEvents()
+				return
+			}
+			if token == v.client.Token() {
+				watchEvents()
+				return
+			}
+
+			v.logger.Debug("vault token file changed", zap.String("token_file", tokenFile))
+			v.client.SetToken(token)
+			stop()
+			watcher(&confmap.ChangeEvent{Error: nil})
+		})
+	}
+	watchErrors = func() {
+		stopErrors = configprovider.WatchChannel[error](fsWatcher.Errors, func(err error, ok bool) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if !ok || stopped {
+				return
+			}
+			v.logger.Warn("error watching vault token file", zap.String("token_file", tokenFile), zap.Error(err))
+			watchErrors()
+		})
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	watchEvents()
+	watchErrors()
+	configprovider.WatchChannel[struct{}](doneCh, func(struct{}, bool) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if !stopped {
+			stop()
+		}
+	})
+
+	return nil
+}
@@ -0,0 +1,155 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vaultconfigsource
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+// tokenServer is a fake Vault server that only accepts its current token.
+type tokenServer struct {
+	*httptest.Server
+	token string
+	mu    sync.Mutex
+}
+
+func newTokenServer(t *testing.T, token string) *tokenServer {
+	ts := &tokenServer{token: token}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ts.mu.Lock()
+		defer ts.mu.Unlock()
+		if r.Header.Get("X-Vault-Token") != ts.token {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{"k0": "v0"},
+		})
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func (ts *tokenServer) setToken(token string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.token = token
+}
+
+// writeTokenFile replaces the token file like Vault Agent does.
+func writeTokenFile(t *testing.T, tokenFile, token string) {
+	tmp := tokenFile + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, []byte(token+"\n"), 0600))
+	require.NoError(t, os.Rename(tmp, tokenFile))
+}
+
+func TestVaultTokenFileWatch(t *testing.T) {
+	server := newTokenServer(t, "token-1")
+	tokenFile := filepath.Join(t.TempDir(), "vault-token")
+	writeTokenFile(t, tokenFile, "token-1")
+
+	config := Config{
+		Endpoint: server.URL,
+		Authentication: &Authentication{
+			TokenFile: &tokenFile,
+		},
+		Path:         "secret/kv",
+		PollInterval: time.Minute,
+	}
+
+	source, err := newConfigSource(configprovider.CreateParams{Logger: zap.NewNop()}, &config)
+	require.NoError(t, err)
+
+	watchCh := make(chan *confmap.ChangeEvent, 1)
+	retrieved, err := source.Retrieve(context.Background(), "k0", nil, func(event *confmap.ChangeEvent) {
+		watchCh <- event
+	})
+	require.NoError(t, err)
+	val, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, "v0", val)
+
+	server.setToken("token-2")
+	writeTokenFile(t, tokenFile, "token-2")
+
+	select {
+	case ce := <-watchCh:
+		require.NoError(t, ce.Error)
+	case <-time.After(5 * time.Second):
+		t.Fatal("watcher not triggered after the token file changed")
+	}
+	assert.Equal(t, "token-2", source.(*vaultConfigSource).client.Token())
+
+	require.NoError(t, retrieved.Close(context.Background()))
+	require.NoError(t, source.Shutdown(context.Background()))
+}
+
+func TestVaultTokenFileReloadOnRejectedToken(t *testing.T) {
+	server := newTokenServer(t, "token-1")
+	tokenFile := filepath.Join(t.TempDir(), "vault-token")
+	writeTokenFile(t, tokenFile, "token-1")
+
+	config := Config{
+		Endpoint: server.URL,
+		Authentication: &Authentication{
+			TokenFile: &tokenFile,
+		},
+		Path:         "secret/kv",
+		PollInterval: time.Minute,
+	}
+
+	source, err := newConfigSource(configprovider.CreateParams{Logger: zap.NewNop()}, &config)
+	require.NoError(t, err)
+
+	// The token is rotated before the first retrieve, without a watcher in place.
+	server.setToken("token-2")
+	writeTokenFile(t, tokenFile, "token-2")
+
+	retrieved, err := source.Retrieve(context.Background(), "k0", nil, nil)
+	require.NoError(t, err)
+	val, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, "v0", val)
+
+	require.NoError(t, source.Shutdown(context.Background()))
+}
+
+func TestVaultTokenFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	emptyFile := filepath.Join(dir, "empty")
+	require.NoError(t, os.WriteFile(emptyFile, []byte("\n"), 0600))
+
+	_, err := readTokenFile(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+	_, err = readTokenFile(emptyFile)
+	assert.Error(t, err)
+}
@@ -0,0 +1,85 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vaultconfigsource
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// Private error types to help with testability.
+type (
+	errTransitDecrypt struct{ error }
+)
+
+// transitParams holds the parameters supported by Retrieve in transit mode.
+type transitParams struct {
+	// Ciphertext is the ciphertext to be decrypted, as returned by transit encrypt,
+	// e.g. "vault:v1:...". It is required.
+	Ciphertext string `mapstructure:"ciphertext"`
+	// Context is the base64 encoded context used for key derivation, it is required
+	// if the key was created with derivation enabled.
+	Context string `mapstructure:"context"`
+}
+
+// decrypt decrypts the ciphertext on the params with the transit key named by the selector.
+func (v *vaultConfigSource) decrypt(ctx context.Context, keyName string, paramsConfigMap *confmap.Conf) (*confmap.Retrieved, error) {
+	var params transitParams
+	if paramsConfigMap != nil {
+		if err := paramsConfigMap.Unmarshal(&params, confmap.WithErrorUnused()); err != nil {
+			return nil, &errInvalidParams{fmt.Errorf("failed to unmarshall retrieve params: %w", err)}
+		}
+	}
+	if params.Ciphertext == "" {
+		return nil, &errInvalidParams{fmt.Errorf("transit key %q: ciphertext param cannot be empty", keyName)}
+	}
+
+	// A '+' on the single-line invocation params is decoded as a space, undo it
+	// since neither the ciphertext nor the context can contain spaces.
+	data := map[string]any{
+		"ciphertext": strings.ReplaceAll(params.Ciphertext, " ", "+"),
+	}
+	if params.Context != "" {
+		data["context"] = strings.ReplaceAll(params.Context, " ", "+")
+	}
+
+	path := fmt.Sprintf("%s/decrypt/%s", strings.TrimSuffix(v.path, "/"), keyName)
+	secret, err := v.withRelogin(path, func() (*api.Secret, error) {
+		return v.client.Logical().WriteWithContext(ctx, path, data)
+	})
+	if err != nil {
+		return nil, &errTransitDecrypt{fmt.Errorf("failed to decrypt with transit key %q: %w", keyName, err)}
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, &errTransitDecrypt{fmt.Errorf("no data returned by %q", path)}
+	}
+
+	encoded, ok := secret.Data["plaintext"].(string)
+	if !ok {
+		return nil, &errTransitDecrypt{fmt.Errorf("no plaintext returned by %q", path)}
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, &errTransitDecrypt{fmt.Errorf("failed to decode plaintext returned by %q: %w", path, err)}
+	}
+
+	return confmap.NewRetrieved(string(plaintext))
+}
@@ -0,0 +1,147 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vaultconfigsource
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestVaultTransitDecrypt(t *testing.T) {
+	// The fake transit engine "decrypts" ciphertexts of the form "vault:v1:<base64 plaintext>",
+	// keys named "derived" require a context.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keyName := strings.TrimPrefix(r.URL.Path, "/v1/transit-prod/decrypt/")
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || r.Method != http.MethodPut || keyName == r.URL.Path {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if keyName == "derived" && body["context"] == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":["missing 'context' for key derivation"]}`))
+			return
+		}
+		if !strings.HasPrefix(body["ciphertext"], "vault:v1:") {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":["invalid ciphertext: no prefix"]}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{"plaintext": strings.TrimPrefix(body["ciphertext"], "vault:v1:")},
+		})
+	}))
+	defer server.Close()
+
+	config := Config{
+		Endpoint: server.URL,
+		Authentication: &Authentication{
+			Token: &tokenStr,
+		},
+		Path:         "transit-prod/",
+		Mode:         modeTransit,
+		PollInterval: time.Minute,
+	}
+
+	source, err := newConfigSource(configprovider.CreateParams{Logger: zap.NewNop()}, &config)
+	require.NoError(t, err)
+
+	// The plaintext was chosen so its base64 encoding contains '+'.
+	plaintext := "s3cr3t>>>"
+	ciphertext := "vault:v1:" + base64.StdEncoding.EncodeToString([]byte(plaintext))
+	require.Contains(t, ciphertext, "+")
+
+	tests := []struct {
+		params   map[string]any
+		wantErr  error
+		name     string
+		selector string
+	}{
+		{
+			name:     "decrypt",
+			selector: "collector",
+			params:   map[string]any{"ciphertext": ciphertext},
+		},
+		{
+			name:     "single_line_params",
+			selector: "collector",
+			params:   map[string]any{"ciphertext": strings.ReplaceAll(ciphertext, "+", " ")},
+		},
+		{
+			name:     "derived_key",
+			selector: "derived",
+			params:   map[string]any{"ciphertext": ciphertext, "context": "Y29sbGVjdG9y"},
+		},
+		{
+			name:     "derived_key_missing_context",
+			selector: "derived",
+			params:   map[string]any{"ciphertext": ciphertext},
+			wantErr:  &errTransitDecrypt{},
+		},
+		{
+			name:     "invalid_ciphertext",
+			selector: "collector",
+			params:   map[string]any{"ciphertext": "not-a-ciphertext"},
+			wantErr:  &errTransitDecrypt{},
+		},
+		{
+			name:     "missing_ciphertext",
+			selector: "collector",
+			wantErr:  &errInvalidParams{},
+		},
+		{
+			name:     "unknown_param",
+			selector: "collector",
+			params:   map[string]any{"ciphertext": ciphertext, "version": 1},
+			wantErr:  &errInvalidParams{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var params *confmap.Conf
+			if tt.params != nil {
+				params = confmap.NewFromStringMap(tt.params)
+			}
+
+			retrieved, err := source.Retrieve(context.Background(), tt.selector, params, nil)
+			if tt.wantErr != nil {
+				assert.Nil(t, retrieved)
+				require.IsType(t, tt.wantErr, err)
+				return
+			}
+			require.NoError(t, err)
+			val, err := retrieved.AsRaw()
+			require.NoError(t, err)
+			assert.Equal(t, plaintext, val)
+		})
+	}
+
+	require.NoError(t, source.Shutdown(context.Background()))
+}
@@ -18,6 +18,8 @@ package zookeeperconfigsource
 import (
 	"time"
 
+	"go.opentelemetry.io/collector/config/configtls"
+
 	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
 )
 
@@ -27,12 +29,55 @@ type Config struct {
 	// Endpoints is an array of Zookeeper server addresses. Thr ConfigSource will try to connect
 	// to these endpoints to access Zookeeper clusters.
 	Endpoints []string `mapstructure:"endpoints"`
+	// Chroot is a znode path prepended to all the selectors, e.g. "/collector" makes
+	// the selector "/token" retrieve the znode "/collector/token".
+	Chroot string `mapstructure:"chroot"`
+	// FailoverEnsembles is an ordered list of standby ensembles. They are tried in
+	// order if no session can be established with the servers in Endpoints, or once
+	// an established session is lost for longer than Timeout.
+	FailoverEnsembles []EnsembleConfig `mapstructure:"failover_ensembles"`
 	// Timeout sets the amount of time for which a session is considered valid after losing
 	// connection to a server. Within the session timeout it's possible to reestablish a connection
 	// to a different server and keep the same session.
 	Timeout time.Duration `mapstructure:"timeout"`
+	// Auth holds the credentials added to the zookeeper session, if any.
+	Auth *AuthConfig `mapstructure:"auth"`
+	// TLS configures the connections to the Zookeeper servers to use TLS. Setting
+	// a client certificate and key enables mutual TLS.
+	TLS *configtls.TLSClientSetting `mapstructure:"tls"`
+}
+
+// EnsembleConfig defines a standby Zookeeper ensemble.
+type EnsembleConfig struct {
+	// Endpoints is an array of the Zookeeper server addresses of the ensemble.
+	Endpoints []string `mapstructure:"endpoints"`
+	// Chroot is the znode path prepended to all the selectors when using the ensemble.
+	// If not specified the chroot of the primary ensemble is used.
+	Chroot string `mapstructure:"chroot"`
+}
+
+// AuthConfig holds the credentials used to authenticate the zookeeper session.
+type AuthConfig struct {
+	// Scheme is the zookeeper authentication scheme, currently only "digest" is supported.
+	Scheme string `mapstructure:"scheme"`
+	// Username is the user name used with the digest scheme.
+	Username string `mapstructure:"username"`
+	// Password is the password used with the digest scheme.
+	Password string `mapstructure:"password"`
 }
 
 func (*Config) Validate() error {
 	return nil
 }
+
+// ensembles returns the primary ensemble followed by the failover ones.
+func (cfg *Config) ensembles() []EnsembleConfig {
+	ensembles := []EnsembleConfig{{Endpoints: cfg.Endpoints, Chroot: cfg.Chroot}}
+	for _, ensemble := range cfg.FailoverEnsembles {
+		if ensemble.Chroot == "" {
+			ensemble.Chroot = cfg.Chroot
+		}
+		ensembles = append(ensembles, ensemble)
+	}
+	return ensembles
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtls"
 	"go.opentelemetry.io/collector/confmap/confmaptest"
 	"go.uber.org/zap"
 
@@ -52,6 +53,27 @@ func TestZookeeperLoadConfig(t *testing.T) {
 			Endpoints:      []string{"https://localhost:3010"},
 			Timeout:        time.Second * 8,
 		},
+		"zookeeper/secure": &Config{
+			SourceSettings: configprovider.NewSourceSettings(component.NewIDWithName(typeStr, "secure")),
+			Endpoints:      []string{"localhost:2281"},
+			Timeout:        time.Second * 10,
+			Auth: &AuthConfig{
+				Scheme:   "digest",
+				Username: "collector",
+				Password: "secret",
+			},
+			TLS: &configtls.TLSClientSetting{ServerName: "zookeeper"},
+		},
+		"zookeeper/failover": &Config{
+			SourceSettings: configprovider.NewSourceSettings(component.NewIDWithName(typeStr, "failover")),
+			Endpoints:      []string{"zk1:2181", "zk2:2181"},
+			Chroot:         "/collector",
+			FailoverEnsembles: []EnsembleConfig{
+				{Endpoints: []string{"dr-zk1:2181", "dr-zk2:2181"}},
+				{Endpoints: []string{"dr2-zk1:2181"}, Chroot: "/dr/collector"},
+			},
+			Timeout: time.Second * 10,
+		},
 	}
 
 	require.Equal(t, expectedSettings, actualSettings)
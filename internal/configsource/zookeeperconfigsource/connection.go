@@ -17,6 +17,8 @@ package zookeeperconfigsource
 
 import (
 	"context"
+	"path"
+	"time"
 
 	"github.com/go-zookeeper/zk"
 )
@@ -26,8 +28,79 @@ import (
 // the connection in tests.
 type zkConnection interface {
 	GetW(string) ([]byte, *zk.Stat, <-chan zk.Event, error)
+	ChildrenW(string) ([]string, *zk.Stat, <-chan zk.Event, error)
 
 	Close()
 }
 
 type connectFunc func(context.Context) (zkConnection, error)
+
+// sessionMonitor is implemented by connections that report when their session is lost
+// and can't be recovered within the same ensemble, so the values retrieved through them
+// must be retrieved again, possibly from another ensemble.
+type sessionMonitor interface {
+	SessionLost() <-chan zk.Event
+}
+
+// ensembleConn is a connection to one of the configured ensembles. It prefixes the
+// paths with the chroot of the ensemble, if any.
+type ensembleConn struct {
+	zkConnection
+	chroot string
+	lostCh chan zk.Event
+}
+
+var _ sessionMonitor = (*ensembleConn)(nil)
+
+func (c *ensembleConn) GetW(znode string) ([]byte, *zk.Stat, <-chan zk.Event, error) {
+	return c.zkConnection.GetW(c.fullPath(znode))
+}
+
+func (c *ensembleConn) ChildrenW(znode string) ([]string, *zk.Stat, <-chan zk.Event, error) {
+	return c.zkConnection.ChildrenW(c.fullPath(znode))
+}
+
+func (c *ensembleConn) SessionLost() <-chan zk.Event {
+	return c.lostCh
+}
+
+func (c *ensembleConn) fullPath(znode string) string {
+	if c.chroot == "" {
+		return znode
+	}
+	return path.Join(c.chroot, znode)
+}
+
+// monitorSession reports on lostCh when the connection stays disconnected for longer
+// than timeout. The zookeeper client keeps trying the servers of the ensemble on its
+// own, a session is only expired by the servers once it reconnects, so without this
+// a lost ensemble wouldn't be noticed. lostCh is closed once the session events stop,
+// i.e. the connection was closed.
+func (c *ensembleConn) monitorSession(events <-chan zk.Event, timeout time.Duration) {
+	defer close(c.lostCh)
+
+	var disconnectedCh <-chan time.Time
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			switch e.State {
+			case zk.StateDisconnected, zk.StateConnecting:
+				if disconnectedCh == nil {
+					disconnectedCh = time.After(timeout)
+				}
+			case zk.StateHasSession:
+				disconnectedCh = nil
+			}
+		case <-disconnectedCh:
+			disconnectedCh = nil
+			// Reported the same way as an expired session: the value is retrieved again.
+			select {
+			case c.lostCh <- zk.Event{Type: zk.EventNotWatching, State: zk.StateDisconnected, Err: zk.ErrSessionExpired}:
+			default:
+			}
+		}
+	}
+}
@@ -29,10 +29,24 @@ const (
 
 	defaultEndpoint = "localhost:2181"
 	defaultTimeout  = time.Second * 10
+
+	// digestAuthScheme is the only auth scheme supported by the zookeeper client.
+	digestAuthScheme = "digest"
+
+	// SASL schemes, not supported by the zookeeper client, rejected with an explicit error.
+	saslAuthScheme     = "sasl"
+	kerberosAuthScheme = "kerberos"
+	gssapiAuthScheme   = "gssapi"
 )
 
-// Private error type to help with testability.
-type errMissingEndpoint struct{ error }
+// Private error types to help with testability.
+type (
+	errMissingEndpoint  struct{ error }
+	errInvalidAuth      struct{ error }
+	errInvalidChroot    struct{ error }
+	errInvalidTLSConfig struct{ error }
+	errInvalidParams    struct{ error }
+)
 
 type zkFactory struct{}
 
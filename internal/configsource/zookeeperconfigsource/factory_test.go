@@ -22,6 +22,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtls"
 	"go.uber.org/zap"
 
 	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
@@ -43,6 +44,96 @@ func TestZookeeperFactory_CreateConfigSource(t *testing.T) {
 			config:  &Config{},
 			wantErr: &errMissingEndpoint{},
 		},
+		{
+			name: "missing_auth_scheme",
+			config: &Config{
+				Endpoints: []string{"localhost:2181"},
+				Auth:      &AuthConfig{Username: "user"},
+			},
+			wantErr: &errInvalidAuth{},
+		},
+		{
+			name: "unsupported_auth_scheme",
+			config: &Config{
+				Endpoints: []string{"localhost:2181"},
+				Auth:      &AuthConfig{Scheme: "ip"},
+			},
+			wantErr: &errInvalidAuth{},
+		},
+		{
+			name: "sasl_auth_scheme",
+			config: &Config{
+				Endpoints: []string{"localhost:2181"},
+				Auth:      &AuthConfig{Scheme: "sasl"},
+			},
+			wantErr: &errInvalidAuth{},
+		},
+		{
+			name: "kerberos_auth_scheme",
+			config: &Config{
+				Endpoints: []string{"localhost:2181"},
+				Auth:      &AuthConfig{Scheme: "kerberos"},
+			},
+			wantErr: &errInvalidAuth{},
+		},
+		{
+			name: "missing_digest_username",
+			config: &Config{
+				Endpoints: []string{"localhost:2181"},
+				Auth:      &AuthConfig{Scheme: "digest", Password: "secret"},
+			},
+			wantErr: &errInvalidAuth{},
+		},
+		{
+			name: "invalid_tls",
+			config: &Config{
+				Endpoints: []string{"localhost:2181"},
+				TLS: &configtls.TLSClientSetting{
+					TLSSetting: configtls.TLSSetting{CAFile: "testdata/not-found.pem"},
+				},
+			},
+			wantErr: &errInvalidTLSConfig{},
+		},
+		{
+			name: "relative_chroot",
+			config: &Config{
+				Endpoints: []string{"localhost:2181"},
+				Chroot:    "collector",
+			},
+			wantErr: &errInvalidChroot{},
+		},
+		{
+			name: "failover_missing_endpoints",
+			config: &Config{
+				Endpoints:         []string{"localhost:2181"},
+				FailoverEnsembles: []EnsembleConfig{{Chroot: "/collector"}},
+			},
+			wantErr: &errMissingEndpoint{},
+		},
+		{
+			name: "failover_invalid_chroot",
+			config: &Config{
+				Endpoints:         []string{"localhost:2181"},
+				FailoverEnsembles: []EnsembleConfig{{Endpoints: []string{"dr:2181"}, Chroot: "/collector/"}},
+			},
+			wantErr: &errInvalidChroot{},
+		},
+		{
+			name: "success_failover",
+			config: &Config{
+				Endpoints:         []string{"localhost:2181"},
+				Chroot:            "/collector",
+				FailoverEnsembles: []EnsembleConfig{{Endpoints: []string{"dr:2181"}}},
+			},
+		},
+		{
+			name: "success_auth_tls",
+			config: &Config{
+				Endpoints: []string{"localhost:2281"},
+				Auth:      &AuthConfig{Scheme: "digest", Username: "user", Password: "secret"},
+				TLS:       &configtls.TLSClientSetting{ServerName: "zookeeper"},
+			},
+		},
 		{
 			name: "success",
 			config: &Config{
@@ -18,6 +18,8 @@ package zookeeperconfigsource
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/go-zookeeper/zk"
 )
@@ -41,13 +43,46 @@ func newMockConnection(db map[string]string) *mockConnection {
 
 func (m *mockConnection) GetW(key string) ([]byte, *zk.Stat, <-chan zk.Event, error) {
 	if value, ok := m.db[key]; ok {
-		m.watcherCh = make(chan zk.Event)
-		return []byte(value), &zk.Stat{}, m.watcherCh, nil
+		return []byte(value), &zk.Stat{}, m.watchChannel(), nil
 	}
 	return nil, nil, nil, fmt.Errorf("value not found")
 }
 
+// ChildrenW returns the direct children of key, the keys in db are treated as
+// the paths of the leaf znodes.
+func (m *mockConnection) ChildrenW(key string) ([]string, *zk.Stat, <-chan zk.Event, error) {
+	prefix := key + "/"
+	seen := make(map[string]bool)
+	var children []string
+	for k := range m.db {
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		child := strings.SplitN(strings.TrimPrefix(k, prefix), "/", 2)[0]
+		if !seen[child] {
+			seen[child] = true
+			children = append(children, child)
+		}
+	}
+	if _, ok := m.db[key]; !ok && len(children) == 0 {
+		return nil, nil, nil, fmt.Errorf("value not found")
+	}
+	sort.Strings(children)
+	return children, &zk.Stat{}, m.watchChannel(), nil
+}
+
+// watchChannel returns the channel shared by all the watches set on the connection.
+func (m *mockConnection) watchChannel() chan zk.Event {
+	if m.watcherCh == nil {
+		m.watcherCh = make(chan zk.Event)
+	}
+	return m.watcherCh
+}
+
 func (m *mockConnection) Close() {
+	if m.watcherCh == nil {
+		return
+	}
 	close(m.watcherCh)
 	m.watcherCh = nil
 }
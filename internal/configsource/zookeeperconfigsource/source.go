@@ -17,12 +17,18 @@ package zookeeperconfigsource
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"net"
+	"path"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-zookeeper/zk"
 	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 
 	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
@@ -34,12 +40,68 @@ type zkConfigSource struct {
 	connect connectFunc
 }
 
+// retrieveParams holds the parameters supported by Retrieve.
+type retrieveParams struct {
+	// Recursive retrieves the whole subtree under the selected znode as a nested map
+	// keyed by the names of the child znodes. Leaf znodes are mapped to their data.
+	Recursive bool `mapstructure:"recursive"`
+}
+
 func newConfigSource(params configprovider.CreateParams, cfg *Config) (configprovider.ConfigSource, error) {
 	if len(cfg.Endpoints) == 0 {
 		return nil, &errMissingEndpoint{errors.New("cannot connect to zk without any endpoints")}
 	}
 
-	return newZkConfigSource(params, newConnectFunc(cfg.Endpoints, cfg.Timeout)), nil
+	if err := validateEnsembles(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validateAuth(cfg.Auth); err != nil {
+		return nil, err
+	}
+
+	var tlsCfg *tls.Config
+	if cfg.TLS != nil {
+		var err error
+		if tlsCfg, err = cfg.TLS.LoadTLSConfig(); err != nil {
+			return nil, &errInvalidTLSConfig{fmt.Errorf("failed to load TLS config: %w", err)}
+		}
+	}
+
+	return newZkConfigSource(params, newConnectFunc(cfg, tlsCfg, params.Logger)), nil
+}
+
+func validateAuth(auth *AuthConfig) error {
+	if auth == nil {
+		return nil
+	}
+	switch auth.Scheme {
+	case digestAuthScheme:
+		if auth.Username == "" {
+			return &errInvalidAuth{errors.New("digest authentication requires a username")}
+		}
+		return nil
+	case "":
+		return &errInvalidAuth{errors.New("auth requires a scheme")}
+	case saslAuthScheme, kerberosAuthScheme, gssapiAuthScheme:
+		// The zookeeper client only implements the plain authentication schemes,
+		// SASL (e.g. Kerberos) requires a handshake the client doesn't provide.
+		return &errInvalidAuth{fmt.Errorf("auth scheme %q is not supported, SASL (Kerberos) authentication is not implemented by the zookeeper client, only %q is supported", auth.Scheme, digestAuthScheme)}
+	default:
+		return &errInvalidAuth{fmt.Errorf("unsupported auth scheme %q, supported schemes: %q", auth.Scheme, digestAuthScheme)}
+	}
+}
+
+func validateEnsembles(cfg *Config) error {
+	for i, ensemble := range cfg.ensembles() {
+		if len(ensemble.Endpoints) == 0 {
+			return &errMissingEndpoint{fmt.Errorf("failover_ensembles[%d]: cannot connect to zk without any endpoints", i-1)}
+		}
+		if ensemble.Chroot != "" && (!strings.HasPrefix(ensemble.Chroot, "/") || ensemble.Chroot != path.Clean(ensemble.Chroot)) {
+			return &errInvalidChroot{fmt.Errorf("invalid chroot %q, it must be an absolute znode path", ensemble.Chroot)}
+		}
+	}
+	return nil
 }
 
 func newZkConfigSource(params configprovider.CreateParams, connect connectFunc) *zkConfigSource {
@@ -49,68 +111,242 @@ func newZkConfigSource(params configprovider.CreateParams, connect connectFunc)
 	}
 }
 
-func (s *zkConfigSource) Retrieve(ctx context.Context, selector string, _ *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+func (s *zkConfigSource) Retrieve(ctx context.Context, selector string, paramsConfigMap *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	var params retrieveParams
+	if paramsConfigMap != nil {
+		if err := paramsConfigMap.Unmarshal(&params, confmap.WithErrorUnused()); err != nil {
+			return nil, &errInvalidParams{fmt.Errorf("failed to unmarshall retrieve params: %w", err)}
+		}
+	}
+
 	conn, err := s.connect(ctx)
 	if err != nil {
 		return nil, err
 	}
-	value, _, watchCh, err := conn.GetW(selector)
+
+	var value any
+	var watchChs []<-chan zk.Event
+	if params.Recursive {
+		value, watchChs, err = retrieveTree(conn, selector)
+	} else {
+		var data []byte
+		var watchCh <-chan zk.Event
+		data, _, watchCh, err = conn.GetW(selector)
+		value, watchChs = string(data), []<-chan zk.Event{watchCh}
+	}
 	if err != nil {
+		conn.Close()
+		if errors.Is(err, zk.ErrNoNode) {
+			err = configprovider.NotFoundError(err)
+		}
 		return nil, err
 	}
+	if monitor, ok := conn.(sessionMonitor); ok && monitor.SessionLost() != nil {
+		watchChs = append(watchChs, monitor.SessionLost())
+	}
 
-	closeCh := make(chan struct{})
-	startWatcher(watchCh, closeCh, watcher)
-	return confmap.NewRetrieved(string(value), confmap.WithRetrievedClose(func(ctx context.Context) error {
-		close(closeCh)
+	stopWatcher := startWatcher(watchChs, watcher)
+	return confmap.NewRetrieved(value, confmap.WithRetrievedClose(func(ctx context.Context) error {
+		stopWatcher()
 		conn.Close()
 		return nil
 	}))
 }
 
+// retrieveTree reads the subtree rooted at the given znode. Znodes with children are
+// returned as a map keyed by the child names, znodes without children are returned as
+// their data. Both the data and the children of every znode in the subtree are watched
+// so that adding, removing, or updating any of them is reported as a change.
+func retrieveTree(conn zkConnection, znode string) (any, []<-chan zk.Event, error) {
+	children, _, childrenCh, err := conn.ChildrenW(znode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(children) == 0 {
+		data, _, dataCh, err := conn.GetW(znode)
+		if err != nil {
+			return nil, nil, err
+		}
+		return string(data), []<-chan zk.Event{childrenCh, dataCh}, nil
+	}
+
+	tree := make(map[string]any, len(children))
+	watchChs := []<-chan zk.Event{childrenCh}
+	for _, child := range children {
+		value, childWatchChs, err := retrieveTree(conn, path.Join(znode, child))
+		if err != nil {
+			return nil, nil, err
+		}
+		tree[child] = value
+		watchChs = append(watchChs, childWatchChs...)
+	}
+	return tree, watchChs, nil
+}
+
 func (s *zkConfigSource) Shutdown(context.Context) error {
 	return nil
 }
 
-func startWatcher(watchCh <-chan zk.Event, closeCh <-chan struct{}, watcher confmap.WatcherFunc) {
-	go func() {
-		select {
-		case <-closeCh:
-			return
-		case e, ok := <-watchCh:
+// startWatcher waits for the first event of the one-shot zookeeper watches set by GetW, or of
+// the loss of the session, and forwards it to the collector. Zookeeper pushes watch events as
+// soon as the znode changes so there's no polling involved. The watches are waited for by the
+// goroutines shared by all the config sources, closed channels, e.g. of a closed connection, are
+// ignored. The returned func stops waiting.
+func startWatcher(watchChs []<-chan zk.Event, watcher confmap.WatcherFunc) (stop func()) {
+	var mutex sync.Mutex
+	var stops []func()
+	stopped := false
+	stop = func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		stopped = true
+		for _, stopWatch := range stops {
+			stopWatch()
+		}
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	for _, watchCh := range watchChs {
+		stops = append(stops, configprovider.WatchChannel(watchCh, func(e zk.Event, ok bool) {
 			if !ok {
 				// Channel close without any event, connection must have been closed.
 				return
 			}
-			if e.Err != nil {
-				watcher(&confmap.ChangeEvent{Error: e.Err})
-			}
-
-			switch e.Type {
-			case zk.EventNodeCreated, zk.EventNodeDataChanged, zk.EventNodeChildrenChanged:
-				// EventNodeCreated should never happen but we cover it for completeness.
-				watcher(&confmap.ChangeEvent{})
+			mutex.Lock()
+			if stopped {
+				mutex.Unlock()
 				return
 			}
-			watcher(&confmap.ChangeEvent{Error: fmt.Errorf("zookeeper watcher stopped")})
+			stopped = true
+			for _, stopWatch := range stops {
+				stopWatch()
+			}
+			mutex.Unlock()
+			if ce := watchEventToChangeEvent(e); ce != nil {
+				watcher(ce)
+			}
+		}))
+	}
+	return stop
+}
+
+// watchEventToChangeEvent translates a zookeeper watch event into the change event
+// reported to the collector. Anything that may have altered the retrieved value,
+// including the loss of the session that held the watch, is reported as a plain
+// change so that the value gets retrieved again and a new watch is set. It returns
+// nil for events caused by closing the connection, those must not be reported.
+func watchEventToChangeEvent(e zk.Event) *confmap.ChangeEvent {
+	switch e.Type {
+	case zk.EventNodeCreated, zk.EventNodeDataChanged, zk.EventNodeChildrenChanged, zk.EventNodeDeleted:
+		// EventNodeCreated should never happen but we cover it for completeness.
+		// EventNodeDeleted is reported as a change, the retrieval that follows will
+		// surface the missing znode.
+		return &confmap.ChangeEvent{}
+	case zk.EventNotWatching:
+		switch {
+		case errors.Is(e.Err, zk.ErrClosing):
+			return nil
+		case errors.Is(e.Err, zk.ErrSessionExpired):
+			return &confmap.ChangeEvent{}
 		}
-	}()
+	}
+	if e.Err != nil {
+		return &confmap.ChangeEvent{Error: e.Err}
+	}
+	return &confmap.ChangeEvent{Error: fmt.Errorf("zookeeper watcher stopped")}
 }
 
 // newConnectFunc returns a new function that can be used to establish and return a connection
-// to a zookeeper cluster. Every function returned by newConnectFunc will return the same
-// underlying connection until it is lost.
-func newConnectFunc(endpoints []string, timeout time.Duration) connectFunc {
-	var conn *zk.Conn
+// to a zookeeper cluster. Every call establishes its own connection: the watch set by a
+// retrieval lives on that connection, which is closed together with the retrieved value.
+// The ensembles are tried in order, the first one on which a session is established within
+// the timeout is used. A non-nil tlsCfg makes the connection use TLS.
+func newConnectFunc(cfg *Config, tlsCfg *tls.Config, logger *zap.Logger) connectFunc {
+	options := []zk.ConnOption{zk.WithLogInfo(false)}
+	if tlsCfg != nil {
+		options = append(options, zk.WithDialer(newTLSDialer(tlsCfg)))
+	}
+
+	ensembles := cfg.ensembles()
 	return func(ctx context.Context) (zkConnection, error) {
-		if conn != nil && conn.State() != zk.StateDisconnected {
-			return conn, nil
+		var errs error
+		for i, ensemble := range ensembles {
+			conn, err := connectEnsemble(ctx, cfg, ensemble, len(ensembles) > 1, options)
+			if err == nil {
+				if i > 0 {
+					logger.Warn("Connected to a failover zookeeper ensemble",
+						zap.Strings("endpoints", ensemble.Endpoints), zap.Error(errs))
+				}
+				return conn, nil
+			}
+			errs = multierr.Append(errs, fmt.Errorf("ensemble %v: %w", ensemble.Endpoints, err))
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		return nil, fmt.Errorf("failed to connect to zookeeper: %w", errs)
+	}
+}
+
+// connectEnsemble connects to the ensemble and waits for the session to be established.
+// If monitor is true the returned connection reports when the session is lost for longer
+// than the timeout, so other ensembles can be tried.
+func connectEnsemble(ctx context.Context, cfg *Config, ensemble EnsembleConfig, monitor bool, options []zk.ConnOption) (zkConnection, error) {
+	conn, events, err := zk.Connect(ensemble.Endpoints, cfg.Timeout, options...)
+	if err != nil {
+		return nil, err
+	}
+	if err = waitForSession(ctx, events, cfg.Timeout); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if cfg.Auth != nil {
+		credentials := []byte(cfg.Auth.Username + ":" + cfg.Auth.Password)
+		if err = conn.AddAuth(cfg.Auth.Scheme, credentials); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to authenticate zookeeper session: %w", err)
 		}
+	}
 
-		conn, _, err := zk.Connect(endpoints, timeout, zk.WithLogInfo(false))
-		if err != nil {
-			return nil, err
+	zkConn := &ensembleConn{
+		zkConnection: conn,
+		chroot:       ensemble.Chroot,
+	}
+	if monitor {
+		zkConn.lostCh = make(chan zk.Event, 1)
+		go zkConn.monitorSession(events, cfg.Timeout)
+	}
+	return zkConn, nil
+}
+
+// waitForSession waits until the session events report an established session.
+func waitForSession(ctx context.Context, events <-chan zk.Event, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return errors.New("connection closed before a session was established")
+			}
+			switch e.State {
+			case zk.StateHasSession:
+				return nil
+			case zk.StateAuthFailed:
+				return zk.ErrAuthFailed
+			}
+		case <-timer.C:
+			return fmt.Errorf("no session established within %v", timeout)
+		case <-ctx.Done():
+			return ctx.Err()
 		}
-		return conn, nil
+	}
+}
+
+func newTLSDialer(tlsCfg *tls.Config) zk.Dialer {
+	return func(network, address string, timeout time.Duration) (net.Conn, error) {
+		return tls.DialWithDialer(&net.Dialer{Timeout: timeout}, network, address, tlsCfg)
 	}
 }
@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/go-zookeeper/zk"
 	"github.com/stretchr/testify/assert"
@@ -122,3 +123,169 @@ func TestWatcher(t *testing.T) {
 		})
 	}
 }
+
+func TestWatchEventToChangeEvent(t *testing.T) {
+	testsCases := []struct {
+		expectedErr error
+		name        string
+		event       zk.Event
+		ignored     bool
+	}{
+		{name: "data-changed", event: zk.Event{Type: zk.EventNodeDataChanged}},
+		{name: "children-changed", event: zk.Event{Type: zk.EventNodeChildrenChanged}},
+		{name: "created", event: zk.Event{Type: zk.EventNodeCreated}},
+		{name: "deleted", event: zk.Event{Type: zk.EventNodeDeleted}},
+		{name: "session-expired", event: zk.Event{Type: zk.EventNotWatching, Err: zk.ErrSessionExpired}},
+		{name: "closing", event: zk.Event{Type: zk.EventNotWatching, Err: zk.ErrClosing}, ignored: true},
+		{name: "error", event: zk.Event{Type: zk.EventNotWatching, Err: zk.ErrNoServer}, expectedErr: zk.ErrNoServer},
+		{name: "unexpected", event: zk.Event{Type: zk.EventSession}, expectedErr: fmt.Errorf("zookeeper watcher stopped")},
+	}
+
+	for _, c := range testsCases {
+		t.Run(c.name, func(t *testing.T) {
+			ce := watchEventToChangeEvent(c.event)
+			if c.ignored {
+				assert.Nil(t, ce)
+				return
+			}
+			require.NotNil(t, ce)
+			if c.expectedErr != nil {
+				assert.EqualError(t, ce.Error, c.expectedErr.Error())
+				return
+			}
+			assert.NoError(t, ce.Error)
+		})
+	}
+}
+
+func TestRecursiveRetrieve(t *testing.T) {
+	conn := newMockConnection(map[string]string{
+		"/receivers/prometheus/endpoint":            "localhost:9090",
+		"/receivers/prometheus/collection_interval": "10s",
+		"/receivers/jaeger/protocols/grpc/endpoint": "0.0.0.0:14250",
+		"/token": "secret",
+	})
+	source := newZkConfigSource(configprovider.CreateParams{Logger: zap.NewNop()}, newMockConnectFunc(conn))
+	params := confmap.NewFromStringMap(map[string]any{"recursive": true})
+
+	retrieved, err := source.Retrieve(context.Background(), "/receivers", params, nil)
+	require.NoError(t, err)
+	val, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"prometheus": map[string]any{
+			"endpoint":            "localhost:9090",
+			"collection_interval": "10s",
+		},
+		"jaeger": map[string]any{
+			"protocols": map[string]any{
+				"grpc": map[string]any{
+					"endpoint": "0.0.0.0:14250",
+				},
+			},
+		},
+	}, val)
+	require.NoError(t, retrieved.Close(context.Background()))
+
+	retrieved, err = source.Retrieve(context.Background(), "/token", params, nil)
+	require.NoError(t, err)
+	val, err = retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, "secret", val)
+	require.NoError(t, retrieved.Close(context.Background()))
+
+	_, err = source.Retrieve(context.Background(), "/missing", params, nil)
+	assert.Error(t, err)
+
+	_, err = source.Retrieve(context.Background(), "/receivers", confmap.NewFromStringMap(map[string]any{"unknown": true}), nil)
+	assert.IsType(t, &errInvalidParams{}, err)
+
+	assert.NoError(t, source.Shutdown(context.Background()))
+}
+
+func TestRecursiveWatcher(t *testing.T) {
+	conn := newMockConnection(map[string]string{
+		"/receivers/prometheus/endpoint": "localhost:9090",
+		"/receivers/jaeger/endpoint":     "0.0.0.0:14250",
+	})
+	source := newZkConfigSource(configprovider.CreateParams{Logger: zap.NewNop()}, newMockConnectFunc(conn))
+
+	watchChannel := make(chan *confmap.ChangeEvent, 1)
+	retrieved, err := source.Retrieve(context.Background(), "/receivers", confmap.NewFromStringMap(map[string]any{"recursive": true}), func(ce *confmap.ChangeEvent) {
+		watchChannel <- ce
+	})
+	require.NoError(t, err)
+	require.NotNil(t, conn.watcherCh)
+
+	conn.watcherCh <- zk.Event{Type: zk.EventNodeChildrenChanged, Path: "/receivers/jaeger"}
+	ce := <-watchChannel
+	assert.NoError(t, ce.Error)
+
+	assert.NoError(t, retrieved.Close(context.Background()))
+	assert.Nil(t, conn.watcherCh)
+	assert.NoError(t, source.Shutdown(context.Background()))
+}
+
+func TestEnsembleConnChroot(t *testing.T) {
+	conn := &ensembleConn{
+		zkConnection: newMockConnection(map[string]string{
+			"/collector/token":                "secret",
+			"/collector/receivers/a/endpoint": "localhost:9090",
+		}),
+		chroot: "/collector",
+	}
+	source := newZkConfigSource(configprovider.CreateParams{Logger: zap.NewNop()}, newMockConnectFunc(conn))
+
+	retrieved, err := source.Retrieve(context.Background(), "/token", nil, nil)
+	require.NoError(t, err)
+	val, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, "secret", val)
+	require.NoError(t, retrieved.Close(context.Background()))
+
+	retrieved, err = source.Retrieve(context.Background(), "/receivers", confmap.NewFromStringMap(map[string]any{"recursive": true}), nil)
+	require.NoError(t, err)
+	val, err = retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": map[string]any{"endpoint": "localhost:9090"}}, val)
+	require.NoError(t, retrieved.Close(context.Background()))
+
+	assert.NoError(t, source.Shutdown(context.Background()))
+}
+
+func TestEnsembleConnSessionLost(t *testing.T) {
+	mock := newMockConnection(map[string]string{"/token": "secret"})
+	conn := &ensembleConn{
+		zkConnection: mock,
+		lostCh:       make(chan zk.Event, 1),
+	}
+	events := make(chan zk.Event)
+	go conn.monitorSession(events, 10*time.Millisecond)
+	source := newZkConfigSource(configprovider.CreateParams{Logger: zap.NewNop()}, newMockConnectFunc(conn))
+
+	watchChannel := make(chan *confmap.ChangeEvent, 1)
+	retrieved, err := source.Retrieve(context.Background(), "/token", nil, func(ce *confmap.ChangeEvent) {
+		watchChannel <- ce
+	})
+	require.NoError(t, err)
+
+	// Reconnecting within the timeout keeps the session.
+	events <- zk.Event{Type: zk.EventSession, State: zk.StateDisconnected}
+	events <- zk.Event{Type: zk.EventSession, State: zk.StateHasSession}
+	select {
+	case <-watchChannel:
+		t.Fatal("unexpected change event")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Staying disconnected is reported as a change so the value is retrieved again.
+	events <- zk.Event{Type: zk.EventSession, State: zk.StateDisconnected}
+	ce := <-watchChannel
+	assert.NoError(t, ce.Error)
+
+	assert.NoError(t, retrieved.Close(context.Background()))
+	close(events)
+	_, ok := <-conn.SessionLost()
+	assert.False(t, ok)
+	assert.NoError(t, source.Shutdown(context.Background()))
+}
@@ -17,10 +17,21 @@
 package configsources
 
 import (
+	"fmt"
+
 	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+	"github.com/signalfx/splunk-otel-collector/internal/configsource/ageconfigsource"
 	"github.com/signalfx/splunk-otel-collector/internal/configsource/envvarconfigsource"
 	"github.com/signalfx/splunk-otel-collector/internal/configsource/etcd2configsource"
+	"github.com/signalfx/splunk-otel-collector/internal/configsource/execconfigsource"
+	"github.com/signalfx/splunk-otel-collector/internal/configsource/fallbackconfigsource"
+	"github.com/signalfx/splunk-otel-collector/internal/configsource/grpcpluginconfigsource"
 	"github.com/signalfx/splunk-otel-collector/internal/configsource/includeconfigsource"
+	"github.com/signalfx/splunk-otel-collector/internal/configsource/o11yconfigsource"
+	"github.com/signalfx/splunk-otel-collector/internal/configsource/pkcs11configsource"
+	"github.com/signalfx/splunk-otel-collector/internal/configsource/sopsconfigsource"
+	"github.com/signalfx/splunk-otel-collector/internal/configsource/tfstateconfigsource"
+	"github.com/signalfx/splunk-otel-collector/internal/configsource/tpmconfigsource"
 	"github.com/signalfx/splunk-otel-collector/internal/configsource/vaultconfigsource"
 	"github.com/signalfx/splunk-otel-collector/internal/configsource/zookeeperconfigsource"
 )
@@ -28,10 +39,39 @@ import (
 // Get returns the factories to all config sources available to the user.
 func Get() []configprovider.Factory {
 	return []configprovider.Factory{
+		ageconfigsource.NewFactory(),
 		envvarconfigsource.NewFactory(),
 		etcd2configsource.NewFactory(),
+		execconfigsource.NewFactory(),
+		fallbackconfigsource.NewFactory(),
+		grpcpluginconfigsource.NewFactory(),
 		includeconfigsource.NewFactory(),
+		o11yconfigsource.NewFactory(),
+		pkcs11configsource.NewFactory(),
+		sopsconfigsource.NewFactory(),
+		tfstateconfigsource.NewFactory(),
+		tpmconfigsource.NewFactory(),
 		vaultconfigsource.NewFactory(),
 		zookeeperconfigsource.NewFactory(),
 	}
 }
+
+// WithPlugins returns the factories along with the ones of the config source plugins in the
+// directory, see grpcpluginconfigsource.PluginFactories. Plugins can't replace the factories.
+func WithPlugins(factories []configprovider.Factory, dir string) ([]configprovider.Factory, error) {
+	plugins, err := grpcpluginconfigsource.PluginFactories(dir)
+	if err != nil {
+		return nil, err
+	}
+	types := make(map[string]bool, len(factories))
+	for _, factory := range factories {
+		types[string(factory.Type())] = true
+	}
+	for _, plugin := range plugins {
+		if types[string(plugin.Type())] {
+			return nil, fmt.Errorf("config source plugin %q conflicts with an existing config source", plugin.Type())
+		}
+		types[string(plugin.Type())] = true
+	}
+	return append(factories, plugins...), nil
+}
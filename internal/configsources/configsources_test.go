@@ -16,6 +16,9 @@
 package configsources
 
 import (
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -29,9 +32,18 @@ func TestConfigSourcesGet(t *testing.T) {
 	tests := []struct {
 		configSourceType component.Type
 	}{
+		{"age"},
 		{"env"},
 		{"etcd2"},
+		{"exec"},
+		{"fallback"},
+		{"grpcplugin"},
 		{"include"},
+		{"o11y"},
+		{"pkcs11"},
+		{"sops"},
+		{"tfstate"},
+		{"tpm"},
 		{"vault"},
 		{"zookeeper"},
 	}
@@ -59,3 +71,20 @@ func TestConfigSourcesGet(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigSourcesWithPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test plugins aren't executables")
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "otelcol-configsource-custom"), nil, 0o700))
+	factories, err := WithPlugins(Get(), dir)
+	require.NoError(t, err)
+	require.Len(t, factories, len(Get())+1)
+	assert.Equal(t, component.Type("custom"), factories[len(factories)-1].Type())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "otelcol-configsource-env"), nil, 0o700))
+	_, err = WithPlugins(Get(), dir)
+	require.EqualError(t, err, `config source plugin "env" conflicts with an existing config source`)
+}
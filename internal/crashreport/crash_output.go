@@ -0,0 +1,36 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.23
+// +build go1.23
+
+package crashreport
+
+import (
+	"os"
+	"runtime/debug"
+)
+
+// setCrashOutput makes the Go runtime write to the given file, in addition to
+// stderr, the output of fatal errors and unrecovered panics on any goroutine.
+func setCrashOutput(f *os.File) error {
+	defer f.Close()
+	return debug.SetCrashOutput(f, debug.CrashOptions{})
+}
+
+// disableCrashOutput stops writing the runtime crash output to the file set via setCrashOutput.
+func disableCrashOutput() {
+	_ = debug.SetCrashOutput(nil, debug.CrashOptions{})
+}
@@ -0,0 +1,31 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !go1.23
+// +build !go1.23
+
+package crashreport
+
+import (
+	"os"
+)
+
+// setCrashOutput is not supported before Go 1.23, only panics recovered via
+// Reporter.Recover are reported.
+func setCrashOutput(f *os.File) error {
+	return f.Close()
+}
+
+func disableCrashOutput() {}
@@ -0,0 +1,455 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package crashreport captures reports of collector crashes into a local spool
+// directory so they can be collected, or sent to a configured endpoint, later.
+package crashreport
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	crashReportDirEnvVar      = "SPLUNK_CRASH_REPORT_DIR"
+	crashReportEndpointEnvVar = "SPLUNK_CRASH_REPORT_ENDPOINT"
+
+	metricsAddressKey     = "service::telemetry::metrics::address"
+	defaultMetricsAddress = "localhost:8888"
+
+	reportFilePrefix    = "crash-"
+	reportFileExtension = ".json"
+	// crashOutputFile receives the output of the Go runtime for fatal errors and
+	// unrecovered panics, see setCrashOutput.
+	crashOutputFile = "crash-output.log"
+	// previousCrashOutputFile keeps the crash output of the previous run until the
+	// configuration is resolved and it can be reported with its secrets redacted.
+	previousCrashOutputFile = "crash-output.previous.log"
+	// stateFile keeps the information about the running collector that is needed
+	// to build a report from the crash output on the next start.
+	stateFile = "state.json"
+
+	maxStackSize   = 1 << 20
+	requestTimeout = 10 * time.Second
+
+	redactedValue = "<redacted>"
+)
+
+var _ confmap.Converter = (*Reporter)(nil)
+
+// Report is the content of a crash report. It does not include the configuration,
+// only its hash, to avoid leaking secrets. The values of the configuration that are
+// redacted, see SetRedaction, are also redacted from the rest of the report.
+type Report struct {
+	Time            time.Time `json:"time"`
+	Version         string    `json:"version"`
+	Reason          string    `json:"reason"`
+	Stack           string    `json:"stack"`
+	ConfigHash      string    `json:"config_hash,omitempty"`
+	InternalMetrics string    `json:"internal_metrics,omitempty"`
+}
+
+// state is the information about the running collector kept on the stateFile.
+type state struct {
+	Version    string `json:"version"`
+	ConfigHash string `json:"config_hash"`
+}
+
+// Reporter writes crash reports to the directory specified via the SPLUNK_CRASH_REPORT_DIR
+// environment variable. If the SPLUNK_CRASH_REPORT_ENDPOINT environment variable is set
+// the reports are posted to it on the next start of the collector. It is a no-op if
+// SPLUNK_CRASH_REPORT_DIR is not set.
+type Reporter struct {
+	client *http.Client
+	redact func(config map[string]any) map[string]any
+	// secrets are the values of the configuration that are redacted from the reports.
+	secrets    *strings.Replacer
+	dir        string
+	endpoint   string
+	metricsURL string
+	state      state
+	mutex      sync.RWMutex
+}
+
+// New creates a Reporter configured via environment variables.
+func New(buildInfo component.BuildInfo) *Reporter {
+	return &Reporter{
+		client:     &http.Client{Timeout: requestTimeout},
+		dir:        os.Getenv(crashReportDirEnvVar),
+		endpoint:   os.Getenv(crashReportEndpointEnvVar),
+		metricsURL: metricsURL(defaultMetricsAddress),
+		state:      state{Version: buildInfo.Version},
+	}
+}
+
+// SetRedaction sets the function redacting the configuration, the values it redacts
+// are replaced by "<redacted>" in the crash reports. It must be the one used for
+// the other reports of the configuration, e.g. the --dry-run output.
+func (r *Reporter) SetRedaction(redact func(config map[string]any) map[string]any) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.redact = redact
+}
+
+func (r *Reporter) enabled() bool {
+	return r != nil && r.dir != ""
+}
+
+// Start prepares the spool directory, keeps the output of a previous crash, if any,
+// to be reported once the configuration is resolved, see Convert, and captures the
+// output of future crashes. Pending reports are sent to the configured endpoint in
+// the background.
+func (r *Reporter) Start() {
+	if !r.enabled() {
+		return
+	}
+
+	if err := os.MkdirAll(r.dir, 0700); err != nil {
+		log.Printf("failed to create crash report directory %q: %v", r.dir, err)
+		return
+	}
+
+	r.keepPreviousCrashOutput()
+
+	crashOutput, err := os.OpenFile(filepath.Join(r.dir, crashOutputFile), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		log.Printf("failed to open crash output file: %v", err)
+	} else if err = setCrashOutput(crashOutput); err != nil {
+		log.Printf("failed to set crash output: %v", err)
+	}
+
+	if r.endpoint != "" {
+		go r.SendPending(context.Background())
+	}
+}
+
+// Convert is intended to be called as the final service confmap.Converter, it records
+// the hash of the effective configuration, the address of the internal metrics, and
+// the values to be redacted from the reports. The output of a previous crash, if any,
+// is reported once they are known.
+func (r *Reporter) Convert(_ context.Context, conf *confmap.Conf) error {
+	if !r.enabled() {
+		return nil
+	}
+
+	config := conf.ToStringMap()
+	cfg, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config for crash report hash: %w", err)
+	}
+
+	r.mutex.Lock()
+	r.state.ConfigHash = fmt.Sprintf("sha256:%x", sha256.Sum256(cfg))
+	if address, ok := conf.Get(metricsAddressKey).(string); ok && address != "" {
+		r.metricsURL = metricsURL(address)
+	}
+	if r.redact != nil {
+		r.secrets = secretsReplacer(redactedValues(config, r.redact(config)))
+	}
+	currentState := r.state
+	r.mutex.Unlock()
+
+	if err = writeJSON(filepath.Join(r.dir, stateFile), currentState); err != nil {
+		log.Printf("failed to write crash report state: %v", err)
+	}
+	r.reportPreviousCrashOutput()
+	return nil
+}
+
+// Recover must be deferred on the main goroutine. In case of panic it writes a crash
+// report and panics again with the same value.
+func (r *Reporter) Recover() {
+	if !r.enabled() {
+		return
+	}
+
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	stack := make([]byte, maxStackSize)
+	stack = stack[:runtime.Stack(stack, true)]
+
+	r.mutex.RLock()
+	currentState := r.state
+	metricsURL := r.metricsURL
+	r.mutex.RUnlock()
+
+	report := Report{
+		Time:            time.Now().UTC(),
+		Version:         currentState.Version,
+		Reason:          r.redactSecrets(fmt.Sprintf("panic: %v", recovered)),
+		Stack:           r.redactSecrets(string(stack)),
+		ConfigHash:      currentState.ConfigHash,
+		InternalMetrics: r.redactSecrets(r.fetchInternalMetrics(metricsURL)),
+	}
+	if err := r.writeReport(report); err != nil {
+		log.Printf("failed to write crash report: %v", err)
+	}
+
+	// The panic is already reported, avoid reporting it again from the crash output.
+	disableCrashOutput()
+	panic(recovered)
+}
+
+// SendPending posts the reports on the spool directory to the configured endpoint,
+// removing the reports that were successfully sent.
+func (r *Reporter) SendPending(ctx context.Context) {
+	if !r.enabled() || r.endpoint == "" {
+		return
+	}
+
+	files, err := filepath.Glob(filepath.Join(r.dir, reportFilePrefix+"*"+reportFileExtension))
+	if err != nil {
+		log.Printf("failed to list crash reports: %v", err)
+		return
+	}
+
+	for _, file := range files {
+		if err = r.send(ctx, file); err != nil {
+			log.Printf("failed to send crash report %q: %v", file, err)
+			continue
+		}
+		if err = os.Remove(file); err != nil {
+			log.Printf("failed to remove sent crash report %q: %v", file, err)
+		}
+	}
+}
+
+func (r *Reporter) send(ctx context.Context, file string) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response status %q", resp.Status)
+	}
+	return nil
+}
+
+// keepPreviousCrashOutput moves the crash output file of the previous run of the
+// collector, if not empty, so it is not truncated before being reported.
+func (r *Reporter) keepPreviousCrashOutput() {
+	crashOutputPath := filepath.Join(r.dir, crashOutputFile)
+	output, err := os.ReadFile(crashOutputPath)
+	if err != nil || len(bytes.TrimSpace(output)) == 0 {
+		return
+	}
+	if err = os.Rename(crashOutputPath, filepath.Join(r.dir, previousCrashOutputFile)); err != nil {
+		log.Printf("failed to keep previous crash output: %v", err)
+	}
+}
+
+// reportPreviousCrashOutput creates a report, with the secrets of the current configuration
+// redacted, from the crash output kept by keepPreviousCrashOutput, if any.
+func (r *Reporter) reportPreviousCrashOutput() {
+	crashOutputPath := filepath.Join(r.dir, previousCrashOutputFile)
+	output, err := os.ReadFile(crashOutputPath)
+	if err != nil {
+		return
+	}
+	redacted := r.redactSecrets(string(output))
+
+	var previous state
+	if content, stateErr := os.ReadFile(filepath.Join(r.dir, stateFile)); stateErr == nil {
+		_ = json.Unmarshal(content, &previous)
+	}
+
+	modTime := time.Now()
+	if info, statErr := os.Stat(crashOutputPath); statErr == nil {
+		modTime = info.ModTime()
+	}
+
+	report := Report{
+		Time:       modTime.UTC(),
+		Version:    previous.Version,
+		Reason:     firstLine(redacted),
+		Stack:      redacted,
+		ConfigHash: previous.ConfigHash,
+	}
+	if err = r.writeReport(report); err != nil {
+		log.Printf("failed to write crash report for previous crash output: %v", err)
+		return
+	}
+	if err = os.Remove(crashOutputPath); err != nil {
+		log.Printf("failed to remove reported crash output: %v", err)
+	}
+}
+
+// redactSecrets replaces the values redacted from the configuration by "<redacted>".
+func (r *Reporter) redactSecrets(s string) string {
+	r.mutex.RLock()
+	secrets := r.secrets
+	r.mutex.RUnlock()
+	if secrets == nil {
+		return s
+	}
+	return secrets.Replace(s)
+}
+
+// redactedValues returns the string form of the values of config that differ on its
+// redacted version.
+func redactedValues(config, redacted map[string]any) []string {
+	var values []string
+	for k, v := range config {
+		redactedV := redacted[k]
+		if reflect.DeepEqual(v, redactedV) {
+			continue
+		}
+		vMap, isMap := toStringMap(v)
+		redactedMap, isRedactedMap := toStringMap(redactedV)
+		switch {
+		case isMap && isRedactedMap:
+			values = append(values, redactedValues(vMap, redactedMap)...)
+		case isMap:
+			values = append(values, redactedValues(vMap, nil)...)
+		case v != nil:
+			values = append(values, fmt.Sprintf("%v", v))
+		}
+	}
+	return values
+}
+
+func toStringMap(v any) (map[string]any, bool) {
+	switch m := v.(type) {
+	case map[string]any:
+		return m, true
+	case map[any]any:
+		out := make(map[string]any, len(m))
+		for k, val := range m {
+			out[fmt.Sprintf("%v", k)] = val
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+// secretsReplacer returns a strings.Replacer of the given values by "<redacted>",
+// or nil if there are no values. Longer values are replaced first so values that
+// contain others are fully redacted.
+func secretsReplacer(values []string) *strings.Replacer {
+	unique := map[string]struct{}{}
+	for _, v := range values {
+		if v != "" && v != redactedValue {
+			unique[v] = struct{}{}
+		}
+	}
+	if len(unique) == 0 {
+		return nil
+	}
+	sorted := make([]string, 0, len(unique))
+	for v := range unique {
+		sorted = append(sorted, v)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i]) != len(sorted[j]) {
+			return len(sorted[i]) > len(sorted[j])
+		}
+		return sorted[i] < sorted[j]
+	})
+	oldnew := make([]string, 0, 2*len(sorted))
+	for _, v := range sorted {
+		oldnew = append(oldnew, v, redactedValue)
+	}
+	return strings.NewReplacer(oldnew...)
+}
+
+func (r *Reporter) writeReport(report Report) error {
+	file := filepath.Join(r.dir, fmt.Sprintf("%s%d%s", reportFilePrefix, report.Time.UnixNano(), reportFileExtension))
+	if err := writeJSON(file, report); err != nil {
+		return err
+	}
+	log.Printf("crash report written to %q", file)
+	return nil
+}
+
+// fetchInternalMetrics returns the current internal metrics of the collector in
+// the Prometheus exposition format, or an empty string if they are not available.
+func (r *Reporter) fetchInternalMetrics(url string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return ""
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+
+	metrics, err := io.ReadAll(resp.Body)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return ""
+	}
+	return string(metrics)
+}
+
+func metricsURL(address string) string {
+	if strings.HasPrefix(address, ":") {
+		address = "localhost" + address
+	}
+	return "http://" + address + "/metrics"
+}
+
+func writeJSON(file string, v any) error {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, content, 0600)
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i > -1 {
+		return s[:i]
+	}
+	return s
+}
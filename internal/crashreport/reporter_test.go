@@ -0,0 +1,209 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crashreport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+func TestReporterDisabled(t *testing.T) {
+	t.Setenv(crashReportDirEnvVar, "")
+	reporter := New(component.BuildInfo{Version: "v0.0.1"})
+	reporter.Start()
+	require.NoError(t, reporter.Convert(context.Background(), confmap.New()))
+	reporter.SendPending(context.Background())
+
+	assert.PanicsWithValue(t, "boom", func() {
+		defer reporter.Recover()
+		panic("boom")
+	})
+}
+
+func TestReporterRecover(t *testing.T) {
+	metricsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("otelcol_process_uptime 42\n"))
+	}))
+	defer metricsServer.Close()
+
+	dir := t.TempDir()
+	t.Setenv(crashReportDirEnvVar, dir)
+	reporter := New(component.BuildInfo{Version: "v0.0.1"})
+	reporter.Start()
+	defer disableCrashOutput()
+
+	conf := confmap.NewFromStringMap(map[string]any{
+		"service": map[string]any{
+			"telemetry": map[string]any{
+				"metrics": map[string]any{"address": metricsServer.Listener.Addr().String()},
+			},
+		},
+	})
+	require.NoError(t, reporter.Convert(context.Background(), conf))
+
+	assert.PanicsWithValue(t, "boom", func() {
+		defer reporter.Recover()
+		panic("boom")
+	})
+
+	reports := readReports(t, dir)
+	require.Len(t, reports, 1)
+	report := reports[0]
+	assert.Equal(t, "v0.0.1", report.Version)
+	assert.Equal(t, "panic: boom", report.Reason)
+	assert.Contains(t, report.Stack, "TestReporterRecover")
+	assert.Regexp(t, "^sha256:[0-9a-f]{64}$", report.ConfigHash)
+	assert.Equal(t, "otelcol_process_uptime 42\n", report.InternalMetrics)
+
+	var st state
+	content, err := os.ReadFile(filepath.Join(dir, stateFile))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(content, &st))
+	assert.Equal(t, state{Version: "v0.0.1", ConfigHash: report.ConfigHash}, st)
+}
+
+func TestReporterPreviousCrashOutput(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, writeJSON(filepath.Join(dir, stateFile), state{Version: "v0.0.0", ConfigHash: "sha256:abc"}))
+	crashOutput := "fatal error: concurrent map writes\n\ngoroutine 1 [running]:\nmain.main()\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, crashOutputFile), []byte(crashOutput), 0600))
+
+	t.Setenv(crashReportDirEnvVar, dir)
+	reporter := New(component.BuildInfo{Version: "v0.0.1"})
+	reporter.Start()
+	defer disableCrashOutput()
+
+	// The crash output is only reported once the configuration is resolved.
+	assert.Empty(t, readReports(t, dir))
+	require.NoError(t, reporter.Convert(context.Background(), confmap.New()))
+
+	reports := readReports(t, dir)
+	require.Len(t, reports, 1)
+	assert.Equal(t, "v0.0.0", reports[0].Version)
+	assert.Equal(t, "fatal error: concurrent map writes", reports[0].Reason)
+	assert.Equal(t, crashOutput, reports[0].Stack)
+	assert.Equal(t, "sha256:abc", reports[0].ConfigHash)
+
+	// The crash output of the previous run is not reported again.
+	reporter.Start()
+	require.NoError(t, reporter.Convert(context.Background(), confmap.New()))
+	assert.Len(t, readReports(t, dir), 1)
+}
+
+func TestReporterRedaction(t *testing.T) {
+	dir := t.TempDir()
+	crashOutput := "panic: invalid token s3cr3t-t0ken\n\ngoroutine 1 [running]:\nmain.main()\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, crashOutputFile), []byte(crashOutput), 0600))
+
+	t.Setenv(crashReportDirEnvVar, dir)
+	reporter := New(component.BuildInfo{Version: "v0.0.1"})
+	reporter.SetRedaction(func(config map[string]any) map[string]any {
+		return map[string]any{
+			"exporters": map[string]any{
+				"signalfx": map[string]any{"access_token": "<redacted>", "realm": "us0"},
+			},
+		}
+	})
+	reporter.Start()
+	defer disableCrashOutput()
+
+	conf := confmap.NewFromStringMap(map[string]any{
+		"exporters": map[string]any{
+			"signalfx": map[string]any{"access_token": "s3cr3t-t0ken", "realm": "us0"},
+		},
+	})
+	require.NoError(t, reporter.Convert(context.Background(), conf))
+
+	reports := readReports(t, dir)
+	require.Len(t, reports, 1)
+	assert.Equal(t, "panic: invalid token <redacted>", reports[0].Reason)
+	assert.NotContains(t, reports[0].Stack, "s3cr3t-t0ken")
+	files, err := filepath.Glob(filepath.Join(dir, reportFilePrefix+"*"+reportFileExtension))
+	require.NoError(t, err)
+	for _, file := range files {
+		require.NoError(t, os.Remove(file))
+	}
+
+	assert.Panics(t, func() {
+		defer reporter.Recover()
+		panic(fmt.Errorf("failed to authenticate with token %q in us0", "s3cr3t-t0ken"))
+	})
+
+	reports = readReports(t, dir)
+	require.Len(t, reports, 1)
+	assert.Equal(t, `panic: failed to authenticate with token "<redacted>" in us0`, reports[0].Reason)
+	assert.NotContains(t, reports[0].Stack, "s3cr3t-t0ken")
+}
+
+func TestReporterSendPending(t *testing.T) {
+	var received []Report
+	fail := true
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var report Report
+		require.NoError(t, json.Unmarshal(body, &report))
+		received = append(received, report)
+	}))
+	defer endpoint.Close()
+
+	dir := t.TempDir()
+	t.Setenv(crashReportDirEnvVar, dir)
+	t.Setenv(crashReportEndpointEnvVar, endpoint.URL)
+	reporter := New(component.BuildInfo{Version: "v0.0.1"})
+	require.NoError(t, reporter.writeReport(Report{Reason: "panic: boom"}))
+
+	reporter.SendPending(context.Background())
+	assert.Empty(t, received)
+	assert.Len(t, readReports(t, dir), 1)
+
+	fail = false
+	reporter.SendPending(context.Background())
+	require.Len(t, received, 1)
+	assert.Equal(t, "panic: boom", received[0].Reason)
+	assert.Empty(t, readReports(t, dir))
+}
+
+func readReports(t *testing.T, dir string) []Report {
+	files, err := filepath.Glob(filepath.Join(dir, reportFilePrefix+"*"+reportFileExtension))
+	require.NoError(t, err)
+
+	var reports []Report
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		var report Report
+		require.NoError(t, json.Unmarshal(content, &report))
+		reports = append(reports, report)
+	}
+	return reports
+}
@@ -0,0 +1,274 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package loglevel allows the level of the collector logger to be changed at
+// runtime, globally or for the loggers of specific components.
+package loglevel
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// HandlerPath is the path in which the Controller http.Handler is expected to be registered.
+	HandlerPath = "/debug/loglevel"
+
+	// Keys of the fields added by the collector service to the loggers of components.
+	componentKindKey = "kind"
+	componentNameKey = "name"
+)
+
+var _ http.Handler = (*Controller)(nil)
+
+// Controller holds the default level of the collector logger and the levels
+// overriding it for the loggers of specific components. The levels are used
+// by the zapcore.Core returned by WrapCore, so changes take effect immediately
+// without the need to restart the collector.
+//
+// The levels from the collector configuration are applied via SetConfiguredLevels
+// on every configuration load. The levels set at runtime, via SetLevel,
+// SetComponentLevel or the http.Handler, take precedence over the configured ones
+// and are kept across configuration reloads until they are reset.
+type Controller struct {
+	configuredComponentLevels map[string]zapcore.Level
+	componentLevels           map[string]zapcore.Level
+	levelOverride             *zapcore.Level
+	level                     zap.AtomicLevel
+	configuredLevel           zapcore.Level
+	mutex                     sync.RWMutex
+}
+
+// NewController creates a new Controller using zapcore.InfoLevel as default level.
+func NewController() *Controller {
+	return &Controller{
+		configuredComponentLevels: map[string]zapcore.Level{},
+		componentLevels:           map[string]zapcore.Level{},
+		configuredLevel:           zapcore.InfoLevel,
+		level:                     zap.NewAtomicLevelAt(zapcore.InfoLevel),
+	}
+}
+
+// Level returns the default level of the collector logger.
+func (c *Controller) Level() zapcore.Level {
+	return c.level.Level()
+}
+
+// SetLevel overrides the default level of the collector logger at runtime.
+func (c *Controller) SetLevel(level zapcore.Level) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.levelOverride = &level
+	c.level.SetLevel(level)
+}
+
+// ResetLevel removes the runtime override, if any, of the default level so the
+// configured one is used again.
+func (c *Controller) ResetLevel() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.levelOverride = nil
+	c.level.SetLevel(c.configuredLevel)
+}
+
+// ComponentLevels returns a copy of the levels overriding the default level for the given component IDs,
+// the runtime overrides take precedence over the configured levels.
+func (c *Controller) ComponentLevels() map[string]zapcore.Level {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	levels := make(map[string]zapcore.Level, len(c.configuredComponentLevels)+len(c.componentLevels))
+	for id, level := range c.configuredComponentLevels {
+		levels[id] = level
+	}
+	for id, level := range c.componentLevels {
+		levels[id] = level
+	}
+	return levels
+}
+
+// SetComponentLevel overrides at runtime the default level for the loggers of the
+// components with the given ID, e.g.: "signalfx" or "otlp/secondary".
+func (c *Controller) SetComponentLevel(componentID string, level zapcore.Level) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.componentLevels[componentID] = level
+}
+
+// ResetComponentLevel removes the runtime level override, if any, for the components
+// with the given ID so the configured level, if any, is used again.
+func (c *Controller) ResetComponentLevel(componentID string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.componentLevels, componentID)
+}
+
+// SetConfiguredLevels replaces the default level and the component levels from the
+// collector configuration by the given ones. Runtime overrides are not affected.
+func (c *Controller) SetConfiguredLevels(level zapcore.Level, componentLevels map[string]zapcore.Level) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.configuredLevel = level
+	if c.levelOverride == nil {
+		c.level.SetLevel(level)
+	}
+	c.configuredComponentLevels = make(map[string]zapcore.Level, len(componentLevels))
+	for id, componentLevel := range componentLevels {
+		c.configuredComponentLevels[id] = componentLevel
+	}
+}
+
+func (c *Controller) enabled(componentID string, level zapcore.Level) bool {
+	if componentID != "" {
+		c.mutex.RLock()
+		componentLevel, ok := c.componentLevels[componentID]
+		if !ok {
+			componentLevel, ok = c.configuredComponentLevels[componentID]
+		}
+		c.mutex.RUnlock()
+		if ok {
+			return componentLevel.Enabled(level)
+		}
+	}
+	return c.level.Enabled(level)
+}
+
+// WrapCore wraps the given zapcore.Core so its level is controlled by the Controller.
+// It is intended to be used with zap.WrapCore when building the collector logger.
+func (c *Controller) WrapCore(core zapcore.Core) zapcore.Core {
+	return &levelCore{
+		Core:       core,
+		controller: c,
+	}
+}
+
+// ServeHTTP reports the current levels on GET requests and changes them on PUT
+// requests. The level is specified via the "level" query parameter and applied
+// to the component specified by the "component" query parameter, if present, or
+// as the default level otherwise. DELETE requests remove the runtime level override
+// for the component specified by the "component" query parameter, if present, or
+// for the default level otherwise. Only requests from the loopback interface are
+// allowed to change the levels.
+func (c *Controller) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	componentID := request.URL.Query().Get("component")
+	if (request.Method == http.MethodPut || request.Method == http.MethodDelete) && !isLoopback(request.RemoteAddr) {
+		http.Error(writer, "log levels can only be changed from localhost", http.StatusForbidden)
+		return
+	}
+	switch request.Method {
+	case http.MethodGet:
+		// Nothing to change, just report the current levels below.
+	case http.MethodPut:
+		var level zapcore.Level
+		if err := level.UnmarshalText([]byte(request.URL.Query().Get("level"))); err != nil {
+			http.Error(writer, fmt.Sprintf("invalid level: %v", err), http.StatusBadRequest)
+			return
+		}
+		if componentID == "" {
+			c.SetLevel(level)
+		} else {
+			c.SetComponentLevel(componentID, level)
+		}
+	case http.MethodDelete:
+		if componentID == "" {
+			c.ResetLevel()
+		} else {
+			c.ResetComponentLevel(componentID)
+		}
+	default:
+		writer.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	componentLevels := map[string]string{}
+	for id, level := range c.ComponentLevels() {
+		componentLevels[id] = level.String()
+	}
+	out, _ := yaml.Marshal(map[string]any{
+		"level":      c.Level().String(),
+		"components": componentLevels,
+	})
+	_, _ = writer.Write(out)
+}
+
+// isLoopback returns true if the given remote address, in the "host:port" form
+// used by http.Request.RemoteAddr, is a loopback address.
+func isLoopback(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// levelCore is a zapcore.Core that delegates to the wrapped core the writing of
+// the entries enabled according to the levels held by its Controller.
+type levelCore struct {
+	zapcore.Core
+	controller *Controller
+	// componentID is the ID of the component that owns the logger, empty
+	// if the logger is not a component logger.
+	componentID string
+}
+
+func (lc *levelCore) Enabled(level zapcore.Level) bool {
+	return lc.controller.enabled(lc.componentID, level)
+}
+
+func (lc *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{
+		Core:        lc.Core.With(fields),
+		controller:  lc.controller,
+		componentID: componentIDFromFields(fields, lc.componentID),
+	}
+}
+
+func (lc *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	// The level of the wrapped core is not checked since the Controller can
+	// enable levels that were not enabled when the wrapped core was built.
+	if lc.Enabled(entry.Level) {
+		return checked.AddCore(entry, lc)
+	}
+	return checked
+}
+
+// componentIDFromFields returns the component ID if the given fields are the ones
+// added by the collector service to component loggers, otherwise it returns the
+// given current ID.
+func componentIDFromFields(fields []zapcore.Field, currentID string) string {
+	var hasKind bool
+	var name string
+	for _, field := range fields {
+		if field.Type != zapcore.StringType {
+			continue
+		}
+		switch field.Key {
+		case componentKindKey:
+			hasKind = true
+		case componentNameKey:
+			name = field.String
+		}
+	}
+	if hasKind && name != "" {
+		return name
+	}
+	return currentID
+}
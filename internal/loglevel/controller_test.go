@@ -0,0 +1,165 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loglevel
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gopkg.in/yaml.v2"
+)
+
+func TestControllerWrapCore(t *testing.T) {
+	controller := NewController()
+	observedCore, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(observedCore, zap.WrapCore(controller.WrapCore))
+
+	signalfxLogger := logger.With(zap.String("kind", "exporter"), zap.String("data_type", "metrics"), zap.String("name", "signalfx"))
+	otlpLogger := logger.With(zap.String("kind", "receiver"), zap.String("name", "otlp"))
+	// A "name" field without the component "kind" is not a component logger.
+	namedLogger := logger.With(zap.String("name", "signalfx"))
+
+	logAll := func() {
+		logger.Debug("service")
+		signalfxLogger.Debug("signalfx")
+		signalfxLogger.With(zap.String("some", "field")).Debug("signalfx child")
+		otlpLogger.Debug("otlp")
+		namedLogger.Debug("named")
+	}
+
+	logAll()
+	assert.Zero(t, logs.Len())
+
+	controller.SetComponentLevel("signalfx", zapcore.DebugLevel)
+	logAll()
+	assert.Equal(t, []string{"signalfx", "signalfx child"}, messages(logs.TakeAll()))
+
+	controller.SetLevel(zapcore.DebugLevel)
+	controller.SetComponentLevel("signalfx", zapcore.ErrorLevel)
+	logAll()
+	assert.Equal(t, []string{"service", "otlp", "named"}, messages(logs.TakeAll()))
+
+	controller.ResetComponentLevel("signalfx")
+	controller.SetLevel(zapcore.WarnLevel)
+	logAll()
+	signalfxLogger.Warn("signalfx warn")
+	assert.Equal(t, []string{"signalfx warn"}, messages(logs.TakeAll()))
+}
+
+func TestControllerServeHTTP(t *testing.T) {
+	controller := NewController()
+
+	tests := []struct {
+		expected       map[string]any
+		name           string
+		method         string
+		query          string
+		remoteAddr     string
+		expectedStatus int
+	}{
+		{
+			name:           "get",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+			expected:       map[string]any{"level": "info", "components": map[any]any{}},
+		},
+		{
+			name:           "set_level",
+			method:         http.MethodPut,
+			query:          "level=warn",
+			expectedStatus: http.StatusOK,
+			expected:       map[string]any{"level": "warn", "components": map[any]any{}},
+		},
+		{
+			name:           "set_component_level",
+			method:         http.MethodPut,
+			query:          "component=signalfx&level=debug",
+			expectedStatus: http.StatusOK,
+			expected:       map[string]any{"level": "warn", "components": map[any]any{"signalfx": "debug"}},
+		},
+		{
+			name:           "invalid_level",
+			method:         http.MethodPut,
+			query:          "level=verbose",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "non_local_set_level",
+			method:         http.MethodPut,
+			query:          "level=debug",
+			remoteAddr:     "192.0.2.1:1234",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "reset_component_level",
+			method:         http.MethodDelete,
+			query:          "component=signalfx",
+			expectedStatus: http.StatusOK,
+			expected:       map[string]any{"level": "warn", "components": map[any]any{}},
+		},
+		{
+			name:           "reset_level",
+			method:         http.MethodDelete,
+			expectedStatus: http.StatusOK,
+			expected:       map[string]any{"level": "info", "components": map[any]any{}},
+		},
+		{
+			name:           "unsupported_method",
+			method:         http.MethodPost,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(tt.method, HandlerPath+"?"+tt.query, nil)
+			request.RemoteAddr = "127.0.0.1:1234"
+			if tt.remoteAddr != "" {
+				request.RemoteAddr = tt.remoteAddr
+			}
+			controller.ServeHTTP(recorder, request)
+
+			response := recorder.Result()
+			defer response.Body.Close()
+			require.Equal(t, tt.expectedStatus, response.StatusCode)
+			if tt.expected == nil {
+				return
+			}
+
+			body, err := io.ReadAll(response.Body)
+			require.NoError(t, err)
+			actual := map[string]any{}
+			require.NoError(t, yaml.Unmarshal(body, &actual))
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func messages(entries []observer.LoggedEntry) []string {
+	var msgs []string
+	for _, entry := range entries {
+		msgs = append(msgs, entry.Message)
+	}
+	return msgs
+}
@@ -32,6 +32,7 @@ const (
 	BallastEnvVar             = "SPLUNK_BALLAST_SIZE_MIB"
 	ConfigEnvVar              = "SPLUNK_CONFIG"
 	ConfigDirEnvVar           = "SPLUNK_CONFIG_DIR"
+	ConfigSourcePluginsEnvVar = "SPLUNK_CONFIG_SOURCE_PLUGINS_DIR"
 	ConfigServerEnabledEnvVar = "SPLUNK_DEBUG_CONFIG_SERVER"
 	ConfigYamlEnvVar          = "SPLUNK_CONFIG_YAML"
 	HecLogIngestURLEnvVar     = "SPLUNK_HEC_URL"
@@ -54,18 +55,42 @@ const (
 
 	DiscoveryModeScheme = "splunk.discovery"
 	ConfigDScheme       = "splunk.configd"
+
+	// ValidateCommand resolves the configuration, including its config sources, and validates
+	// the component configurations without running the service.
+	ValidateCommand = "validate"
+	// ConfigValidateCommand is the same command as ValidateCommand, in the "config" command group.
+	ConfigValidateCommand = "config validate"
+	// ConfigDiffCommand resolves the configuration and prints its differences with the one of the
+	// --diff-against URIs, or with the last configuration cached for the same URIs.
+	ConfigDiffCommand = "config diff"
+
+	// TextOutput is the default format of the result of the validate and diff commands.
+	TextOutput = "text"
+	// JSONOutput is the machine-readable format of the result of the validate and diff commands,
+	// e.g. for CI gates.
+	JSONOutput = "json"
 )
 
 type Settings struct {
 	configPaths     *stringArrayFlagValue
 	setProperties   *stringArrayFlagValue
 	configDir       *stringPointerFlagValue
+	featureGates    *stringArrayFlagValue
+	diffAgainst     *stringArrayFlagValue
 	colCoreArgs     []string
 	versionFlag     bool
 	noConvertConfig bool
 	configD         bool
 	discoveryMode   bool
 	dryRun          bool
+	validate        bool
+	diff            bool
+	configSnapshot  string
+	migrateSyntax   string
+	pluginsDir      string
+	k8sExport       string
+	output          string
 }
 
 func New(args []string) (*Settings, error) {
@@ -143,10 +168,7 @@ func (s *Settings) ConfMapConverters() []confmap.Converter {
 	if !s.noConvertConfig {
 		confMapConverters = append(
 			confMapConverters,
-			configconverter.RemoveBallastKey{},
-			configconverter.MoveOTLPInsecureKey{},
-			configconverter.MoveHecTLS{},
-			configconverter.RenameK8sTagger{},
+			configconverter.ConfigUpgrade{},
 		)
 	}
 	return confMapConverters
@@ -162,6 +184,63 @@ func (s *Settings) IsDryRun() bool {
 	return s.dryRun
 }
 
+// FeatureGates returns the values of the --feature-gates flags, these are applied by the collector
+// core service when it runs.
+func (s *Settings) FeatureGates() []string {
+	return s.featureGates.value
+}
+
+// IsValidate returns whether the validate command was requested, resolving and validating
+// the configuration without running the service.
+func (s *Settings) IsValidate() bool {
+	return s.validate
+}
+
+// IsDiff returns whether the config diff command was requested, printing the differences of the
+// configuration with another one without running the service.
+func (s *Settings) IsDiff() bool {
+	return s.diff
+}
+
+// DiffAgainstURIs returns the URIs of the configuration the config diff command compares the
+// configuration with, set by --diff-against, empty to compare it with the cached configuration.
+func (s *Settings) DiffAgainstURIs() []string {
+	return s.diffAgainst.value
+}
+
+// ValidateOutput returns the format of the result of the validate and diff commands set by
+// --output, TextOutput or JSONOutput.
+func (s *Settings) ValidateOutput() string {
+	return s.output
+}
+
+// ConfigSnapshotPath returns the path of the config snapshot requested by --generate-config-snapshot,
+// empty if it wasn't.
+func (s *Settings) ConfigSnapshotPath() string {
+	return s.configSnapshot
+}
+
+// MigrateConfigSyntaxPath returns the path of the migrated configuration requested by
+// --migrate-config-syntax, empty if it wasn't.
+func (s *Settings) MigrateConfigSyntaxPath() string {
+	return s.migrateSyntax
+}
+
+// K8sConfigExportTarget returns the "<secret|configmap>/<namespace>/<name>" Kubernetes object the
+// resolved configuration is written to, requested by --export-config-to-k8s, empty if it wasn't.
+func (s *Settings) K8sConfigExportTarget() string {
+	return s.k8sExport
+}
+
+// ConfigSourcePluginsDir returns the directory of the config source plugins set by
+// --config-source-plugins-dir or the SPLUNK_CONFIG_SOURCE_PLUGINS_DIR env var, empty if none.
+func (s *Settings) ConfigSourcePluginsDir() string {
+	if s.pluginsDir != "" {
+		return s.pluginsDir
+	}
+	return os.Getenv(ConfigSourcePluginsEnvVar)
+}
+
 // parseArgs returns new Settings instance from command line arguments.
 func parseArgs(args []string) (*Settings, error) {
 	flagSet := flag.NewFlagSet("otelcol", flag.ContinueOnError)
@@ -170,6 +249,8 @@ func parseArgs(args []string) (*Settings, error) {
 		configPaths:   new(stringArrayFlagValue),
 		setProperties: new(stringArrayFlagValue),
 		configDir:     new(stringPointerFlagValue),
+		featureGates:  new(stringArrayFlagValue),
+		diffAgainst:   new(stringArrayFlagValue),
 	}
 
 	flagSet.Var(settings.configPaths, "config", "Locations to the config file(s), "+
@@ -178,8 +259,25 @@ func parseArgs(args []string) (*Settings, error) {
 	flagSet.Var(settings.setProperties, "set", "Set arbitrary component config property. "+
 		"The component has to be defined in the config file and the flag has a higher precedence. "+
 		"Array config properties are overridden and maps are joined. Example --set=processors.batch.timeout=2s")
-	flagSet.BoolVar(&settings.dryRun, "dry-run", false, "Don't run the service, just show the configuration")
-	flagSet.MarkHidden("dry-run")
+	flagSet.BoolVar(&settings.dryRun, "dry-run", false, "Don't run the service, print the final configuration, "+
+		"discovered, resolved, and converted, with the sensitive values redacted.")
+	flagSet.StringVar(&settings.configSnapshot, "generate-config-snapshot", "",
+		"Don't run the service, write the values retrieved by the config sources to the given snapshot bundle "+
+			"used by the offline mode, see SPLUNK_CONFIG_SNAPSHOT.")
+	flagSet.StringVar(&settings.migrateSyntax, "migrate-config-syntax", "",
+		"Don't run the service, write the configuration with the legacy $<config source>:<selector> references "+
+			"rewritten to the ${<config source>:<selector>} form to the given file. Comments aren't kept.")
+	flagSet.StringVar(&settings.k8sExport, "export-config-to-k8s", "",
+		"Don't run the service, write the resolved configuration, with the values of its config sources, to the "+
+			"config.yaml key of the given <secret|configmap>/<namespace>/<name> Kubernetes object.")
+	flagSet.StringVar(&settings.pluginsDir, "config-source-plugins-dir", "",
+		"Directory of the otelcol-configsource-<type> go-plugin binaries providing additional config sources. "+
+			"Can also be set with the SPLUNK_CONFIG_SOURCE_PLUGINS_DIR env var.")
+	flagSet.StringVar(&settings.output, "output", TextOutput,
+		"Format of the result of the validate and config diff commands, text or json.")
+	flagSet.Var(settings.diffAgainst, "diff-against", "Locations of the config file(s) the config diff command "+
+		"compares the configuration with, like --config. By default the configuration last cached in "+
+		"SPLUNK_CONFIG_CACHE_FILE for the same --config locations.")
 	flagSet.BoolVar(&settings.noConvertConfig, "no-convert-config", false,
 		"Do not translate old configurations to the new format automatically. "+
 			"By default, old configurations are translated to the new format for backward compatibility.")
@@ -199,7 +297,7 @@ func parseArgs(args []string) (*Settings, error) {
 	// OTel Collector Core flags
 	colCoreFlags := []string{"version", "feature-gates"}
 	flagSet.BoolVarP(&settings.versionFlag, colCoreFlags[0], "v", false, "Version of the collector.")
-	flagSet.Var(new(stringArrayFlagValue), colCoreFlags[1],
+	flagSet.Var(settings.featureGates, colCoreFlags[1],
 		"Comma-delimited list of feature gate identifiers. Prefix with '-' to disable the feature. "+
 			"'+' or no prefix will enable the feature.")
 
@@ -207,6 +305,24 @@ func parseArgs(args []string) (*Settings, error) {
 		return nil, err
 	}
 
+	if commands := flagSet.Args(); len(commands) > 0 {
+		switch command := strings.Join(commands, " "); command {
+		case ValidateCommand, ConfigValidateCommand:
+			settings.validate = true
+		case ConfigDiffCommand:
+			settings.diff = true
+		default:
+			return nil, fmt.Errorf("unknown command %q, the supported commands are %q, %q, and %q",
+				command, ValidateCommand, ConfigValidateCommand, ConfigDiffCommand)
+		}
+	}
+	if len(settings.diffAgainst.value) > 0 && !settings.diff {
+		return nil, fmt.Errorf("--diff-against is only supported by the %q command", ConfigDiffCommand)
+	}
+	if settings.output != TextOutput && settings.output != JSONOutput {
+		return nil, fmt.Errorf("invalid --output %q, must be %q or %q", settings.output, TextOutput, JSONOutput)
+	}
+
 	// Pass flags that are handled by the collector core service as raw command line arguments.
 	settings.colCoreArgs = flagSetToArgs(colCoreFlags, flagSet)
 
@@ -385,6 +501,10 @@ func checkInputConfigs(settings *Settings) error {
 	configYaml := os.Getenv(ConfigYamlEnvVar)
 
 	for _, filePath := range settings.configPaths.value {
+		if isConfigURI(filePath) {
+			// Retrieved by the provider of the scheme, e.g. "etcd2://config/collector.yaml".
+			continue
+		}
 		if _, err := os.Stat(filePath); err != nil {
 			return fmt.Errorf("unable to find the configuration file %s, ensure flag '--config' is set properly: %w", filePath, err)
 		}
@@ -401,6 +521,25 @@ func checkInputConfigs(settings *Settings) error {
 	return confirmRequiredEnvVarsForDefaultConfigs(settings.configPaths.value)
 }
 
+// isConfigURI returns true if the config location is a "<scheme>:<opaque>" URI instead of a file path.
+// Like for the confmap resolver the scheme has at least two characters, not to match Windows drives.
+func isConfigURI(location string) bool {
+	scheme, _, ok := strings.Cut(location, ":")
+	if !ok || len(scheme) < 2 || !isAlpha(scheme[0]) {
+		return false
+	}
+	for i := 1; i < len(scheme); i++ {
+		if c := scheme[i]; !isAlpha(c) && !('0' <= c && c <= '9') && c != '+' && c != '.' && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlpha(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
 func checkConfigPathEnvVar(settings *Settings) error {
 	configPathVar := os.Getenv(ConfigEnvVar)
 	configYaml := os.Getenv(ConfigYamlEnvVar)
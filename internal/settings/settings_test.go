@@ -105,6 +105,7 @@ func TestNewSettingsNoConvertConfig(t *testing.T) {
 		configconverter.NewOverwritePropertiesConverter(settings.setProperties.value),
 	}, settings.ConfMapConverters())
 	require.Equal(t, []string{"--feature-gates", "foo", "--feature-gates", "-bar"}, settings.ColCoreArgs())
+	require.Equal(t, []string{"foo", "-bar"}, settings.FeatureGates())
 }
 
 func TestNewSettingsConvertConfig(t *testing.T) {
@@ -129,10 +130,7 @@ func TestNewSettingsConvertConfig(t *testing.T) {
 	require.Equal(t, []string{configPath, anotherConfigPath}, settings.ResolverURIs())
 	require.Equal(t, []confmap.Converter{
 		configconverter.NewOverwritePropertiesConverter(settings.setProperties.value),
-		configconverter.RemoveBallastKey{},
-		configconverter.MoveOTLPInsecureKey{},
-		configconverter.MoveHecTLS{},
-		configconverter.RenameK8sTagger{},
+		configconverter.ConfigUpgrade{},
 	}, settings.ConfMapConverters())
 	require.Equal(t, []string{"--feature-gates", "foo", "--feature-gates", "-bar"}, settings.ColCoreArgs())
 }
@@ -412,6 +410,132 @@ func TestEnablingConfigD(t *testing.T) {
 	require.Equal(t, "/etc/otel/collector/config.d", getConfigDir(settings))
 }
 
+func TestGenerateConfigSnapshot(t *testing.T) {
+	t.Cleanup(clearEnv(t))
+	settings, err := New([]string{"--config", configPath})
+	require.NoError(t, err)
+	require.Empty(t, settings.ConfigSnapshotPath())
+
+	settings, err = New([]string{"--config", configPath, "--generate-config-snapshot", "/tmp/snapshot.yaml"})
+	require.NoError(t, err)
+	require.Equal(t, "/tmp/snapshot.yaml", settings.ConfigSnapshotPath())
+}
+
+func TestConfigURIs(t *testing.T) {
+	t.Cleanup(clearEnv(t))
+	settings, err := New([]string{"--config", configPath, "--config", "etcd2://config/collector.yaml"})
+	require.NoError(t, err)
+	require.Equal(t, []string{configPath, "etcd2://config/collector.yaml"}, settings.ResolverURIs())
+
+	settings, err = New([]string{"--config", "/not/a/config.yaml"})
+	require.Error(t, err)
+	require.Nil(t, settings)
+
+	for location, isURI := range map[string]bool{
+		"etcd2://config/collector.yaml": true,
+		"vault:secret/data/app":         true,
+		"splunk.configd:/etc/config.d":  true,
+		"/etc/otel/config.yaml":         false,
+		`C:\otel\config.yaml`:           false,
+		"1env:VAR":                      false,
+		"config.yaml":                   false,
+	} {
+		require.Equal(t, isURI, isConfigURI(location), location)
+	}
+}
+
+func TestMigrateConfigSyntax(t *testing.T) {
+	t.Cleanup(clearEnv(t))
+	settings, err := New([]string{"--config", configPath})
+	require.NoError(t, err)
+	require.Empty(t, settings.MigrateConfigSyntaxPath())
+
+	settings, err = New([]string{"--config", configPath, "--migrate-config-syntax", "/tmp/migrated.yaml"})
+	require.NoError(t, err)
+	require.Equal(t, "/tmp/migrated.yaml", settings.MigrateConfigSyntaxPath())
+}
+
+func TestConfigSourcePluginsDir(t *testing.T) {
+	t.Cleanup(clearEnv(t))
+	settings, err := New([]string{"--config", configPath})
+	require.NoError(t, err)
+	require.Empty(t, settings.ConfigSourcePluginsDir())
+
+	require.NoError(t, os.Setenv(ConfigSourcePluginsEnvVar, "/opt/otel/plugins"))
+	require.Equal(t, "/opt/otel/plugins", settings.ConfigSourcePluginsDir())
+
+	settings, err = New([]string{"--config", configPath, "--config-source-plugins-dir", "/tmp/plugins"})
+	require.NoError(t, err)
+	require.Equal(t, "/tmp/plugins", settings.ConfigSourcePluginsDir())
+}
+
+func TestK8sConfigExportTarget(t *testing.T) {
+	t.Cleanup(clearEnv(t))
+	settings, err := New([]string{"--config", configPath})
+	require.NoError(t, err)
+	require.Empty(t, settings.K8sConfigExportTarget())
+
+	settings, err = New([]string{"--config", configPath, "--export-config-to-k8s", "secret/monitoring/collector-config"})
+	require.NoError(t, err)
+	require.Equal(t, "secret/monitoring/collector-config", settings.K8sConfigExportTarget())
+	require.Empty(t, settings.ColCoreArgs())
+}
+
+func TestConfigDiffCommand(t *testing.T) {
+	t.Cleanup(clearEnv(t))
+	settings, err := New([]string{"config", "diff", "--config", configPath})
+	require.NoError(t, err)
+	require.True(t, settings.IsDiff())
+	require.False(t, settings.IsValidate())
+	require.Empty(t, settings.DiffAgainstURIs())
+
+	settings, err = New([]string{"config", "diff", "--config", configPath, "--diff-against", "file:old.yaml", "--output", "json"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"file:old.yaml"}, settings.DiffAgainstURIs())
+	require.Equal(t, JSONOutput, settings.ValidateOutput())
+	require.Empty(t, settings.ColCoreArgs())
+
+	settings, err = New([]string{"--config", configPath, "--diff-against", "file:old.yaml"})
+	require.EqualError(t, err, `--diff-against is only supported by the "config diff" command`)
+	require.Nil(t, settings)
+}
+
+func TestValidateCommand(t *testing.T) {
+	t.Cleanup(clearEnv(t))
+	settings, err := New([]string{"--config", configPath})
+	require.NoError(t, err)
+	require.False(t, settings.IsValidate())
+
+	settings, err = New([]string{"validate", "--config", configPath})
+	require.NoError(t, err)
+	require.True(t, settings.IsValidate())
+	require.Empty(t, settings.ColCoreArgs())
+
+	settings, err = New([]string{"--config", configPath, "validate"})
+	require.NoError(t, err)
+	require.True(t, settings.IsValidate())
+
+	require.Equal(t, TextOutput, settings.ValidateOutput())
+
+	settings, err = New([]string{"config", "validate", "--config", configPath, "--output", "json"})
+	require.NoError(t, err)
+	require.True(t, settings.IsValidate())
+	require.Equal(t, JSONOutput, settings.ValidateOutput())
+	require.Empty(t, settings.ColCoreArgs())
+
+	settings, err = New([]string{"--config", configPath, "check"})
+	require.EqualError(t, err, `unknown command "check", the supported commands are "validate", "config validate", and "config diff"`)
+	require.Nil(t, settings)
+
+	settings, err = New([]string{"--config", configPath, "validate", "now"})
+	require.EqualError(t, err, `unknown command "validate now", the supported commands are "validate", "config validate", and "config diff"`)
+	require.Nil(t, settings)
+
+	settings, err = New([]string{"--config", configPath, "validate", "--output", "xml"})
+	require.EqualError(t, err, `invalid --output "xml", must be "text" or "json"`)
+	require.Nil(t, settings)
+}
+
 func TestConfigDirFromArgs(t *testing.T) {
 	t.Cleanup(clearEnv(t))
 	for _, args := range [][]string{
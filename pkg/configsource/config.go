@@ -0,0 +1,199 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configsource
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// SourceSettings defines common settings of a Source configuration.
+// Specific config sources can embed this struct and extend it with more fields if needed.
+// When embedded it must be with `mapstructure:",squash"` tag.
+type SourceSettings struct {
+	id component.ID `mapstructure:"-"`
+	// Cache configures the caching of the values retrieved by the config source. The
+	// values are cached by the config provider, so they are available to all config
+	// sources, and to the re-resolutions of the configuration, e.g. on reloads.
+	Cache CacheSettings `mapstructure:"cache"`
+	// RetrieveTimeout is the maximum time the config provider waits for each attempt
+	// to retrieve a value from the config source. The default value is 0, no timeout.
+	RetrieveTimeout time.Duration `mapstructure:"retrieve_timeout"`
+	// Retry configures the retries of the failed attempts to retrieve a value.
+	Retry RetrySettings `mapstructure:"retry"`
+	// CircuitBreaker configures the circuit breaker failing fast the retrieves from the
+	// config source after repeated failures.
+	CircuitBreaker CircuitBreakerSettings `mapstructure:"circuit_breaker"`
+	// Limits configures the maximum number of retrieves in progress, and the rate of the
+	// retrieves, reaching the config source, e.g. so a large configuration doesn't overwhelm
+	// its backend during the resolution.
+	Limits LimitSettings `mapstructure:"limits"`
+	// Redact controls if the values resolved from the config source are redacted wherever the
+	// effective configuration is reported. By default only the values of the config sources
+	// retrieving secrets are redacted, see SensitiveFactory.
+	Redact *bool `mapstructure:"redact"`
+}
+
+// CircuitBreakerSettings defines the circuit breaker of a config source. After the failure
+// threshold is reached the circuit opens and the retrieves fail immediately, or fall back to
+// the cached values, until the open duration expires and a retrieve is tried again.
+type CircuitBreakerSettings struct {
+	// FailureThreshold is the number of consecutive failed retrieves opening the circuit.
+	// The default value is 0, disabling the circuit breaker.
+	FailureThreshold int `mapstructure:"failure_threshold"`
+	// OpenDuration is the time the circuit stays open before a retrieve is tried again.
+	// The default value is 1m.
+	OpenDuration time.Duration `mapstructure:"open_duration"`
+}
+
+// Validate checks that the circuit breaker settings aren't negative.
+func (c CircuitBreakerSettings) Validate() error {
+	if c.FailureThreshold < 0 || c.OpenDuration < 0 {
+		return errors.New("circuit_breaker failure_threshold and open_duration can't be negative")
+	}
+	return nil
+}
+
+// LimitSettings defines the limits of the retrieves reaching a config source, the retrieves
+// exceeding them wait. The retries of the failed retrieves are also limited.
+type LimitSettings struct {
+	// MaxInFlight is the maximum number of retrieves in progress at the same time. The default
+	// value is 0, no limit.
+	MaxInFlight int `mapstructure:"max_in_flight"`
+	// RequestsPerSecond is the maximum rate of the retrieves. The default value is 0, no limit.
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+}
+
+// Validate checks that the limit settings aren't negative.
+func (l LimitSettings) Validate() error {
+	if l.MaxInFlight < 0 || l.RequestsPerSecond < 0 {
+		return errors.New("limits max_in_flight and requests_per_second can't be negative")
+	}
+	return nil
+}
+
+// RetrySettings defines the retries, with exponential backoff, of the failed retrieves
+// of a config source.
+type RetrySettings struct {
+	// MaxRetries is the number of times a failed retrieve is retried. The default
+	// value is 0, disabling the retries.
+	MaxRetries int `mapstructure:"max_retries"`
+	// InitialInterval is the time to wait before the first retry. The default value is 1s.
+	InitialInterval time.Duration `mapstructure:"initial_interval"`
+	// MaxInterval is the maximum time to wait between retries. The default value is 30s.
+	MaxInterval time.Duration `mapstructure:"max_interval"`
+	// Multiplier is the factor by which the interval grows after each retry. The
+	// default value is 2.
+	Multiplier float64 `mapstructure:"multiplier"`
+}
+
+// Validate checks that the retry settings aren't negative.
+func (r RetrySettings) Validate() error {
+	if r.MaxRetries < 0 || r.InitialInterval < 0 || r.MaxInterval < 0 {
+		return errors.New("retry max_retries, initial_interval, and max_interval can't be negative")
+	}
+	if r.Multiplier != 0 && r.Multiplier < 1 {
+		return errors.New("retry multiplier must be at least 1")
+	}
+	return nil
+}
+
+// CacheSettings defines the caching of the values retrieved by a config source.
+type CacheSettings struct {
+	// TTL is the time during which a retrieved value is reused for the same invocation
+	// instead of retrieving it again. The default value is 0, disabling the cache.
+	// Values reused from the cache aren't watched for updates.
+	TTL time.Duration `mapstructure:"ttl"`
+	// StaleIfError is the time, after the TTL expired, during which a cached value is
+	// still used if the config source fails to retrieve it again, e.g. during a transient
+	// outage of its backend. The default value is 0, returning the error.
+	StaleIfError time.Duration `mapstructure:"stale_if_error"`
+}
+
+// Enabled reports if the values are cached.
+func (c CacheSettings) Enabled() bool {
+	return c.TTL > 0 || c.StaleIfError > 0
+}
+
+// Validate checks that the cache durations aren't negative.
+func (c CacheSettings) Validate() error {
+	if c.TTL < 0 || c.StaleIfError < 0 {
+		return errors.New("cache ttl and stale_if_error can't be negative")
+	}
+	return nil
+}
+
+// ID returns the ID of the component that this configuration belongs to.
+func (s *SourceSettings) ID() component.ID {
+	return s.id
+}
+
+// SetIDName updates the name part of the ID for the component that this configuration belongs to.
+func (s *SourceSettings) SetIDName(idName string) {
+	s.id = component.NewIDWithName(s.id.Type(), idName)
+}
+
+// NewSourceSettings return a new config.SourceSettings struct with the given ComponentID.
+func NewSourceSettings(id component.ID) SourceSettings {
+	return SourceSettings{id: id}
+}
+
+// CacheSettings returns the cache settings of the config source.
+func (s *SourceSettings) CacheSettings() CacheSettings {
+	return s.Cache
+}
+
+// RetrieveSettings returns the timeout and retry settings of the config source.
+func (s *SourceSettings) RetrieveSettings() (time.Duration, RetrySettings) {
+	return s.RetrieveTimeout, s.Retry
+}
+
+// CircuitBreakerSettings returns the circuit breaker settings of the config source.
+func (s *SourceSettings) CircuitBreakerSettings() CircuitBreakerSettings {
+	return s.CircuitBreaker
+}
+
+// LimitSettings returns the limit settings of the config source.
+func (s *SourceSettings) LimitSettings() LimitSettings {
+	return s.Limits
+}
+
+// RedactSettings returns the redact setting of the config source, nil if not set.
+func (s *SourceSettings) RedactSettings() *bool {
+	return s.Redact
+}
+
+// Source is the configuration of a config source. Specific config sources must implement this
+// interface and will typically embed SourceSettings struct or a struct that extends it.
+type Source interface {
+	// TODO: While config sources are experimental and not in the config package they can't
+	//	 reference the private interfaces config.identifiable and config.validatable.
+	//   Defining the required methods of the interfaces temporarily here.
+
+	// From config.identifiable:
+
+	// ID returns the ID of the component that this configuration belongs to.
+	ID() component.ID
+	// SetIDName updates the name part of the ID for the component that this configuration belongs to.
+	SetIDName(idName string)
+
+	// From config.validatable:
+
+	// Validate validates the configuration and returns an error if invalid.
+	Validate() error
+}
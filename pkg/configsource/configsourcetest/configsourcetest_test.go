@@ -0,0 +1,94 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configsourcetest
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+func TestMockSourceGolden(t *testing.T) {
+	source := NewMockSource(map[string]any{
+		"token":    "abc",
+		"endpoint": map[string]any{"host": "localhost", "port": int64(8080)},
+	})
+	RunGolden(t, source, filepath.Join("testdata", "golden.yaml"))
+
+	assert.Equal(t, []Retrieve{
+		{Selector: "token"},
+		{Selector: "endpoint", Params: map[string]any{"version": 2}},
+		{Selector: "token", Optional: true},
+		{Selector: "missing"},
+	}, source.Retrieves())
+}
+
+func TestMockSourceWatch(t *testing.T) {
+	source := NewMockSource(map[string]any{"token": "abc"})
+
+	var events []*confmap.ChangeEvent
+	watcher := func(event *confmap.ChangeEvent) {
+		events = append(events, event)
+	}
+	retrieved, err := source.Retrieve(context.Background(), "token", nil, watcher)
+	require.NoError(t, err)
+
+	source.Update("token", "def")
+	require.Len(t, events, 1)
+	assert.NoError(t, events[0].Error)
+
+	// The watchers are notified once.
+	source.Update("token", "ghi")
+	require.Len(t, events, 1)
+	require.NoError(t, retrieved.Close(context.Background()))
+
+	retrieved, err = source.Retrieve(context.Background(), "token", nil, watcher)
+	require.NoError(t, err)
+	value, err := retrieved.AsRaw()
+	require.NoError(t, err)
+	assert.Equal(t, "ghi", value)
+
+	source.Fail("token", errors.New("backend unavailable"))
+	require.Len(t, events, 2)
+	assert.EqualError(t, events[1].Error, "backend unavailable")
+
+	// Closed watches aren't notified.
+	retrieved, err = source.Retrieve(context.Background(), "token", nil, watcher)
+	require.NoError(t, err)
+	require.NoError(t, retrieved.Close(context.Background()))
+	source.Update("token", "jkl")
+	require.Len(t, events, 2)
+
+	source.ErrOnRetrieve = errors.New("retrieve failed")
+	_, err = source.Retrieve(context.Background(), "token", nil, nil)
+	assert.EqualError(t, err, "retrieve failed")
+}
+
+func TestMockFactory(t *testing.T) {
+	source := NewMockSource(nil)
+	factory := NewMockFactory("mock", source)
+	CheckFactory(t, factory)
+
+	cfg := LoadConfig(t, factory, "named", map[string]any{"retrieve_timeout": "1s"})
+	assert.Equal(t, component.NewIDWithName("mock", "named"), cfg.ID())
+	assert.Same(t, source, CreateConfigSource(t, factory, cfg))
+}
@@ -0,0 +1,67 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configsourcetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/pkg/configsource"
+)
+
+// CheckFactory checks that the default configuration of the factory has the type of the
+// factory, is valid, and is kept when an empty configuration is loaded.
+func CheckFactory(t testing.TB, factory configsource.Factory) {
+	cfg := factory.CreateDefaultConfig()
+	require.NotNil(t, cfg)
+	assert.Equal(t, factory.Type(), cfg.ID().Type())
+	assert.NoError(t, cfg.Validate())
+
+	loaded := LoadConfig(t, factory, "", nil)
+	assert.Equal(t, cfg, loaded)
+}
+
+// LoadConfig loads the settings of a config source named name, empty for none, on top of the
+// default configuration of the factory, like the collector does for the config_sources section.
+func LoadConfig(t testing.TB, factory configsource.Factory, name string, settings map[string]any) configsource.Source {
+	cfg := factory.CreateDefaultConfig()
+	cfg.SetIDName(name)
+	require.NoError(t, confmap.NewFromStringMap(settings).Unmarshal(&cfg, confmap.WithErrorUnused()))
+	require.NoError(t, cfg.Validate())
+	return cfg
+}
+
+// CreateConfigSource creates a config source with the factory, failing the test on error. The
+// config source is shut down when the test completes.
+func CreateConfigSource(t testing.TB, factory configsource.Factory, cfg configsource.Source) configsource.ConfigSource {
+	params := configsource.CreateParams{
+		Logger:    zap.NewNop(),
+		BuildInfo: component.NewDefaultBuildInfo(),
+	}
+	source, err := factory.CreateConfigSource(context.Background(), params, cfg)
+	require.NoError(t, err)
+	require.NotNil(t, source)
+	t.Cleanup(func() {
+		assert.NoError(t, source.Shutdown(context.Background()))
+	})
+	return source
+}
@@ -0,0 +1,100 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configsourcetest
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/signalfx/splunk-otel-collector/pkg/configsource"
+)
+
+// GoldenCase is a case of a golden file, see RunGolden.
+type GoldenCase struct {
+	// Value is the expected value, ignored if Error is set.
+	Value any `yaml:"value"`
+	// Params of the retrieve, none if empty.
+	Params map[string]any `yaml:"params"`
+	Name   string         `yaml:"name"`
+	// Selector of the retrieve.
+	Selector string `yaml:"selector"`
+	// Error is a substring of the expected error, if any.
+	Error string `yaml:"error"`
+	// Optional makes the retrieve optional, see configsource.IsOptional.
+	Optional bool `yaml:"optional"`
+}
+
+// RunGolden runs the cases of the golden YAML file as subtests, retrieving their selectors from
+// the source and comparing the values, or the errors, with the expected ones. The file lists the
+// cases under the "cases" key:
+//
+//	cases:
+//	  - name: token
+//	    selector: secret/token
+//	    params:
+//	      version: 2
+//	    value: abc
+//	  - name: missing
+//	    selector: secret/missing
+//	    error: not found
+//
+// The values are compared once encoded in YAML, so the types of the numbers and maps returned
+// by the source don't need to match the ones decoded from the file.
+func RunGolden(t *testing.T, source configsource.ConfigSource, path string) {
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var golden struct {
+		Cases []GoldenCase `yaml:"cases"`
+	}
+	require.NoError(t, yaml.Unmarshal(content, &golden))
+	require.NotEmpty(t, golden.Cases, "no cases in %s", path)
+
+	for _, tc := range golden.Cases {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.Optional {
+				ctx = configsource.ContextWithOptional(ctx)
+			}
+			var params *confmap.Conf
+			if len(tc.Params) > 0 {
+				params = confmap.NewFromStringMap(tc.Params)
+			}
+
+			retrieved, err := source.Retrieve(ctx, tc.Selector, params, nil)
+			if tc.Error != "" {
+				require.ErrorContains(t, err, tc.Error)
+				return
+			}
+			require.NoError(t, err)
+			value, err := retrieved.AsRaw()
+			require.NoError(t, err)
+			assert.NoError(t, retrieved.Close(ctx))
+
+			expected, err := yaml.Marshal(tc.Value)
+			require.NoError(t, err)
+			actual, err := yaml.Marshal(value)
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), string(actual))
+		})
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package configsourcetest helps to unit test config sources and the code using them.
+package configsourcetest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/signalfx/splunk-otel-collector/pkg/configsource"
+)
+
+var _ configsource.ConfigSource = (*MockSource)(nil)
+
+// MockSource is a config source retrieving the values set on it. The retrieved values are
+// watched, Update and Fail notify their watchers.
+type MockSource struct {
+	values    map[string]any
+	watchers  map[string]map[int]confmap.WatcherFunc
+	retrieves []Retrieve
+	nextID    int
+	mutex     sync.Mutex
+
+	// ErrOnRetrieve, if set, is returned by Retrieve.
+	ErrOnRetrieve error
+	// ErrOnShutdown, if set, is returned by Shutdown.
+	ErrOnShutdown error
+}
+
+// Retrieve is a call to MockSource.Retrieve.
+type Retrieve struct {
+	// Params are the params of the call, nil if there are none.
+	Params   map[string]any
+	Selector string
+	Optional bool
+}
+
+// NewMockSource returns a MockSource retrieving the values by selector.
+func NewMockSource(values map[string]any) *MockSource {
+	m := &MockSource{
+		values:   make(map[string]any, len(values)),
+		watchers: map[string]map[int]confmap.WatcherFunc{},
+	}
+	for selector, value := range values {
+		m.values[selector] = value
+	}
+	return m
+}
+
+func (m *MockSource) Retrieve(ctx context.Context, selector string, paramsConfigMap *confmap.Conf, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	retrieve := Retrieve{Selector: selector, Optional: configsource.IsOptional(ctx)}
+	if paramsConfigMap != nil {
+		retrieve.Params = paramsConfigMap.ToStringMap()
+	}
+	m.retrieves = append(m.retrieves, retrieve)
+
+	if m.ErrOnRetrieve != nil {
+		return nil, m.ErrOnRetrieve
+	}
+	value, ok := m.values[selector]
+	if !ok {
+		return nil, configsource.NotFoundError(fmt.Errorf("no value for selector %q", selector))
+	}
+	if watcher == nil {
+		return confmap.NewRetrieved(value)
+	}
+
+	id := m.nextID
+	m.nextID++
+	if m.watchers[selector] == nil {
+		m.watchers[selector] = map[int]confmap.WatcherFunc{}
+	}
+	m.watchers[selector][id] = watcher
+	return confmap.NewRetrieved(value, confmap.WithRetrievedClose(func(context.Context) error {
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		delete(m.watchers[selector], id)
+		return nil
+	}))
+}
+
+func (m *MockSource) Shutdown(context.Context) error {
+	return m.ErrOnShutdown
+}
+
+// Update sets the value of the selector and notifies its watchers of the change.
+func (m *MockSource) Update(selector string, value any) {
+	m.mutex.Lock()
+	m.values[selector] = value
+	m.mutex.Unlock()
+	m.notify(selector, nil)
+}
+
+// Fail notifies the watchers of the selector of the error.
+func (m *MockSource) Fail(selector string, err error) {
+	m.notify(selector, err)
+}
+
+// Retrieves returns the calls to Retrieve, in order.
+func (m *MockSource) Retrieves() []Retrieve {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]Retrieve(nil), m.retrieves...)
+}
+
+// notify calls the watchers of the selector once, as expected by the config provider.
+func (m *MockSource) notify(selector string, err error) {
+	m.mutex.Lock()
+	watchers := m.watchers[selector]
+	delete(m.watchers, selector)
+	m.mutex.Unlock()
+
+	for _, watcher := range watchers {
+		watcher(&confmap.ChangeEvent{Error: err})
+	}
+}
+
+// MockConfig is the configuration of the config sources created by NewMockFactory.
+type MockConfig struct {
+	configsource.SourceSettings `mapstructure:",squash"`
+}
+
+func (*MockConfig) Validate() error {
+	return nil
+}
+
+type mockFactory struct {
+	source configsource.ConfigSource
+	typ    component.Type
+}
+
+// NewMockFactory returns a factory of the type creating the source, e.g. a MockSource, for
+// every configuration.
+func NewMockFactory(typ component.Type, source configsource.ConfigSource) configsource.Factory {
+	return &mockFactory{typ: typ, source: source}
+}
+
+func (f *mockFactory) Type() component.Type {
+	return f.typ
+}
+
+func (f *mockFactory) CreateDefaultConfig() configsource.Source {
+	return &MockConfig{SourceSettings: configsource.NewSourceSettings(component.NewID(f.typ))}
+}
+
+func (f *mockFactory) CreateConfigSource(context.Context, configsource.CreateParams, configsource.Source) (configsource.ConfigSource, error) {
+	return f.source, nil
+}
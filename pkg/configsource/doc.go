@@ -0,0 +1,22 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package configsource defines the interfaces implemented by the config sources of the Splunk
+// distribution of the OpenTelemetry Collector, the built-in ones and the ones built by third
+// parties. A config source is made of a Factory, creating the default Source configuration and
+// the ConfigSource retrieving the values injected in the collector configuration.
+//
+// The configsourcetest package provides mock config sources and helpers to unit test them.
+package configsource
@@ -0,0 +1,54 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configsource
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// ErrNotFound is matched, via errors.Is, by the errors returned by config sources when the
+// selected value doesn't exist, as opposed to failing to retrieve it, e.g. because of an
+// authentication failure or a timeout. Only these errors make the config provider fall back
+// to the default value, or nil for optional invocations, of a config source reference.
+var ErrNotFound = errors.New("value not found")
+
+// NotFoundError returns an error with the same message as err that is matched by ErrNotFound.
+func NotFoundError(err error) error {
+	return &notFoundError{err: err}
+}
+
+type notFoundError struct {
+	err error
+}
+
+func (e *notFoundError) Error() string {
+	return e.err.Error()
+}
+
+func (e *notFoundError) Unwrap() error {
+	return e.err
+}
+
+func (e *notFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// IsNotFound reports if err means that the value doesn't exist: it matches ErrNotFound or,
+// for the config sources reading files, fs.ErrNotExist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, fs.ErrNotExist)
+}
@@ -0,0 +1,38 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configsource
+
+import "context"
+
+// OptionalParam is the parameter, handled by the config provider for every config source,
+// that makes an invocation resolve to nil instead of failing if the value can't be retrieved.
+const OptionalParam = "optional"
+
+type optionalCtxKey struct{}
+
+// ContextWithOptional returns a copy of ctx marking the retrieve as optional.
+func ContextWithOptional(ctx context.Context) context.Context {
+	return context.WithValue(ctx, optionalCtxKey{}, true)
+}
+
+// IsOptional reports if the retrieve using ctx was invoked with the optional parameter. Config
+// sources can use it to handle missing values themselves, e.g. to resolve them to an empty map
+// and watch for their creation, instead of failing and letting the config provider resolve
+// them to nil.
+func IsOptional(ctx context.Context) bool {
+	optional, _ := ctx.Value(optionalCtxKey{}).(bool)
+	return optional
+}
@@ -0,0 +1,133 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package distro exposes the config provider of the Splunk distribution, with its config sources,
+// converters, and discovery providers, so custom distributions can embed them in their
+// otelcol.CollectorSettings.
+package distro
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/provider/envprovider"
+	"go.opentelemetry.io/collector/confmap/provider/fileprovider"
+	"go.opentelemetry.io/collector/confmap/provider/httpprovider"
+	"go.opentelemetry.io/collector/otelcol"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configconverter"
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+	"github.com/signalfx/splunk-otel-collector/internal/configsources"
+	"github.com/signalfx/splunk-otel-collector/internal/confmapprovider/discovery"
+	"github.com/signalfx/splunk-otel-collector/pkg/configsource"
+)
+
+// Hook is notified about the lifecycle of the config provider, e.g. to report the health of the
+// config sources or to refresh the configuration.
+type Hook = configprovider.Hook
+
+// ConfigProviderSettings are the settings of the config provider created by NewConfigProvider.
+type ConfigProviderSettings struct {
+	// BuildInfo is passed to the config sources when they are created.
+	BuildInfo component.BuildInfo
+	// URIs are the locations of the configuration, e.g. the "--config" flag values.
+	URIs []string
+	// ConfigSources are the factories of the config sources that can be declared in the
+	// configuration, the ones returned by ConfigSources if nil.
+	ConfigSources []configsource.Factory
+	// Converters are applied to the retrieved configuration, the ones returned by
+	// DefaultConverters if nil.
+	Converters []confmap.Converter
+	// Hooks are notified about the lifecycle of the config provider.
+	Hooks []Hook
+	// Logger is the logger of the config sources, a nop logger by default since the service
+	// logger isn't available before the configuration is resolved.
+	Logger *zap.Logger
+}
+
+// ConfigSources returns the factories of the config sources built in the Splunk distribution.
+func ConfigSources() []configsource.Factory {
+	return configsources.Get()
+}
+
+// DefaultConverters returns the converters upgrading the deprecated configurations.
+func DefaultConverters() []confmap.Converter {
+	return []confmap.Converter{configconverter.ConfigUpgrade{}}
+}
+
+// NewConfigProvider creates the config provider of the Splunk distribution. The configuration is
+// retrieved from the env, file, http, discovery, and config source schemes, and its config sources
+// and "${env:VAR}" and "${file:path}" references are resolved in a single pass. The URIs are merged
+// in order whatever their schemes, the keys of the later URIs taking precedence, and the config
+// sources declared by a URI can be used by the following ones.
+func NewConfigProvider(settings ConfigProviderSettings) (otelcol.ConfigProvider, error) {
+	if settings.ConfigSources == nil {
+		settings.ConfigSources = ConfigSources()
+	}
+	if settings.Converters == nil {
+		settings.Converters = DefaultConverters()
+	}
+	if settings.Logger == nil {
+		settings.Logger = zap.NewNop()
+	}
+
+	discoveryProvider, err := discovery.New()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery provider: %w", err)
+	}
+
+	envProvider := envprovider.New()
+	fileProvider := fileprovider.New()
+	httpProvider := httpprovider.New()
+	providerOptions := configprovider.Options{
+		Logger:    settings.Logger,
+		BuildInfo: settings.BuildInfo,
+		Hooks:     settings.Hooks,
+		SchemeProviders: map[string]confmap.Provider{
+			envProvider.Scheme():  envProvider,
+			fileProvider.Scheme(): fileProvider,
+		},
+		Factories: settings.ConfigSources,
+		// The URIs of all the schemes are merged in order before their config sources are resolved.
+		URIMerger: configprovider.NewURIMerger(),
+	}
+	providers := map[string]confmap.Provider{
+		discoveryProvider.ConfigDScheme():       configprovider.New(discoveryProvider.ConfigDProvider(), providerOptions),
+		discoveryProvider.DiscoveryModeScheme(): configprovider.New(discoveryProvider.DiscoveryModeProvider(), providerOptions),
+		envProvider.Scheme():                    configprovider.New(envProvider, providerOptions),
+		fileProvider.Scheme():                   configprovider.New(fileProvider, providerOptions),
+		httpProvider.Scheme():                   configprovider.New(httpProvider, providerOptions),
+	}
+	// The config sources also retrieve the configuration, e.g. "--config=etcd2://config/collector.yaml".
+	for scheme, sourceProvider := range configprovider.NewSourceProviders(providerOptions) {
+		if _, ok := providers[scheme]; !ok {
+			providers[scheme] = configprovider.New(sourceProvider, providerOptions)
+		}
+	}
+
+	provider, err := otelcol.NewConfigProvider(otelcol.ConfigProviderSettings{
+		ResolverSettings: confmap.ResolverSettings{
+			URIs:       settings.URIs,
+			Providers:  providers,
+			Converters: settings.Converters,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return configprovider.NewRollbackConfigProvider(provider, settings.Hooks...), nil
+}
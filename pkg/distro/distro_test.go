@@ -0,0 +1,82 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distro
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/otelcol/otelcoltest"
+)
+
+type recordingHook struct {
+	schemes []string
+}
+
+func (h *recordingHook) OnNew() {}
+
+func (h *recordingHook) OnRetrieve(scheme string, _ map[string]any) {
+	h.schemes = append(h.schemes, scheme)
+}
+
+func (h *recordingHook) OnShutdown() {}
+
+func TestNewConfigProvider(t *testing.T) {
+	t.Setenv("DISTRO_TEST_METRICS_ADDRESS", "localhost:9999")
+	hook := &recordingHook{}
+	provider, err := NewConfigProvider(ConfigProviderSettings{
+		URIs:  []string{"file:" + filepath.Join("testdata", "config.yaml")},
+		Hooks: []Hook{hook},
+	})
+	require.NoError(t, err)
+
+	factories, err := otelcoltest.NopFactories()
+	require.NoError(t, err)
+	cfg, err := provider.Get(context.Background(), factories)
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:9999", cfg.Service.Telemetry.Metrics.Address)
+	assert.Equal(t, []string{"file"}, hook.schemes)
+	require.NoError(t, provider.Shutdown(context.Background()))
+}
+
+func TestNewConfigProviderMergesSchemes(t *testing.T) {
+	t.Setenv("DISTRO_TEST_METRICS_ADDRESS", "localhost:9999")
+	t.Setenv("DISTRO_TEST_OVERRIDES", "service: {telemetry: {metrics: {address: localhost:8888}}}")
+	provider, err := NewConfigProvider(ConfigProviderSettings{
+		URIs: []string{
+			"file:" + filepath.Join("testdata", "config.yaml"),
+			"env:DISTRO_TEST_OVERRIDES",
+		},
+	})
+	require.NoError(t, err)
+
+	factories, err := otelcoltest.NopFactories()
+	require.NoError(t, err)
+	cfg, err := provider.Get(context.Background(), factories)
+	require.NoError(t, err)
+	// The later env URI takes precedence over the file one.
+	assert.Equal(t, "localhost:8888", cfg.Service.Telemetry.Metrics.Address)
+	assert.Len(t, cfg.Service.Pipelines, 1)
+	require.NoError(t, provider.Shutdown(context.Background()))
+}
+
+func TestDefaults(t *testing.T) {
+	assert.NotEmpty(t, ConfigSources())
+	assert.Len(t, DefaultConverters(), 1)
+}
@@ -24,11 +24,12 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
 
 	"github.com/signalfx/splunk-otel-collector/tests/testutils"
 )
 
-func TestDryRunDoesntExpandEnvVars(t *testing.T) {
+func TestDryRunPrintsResolvedConfig(t *testing.T) {
 	tc := testutils.NewTestcase(t)
 	defer tc.PrintLogsOnFailure()
 	defer tc.ShutdownOTLPReceiverSink()
@@ -83,9 +84,18 @@ service:
 	dryRun, err := io.ReadAll(reader)
 	require.NoError(t, err)
 	require.True(t, len(dryRun) >= 8)
-	require.Equal(t, config, string(dryRun[8:len(dryRun)-1])) // strip leading control character
+	resolved := map[string]any{}
+	require.NoError(t, yaml.Unmarshal(dryRun[8:], &resolved)) // strip leading control character
 	require.Zero(t, sc)
 
+	// The config sources and env vars are resolved and the config_sources section removed.
+	require.NotContains(t, resolved, "config_sources")
+	receivers := resolved["receivers"].(map[any]any)
+	require.Equal(t, "1s", receivers["hostmetrics"].(map[any]any)["collection_interval"])
+	pipeline := resolved["service"].(map[any]any)["pipelines"].(map[any]any)["metrics"].(map[any]any)
+	require.Equal(t, []any{"otlp"}, pipeline["exporters"])
+	require.Equal(t, []any{"hostmetrics"}, pipeline["receivers"])
+
 	// confirm successful service functionality
 	sc, _, err = c.Container.Exec(ctx, []string{"bash", "-c", "/otelcol &"})
 	assert.NoError(t, err)